@@ -0,0 +1,177 @@
+// Package agent defines the agents headjack can start in a session: how to
+// launch them, how they take a prompt, which credentials they use, and what
+// must be set up in the container before they run.
+//
+// The built-in agents (claude, gemini, codex) are ordinary definitions.
+// Custom agents from configuration are registered alongside them and may
+// also override a built-in.
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+)
+
+// Errors returned by the registry.
+var (
+	ErrUnknownAgent      = errors.New("unknown agent")
+	ErrInvalidDefinition = errors.New("invalid agent definition")
+)
+
+// namePattern matches valid agent names. Names are used in session types,
+// config keys, and file names, so they are kept simple.
+var namePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+
+// PromptStyle describes how an agent receives its initial prompt.
+type PromptStyle string
+
+// PromptStyle constants.
+const (
+	PromptArg  PromptStyle = "arg"  // Appended as the last argument (default)
+	PromptFlag PromptStyle = "flag" // Passed as the value of PromptFlag
+	PromptNone PromptStyle = "none" // Not accepted; the prompt is ignored
+)
+
+// Definition describes an agent.
+type Definition struct {
+	Name       string
+	Command    []string          // Command and arguments that start the agent
+	Prompt     PromptStyle       // How the prompt is passed (empty = PromptArg)
+	PromptFlag string            // Flag preceding the prompt when Prompt is PromptFlag
+	Env        map[string]string // Environment variables set in every session
+	Auth       string            // Name of the auth provider whose credential is injected (empty = none)
+
+	// Setup is a shell script run in the container before every session.
+	Setup string
+
+	// AuthSetup is a shell script run in the container before a session
+	// whose credential must be written to files, with the credential's
+	// environment variables set.
+	AuthSetup string
+
+	// HeadlessArgs follow Command to run the agent headless, streaming its
+	// progress as JSON lines; the prompt is appended after them.
+	HeadlessArgs []string
+
+	// TranscriptFormat names the format of the headless agent's JSON events
+	// (claude, codex, or gemini; empty = no transcript mode).
+	TranscriptFormat string
+
+	// UsageFiles is a shell glob of the session files the agent writes,
+	// relative to $HOME (empty = no usage accounting).
+	UsageFiles string
+
+	// UsageFormat names the format of the session files (claude, codex, or
+	// gemini).
+	UsageFormat string
+}
+
+// Validate checks that the definition can be used to start an agent.
+func (d *Definition) Validate() error {
+	if !ValidName(d.Name) {
+		return fmt.Errorf("%w: invalid name %q (use lowercase letters, digits, '-' and '_')", ErrInvalidDefinition, d.Name)
+	}
+	if len(d.Command) == 0 || d.Command[0] == "" {
+		return fmt.Errorf("%w: %s has no command", ErrInvalidDefinition, d.Name)
+	}
+	switch d.Prompt {
+	case "", PromptArg, PromptNone:
+	case PromptFlag:
+		if d.PromptFlag == "" {
+			return fmt.Errorf("%w: %s passes the prompt by flag but sets no prompt flag", ErrInvalidDefinition, d.Name)
+		}
+	default:
+		return fmt.Errorf("%w: %s has unknown prompt style %q (valid: arg, flag, none)", ErrInvalidDefinition, d.Name, d.Prompt)
+	}
+	return nil
+}
+
+// BuildCommand returns the command that starts the agent with an optional prompt.
+func (d *Definition) BuildCommand(prompt string) []string {
+	cmd := slices.Clone(d.Command)
+	if prompt == "" {
+		return cmd
+	}
+	switch d.Prompt {
+	case PromptNone:
+		return cmd
+	case PromptFlag:
+		return append(cmd, d.PromptFlag, prompt)
+	default:
+		return append(cmd, prompt)
+	}
+}
+
+// BuildHeadlessCommand returns the command that runs the agent headless on a
+// prompt.
+func (d *Definition) BuildHeadlessCommand(prompt string) []string {
+	cmd := slices.Concat(d.Command, d.HeadlessArgs)
+	return append(cmd, prompt)
+}
+
+// ValidName reports whether name can be used as an agent name.
+func ValidName(name string) bool {
+	return namePattern.MatchString(name)
+}
+
+// Registry holds the agents that can be started.
+type Registry struct {
+	defs  map[string]*Definition
+	order []string
+}
+
+// DefaultRegistry returns a registry of the built-in agents.
+func DefaultRegistry() *Registry {
+	r := &Registry{defs: make(map[string]*Definition)}
+	for _, def := range Builtins() {
+		r.add(def)
+	}
+	return r
+}
+
+// NewRegistry returns a registry of the built-in agents plus custom, which
+// replace built-ins of the same name.
+func NewRegistry(custom ...Definition) (*Registry, error) {
+	r := DefaultRegistry()
+
+	// Register custom agents in a stable order after the built-ins
+	sorted := slices.Clone(custom)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	for i := range sorted {
+		if err := sorted[i].Validate(); err != nil {
+			return nil, err
+		}
+		r.add(sorted[i])
+	}
+	return r, nil
+}
+
+func (r *Registry) add(def Definition) {
+	if _, exists := r.defs[def.Name]; !exists {
+		r.order = append(r.order, def.Name)
+	}
+	r.defs[def.Name] = &def
+}
+
+// Get returns the definition of an agent.
+func (r *Registry) Get(name string) (*Definition, error) {
+	def, ok := r.defs[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownAgent, name)
+	}
+	return def, nil
+}
+
+// Has reports whether an agent is registered.
+func (r *Registry) Has(name string) bool {
+	_, ok := r.defs[name]
+	return ok
+}
+
+// Names returns the registered agent names, built-ins first.
+func (r *Registry) Names() []string {
+	return slices.Clone(r.order)
+}
@@ -0,0 +1,113 @@
+package agent
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefinition_BuildCommand(t *testing.T) {
+	tests := []struct {
+		name   string
+		def    Definition
+		prompt string
+		want   []string
+	}{
+		{"no prompt", Definition{Command: []string{"claude"}}, "", []string{"claude"}},
+		{"prompt as argument", Definition{Command: []string{"claude"}}, "fix it", []string{"claude", "fix it"}},
+		{"prompt by flag", Definition{Command: []string{"aider", "--yes"}, Prompt: PromptFlag, PromptFlag: "--message"}, "fix it", []string{"aider", "--yes", "--message", "fix it"}},
+		{"prompt ignored", Definition{Command: []string{"goose", "session"}, Prompt: PromptNone}, "fix it", []string{"goose", "session"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.def.BuildCommand(tt.prompt))
+		})
+	}
+
+	t.Run("does not modify the definition", func(t *testing.T) {
+		def := Definition{Command: make([]string, 1, 4)}
+		def.Command[0] = "claude"
+
+		_ = def.BuildCommand("fix it")
+
+		assert.Equal(t, []string{"claude"}, def.Command)
+	})
+}
+
+func TestDefinition_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		def     Definition
+		wantErr bool
+	}{
+		{"valid", Definition{Name: "aider", Command: []string{"aider"}}, false},
+		{"valid flag prompt", Definition{Name: "aider", Command: []string{"aider"}, Prompt: PromptFlag, PromptFlag: "-m"}, false},
+		{"invalid name", Definition{Name: "Aider!", Command: []string{"aider"}}, true},
+		{"missing command", Definition{Name: "aider"}, true},
+		{"flag prompt without flag", Definition{Name: "aider", Command: []string{"aider"}, Prompt: PromptFlag}, true},
+		{"unknown prompt style", Definition{Name: "aider", Command: []string{"aider"}, Prompt: "stdin"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.def.Validate()
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrInvalidDefinition)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	t.Run("includes the built-ins", func(t *testing.T) {
+		reg := DefaultRegistry()
+
+		assert.Equal(t, []string{"claude", "gemini", "codex"}, reg.Names())
+		def, err := reg.Get("claude")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"claude"}, def.Command)
+		assert.Equal(t, "claude", def.Auth)
+		assert.NotEmpty(t, def.Setup)
+	})
+
+	t.Run("adds custom agents after the built-ins", func(t *testing.T) {
+		reg, err := NewRegistry(
+			Definition{Name: "opencode", Command: []string{"opencode"}},
+			Definition{Name: "aider", Command: []string{"aider"}},
+		)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"claude", "gemini", "codex", "aider", "opencode"}, reg.Names())
+		assert.True(t, reg.Has("aider"))
+	})
+
+	t.Run("custom agents replace built-ins", func(t *testing.T) {
+		reg, err := NewRegistry(Definition{Name: "claude", Command: []string{"claude", "--model", "opus"}})
+		require.NoError(t, err)
+
+		def, err := reg.Get("claude")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"claude", "--model", "opus"}, def.Command)
+		assert.Len(t, reg.Names(), 3)
+	})
+
+	t.Run("rejects invalid definitions", func(t *testing.T) {
+		_, err := NewRegistry(Definition{Name: "aider"})
+		require.ErrorIs(t, err, ErrInvalidDefinition)
+	})
+
+	t.Run("unknown agent", func(t *testing.T) {
+		_, err := DefaultRegistry().Get("aider")
+		require.ErrorIs(t, err, ErrUnknownAgent)
+	})
+}
+
+func TestIsBuiltin(t *testing.T) {
+	assert.True(t, IsBuiltin("codex"))
+	assert.False(t, IsBuiltin("aider"))
+	assert.Equal(t, []string{"claude", "gemini", "codex"}, BuiltinNames())
+}
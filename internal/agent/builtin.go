@@ -0,0 +1,72 @@
+package agent
+
+import "slices"
+
+// builtins are the agents headjack supports out of the box.
+var builtins = []Definition{
+	{
+		Name:    "claude",
+		Command: []string{"claude"},
+		Auth:    "claude",
+		// Always create ~/.claude.json with hasCompletedOnboarding to skip interactive setup.
+		// This is required for both OAuth token and API key authentication in headless environments.
+		// See: https://github.com/anthropics/claude-code/issues/8938
+		Setup: `mkdir -p ~/.claude && echo '{"hasCompletedOnboarding":true}' > ~/.claude.json`,
+		// Print mode streams JSON events only with --verbose.
+		HeadlessArgs:     []string{"--output-format", "stream-json", "--verbose", "-p"},
+		TranscriptFormat: "claude",
+		UsageFiles:       ".claude/projects/*/*.jsonl",
+		UsageFormat:      "claude",
+	},
+	{
+		Name:    "gemini",
+		Command: []string{"gemini"},
+		Auth:    "gemini",
+		// GEMINI_OAUTH_CREDS contains JSON with oauth_creds and google_accounts.
+		// A minimal settings.json selects the OAuth auth type.
+		AuthSetup: `mkdir -p ~/.gemini && \
+echo "$GEMINI_OAUTH_CREDS" | jq -r '.oauth_creds' > ~/.gemini/oauth_creds.json && \
+echo "$GEMINI_OAUTH_CREDS" | jq -r '.google_accounts' > ~/.gemini/google_accounts.json && \
+echo '{"security":{"auth":{"selectedType":"oauth-personal"}}}' > ~/.gemini/settings.json`,
+		HeadlessArgs:     []string{"--output-format", "stream-json", "-p"},
+		TranscriptFormat: "gemini",
+		UsageFiles:       ".gemini/tmp/*/chats/*.json",
+		UsageFormat:      "gemini",
+	},
+	{
+		Name:    "codex",
+		Command: []string{"codex"},
+		Auth:    "codex",
+		// CODEX_AUTH_JSON contains the contents of ~/.codex/auth.json.
+		AuthSetup:        `mkdir -p ~/.codex && echo "$CODEX_AUTH_JSON" > ~/.codex/auth.json`,
+		HeadlessArgs:     []string{"exec", "--json"},
+		TranscriptFormat: "codex",
+		UsageFiles:       ".codex/sessions/*/*/*/*.jsonl",
+		UsageFormat:      "codex",
+	},
+}
+
+// Builtins returns the definitions of the built-in agents.
+func Builtins() []Definition {
+	defs := make([]Definition, len(builtins))
+	for i, def := range builtins {
+		def.Command = slices.Clone(def.Command)
+		def.HeadlessArgs = slices.Clone(def.HeadlessArgs)
+		defs[i] = def
+	}
+	return defs
+}
+
+// BuiltinNames returns the names of the built-in agents.
+func BuiltinNames() []string {
+	names := make([]string, len(builtins))
+	for i := range builtins {
+		names[i] = builtins[i].Name
+	}
+	return names
+}
+
+// IsBuiltin reports whether name is a built-in agent.
+func IsBuiltin(name string) bool {
+	return slices.ContainsFunc(builtins, func(d Definition) bool { return d.Name == name })
+}
@@ -0,0 +1,129 @@
+// Package audit provides an append-only record of headjack actions.
+//
+// Every event is written as a single JSON line. Environment variables and
+// prompts attached to events are scrubbed of secret values before they reach
+// disk.
+package audit
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Action identifies the kind of operation being audited.
+type Action string
+
+// Action constants for audited operations.
+const (
+	ActionInstanceCreate   Action = "instance.create"
+	ActionInstanceRecreate Action = "instance.recreate"
+	ActionInstanceStop     Action = "instance.stop"
+	ActionInstanceRemove   Action = "instance.remove"
+	ActionSessionCreate    Action = "session.create"
+	ActionSessionKill      Action = "session.kill"
+	ActionSessionSend      Action = "session.send"
+	ActionSessionTimeout   Action = "session.timeout"
+	ActionSessionBudget    Action = "session.budget"
+	ActionAuthConfigure    Action = "auth.configure"
+	ActionAuthExport       Action = "auth.export"
+	ActionAuthImport       Action = "auth.import"
+	ActionAuthLogout       Action = "auth.logout"
+	ActionSecretSet        Action = "secret.set"
+	ActionSecretRemove     Action = "secret.remove"
+)
+
+// redactedValue replaces secret values in recorded environment variables.
+const redactedValue = "[REDACTED]"
+
+// Event is a single audited action.
+type Event struct {
+	Time           time.Time `json:"time"`
+	Action         Action    `json:"action"`
+	User           string    `json:"user"`
+	InstanceID     string    `json:"instance_id,omitempty"`
+	Repo           string    `json:"repo,omitempty"`
+	Branch         string    `json:"branch,omitempty"`
+	SessionID      string    `json:"session_id,omitempty"`
+	Session        string    `json:"session,omitempty"`         // Session name
+	Agent          string    `json:"agent,omitempty"`           // Session type or auth provider
+	Image          string    `json:"image,omitempty"`           // Container image
+	Prompt         string    `json:"prompt,omitempty"`          // Initial agent prompt
+	CredentialType string    `json:"credential_type,omitempty"` // subscription or apikey
+	AuthProfile    string    `json:"auth_profile,omitempty"`    // Credential profile
+	Secret         string    `json:"secret,omitempty"`          // Secret name, never its value
+	Env            []string  `json:"env,omitempty"`             // Redacted KEY=VALUE pairs
+	Reason         string    `json:"reason,omitempty"`          // Why headjack acted on its own, e.g. a timeout
+}
+
+// Filter narrows audit queries.
+type Filter struct {
+	Since  time.Time // Only events at or after this time (zero = all)
+	Branch string    // Only events for this branch (empty = all)
+}
+
+// Recorder records audit events.
+//
+//go:generate go run github.com/matryer/moq@latest -pkg mocks -out mocks/recorder.go . Recorder
+type Recorder interface {
+	// Record appends an event to the audit trail.
+	// Implementations must redact secret values before persisting.
+	Record(ctx context.Context, event *Event) error
+}
+
+// secretKeyPattern matches environment variable names that typically hold secrets.
+var secretKeyPattern = regexp.MustCompile(`(?i)(TOKEN|SECRET|PASSWORD|PASSWD|API_?KEY|CREDENTIAL|CREDS|AUTH|PRIVATE)`)
+
+// secretValuePrefixes are prefixes of well-known credential formats.
+var secretValuePrefixes = []string{"sk-", "AIza", "ghp_", "gho_", "github_pat_", "xoxb-", "xoxp-"}
+
+// secretWordPattern matches words of free text starting with one of
+// secretValuePrefixes.
+var secretWordPattern = regexp.MustCompile(`\b(?:` + strings.Join(secretValuePrefixes, "|") + `)[\w\-+/=]*`)
+
+// RedactEnv returns a copy of env with secret values replaced.
+// A value is considered secret if its key looks like a credential name or
+// the value itself has the shape of a well-known credential.
+func RedactEnv(env []string) []string {
+	if len(env) == 0 {
+		return nil
+	}
+
+	redacted := make([]string, len(env))
+	for i, e := range env {
+		key, value, ok := strings.Cut(e, "=")
+		if !ok {
+			redacted[i] = e
+			continue
+		}
+		if isSecret(key, value) {
+			redacted[i] = key + "=" + redactedValue
+			continue
+		}
+		redacted[i] = e
+	}
+	return redacted
+}
+
+// RedactText returns text with every word that has the shape of a
+// well-known credential replaced.
+func RedactText(text string) string {
+	return secretWordPattern.ReplaceAllString(text, redactedValue)
+}
+
+// isSecret reports whether an environment variable should be redacted.
+func isSecret(key, value string) bool {
+	if value == "" {
+		return false
+	}
+	if secretKeyPattern.MatchString(key) {
+		return true
+	}
+	for _, prefix := range secretValuePrefixes {
+		if strings.HasPrefix(value, prefix) {
+			return true
+		}
+	}
+	return false
+}
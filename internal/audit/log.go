@@ -0,0 +1,180 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	fileMode = 0o600
+	dirMode  = 0o750
+
+	// maxLineSize bounds a single audit line (prompts can be long).
+	maxLineSize = 1024 * 1024
+
+	// maxTextSize bounds each free-text field of an event, so that even
+	// with JSON escaping an event fits in maxLineSize.
+	maxTextSize = 64 * 1024
+)
+
+// truncatedSuffix marks free text cut to maxTextSize.
+const truncatedSuffix = "...[truncated]"
+
+// ErrCorruptLines is returned by Query, along with the events that could be
+// read, when lines of the log cannot be parsed.
+var ErrCorruptLines = errors.New("unreadable audit log lines")
+
+// Log is a file-backed Recorder that appends JSON lines.
+type Log struct {
+	path string
+	now  func() time.Time
+}
+
+// NewLog creates a Log writing to the given path.
+// The file and its parent directory are created on first write.
+func NewLog(path string) *Log {
+	return &Log{path: path, now: time.Now}
+}
+
+// Path returns the audit log file path.
+func (l *Log) Path() string {
+	return l.path
+}
+
+// Record appends an event to the log. The event's Env and Prompt are
+// redacted, free text is truncated to keep the line readable by Query, and
+// Time and User are filled in when empty. The caller's event is not modified.
+func (l *Log) Record(ctx context.Context, event *Event) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	ev := *event
+	ev.Env = RedactEnv(event.Env)
+	for i, e := range ev.Env {
+		ev.Env[i] = truncateText(e)
+	}
+	ev.Prompt = truncateText(RedactText(event.Prompt))
+	ev.Reason = truncateText(event.Reason)
+	if ev.Time.IsZero() {
+		ev.Time = l.now()
+	}
+	ev.Time = ev.Time.UTC()
+	if ev.User == "" {
+		ev.User = currentUser()
+	}
+
+	data, err := json.Marshal(&ev)
+	if err != nil {
+		return fmt.Errorf("marshal audit event: %w", err)
+	}
+	if len(data) >= maxLineSize {
+		return fmt.Errorf("audit event of %d bytes exceeds the %d byte limit", len(data), maxLineSize)
+	}
+	data = append(data, '\n')
+
+	if err := os.MkdirAll(filepath.Dir(l.path), dirMode); err != nil {
+		return fmt.Errorf("create audit directory: %w", err)
+	}
+
+	file, err := os.OpenFile(l.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, fileMode)
+	if err != nil {
+		return fmt.Errorf("open audit log: %w", err)
+	}
+	defer file.Close()
+
+	// Serialize concurrent writers from separate hjk processes
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX); err != nil {
+		return fmt.Errorf("lock audit log: %w", err)
+	}
+	//nolint:errcheck // Unlock errors are not actionable; closing releases the lock anyway
+	defer syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+
+	if _, err := file.Write(data); err != nil {
+		return fmt.Errorf("write audit event: %w", err)
+	}
+
+	return nil
+}
+
+// Query returns all events matching the filter in the order they were recorded.
+// A missing log file yields no events. Lines that cannot be parsed, such as
+// from an interrupted write, are skipped and reported with ErrCorruptLines.
+func (l *Log) Query(filter Filter) ([]Event, error) {
+	file, err := os.Open(l.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("open audit log: %w", err)
+	}
+	defer file.Close()
+
+	var events []Event
+	var corrupt []string
+	reader := bufio.NewReader(file)
+	for lineNum := 1; ; lineNum++ {
+		line, readErr := reader.ReadBytes('\n')
+		if readErr != nil && !errors.Is(readErr, io.EOF) {
+			return nil, fmt.Errorf("read audit log: %w", readErr)
+		}
+		line = bytes.TrimSpace(line)
+
+		var ev Event
+		switch {
+		case len(line) == 0:
+		case json.Unmarshal(line, &ev) != nil:
+			corrupt = append(corrupt, strconv.Itoa(lineNum))
+		case !filter.Since.IsZero() && ev.Time.Before(filter.Since):
+		case filter.Branch != "" && ev.Branch != filter.Branch:
+		default:
+			events = append(events, ev)
+		}
+
+		if readErr != nil {
+			break
+		}
+	}
+
+	if len(corrupt) > 0 {
+		return events, fmt.Errorf("%w: line %s", ErrCorruptLines, strings.Join(corrupt, ", "))
+	}
+	return events, nil
+}
+
+// truncateText cuts text longer than maxTextSize at a character boundary and
+// marks it as truncated.
+func truncateText(text string) string {
+	if len(text) <= maxTextSize {
+		return text
+	}
+	n := maxTextSize - len(truncatedSuffix)
+	for n > 0 && !utf8.RuneStart(text[n]) {
+		n--
+	}
+	return text[:n] + truncatedSuffix
+}
+
+// currentUser returns the login name of the invoking user.
+func currentUser() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	return "unknown"
+}
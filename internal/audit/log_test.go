@@ -0,0 +1,177 @@
+package audit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactEnv(t *testing.T) {
+	tests := []struct {
+		name string
+		env  []string
+		want []string
+	}{
+		{
+			name: "redacts credential keys",
+			env:  []string{"CLAUDE_CODE_OAUTH_TOKEN=abc", "GEMINI_API_KEY=xyz", "GEMINI_OAUTH_CREDS={}"},
+			want: []string{"CLAUDE_CODE_OAUTH_TOKEN=[REDACTED]", "GEMINI_API_KEY=[REDACTED]", "GEMINI_OAUTH_CREDS=[REDACTED]"},
+		},
+		{
+			name: "redacts credential-shaped values",
+			env:  []string{"SOMETHING=sk-ant-api03-secret", "GH=ghp_abcdef"},
+			want: []string{"SOMETHING=[REDACTED]", "GH=[REDACTED]"},
+		},
+		{
+			name: "keeps benign values",
+			env:  []string{"CLAUDE_CODE_MAX_TURNS=100", "EMPTY_TOKEN=", "NOEQUALS"},
+			want: []string{"CLAUDE_CODE_MAX_TURNS=100", "EMPTY_TOKEN=", "NOEQUALS"},
+		},
+		{
+			name: "nil env",
+			env:  nil,
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, RedactEnv(tt.env))
+		})
+	}
+}
+
+func TestRedactText(t *testing.T) {
+	assert.Equal(t, "key [REDACTED], then [REDACTED]", RedactText("key sk-ant-api03-secret, then xoxb-1-2"))
+	assert.Equal(t, "ask-me about task-runner", RedactText("ask-me about task-runner"))
+}
+
+func TestLog_RecordAndQuery(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("round-trips events with redacted env", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "nested", "audit.jsonl")
+		log := NewLog(path)
+
+		event := &Event{
+			Action:         ActionSessionCreate,
+			User:           "alice",
+			Branch:         "feat/auth",
+			Session:        "happy-panda",
+			Agent:          "claude",
+			Prompt:         "Implement JWT",
+			CredentialType: "apikey",
+			Env:            []string{"ANTHROPIC_API_KEY=sk-ant-api03-secret", "CLAUDE_CODE_MAX_TURNS=100"},
+		}
+		require.NoError(t, log.Record(ctx, event))
+
+		// Caller's event is not mutated
+		assert.Equal(t, "ANTHROPIC_API_KEY=sk-ant-api03-secret", event.Env[0])
+
+		raw, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.NotContains(t, string(raw), "sk-ant-api03-secret")
+
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+		events, err := log.Query(Filter{})
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		assert.Equal(t, ActionSessionCreate, events[0].Action)
+		assert.Equal(t, "alice", events[0].User)
+		assert.Equal(t, "Implement JWT", events[0].Prompt)
+		assert.Equal(t, []string{"ANTHROPIC_API_KEY=[REDACTED]", "CLAUDE_CODE_MAX_TURNS=100"}, events[0].Env)
+		assert.False(t, events[0].Time.IsZero())
+	})
+
+	t.Run("appends without rewriting existing lines", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "audit.jsonl")
+		log := NewLog(path)
+
+		require.NoError(t, log.Record(ctx, &Event{Action: ActionInstanceCreate, Branch: "main"}))
+		first, err := os.ReadFile(path)
+		require.NoError(t, err)
+
+		require.NoError(t, log.Record(ctx, &Event{Action: ActionInstanceRemove, Branch: "main"}))
+		all, err := os.ReadFile(path)
+		require.NoError(t, err)
+
+		assert.True(t, strings.HasPrefix(string(all), string(first)))
+		assert.Equal(t, 2, strings.Count(string(all), "\n"))
+	})
+
+	t.Run("filters by since and branch", func(t *testing.T) {
+		log := NewLog(filepath.Join(t.TempDir(), "audit.jsonl"))
+		base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+		require.NoError(t, log.Record(ctx, &Event{Time: base, Action: ActionInstanceCreate, Branch: "main"}))
+		require.NoError(t, log.Record(ctx, &Event{Time: base.Add(time.Hour), Action: ActionInstanceCreate, Branch: "feat/auth"}))
+		require.NoError(t, log.Record(ctx, &Event{Time: base.Add(2 * time.Hour), Action: ActionInstanceStop, Branch: "feat/auth"}))
+
+		events, err := log.Query(Filter{Since: base.Add(30 * time.Minute)})
+		require.NoError(t, err)
+		assert.Len(t, events, 2)
+
+		events, err = log.Query(Filter{Branch: "main"})
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		assert.Equal(t, "main", events[0].Branch)
+
+		events, err = log.Query(Filter{Since: base.Add(90 * time.Minute), Branch: "feat/auth"})
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		assert.Equal(t, ActionInstanceStop, events[0].Action)
+	})
+
+	t.Run("missing log yields no events", func(t *testing.T) {
+		log := NewLog(filepath.Join(t.TempDir(), "missing.jsonl"))
+
+		events, err := log.Query(Filter{})
+		require.NoError(t, err)
+		assert.Empty(t, events)
+	})
+
+	t.Run("redacts and truncates prompts", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "audit.jsonl")
+		log := NewLog(path)
+
+		require.NoError(t, log.Record(ctx, &Event{Action: ActionSessionSend, Prompt: "use token ghp_abc123 to push"}))
+		require.NoError(t, log.Record(ctx, &Event{Action: ActionSessionCreate, Prompt: strings.Repeat("<é>", maxLineSize)}))
+
+		events, err := log.Query(Filter{})
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+		assert.Equal(t, "use token [REDACTED] to push", events[0].Prompt)
+		assert.LessOrEqual(t, len(events[1].Prompt), maxTextSize)
+		assert.True(t, strings.HasSuffix(events[1].Prompt, truncatedSuffix))
+		assert.True(t, utf8.ValidString(events[1].Prompt))
+	})
+
+	t.Run("skips and reports corrupt lines", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "audit.jsonl")
+		log := NewLog(path)
+		require.NoError(t, log.Record(ctx, &Event{Action: ActionInstanceCreate, Branch: "main"}))
+		file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+		require.NoError(t, err)
+		_, err = file.WriteString(`{"action":"instance.st` + "\n")
+		require.NoError(t, err)
+		require.NoError(t, file.Close())
+		require.NoError(t, log.Record(ctx, &Event{Action: ActionInstanceStop, Branch: "main"}))
+
+		events, err := log.Query(Filter{})
+
+		require.ErrorIs(t, err, ErrCorruptLines)
+		assert.ErrorContains(t, err, "line 2")
+		require.Len(t, events, 2)
+		assert.Equal(t, ActionInstanceStop, events[1].Action)
+	})
+}
@@ -3,9 +3,78 @@ package auth
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jmgilman/headjack/internal/credstore"
+	"github.com/jmgilman/headjack/internal/keychain"
+)
+
+// Sentinel errors for provider and profile lookups.
+var (
+	ErrUnknownProvider = errors.New("unknown auth provider")
+	ErrInvalidProfile  = errors.New("invalid auth profile")
 )
 
+// DefaultProfile is the profile used when none is selected. Its credentials
+// are stored under the provider's plain keychain account, so credentials
+// configured before profiles existed keep working.
+const DefaultProfile = "default"
+
+// indexAccount is the storage account listing the accounts credentials are
+// stored under.
+const indexAccount = "auth-index"
+
+// profilePattern matches valid profile names.
+var profilePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// ValidateProfile checks that name is usable as a profile name.
+func ValidateProfile(name string) error {
+	if !profilePattern.MatchString(name) {
+		return fmt.Errorf("%w: %q (use lowercase letters, digits, '-' and '_')", ErrInvalidProfile, name)
+	}
+	return nil
+}
+
+// ProfileAccount returns the keychain account holding a provider's credential
+// for the given profile. An empty profile is the default profile.
+func ProfileAccount(account, profile string) string {
+	if profile == "" || profile == DefaultProfile {
+		return account
+	}
+	return account + ":" + profile
+}
+
+// providers constructs each provider by name.
+var providers = map[string]func() Provider{
+	claudeInfo.Name: func() Provider { return NewClaudeProvider() },
+	geminiInfo.Name: func() Provider { return NewGeminiProvider() },
+	codexInfo.Name:  func() Provider { return NewCodexProvider() },
+}
+
+// NewProvider returns the provider with the given name.
+func NewProvider(name string) (Provider, error) {
+	newProvider, ok := providers[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s (valid: %s)", ErrUnknownProvider, name, strings.Join(ProviderNames(), ", "))
+	}
+	return newProvider(), nil
+}
+
+// ProviderNames returns the names of all providers, sorted.
+func ProviderNames() []string {
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
 // CredentialType distinguishes between subscription-based and API key authentication.
 type CredentialType string
 
@@ -21,6 +90,14 @@ const (
 type Credential struct {
 	Type  CredentialType `json:"type"`
 	Value string         `json:"value"`
+
+	// SourceModTime is the modification time of the host credential files the
+	// credential was imported from. Zero for credentials entered by hand.
+	SourceModTime time.Time `json:"source_mod_time,omitzero"`
+
+	// StoredAt is when the credential was first stored. Zero for credentials
+	// stored before it was recorded.
+	StoredAt time.Time `json:"stored_at,omitzero"`
 }
 
 // MarshalJSON implements json.Marshaler.
@@ -51,7 +128,8 @@ type ProviderInfo struct {
 	// APIKeyEnvVar is the environment variable for API key credentials.
 	APIKeyEnvVar string
 
-	// KeychainAccount is the keychain account name for storing credentials.
+	// KeychainAccount is the keychain account name for storing credentials
+	// of the default profile. Other profiles are stored under ProfileAccount.
 	KeychainAccount string
 
 	// RequiresContainerSetup indicates whether subscription credentials need
@@ -86,17 +164,25 @@ type Provider interface {
 	// this returns an error with instructions.
 	CheckSubscription() (string, error)
 
+	// SubscriptionModTime returns when the host credential files read by
+	// CheckSubscription last changed. Zero if there are none, including for
+	// providers requiring manual entry.
+	SubscriptionModTime() (time.Time, error)
+
+	// Metadata parses expiry and account information from a credential.
+	Metadata(cred *Credential) (*CredentialMetadata, error)
+
 	// ValidateSubscription validates a subscription credential value.
 	ValidateSubscription(value string) error
 
 	// ValidateAPIKey validates an API key credential value.
 	ValidateAPIKey(value string) error
 
-	// Store saves a credential to storage.
-	Store(storage Storage, cred Credential) error
+	// Store saves a credential to storage under the given profile.
+	Store(storage Storage, profile string, cred Credential) error
 
-	// Load retrieves the stored credential for this provider.
-	Load(storage Storage) (*Credential, error)
+	// Load retrieves the stored credential of the given profile.
+	Load(storage Storage, profile string) (*Credential, error)
 }
 
 // Prompter abstracts user interaction for credential collection.
@@ -114,12 +200,102 @@ type Prompter interface {
 }
 
 // StoreCredential is a helper function to store a credential in JSON format.
+// The account is recorded in the credential index so Profiles can find it,
+// and the credential's StoredAt is set if it is zero.
 func StoreCredential(storage Storage, account string, cred Credential) error {
+	if cred.StoredAt.IsZero() {
+		cred.StoredAt = time.Now().UTC()
+	}
 	data, err := json.Marshal(cred)
 	if err != nil {
 		return fmt.Errorf("marshal credential: %w", err)
 	}
-	return storage.Set(account, string(data))
+	if err := storage.Set(account, string(data)); err != nil {
+		return err
+	}
+
+	return credentialIndex(storage).Add(account)
+}
+
+// DeleteCredential removes the credential stored under account and its entry
+// in the credential index.
+func DeleteCredential(storage Storage, account string) error {
+	if err := storage.Delete(account); err != nil {
+		return err
+	}
+
+	_, err := credentialIndex(storage).Remove(account)
+	return err
+}
+
+// Profiles returns the profiles of a provider with a stored credential,
+// sorted, with the default profile first.
+func Profiles(storage Storage, provider Provider) ([]string, error) {
+	accounts, err := credentialIndex(storage).Load()
+	if err != nil {
+		return nil, err
+	}
+
+	base := provider.Info().KeychainAccount
+	var profiles []string
+	for _, account := range accounts {
+		if profile, ok := strings.CutPrefix(account, base+":"); ok {
+			profiles = append(profiles, profile)
+		}
+	}
+	slices.Sort(profiles)
+
+	// Credentials of the default profile stored before the index existed are
+	// not listed in it.
+	if _, err := storage.Get(base); err == nil {
+		profiles = append([]string{DefaultProfile}, profiles...)
+	} else if !errors.Is(err, keychain.ErrNotFound) {
+		return nil, fmt.Errorf("load credential: %w", err)
+	}
+	return profiles, nil
+}
+
+// credentialIndex returns the index of accounts credentials are stored under.
+func credentialIndex(storage Storage) *credstore.Index[string] {
+	return credstore.NewIndex[string](storage, indexAccount)
+}
+
+// BackfillIndex adds the stored credentials of the given profiles to the
+// credential index. Credentials of non-default profiles stored before the
+// index existed are not listed in it, so Profiles cannot find them until
+// their profiles are backfilled.
+func BackfillIndex(storage Storage, profiles []string) error {
+	index := credentialIndex(storage)
+	indexed, err := index.Load()
+	if err != nil {
+		return err
+	}
+
+	var missing []string
+	for _, name := range ProviderNames() {
+		provider, err := NewProvider(name)
+		if err != nil {
+			return err
+		}
+		for _, profile := range profiles {
+			if profile == "" || profile == DefaultProfile {
+				continue
+			}
+			account := ProfileAccount(provider.Info().KeychainAccount, profile)
+			if slices.Contains(indexed, account) || slices.Contains(missing, account) {
+				continue
+			}
+			if _, err := storage.Get(account); err == nil {
+				missing = append(missing, account)
+			} else if !errors.Is(err, keychain.ErrNotFound) {
+				return fmt.Errorf("load credential: %w", err)
+			}
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	return index.Add(missing...)
 }
 
 // LoadCredential is a helper function to load a credential from JSON format.
@@ -0,0 +1,149 @@
+package auth
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmgilman/headjack/internal/keychain"
+)
+
+func TestNewProvider(t *testing.T) {
+	for _, name := range ProviderNames() {
+		provider, err := NewProvider(name)
+		require.NoError(t, err)
+		assert.Equal(t, name, provider.Info().Name)
+	}
+
+	_, err := NewProvider("aider")
+	assert.ErrorIs(t, err, ErrUnknownProvider)
+}
+
+func TestProviderNames(t *testing.T) {
+	assert.Equal(t, []string{"claude", "codex", "gemini"}, ProviderNames())
+}
+
+func TestValidateProfile(t *testing.T) {
+	for _, name := range []string{"default", "work", "client-a", "team_2"} {
+		assert.NoError(t, ValidateProfile(name), name)
+	}
+	for _, name := range []string{"", "Work", "my profile", "-work", "a:b"} {
+		assert.ErrorIs(t, ValidateProfile(name), ErrInvalidProfile, name)
+	}
+}
+
+func TestProfileAccount(t *testing.T) {
+	assert.Equal(t, "claude-credential", ProfileAccount("claude-credential", ""))
+	assert.Equal(t, "claude-credential", ProfileAccount("claude-credential", DefaultProfile))
+	assert.Equal(t, "claude-credential:work", ProfileAccount("claude-credential", "work"))
+}
+
+func TestProvider_StoreLoadProfile(t *testing.T) {
+	storage := mapStorage{}
+	provider := NewClaudeProvider()
+
+	require.NoError(t, provider.Store(storage, DefaultProfile, Credential{Type: CredentialTypeSubscription, Value: "sk-ant-oat01-personal"}))
+	require.NoError(t, provider.Store(storage, "work", Credential{Type: CredentialTypeAPIKey, Value: "sk-ant-api03-work"}))
+
+	assert.Contains(t, storage, "claude-credential")
+	assert.Contains(t, storage, "claude-credential:work")
+
+	cred, err := provider.Load(storage, "work")
+	require.NoError(t, err)
+	assert.Equal(t, CredentialTypeAPIKey, cred.Type)
+	assert.Equal(t, "sk-ant-api03-work", cred.Value)
+}
+
+func TestProfiles(t *testing.T) {
+	t.Run("lists stored profiles", func(t *testing.T) {
+		storage := mapStorage{}
+		claude := NewClaudeProvider()
+		require.NoError(t, claude.Store(storage, "work", Credential{Type: CredentialTypeAPIKey, Value: "sk-ant-api03-work"}))
+		require.NoError(t, claude.Store(storage, DefaultProfile, Credential{Type: CredentialTypeSubscription, Value: "sk-ant-oat01-personal"}))
+		require.NoError(t, claude.Store(storage, "ci", Credential{Type: CredentialTypeAPIKey, Value: "sk-ant-api03-ci"}))
+		require.NoError(t, NewCodexProvider().Store(storage, "work", Credential{Type: CredentialTypeAPIKey, Value: "sk-work"}))
+
+		profiles, err := Profiles(storage, claude)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{DefaultProfile, "ci", "work"}, profiles)
+	})
+
+	t.Run("finds default profile stored before the index", func(t *testing.T) {
+		storage := mapStorage{"gemini-credential": `{"type":"apikey","value":"AIza-test"}`}
+
+		profiles, err := Profiles(storage, NewGeminiProvider())
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{DefaultProfile}, profiles)
+	})
+}
+
+func TestBackfillIndex(t *testing.T) {
+	storage := mapStorage{}
+	claude := NewClaudeProvider()
+	require.NoError(t, claude.Store(storage, "ci", Credential{Type: CredentialTypeAPIKey, Value: "sk-ant-api03-ci"}))
+	// Profiles stored before the index existed are not listed in it
+	storage["claude-credential:work"] = `{"type":"apikey","value":"sk-ant-api03-work"}`
+	storage["codex-credential:work"] = `{"type":"apikey","value":"sk-work"}`
+
+	profiles, err := Profiles(storage, claude)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ci"}, profiles)
+
+	require.NoError(t, BackfillIndex(storage, []string{DefaultProfile, "work", "missing"}))
+
+	profiles, err = Profiles(storage, claude)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ci", "work"}, profiles)
+	profiles, err = Profiles(storage, NewCodexProvider())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"work"}, profiles)
+}
+
+// mapStorage is an in-memory Storage.
+type mapStorage map[string]string
+
+func (s mapStorage) Set(account, secret string) error {
+	s[account] = secret
+	return nil
+}
+
+func (s mapStorage) Get(account string) (string, error) {
+	secret, ok := s[account]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", keychain.ErrNotFound, account)
+	}
+	return secret, nil
+}
+
+func (s mapStorage) Delete(account string) error {
+	delete(s, account)
+	return nil
+}
+
+func TestDeleteCredential(t *testing.T) {
+	storage := mapStorage{}
+	claude := NewClaudeProvider()
+	require.NoError(t, claude.Store(storage, "work", Credential{Type: CredentialTypeAPIKey, Value: "sk-ant-api03-work"}))
+
+	require.NoError(t, DeleteCredential(storage, ProfileAccount(claudeInfo.KeychainAccount, "work")))
+
+	assert.Empty(t, storage, "the credential and the emptied index are removed")
+	profiles, err := Profiles(storage, claude)
+	require.NoError(t, err)
+	assert.Empty(t, profiles)
+}
+
+func TestStoreCredential_StoredAt(t *testing.T) {
+	storage := mapStorage{}
+	claude := NewClaudeProvider()
+	require.NoError(t, claude.Store(storage, DefaultProfile, Credential{Type: CredentialTypeAPIKey, Value: "sk-ant-api03-key"}))
+
+	cred, err := claude.Load(storage, DefaultProfile)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), cred.StoredAt, time.Minute)
+}
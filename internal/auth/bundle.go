@@ -0,0 +1,165 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Sentinel errors for credential bundles.
+var (
+	ErrInvalidBundle = errors.New("invalid credential bundle")
+	ErrDecryptBundle = errors.New("decrypt credential bundle: wrong passphrase or corrupted bundle")
+)
+
+// bundleMagic starts every sealed bundle and identifies its format version.
+var bundleMagic = []byte("headjack-auth-bundle/v1\n")
+
+// scrypt parameters deriving the bundle key from the passphrase.
+const (
+	scryptN       = 1 << 15
+	scryptR       = 8
+	scryptP       = 1
+	bundleSaltLen = 16
+	bundleKeyLen  = 32
+	bundleNonce   = 24
+)
+
+// BundleEntry is a stored credential of one provider profile.
+type BundleEntry struct {
+	Provider   string     `json:"provider"`
+	Profile    string     `json:"profile"`
+	Credential Credential `json:"credential"`
+}
+
+// ExportCredentials returns the stored credentials of every profile of the
+// given providers.
+func ExportCredentials(storage Storage, providers []Provider) ([]BundleEntry, error) {
+	var entries []BundleEntry
+	for _, provider := range providers {
+		name := provider.Info().Name
+		profiles, err := Profiles(storage, provider)
+		if err != nil {
+			return nil, fmt.Errorf("list %s profiles: %w", name, err)
+		}
+		for _, profile := range profiles {
+			cred, err := provider.Load(storage, profile)
+			if err != nil {
+				return nil, fmt.Errorf("load %s credential (%s): %w", name, profile, err)
+			}
+			entries = append(entries, BundleEntry{Provider: name, Profile: profile, Credential: *cred})
+		}
+	}
+	return entries, nil
+}
+
+// ImportCredentials validates every entry with its provider, then stores
+// them, replacing credentials of the same profiles. Nothing is stored if any
+// entry is invalid.
+func ImportCredentials(storage Storage, entries []BundleEntry) error {
+	providers := make([]Provider, len(entries))
+	for i, entry := range entries {
+		provider, err := NewProvider(entry.Provider)
+		if err != nil {
+			return err
+		}
+		if err := ValidateProfile(entry.Profile); err != nil {
+			return fmt.Errorf("%s: %w", entry.Provider, err)
+		}
+		if err := validateCredential(provider, entry.Credential); err != nil {
+			return fmt.Errorf("%s credential (%s): %w", entry.Provider, entry.Profile, err)
+		}
+		providers[i] = provider
+	}
+
+	for i, entry := range entries {
+		if err := providers[i].Store(storage, entry.Profile, entry.Credential); err != nil {
+			return fmt.Errorf("store %s credential (%s): %w", entry.Provider, entry.Profile, err)
+		}
+	}
+	return nil
+}
+
+// validateCredential checks a credential's value with the validation of its type.
+func validateCredential(provider Provider, cred Credential) error {
+	switch cred.Type {
+	case CredentialTypeSubscription:
+		return provider.ValidateSubscription(cred.Value)
+	case CredentialTypeAPIKey:
+		return provider.ValidateAPIKey(cred.Value)
+	default:
+		return fmt.Errorf("unknown credential type %q", cred.Type)
+	}
+}
+
+// SealBundle encrypts entries with a key derived from passphrase by scrypt,
+// using NaCl secretbox.
+func SealBundle(entries []BundleEntry, passphrase string) ([]byte, error) {
+	if passphrase == "" {
+		return nil, errors.New("passphrase is empty")
+	}
+	plaintext, err := json.Marshal(entries)
+	if err != nil {
+		return nil, fmt.Errorf("marshal credentials: %w", err)
+	}
+
+	salt := make([]byte, bundleSaltLen)
+	var nonce [bundleNonce]byte
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("generate salt: %w", err)
+	}
+	if _, err := rand.Read(nonce[:]); err != nil {
+		return nil, fmt.Errorf("generate nonce: %w", err)
+	}
+	key, err := bundleKey(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, len(bundleMagic)+len(salt)+len(nonce)+len(plaintext)+secretbox.Overhead)
+	out = append(out, bundleMagic...)
+	out = append(out, salt...)
+	out = append(out, nonce[:]...)
+	return secretbox.Seal(out, plaintext, &nonce, key), nil
+}
+
+// OpenBundle decrypts a bundle sealed by SealBundle.
+func OpenBundle(data []byte, passphrase string) ([]BundleEntry, error) {
+	rest, ok := bytes.CutPrefix(data, bundleMagic)
+	if !ok || len(rest) < bundleSaltLen+bundleNonce+secretbox.Overhead {
+		return nil, ErrInvalidBundle
+	}
+	salt := rest[:bundleSaltLen]
+	var nonce [bundleNonce]byte
+	copy(nonce[:], rest[bundleSaltLen:])
+	key, err := bundleKey(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, ok := secretbox.Open(nil, rest[bundleSaltLen+bundleNonce:], &nonce, key)
+	if !ok {
+		return nil, ErrDecryptBundle
+	}
+	var entries []BundleEntry
+	if err := json.Unmarshal(plaintext, &entries); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidBundle, err)
+	}
+	return entries, nil
+}
+
+// bundleKey derives the secretbox key from a passphrase.
+func bundleKey(passphrase string, salt []byte) (*[bundleKeyLen]byte, error) {
+	derived, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, bundleKeyLen)
+	if err != nil {
+		return nil, fmt.Errorf("derive key: %w", err)
+	}
+	var key [bundleKeyLen]byte
+	copy(key[:], derived)
+	return &key, nil
+}
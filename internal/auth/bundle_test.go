@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBundle_RoundTrip(t *testing.T) {
+	source := mapStorage{}
+	require.NoError(t, NewClaudeProvider().Store(source, DefaultProfile, Credential{Type: CredentialTypeSubscription, Value: "sk-ant-oat01-personal"}))
+	require.NoError(t, NewClaudeProvider().Store(source, "work", Credential{Type: CredentialTypeAPIKey, Value: "sk-ant-api03-work"}))
+	require.NoError(t, NewCodexProvider().Store(source, DefaultProfile, Credential{Type: CredentialTypeAPIKey, Value: "sk-codex"}))
+
+	entries, err := ExportCredentials(source, []Provider{NewClaudeProvider(), NewGeminiProvider(), NewCodexProvider()})
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+
+	sealed, err := SealBundle(entries, "correct horse")
+	require.NoError(t, err)
+	assert.NotContains(t, string(sealed), "sk-ant-api03-work")
+
+	opened, err := OpenBundle(sealed, "correct horse")
+	require.NoError(t, err)
+	assert.Equal(t, entries, opened)
+
+	target := mapStorage{}
+	require.NoError(t, ImportCredentials(target, opened))
+
+	cred, err := NewClaudeProvider().Load(target, "work")
+	require.NoError(t, err)
+	assert.Equal(t, "sk-ant-api03-work", cred.Value)
+
+	profiles, err := Profiles(target, NewClaudeProvider())
+	require.NoError(t, err)
+	assert.Equal(t, []string{DefaultProfile, "work"}, profiles)
+}
+
+func TestOpenBundle(t *testing.T) {
+	sealed, err := SealBundle([]BundleEntry{{Provider: "codex", Profile: DefaultProfile, Credential: Credential{Type: CredentialTypeAPIKey, Value: "sk-codex"}}}, "secret")
+	require.NoError(t, err)
+
+	t.Run("wrong passphrase", func(t *testing.T) {
+		_, err := OpenBundle(sealed, "guess")
+		assert.ErrorIs(t, err, ErrDecryptBundle)
+	})
+
+	t.Run("not a bundle", func(t *testing.T) {
+		_, err := OpenBundle([]byte(`{"type":"apikey"}`), "secret")
+		assert.ErrorIs(t, err, ErrInvalidBundle)
+	})
+
+	t.Run("tampered", func(t *testing.T) {
+		tampered := append([]byte(nil), sealed...)
+		tampered[len(tampered)-1] ^= 0xff
+		_, err := OpenBundle(tampered, "secret")
+		assert.ErrorIs(t, err, ErrDecryptBundle)
+	})
+}
+
+func TestImportCredentials_Invalid(t *testing.T) {
+	storage := mapStorage{}
+	entries := []BundleEntry{
+		{Provider: "claude", Profile: DefaultProfile, Credential: Credential{Type: CredentialTypeAPIKey, Value: "sk-ant-api03-ok"}},
+		{Provider: "claude", Profile: "work", Credential: Credential{Type: CredentialTypeAPIKey, Value: "not-a-key"}},
+	}
+
+	err := ImportCredentials(storage, entries)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "claude credential (work)")
+	assert.Empty(t, storage, "nothing is stored when any entry is invalid")
+
+	err = ImportCredentials(storage, []BundleEntry{{Provider: "aider", Profile: DefaultProfile}})
+	assert.ErrorIs(t, err, ErrUnknownProvider)
+}
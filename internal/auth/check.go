@@ -0,0 +1,169 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ErrCredentialRejected is returned when a provider's API refuses a credential.
+var ErrCredentialRejected = errors.New("credential rejected by provider")
+
+// probe is a lightweight authenticated request to a provider's API.
+type probe struct {
+	baseURL string
+	path    string
+	header  func(h http.Header, token string)
+}
+
+// bearer sets a bearer token.
+func bearer(h http.Header, token string) {
+	h.Set("Authorization", "Bearer "+token)
+}
+
+// probes holds the request validating each provider's credential types.
+// Subscription credentials of Gemini and Codex are checked with their OAuth
+// provider's userinfo endpoint, since the agents' own backends are private.
+var probes = map[string]map[CredentialType]probe{
+	claudeInfo.Name: {
+		CredentialTypeAPIKey: {
+			baseURL: "https://api.anthropic.com",
+			path:    "/v1/models",
+			header: func(h http.Header, token string) {
+				h.Set("X-Api-Key", token)
+				h.Set("Anthropic-Version", "2023-06-01")
+			},
+		},
+		CredentialTypeSubscription: {
+			baseURL: "https://api.anthropic.com",
+			path:    "/v1/models",
+			header: func(h http.Header, token string) {
+				bearer(h, token)
+				h.Set("Anthropic-Version", "2023-06-01")
+				h.Set("Anthropic-Beta", "oauth-2025-04-20")
+			},
+		},
+	},
+	geminiInfo.Name: {
+		CredentialTypeAPIKey: {
+			baseURL: "https://generativelanguage.googleapis.com",
+			path:    "/v1beta/models",
+			header: func(h http.Header, token string) {
+				h.Set("X-Goog-Api-Key", token)
+			},
+		},
+		CredentialTypeSubscription: {
+			baseURL: "https://openidconnect.googleapis.com",
+			path:    "/v1/userinfo",
+			header:  bearer,
+		},
+	},
+	codexInfo.Name: {
+		CredentialTypeAPIKey: {
+			baseURL: "https://api.openai.com",
+			path:    "/v1/models",
+			header:  bearer,
+		},
+		CredentialTypeSubscription: {
+			baseURL: "https://auth.openai.com",
+			path:    "/userinfo",
+			header:  bearer,
+		},
+	},
+}
+
+// Checker validates credentials with a request to the provider's API.
+type Checker struct {
+	// Client sends the requests. Nil means http.DefaultClient.
+	Client *http.Client
+
+	// BaseURLs replaces the base URL of every request to a provider, keyed
+	// by provider name, e.g. to check against a proxy or a test server.
+	BaseURLs map[string]string
+}
+
+// Check makes a lightweight authenticated request with a credential and
+// returns ErrCredentialRejected if the provider refuses it.
+func (c *Checker) Check(ctx context.Context, provider string, cred *Credential) error {
+	p, ok := probes[provider][cred.Type]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownProvider, provider)
+	}
+	token, err := accessToken(provider, cred)
+	if err != nil {
+		return err
+	}
+
+	baseURL := p.baseURL
+	if override := c.BaseURLs[provider]; override != "" {
+		baseURL = override
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(baseURL, "/")+p.path, http.NoBody)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	p.header(req.Header, token)
+
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("check credential: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16)) //nolint:errcheck // drain for connection reuse
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return fmt.Errorf("%w: %s", ErrCredentialRejected, resp.Status)
+	default:
+		return fmt.Errorf("check credential: unexpected response %s", resp.Status)
+	}
+}
+
+// accessToken returns the token a credential authenticates requests with.
+// API keys and Claude OAuth tokens are used as they are; Gemini and Codex
+// subscription credentials carry an OAuth access token.
+func accessToken(provider string, cred *Credential) (string, error) {
+	if cred.Type != CredentialTypeSubscription || provider == claudeInfo.Name {
+		return strings.TrimSpace(cred.Value), nil
+	}
+
+	var token string
+	switch provider {
+	case geminiInfo.Name:
+		var config GeminiConfig
+		if err := json.Unmarshal([]byte(cred.Value), &config); err != nil {
+			return "", fmt.Errorf("parse gemini credentials: %w", err)
+		}
+		var oauthCreds struct {
+			AccessToken string `json:"access_token"`
+		}
+		if err := json.Unmarshal(config.OAuthCreds, &oauthCreds); err != nil {
+			return "", fmt.Errorf("parse oauth_creds: %w", err)
+		}
+		token = oauthCreds.AccessToken
+	case codexInfo.Name:
+		var authFile struct {
+			Tokens struct {
+				AccessToken string `json:"access_token"`
+			} `json:"tokens"`
+		}
+		if err := json.Unmarshal([]byte(cred.Value), &authFile); err != nil {
+			return "", fmt.Errorf("parse codex auth.json: %w", err)
+		}
+		token = authFile.Tokens.AccessToken
+	}
+	if token == "" {
+		return "", fmt.Errorf("%s credential has no access token", provider)
+	}
+	return token, nil
+}
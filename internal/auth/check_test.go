@@ -0,0 +1,85 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChecker_Check(t *testing.T) {
+	ctx := context.Background()
+
+	// The test server accepts requests authenticated with "good" tokens.
+	var got *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		switch {
+		case r.Header.Get("Authorization") == "Bearer good",
+			r.Header.Get("X-Api-Key") == "good",
+			r.Header.Get("X-Goog-Api-Key") == "good":
+			w.WriteHeader(http.StatusOK)
+		case r.URL.Path == "/v1/models" && r.Header.Get("Authorization") == "Bearer unavailable":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+
+	checker := &Checker{
+		Client:   server.Client(),
+		BaseURLs: map[string]string{"claude": server.URL, "gemini": server.URL, "codex": server.URL + "/"},
+	}
+
+	tests := []struct {
+		name     string
+		provider string
+		cred     Credential
+		path     string
+	}{
+		{"claude api key", "claude", Credential{Type: CredentialTypeAPIKey, Value: "good"}, "/v1/models"},
+		{"claude token", "claude", Credential{Type: CredentialTypeSubscription, Value: "good"}, "/v1/models"},
+		{"gemini api key", "gemini", Credential{Type: CredentialTypeAPIKey, Value: "good"}, "/v1beta/models"},
+		{"gemini oauth", "gemini", Credential{Type: CredentialTypeSubscription, Value: `{"oauth_creds":{"access_token":"good"}}`}, "/v1/userinfo"},
+		{"codex api key", "codex", Credential{Type: CredentialTypeAPIKey, Value: "good"}, "/v1/models"},
+		{"codex oauth", "codex", Credential{Type: CredentialTypeSubscription, Value: `{"tokens":{"access_token":"good"}}`}, "/userinfo"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, checker.Check(ctx, tt.provider, &tt.cred))
+			assert.Equal(t, tt.path, got.URL.Path)
+		})
+	}
+
+	t.Run("sends the anthropic headers", func(t *testing.T) {
+		require.NoError(t, checker.Check(ctx, "claude", &Credential{Type: CredentialTypeSubscription, Value: "good"}))
+		assert.Equal(t, "2023-06-01", got.Header.Get("Anthropic-Version"))
+		assert.Equal(t, "oauth-2025-04-20", got.Header.Get("Anthropic-Beta"))
+	})
+
+	t.Run("rejected credential", func(t *testing.T) {
+		err := checker.Check(ctx, "codex", &Credential{Type: CredentialTypeAPIKey, Value: "revoked"})
+		assert.ErrorIs(t, err, ErrCredentialRejected)
+	})
+
+	t.Run("unexpected response", func(t *testing.T) {
+		err := checker.Check(ctx, "codex", &Credential{Type: CredentialTypeAPIKey, Value: "unavailable"})
+		require.Error(t, err)
+		assert.NotErrorIs(t, err, ErrCredentialRejected)
+		assert.Contains(t, err.Error(), "503")
+	})
+
+	t.Run("subscription without access token", func(t *testing.T) {
+		err := checker.Check(ctx, "codex", &Credential{Type: CredentialTypeSubscription, Value: `{"tokens":{}}`})
+		assert.ErrorContains(t, err, "no access token")
+	})
+
+	t.Run("unknown provider", func(t *testing.T) {
+		err := checker.Check(ctx, "aider", &Credential{Type: CredentialTypeAPIKey, Value: "good"})
+		assert.ErrorIs(t, err, ErrUnknownProvider)
+	})
+}
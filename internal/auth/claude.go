@@ -3,6 +3,7 @@ package auth
 import (
 	"errors"
 	"strings"
+	"time"
 )
 
 // Claude provider configuration.
@@ -39,6 +40,18 @@ To get your OAuth token:
   3. Copy the token (starts with sk-ant-)`)
 }
 
+// SubscriptionModTime returns zero: Claude tokens are entered by hand, not
+// read from host files.
+func (p *ClaudeProvider) SubscriptionModTime() (time.Time, error) {
+	return time.Time{}, nil
+}
+
+// Metadata returns empty metadata: Claude OAuth tokens and API keys are
+// opaque and carry no expiry.
+func (p *ClaudeProvider) Metadata(_ *Credential) (*CredentialMetadata, error) {
+	return &CredentialMetadata{}, nil
+}
+
 // ValidateSubscription validates a Claude OAuth token.
 func (p *ClaudeProvider) ValidateSubscription(value string) error {
 	value = strings.TrimSpace(value)
@@ -64,14 +77,14 @@ func (p *ClaudeProvider) ValidateAPIKey(value string) error {
 	return nil
 }
 
-// Store saves a credential to storage.
-func (p *ClaudeProvider) Store(storage Storage, cred Credential) error {
-	return StoreCredential(storage, claudeInfo.KeychainAccount, cred)
+// Store saves a credential to storage under the given profile.
+func (p *ClaudeProvider) Store(storage Storage, profile string, cred Credential) error {
+	return StoreCredential(storage, ProfileAccount(claudeInfo.KeychainAccount, profile), cred)
 }
 
-// Load retrieves the stored credential for Claude.
-func (p *ClaudeProvider) Load(storage Storage) (*Credential, error) {
-	return LoadCredential(storage, claudeInfo.KeychainAccount)
+// Load retrieves the stored Claude credential of the given profile.
+func (p *ClaudeProvider) Load(storage Storage, profile string) (*Credential, error) {
+	return LoadCredential(storage, ProfileAccount(claudeInfo.KeychainAccount, profile))
 }
 
 // isClaudeToken checks if a string looks like a Claude OAuth token.
@@ -1,11 +1,13 @@
 package auth
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
 // codexConfigDir is the path where Codex CLI stores its configuration.
@@ -44,6 +46,36 @@ func (p *CodexProvider) CheckSubscription() (string, error) {
 	return string(authData), nil
 }
 
+// SubscriptionModTime returns when Codex CLI's auth.json last changed.
+func (p *CodexProvider) SubscriptionModTime() (time.Time, error) {
+	return latestModTime(filepath.Join(codexConfigDir, "auth.json"))
+}
+
+// Metadata parses the account, access token expiry, and refresh token from
+// Codex auth.json credentials. API keys carry no metadata.
+func (p *CodexProvider) Metadata(cred *Credential) (*CredentialMetadata, error) {
+	if cred.Type != CredentialTypeSubscription {
+		return &CredentialMetadata{}, nil
+	}
+
+	var authFile struct {
+		Tokens struct {
+			AccessToken  string `json:"access_token"`
+			RefreshToken string `json:"refresh_token"`
+			AccountID    string `json:"account_id"`
+		} `json:"tokens"`
+	}
+	if err := json.Unmarshal([]byte(cred.Value), &authFile); err != nil {
+		return nil, fmt.Errorf("parse codex auth.json: %w", err)
+	}
+
+	return &CredentialMetadata{
+		Account:     authFile.Tokens.AccountID,
+		ExpiresAt:   jwtExpiry(authFile.Tokens.AccessToken),
+		Refreshable: authFile.Tokens.RefreshToken != "",
+	}, nil
+}
+
 // ValidateSubscription validates Codex auth.json credentials.
 func (p *CodexProvider) ValidateSubscription(value string) error {
 	value = strings.TrimSpace(value)
@@ -70,14 +102,14 @@ func (p *CodexProvider) ValidateAPIKey(value string) error {
 	return nil
 }
 
-// Store saves a credential to storage.
-func (p *CodexProvider) Store(storage Storage, cred Credential) error {
-	return StoreCredential(storage, codexInfo.KeychainAccount, cred)
+// Store saves a credential to storage under the given profile.
+func (p *CodexProvider) Store(storage Storage, profile string, cred Credential) error {
+	return StoreCredential(storage, ProfileAccount(codexInfo.KeychainAccount, profile), cred)
 }
 
-// Load retrieves the stored credential for Codex.
-func (p *CodexProvider) Load(storage Storage) (*Credential, error) {
-	return LoadCredential(storage, codexInfo.KeychainAccount)
+// Load retrieves the stored Codex credential of the given profile.
+func (p *CodexProvider) Load(storage Storage, profile string) (*Credential, error) {
+	return LoadCredential(storage, ProfileAccount(codexInfo.KeychainAccount, profile))
 }
 
 // readCodexAuth reads the auth.json file from the Codex config directory.
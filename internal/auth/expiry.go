@@ -0,0 +1,125 @@
+package auth
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// ErrCredentialExpired is returned for stored credentials that have expired
+// and cannot be refreshed by the agent.
+var ErrCredentialExpired = errors.New("credential expired")
+
+// CredentialMetadata is information parsed from a stored credential. Fields
+// a provider cannot determine are left zero.
+type CredentialMetadata struct {
+	// Account identifies the account the credential belongs to, e.g. an
+	// email address or account ID.
+	Account string
+
+	// ExpiresAt is when the credential's access token expires. Zero means
+	// unknown or never.
+	ExpiresAt time.Time
+
+	// Refreshable indicates the credential carries a refresh token the agent
+	// uses to obtain a new access token once it expires.
+	Refreshable bool
+}
+
+// Expired reports whether the access token has expired as of now.
+func (m *CredentialMetadata) Expired(now time.Time) bool {
+	return !m.ExpiresAt.IsZero() && !now.Before(m.ExpiresAt)
+}
+
+// ReimportSubscription replaces a stored subscription credential with the
+// host's when the agent CLI's credential files on the host changed after the
+// stored copy was imported, so a login or token refresh on the host reaches
+// new sessions without running hjk auth again. The host credential is only
+// imported if it belongs to the same account as the stored one. It returns
+// the credential to use and whether it was re-imported.
+func ReimportSubscription(provider Provider, storage Storage, profile string, stored *Credential) (*Credential, bool, error) {
+	if stored.Type != CredentialTypeSubscription {
+		return stored, false, nil
+	}
+
+	modTime, err := provider.SubscriptionModTime()
+	if err != nil {
+		return stored, false, err
+	}
+	if modTime.IsZero() || !modTime.After(stored.SourceModTime) {
+		return stored, false, nil
+	}
+
+	value, err := provider.CheckSubscription()
+	if err != nil {
+		return stored, false, err
+	}
+	if err := provider.ValidateSubscription(value); err != nil {
+		return stored, false, fmt.Errorf("host credentials: %w", err)
+	}
+	fresh := &Credential{
+		Type:          CredentialTypeSubscription,
+		Value:         value,
+		SourceModTime: modTime,
+	}
+
+	storedMeta, err := provider.Metadata(stored)
+	if err != nil {
+		return stored, false, err
+	}
+	freshMeta, err := provider.Metadata(fresh)
+	if err != nil {
+		return stored, false, err
+	}
+	if storedMeta.Account == "" || storedMeta.Account != freshMeta.Account {
+		return stored, false, nil
+	}
+
+	if err := provider.Store(storage, profile, *fresh); err != nil {
+		return stored, false, fmt.Errorf("store credential: %w", err)
+	}
+	return fresh, true, nil
+}
+
+// latestModTime returns the most recent modification time of the given files.
+// Missing files are skipped; if none exist the result is zero.
+func latestModTime(paths ...string) (time.Time, error) {
+	var latest time.Time
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+// jwtExpiry returns the expiry ("exp" claim) of a JWT without verifying its
+// signature. Tokens that are not JWTs or carry no expiry yield zero.
+func jwtExpiry(token string) time.Time {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return time.Time{}
+	}
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == 0 {
+		return time.Time{}
+	}
+	return time.Unix(claims.Exp, 0)
+}
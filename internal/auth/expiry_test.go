@@ -0,0 +1,154 @@
+package auth
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testJWT returns an unsigned JWT whose payload is the given JSON.
+func testJWT(payload string) string {
+	return "eyJhbGciOiJub25lIn0." + base64.RawURLEncoding.EncodeToString([]byte(payload)) + ".sig"
+}
+
+func TestJWTExpiry(t *testing.T) {
+	assert.Equal(t, time.Unix(1760000000, 0), jwtExpiry(testJWT(`{"exp":1760000000}`)))
+	assert.True(t, jwtExpiry(testJWT(`{"sub":"user"}`)).IsZero(), "no exp claim")
+	assert.True(t, jwtExpiry("opaque-token").IsZero(), "not a JWT")
+	assert.True(t, jwtExpiry("a.!!!.c").IsZero(), "invalid payload")
+}
+
+func TestCredentialMetadata_Expired(t *testing.T) {
+	now := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	assert.False(t, (&CredentialMetadata{}).Expired(now), "unknown expiry never expires")
+	assert.False(t, (&CredentialMetadata{ExpiresAt: now.Add(time.Minute)}).Expired(now))
+	assert.True(t, (&CredentialMetadata{ExpiresAt: now}).Expired(now))
+}
+
+func TestGeminiProvider_Metadata(t *testing.T) {
+	p := NewGeminiProvider()
+
+	t.Run("subscription", func(t *testing.T) {
+		cred := &Credential{
+			Type:  CredentialTypeSubscription,
+			Value: `{"oauth_creds":{"access_token":"ya29.test","refresh_token":"1//refresh","expiry_date":1760000000000},"google_accounts":{"active":"dev@example.com"}}`,
+		}
+
+		meta, err := p.Metadata(cred)
+
+		require.NoError(t, err)
+		assert.Equal(t, "dev@example.com", meta.Account)
+		assert.Equal(t, time.UnixMilli(1760000000000), meta.ExpiresAt)
+		assert.True(t, meta.Refreshable)
+	})
+
+	t.Run("api key", func(t *testing.T) {
+		meta, err := p.Metadata(&Credential{Type: CredentialTypeAPIKey, Value: "AIzaTest"})
+
+		require.NoError(t, err)
+		assert.Equal(t, &CredentialMetadata{}, meta)
+	})
+
+	t.Run("invalid credential", func(t *testing.T) {
+		_, err := p.Metadata(&Credential{Type: CredentialTypeSubscription, Value: "{invalid}"})
+		assert.Error(t, err)
+	})
+}
+
+func TestCodexProvider_Metadata(t *testing.T) {
+	p := NewCodexProvider()
+	cred := &Credential{
+		Type:  CredentialTypeSubscription,
+		Value: `{"tokens":{"access_token":"` + testJWT(`{"exp":1760000000}`) + `","refresh_token":"rt-test","account_id":"acct-123"}}`,
+	}
+
+	meta, err := p.Metadata(cred)
+
+	require.NoError(t, err)
+	assert.Equal(t, "acct-123", meta.Account)
+	assert.Equal(t, time.Unix(1760000000, 0), meta.ExpiresAt)
+	assert.True(t, meta.Refreshable)
+}
+
+func TestReimportSubscription(t *testing.T) {
+	originalDir := codexConfigDir
+	t.Cleanup(func() { codexConfigDir = originalDir })
+
+	codexAuth := func(account, refresh string) string {
+		return `{"tokens":{"access_token":"at","refresh_token":"` + refresh + `","account_id":"` + account + `"}}`
+	}
+	writeHost := func(t *testing.T, content string, modTime time.Time) {
+		t.Helper()
+		codexConfigDir = t.TempDir()
+		path := filepath.Join(codexConfigDir, "auth.json")
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		require.NoError(t, os.Chtimes(path, modTime, modTime))
+	}
+	imported := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	stored := &Credential{Type: CredentialTypeSubscription, Value: codexAuth("acct-123", "old"), SourceModTime: imported}
+	p := NewCodexProvider()
+
+	t.Run("re-imports newer host credentials", func(t *testing.T) {
+		writeHost(t, codexAuth("acct-123", "new"), imported.Add(time.Hour))
+		storage := mapStorage{}
+
+		cred, reimported, err := ReimportSubscription(p, storage, "work", stored)
+
+		require.NoError(t, err)
+		assert.True(t, reimported)
+		assert.Equal(t, codexAuth("acct-123", "new"), cred.Value)
+		assert.Equal(t, imported.Add(time.Hour), cred.SourceModTime.UTC())
+
+		saved, err := p.Load(storage, "work")
+		require.NoError(t, err)
+		assert.Equal(t, cred.Value, saved.Value)
+	})
+
+	t.Run("keeps credentials when host is not newer", func(t *testing.T) {
+		writeHost(t, codexAuth("acct-123", "new"), imported)
+
+		cred, reimported, err := ReimportSubscription(p, mapStorage{}, DefaultProfile, stored)
+
+		require.NoError(t, err)
+		assert.False(t, reimported)
+		assert.Same(t, stored, cred)
+	})
+
+	t.Run("keeps credentials of another account", func(t *testing.T) {
+		writeHost(t, codexAuth("acct-456", "new"), imported.Add(time.Hour))
+		storage := mapStorage{}
+
+		cred, reimported, err := ReimportSubscription(p, storage, "work", stored)
+
+		require.NoError(t, err)
+		assert.False(t, reimported)
+		assert.Same(t, stored, cred)
+		assert.Empty(t, storage)
+	})
+
+	t.Run("keeps credentials when host has none", func(t *testing.T) {
+		codexConfigDir = t.TempDir()
+
+		_, reimported, err := ReimportSubscription(p, mapStorage{}, DefaultProfile, stored)
+
+		require.NoError(t, err)
+		assert.False(t, reimported)
+	})
+
+	t.Run("ignores api keys", func(t *testing.T) {
+		writeHost(t, codexAuth("acct-123", "new"), imported.Add(time.Hour))
+		apiKey := &Credential{Type: CredentialTypeAPIKey, Value: "sk-test"}
+
+		cred, reimported, err := ReimportSubscription(p, mapStorage{}, DefaultProfile, apiKey)
+
+		require.NoError(t, err)
+		assert.False(t, reimported)
+		assert.Same(t, apiKey, cred)
+	})
+}
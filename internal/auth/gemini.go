@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
 // geminiConfigDir is the path where Gemini CLI stores its configuration.
@@ -58,6 +59,51 @@ func (p *GeminiProvider) CheckSubscription() (string, error) {
 	return string(configJSON), nil
 }
 
+// SubscriptionModTime returns when Gemini CLI's cached credentials last changed.
+func (p *GeminiProvider) SubscriptionModTime() (time.Time, error) {
+	return latestModTime(
+		filepath.Join(geminiConfigDir, "oauth_creds.json"),
+		filepath.Join(geminiConfigDir, "google_accounts.json"),
+	)
+}
+
+// Metadata parses the account, access token expiry, and refresh token from
+// Gemini OAuth credentials. API keys carry no metadata.
+func (p *GeminiProvider) Metadata(cred *Credential) (*CredentialMetadata, error) {
+	if cred.Type != CredentialTypeSubscription {
+		return &CredentialMetadata{}, nil
+	}
+
+	var config GeminiConfig
+	if err := json.Unmarshal([]byte(cred.Value), &config); err != nil {
+		return nil, fmt.Errorf("parse gemini credentials: %w", err)
+	}
+	var oauthCreds struct {
+		RefreshToken string `json:"refresh_token"`
+		ExpiryDate   int64  `json:"expiry_date"` // Milliseconds since the epoch
+	}
+	if err := json.Unmarshal(config.OAuthCreds, &oauthCreds); err != nil {
+		return nil, fmt.Errorf("parse oauth_creds: %w", err)
+	}
+	var accounts struct {
+		Active string `json:"active"`
+	}
+	if len(config.GoogleAccounts) > 0 {
+		if err := json.Unmarshal(config.GoogleAccounts, &accounts); err != nil {
+			return nil, fmt.Errorf("parse google_accounts: %w", err)
+		}
+	}
+
+	meta := &CredentialMetadata{
+		Account:     accounts.Active,
+		Refreshable: oauthCreds.RefreshToken != "",
+	}
+	if oauthCreds.ExpiryDate > 0 {
+		meta.ExpiresAt = time.UnixMilli(oauthCreds.ExpiryDate)
+	}
+	return meta, nil
+}
+
 // ValidateSubscription validates Gemini OAuth credentials.
 func (p *GeminiProvider) ValidateSubscription(value string) error {
 	value = strings.TrimSpace(value)
@@ -105,14 +151,14 @@ func (p *GeminiProvider) ValidateAPIKey(value string) error {
 	return nil
 }
 
-// Store saves a credential to storage.
-func (p *GeminiProvider) Store(storage Storage, cred Credential) error {
-	return StoreCredential(storage, geminiInfo.KeychainAccount, cred)
+// Store saves a credential to storage under the given profile.
+func (p *GeminiProvider) Store(storage Storage, profile string, cred Credential) error {
+	return StoreCredential(storage, ProfileAccount(geminiInfo.KeychainAccount, profile), cred)
 }
 
-// Load retrieves the stored credential for Gemini.
-func (p *GeminiProvider) Load(storage Storage) (*Credential, error) {
-	return LoadCredential(storage, geminiInfo.KeychainAccount)
+// Load retrieves the stored Gemini credential of the given profile.
+func (p *GeminiProvider) Load(storage Storage, profile string) (*Credential, error) {
+	return LoadCredential(storage, ProfileAccount(geminiInfo.KeychainAccount, profile))
 }
 
 // readGeminiConfig reads OAuth credentials and account info from Gemini CLI's cache.
@@ -4,9 +4,9 @@
 package mocks
 
 import (
-	"sync"
-
 	"github.com/jmgilman/headjack/internal/auth"
+	"sync"
+	"time"
 )
 
 // Ensure, that ProviderMock does implement auth.Provider.
@@ -25,12 +25,18 @@ var _ auth.Provider = &ProviderMock{}
 //			InfoFunc: func() auth.ProviderInfo {
 //				panic("mock out the Info method")
 //			},
-//			LoadFunc: func(storage auth.Storage) (*auth.Credential, error) {
+//			LoadFunc: func(storage auth.Storage, profile string) (*auth.Credential, error) {
 //				panic("mock out the Load method")
 //			},
-//			StoreFunc: func(storage auth.Storage, cred auth.Credential) error {
+//			MetadataFunc: func(cred *auth.Credential) (*auth.CredentialMetadata, error) {
+//				panic("mock out the Metadata method")
+//			},
+//			StoreFunc: func(storage auth.Storage, profile string, cred auth.Credential) error {
 //				panic("mock out the Store method")
 //			},
+//			SubscriptionModTimeFunc: func() (time.Time, error) {
+//				panic("mock out the SubscriptionModTime method")
+//			},
 //			ValidateAPIKeyFunc: func(value string) error {
 //				panic("mock out the ValidateAPIKey method")
 //			},
@@ -51,10 +57,16 @@ type ProviderMock struct {
 	InfoFunc func() auth.ProviderInfo
 
 	// LoadFunc mocks the Load method.
-	LoadFunc func(storage auth.Storage) (*auth.Credential, error)
+	LoadFunc func(storage auth.Storage, profile string) (*auth.Credential, error)
+
+	// MetadataFunc mocks the Metadata method.
+	MetadataFunc func(cred *auth.Credential) (*auth.CredentialMetadata, error)
 
 	// StoreFunc mocks the Store method.
-	StoreFunc func(storage auth.Storage, cred auth.Credential) error
+	StoreFunc func(storage auth.Storage, profile string, cred auth.Credential) error
+
+	// SubscriptionModTimeFunc mocks the SubscriptionModTime method.
+	SubscriptionModTimeFunc func() (time.Time, error)
 
 	// ValidateAPIKeyFunc mocks the ValidateAPIKey method.
 	ValidateAPIKeyFunc func(value string) error
@@ -74,14 +86,26 @@ type ProviderMock struct {
 		Load []struct {
 			// Storage is the storage argument value.
 			Storage auth.Storage
+			// Profile is the profile argument value.
+			Profile string
+		}
+		// Metadata holds details about calls to the Metadata method.
+		Metadata []struct {
+			// Cred is the cred argument value.
+			Cred *auth.Credential
 		}
 		// Store holds details about calls to the Store method.
 		Store []struct {
 			// Storage is the storage argument value.
 			Storage auth.Storage
+			// Profile is the profile argument value.
+			Profile string
 			// Cred is the cred argument value.
 			Cred auth.Credential
 		}
+		// SubscriptionModTime holds details about calls to the SubscriptionModTime method.
+		SubscriptionModTime []struct {
+		}
 		// ValidateAPIKey holds details about calls to the ValidateAPIKey method.
 		ValidateAPIKey []struct {
 			// Value is the value argument value.
@@ -96,7 +120,9 @@ type ProviderMock struct {
 	lockCheckSubscription    sync.RWMutex
 	lockInfo                 sync.RWMutex
 	lockLoad                 sync.RWMutex
+	lockMetadata             sync.RWMutex
 	lockStore                sync.RWMutex
+	lockSubscriptionModTime  sync.RWMutex
 	lockValidateAPIKey       sync.RWMutex
 	lockValidateSubscription sync.RWMutex
 }
@@ -156,19 +182,21 @@ func (mock *ProviderMock) InfoCalls() []struct {
 }
 
 // Load calls LoadFunc.
-func (mock *ProviderMock) Load(storage auth.Storage) (*auth.Credential, error) {
+func (mock *ProviderMock) Load(storage auth.Storage, profile string) (*auth.Credential, error) {
 	if mock.LoadFunc == nil {
 		panic("ProviderMock.LoadFunc: method is nil but Provider.Load was just called")
 	}
 	callInfo := struct {
 		Storage auth.Storage
+		Profile string
 	}{
 		Storage: storage,
+		Profile: profile,
 	}
 	mock.lockLoad.Lock()
 	mock.calls.Load = append(mock.calls.Load, callInfo)
 	mock.lockLoad.Unlock()
-	return mock.LoadFunc(storage)
+	return mock.LoadFunc(storage, profile)
 }
 
 // LoadCalls gets all the calls that were made to Load.
@@ -177,9 +205,11 @@ func (mock *ProviderMock) Load(storage auth.Storage) (*auth.Credential, error) {
 //	len(mockedProvider.LoadCalls())
 func (mock *ProviderMock) LoadCalls() []struct {
 	Storage auth.Storage
+	Profile string
 } {
 	var calls []struct {
 		Storage auth.Storage
+		Profile string
 	}
 	mock.lockLoad.RLock()
 	calls = mock.calls.Load
@@ -187,22 +217,56 @@ func (mock *ProviderMock) LoadCalls() []struct {
 	return calls
 }
 
+// Metadata calls MetadataFunc.
+func (mock *ProviderMock) Metadata(cred *auth.Credential) (*auth.CredentialMetadata, error) {
+	if mock.MetadataFunc == nil {
+		panic("ProviderMock.MetadataFunc: method is nil but Provider.Metadata was just called")
+	}
+	callInfo := struct {
+		Cred *auth.Credential
+	}{
+		Cred: cred,
+	}
+	mock.lockMetadata.Lock()
+	mock.calls.Metadata = append(mock.calls.Metadata, callInfo)
+	mock.lockMetadata.Unlock()
+	return mock.MetadataFunc(cred)
+}
+
+// MetadataCalls gets all the calls that were made to Metadata.
+// Check the length with:
+//
+//	len(mockedProvider.MetadataCalls())
+func (mock *ProviderMock) MetadataCalls() []struct {
+	Cred *auth.Credential
+} {
+	var calls []struct {
+		Cred *auth.Credential
+	}
+	mock.lockMetadata.RLock()
+	calls = mock.calls.Metadata
+	mock.lockMetadata.RUnlock()
+	return calls
+}
+
 // Store calls StoreFunc.
-func (mock *ProviderMock) Store(storage auth.Storage, cred auth.Credential) error {
+func (mock *ProviderMock) Store(storage auth.Storage, profile string, cred auth.Credential) error {
 	if mock.StoreFunc == nil {
 		panic("ProviderMock.StoreFunc: method is nil but Provider.Store was just called")
 	}
 	callInfo := struct {
 		Storage auth.Storage
+		Profile string
 		Cred    auth.Credential
 	}{
 		Storage: storage,
+		Profile: profile,
 		Cred:    cred,
 	}
 	mock.lockStore.Lock()
 	mock.calls.Store = append(mock.calls.Store, callInfo)
 	mock.lockStore.Unlock()
-	return mock.StoreFunc(storage, cred)
+	return mock.StoreFunc(storage, profile, cred)
 }
 
 // StoreCalls gets all the calls that were made to Store.
@@ -211,10 +275,12 @@ func (mock *ProviderMock) Store(storage auth.Storage, cred auth.Credential) erro
 //	len(mockedProvider.StoreCalls())
 func (mock *ProviderMock) StoreCalls() []struct {
 	Storage auth.Storage
+	Profile string
 	Cred    auth.Credential
 } {
 	var calls []struct {
 		Storage auth.Storage
+		Profile string
 		Cred    auth.Credential
 	}
 	mock.lockStore.RLock()
@@ -223,6 +289,33 @@ func (mock *ProviderMock) StoreCalls() []struct {
 	return calls
 }
 
+// SubscriptionModTime calls SubscriptionModTimeFunc.
+func (mock *ProviderMock) SubscriptionModTime() (time.Time, error) {
+	if mock.SubscriptionModTimeFunc == nil {
+		panic("ProviderMock.SubscriptionModTimeFunc: method is nil but Provider.SubscriptionModTime was just called")
+	}
+	callInfo := struct {
+	}{}
+	mock.lockSubscriptionModTime.Lock()
+	mock.calls.SubscriptionModTime = append(mock.calls.SubscriptionModTime, callInfo)
+	mock.lockSubscriptionModTime.Unlock()
+	return mock.SubscriptionModTimeFunc()
+}
+
+// SubscriptionModTimeCalls gets all the calls that were made to SubscriptionModTime.
+// Check the length with:
+//
+//	len(mockedProvider.SubscriptionModTimeCalls())
+func (mock *ProviderMock) SubscriptionModTimeCalls() []struct {
+} {
+	var calls []struct {
+	}
+	mock.lockSubscriptionModTime.RLock()
+	calls = mock.calls.SubscriptionModTime
+	mock.lockSubscriptionModTime.RUnlock()
+	return calls
+}
+
 // ValidateAPIKey calls ValidateAPIKeyFunc.
 func (mock *ProviderMock) ValidateAPIKey(value string) error {
 	if mock.ValidateAPIKeyFunc == nil {
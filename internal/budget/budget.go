@@ -0,0 +1,153 @@
+// Package budget defines spending limits for agent sessions and persists
+// which limits have been warned about or used up.
+//
+// A limit caps either the cost an agent reports or the tokens it uses, over
+// one of three scopes: a single session, all of an agent's sessions in an
+// instance, or all of an agent's sessions on a calendar day.
+package budget
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmgilman/headjack/internal/meter"
+)
+
+// ErrInvalidLimit is returned when a limit cannot be parsed.
+var ErrInvalidLimit = errors.New("invalid budget limit")
+
+// DefaultWarnAt is the fraction of a limit at which a warning is issued
+// when a policy does not set one.
+const DefaultWarnAt = 0.8
+
+// Scope identifies what a limit applies to.
+type Scope string
+
+// Scope constants.
+const (
+	ScopeSession  Scope = "session"  // A single session
+	ScopeInstance Scope = "instance" // All of an agent's sessions in an instance
+	ScopeDaily    Scope = "daily"    // All of an agent's sessions on a calendar day
+)
+
+// Scopes lists all scopes from narrowest to widest.
+var Scopes = []Scope{ScopeSession, ScopeInstance, ScopeDaily}
+
+// Limit caps the cost or tokens a scope may use. At most one of its fields is set.
+type Limit struct {
+	CostUSD float64 // Maximum reported cost (0 = no cost limit)
+	Tokens  int64   // Maximum total tokens (0 = no token limit)
+}
+
+// ParseLimit parses a limit. Costs are written with a dollar sign ("$5",
+// "$0.50"); anything else is a token count with an optional k or M suffix
+// ("500k", "2M", "100000"). An empty string yields no limit.
+func ParseLimit(s string) (Limit, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Limit{}, nil
+	}
+
+	if cost, ok := strings.CutPrefix(s, "$"); ok {
+		v, err := strconv.ParseFloat(cost, 64)
+		if err != nil || v <= 0 {
+			return Limit{}, fmt.Errorf("%w: %q (use a cost like $5 or a token count like 2M)", ErrInvalidLimit, s)
+		}
+		return Limit{CostUSD: v}, nil
+	}
+
+	tokens := s
+	multiplier := 1.0
+	switch {
+	case strings.HasSuffix(s, "k"), strings.HasSuffix(s, "K"):
+		multiplier = 1_000
+		tokens = s[:len(s)-1]
+	case strings.HasSuffix(s, "M"), strings.HasSuffix(s, "m"):
+		multiplier = 1_000_000
+		tokens = s[:len(s)-1]
+	}
+	v, err := strconv.ParseFloat(tokens, 64)
+	if err != nil || v <= 0 {
+		return Limit{}, fmt.Errorf("%w: %q (use a cost like $5 or a token count like 2M)", ErrInvalidLimit, s)
+	}
+	return Limit{Tokens: int64(v * multiplier)}, nil
+}
+
+// IsZero reports whether the limit is unset.
+func (l Limit) IsZero() bool {
+	return l == Limit{}
+}
+
+// Used returns the fraction of the limit that u has used, or 0 for no limit.
+func (l Limit) Used(u meter.Usage) float64 {
+	switch {
+	case l.CostUSD > 0:
+		return u.CostUSD / l.CostUSD
+	case l.Tokens > 0:
+		return float64(u.TotalTokens()) / float64(l.Tokens)
+	default:
+		return 0
+	}
+}
+
+// String formats the limit as it would be configured.
+func (l Limit) String() string {
+	switch {
+	case l.CostUSD > 0:
+		return fmt.Sprintf("$%.2f", l.CostUSD)
+	case l.Tokens > 0:
+		return fmt.Sprintf("%d tokens", l.Tokens)
+	default:
+		return "none"
+	}
+}
+
+// Policy holds the limits of one agent.
+type Policy struct {
+	Session  Limit
+	Instance Limit
+	Daily    Limit
+	WarnAt   float64 // Fraction of a limit at which to warn (0 = DefaultWarnAt)
+}
+
+// IsZero reports whether the policy sets no limits.
+func (p Policy) IsZero() bool {
+	return p.Session.IsZero() && p.Instance.IsZero() && p.Daily.IsZero()
+}
+
+// Limit returns the limit for a scope.
+func (p Policy) Limit(scope Scope) Limit {
+	switch scope {
+	case ScopeSession:
+		return p.Session
+	case ScopeInstance:
+		return p.Instance
+	case ScopeDaily:
+		return p.Daily
+	default:
+		return Limit{}
+	}
+}
+
+// WarnThreshold returns the fraction of a limit at which to warn.
+func (p Policy) WarnThreshold() float64 {
+	if p.WarnAt <= 0 {
+		return DefaultWarnAt
+	}
+	return p.WarnAt
+}
+
+// DayStart returns the start of the local calendar day containing t.
+func DayStart(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// NextDay returns the start of the local calendar day after t, when daily
+// limits reset.
+func NextDay(t time.Time) time.Time {
+	return DayStart(t).AddDate(0, 0, 1)
+}
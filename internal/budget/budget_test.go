@@ -0,0 +1,76 @@
+package budget
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmgilman/headjack/internal/meter"
+)
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    Limit
+		wantErr bool
+	}{
+		{"empty", "", Limit{}, false},
+		{"cost", "$5", Limit{CostUSD: 5}, false},
+		{"fractional cost", "$0.50", Limit{CostUSD: 0.5}, false},
+		{"tokens", "100000", Limit{Tokens: 100_000}, false},
+		{"thousands", "500k", Limit{Tokens: 500_000}, false},
+		{"millions", "2M", Limit{Tokens: 2_000_000}, false},
+		{"fractional millions", "1.5M", Limit{Tokens: 1_500_000}, false},
+		{"negative cost", "$-1", Limit{}, true},
+		{"zero tokens", "0", Limit{}, true},
+		{"garbage", "lots", Limit{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLimit(tt.value)
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrInvalidLimit)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestLimit_Used(t *testing.T) {
+	u := meter.Usage{InputTokens: 100, OutputTokens: 300, CacheReadTokens: 600, CostUSD: 2.5}
+
+	assert.InDelta(t, 0.5, Limit{CostUSD: 5}.Used(u), 1e-9)
+	assert.InDelta(t, 2.0, Limit{Tokens: 500}.Used(u), 1e-9)
+	assert.Zero(t, Limit{}.Used(u))
+}
+
+func TestLimit_String(t *testing.T) {
+	assert.Equal(t, "$5.00", Limit{CostUSD: 5}.String())
+	assert.Equal(t, "2000000 tokens", Limit{Tokens: 2_000_000}.String())
+	assert.Equal(t, "none", Limit{}.String())
+}
+
+func TestPolicy(t *testing.T) {
+	p := Policy{Session: Limit{CostUSD: 1}, Daily: Limit{Tokens: 10}}
+
+	assert.False(t, p.IsZero())
+	assert.True(t, Policy{WarnAt: 0.5}.IsZero())
+	assert.Equal(t, Limit{CostUSD: 1}, p.Limit(ScopeSession))
+	assert.True(t, p.Limit(ScopeInstance).IsZero())
+	assert.Equal(t, Limit{Tokens: 10}, p.Limit(ScopeDaily))
+	assert.InDelta(t, DefaultWarnAt, p.WarnThreshold(), 1e-9)
+	assert.InDelta(t, 0.5, Policy{WarnAt: 0.5}.WarnThreshold(), 1e-9)
+}
+
+func TestNextDay(t *testing.T) {
+	now := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+
+	assert.Equal(t, time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC), DayStart(now))
+	assert.Equal(t, time.Date(2026, 1, 3, 0, 0, 0, 0, time.UTC), NextDay(now))
+}
@@ -0,0 +1,225 @@
+package budget
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/jmgilman/headjack/internal/meter"
+)
+
+const (
+	fileMode = 0o600
+	dirMode  = 0o750
+)
+
+// warningTTL is how long a warning is remembered. Scopes that are still
+// active after this long are warned about again.
+const warningTTL = 30 * 24 * time.Hour
+
+// State is the persisted budget state.
+type State struct {
+	// Warned records when each limit was last warned about, keyed by WarningKey.
+	Warned map[string]time.Time `json:"warned,omitempty"`
+
+	// Exhausted records, per agent, when a used-up daily limit resets.
+	Exhausted map[string]time.Time `json:"exhausted,omitempty"`
+
+	// Baselines records, per session ID, the usage of sessions started before
+	// the current day when they were first seen on it.
+	Baselines map[string]Baseline `json:"baselines,omitempty"`
+}
+
+// Baseline is a session's usage at the start of a day.
+type Baseline struct {
+	Day   string      `json:"day"` // The day, as time.DateOnly
+	Usage meter.Usage `json:"usage"`
+}
+
+// WarningKey identifies a limit for a scope. id is the session ID for
+// ScopeSession, the instance ID for ScopeInstance, and ignored for ScopeDaily,
+// which is keyed by the day of now instead.
+func WarningKey(scope Scope, agent, id string, now time.Time) string {
+	if scope == ScopeDaily {
+		id = DayStart(now).Format(time.DateOnly)
+	}
+	return string(scope) + "/" + agent + "/" + id
+}
+
+// Blocked reports whether an agent's daily limit is used up as of now, and if
+// so when it resets.
+func (s *State) Blocked(agent string, now time.Time) (time.Time, bool) {
+	until, ok := s.Exhausted[agent]
+	if !ok || !now.Before(until) {
+		return time.Time{}, false
+	}
+	return until, true
+}
+
+// Exhaust marks an agent's daily limit as used up until the next day.
+func (s *State) Exhaust(agent string, now time.Time) {
+	if s.Exhausted == nil {
+		s.Exhausted = make(map[string]time.Time)
+	}
+	s.Exhausted[agent] = NextDay(now)
+}
+
+// Warn records a warning for key, reporting false if it was already issued.
+func (s *State) Warn(key string, now time.Time) bool {
+	if _, ok := s.Warned[key]; ok {
+		return false
+	}
+	if s.Warned == nil {
+		s.Warned = make(map[string]time.Time)
+	}
+	s.Warned[key] = now
+	return true
+}
+
+// DailyUsage returns the part of a session's total usage counted against the
+// daily limit of the day of now. All of it counts for sessions started that
+// day. Sessions started earlier count only usage beyond their total when
+// first seen on the day, which is recorded as their baseline.
+func (s *State) DailyUsage(sessionID string, started time.Time, total meter.Usage, now time.Time) meter.Usage {
+	if !started.Before(DayStart(now)) {
+		return total
+	}
+	baseline, ok := s.StartOfDay(sessionID, now)
+	if !ok {
+		if s.Baselines == nil {
+			s.Baselines = make(map[string]Baseline)
+		}
+		s.Baselines[sessionID] = Baseline{Day: DayStart(now).Format(time.DateOnly), Usage: total}
+		baseline = total
+	}
+	return total.Since(baseline)
+}
+
+// StartOfDay returns the baseline recorded for a session on the day of now.
+func (s *State) StartOfDay(sessionID string, now time.Time) (meter.Usage, bool) {
+	baseline, ok := s.Baselines[sessionID]
+	if !ok || baseline.Day != DayStart(now).Format(time.DateOnly) {
+		return meter.Usage{}, false
+	}
+	return baseline.Usage, true
+}
+
+// Prune forgets exhausted limits that have reset, old warnings, and
+// baselines of earlier days.
+func (s *State) Prune(now time.Time) {
+	for agent, until := range s.Exhausted {
+		if !now.Before(until) {
+			delete(s.Exhausted, agent)
+		}
+	}
+	for key, at := range s.Warned {
+		if now.Sub(at) > warningTTL {
+			delete(s.Warned, key)
+		}
+	}
+	day := DayStart(now).Format(time.DateOnly)
+	for id, baseline := range s.Baselines {
+		if baseline.Day != day {
+			delete(s.Baselines, id)
+		}
+	}
+}
+
+// Store persists budget state.
+//
+//go:generate go run github.com/matryer/moq@latest -pkg mocks -out mocks/store.go . Store
+type Store interface {
+	// Load returns the current state.
+	Load(ctx context.Context) (*State, error)
+
+	// Update applies fn to the current state and saves the result. No other
+	// update can run concurrently.
+	Update(ctx context.Context, fn func(*State) error) error
+}
+
+// FileStore is a Store backed by a JSON file.
+type FileStore struct {
+	path string
+}
+
+// NewFileStore creates a FileStore at the given path.
+// The file and its parent directory are created on first update.
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+// Load returns the current state. A missing file yields an empty state.
+func (f *FileStore) Load(ctx context.Context) (*State, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &State{}, nil
+		}
+		return nil, fmt.Errorf("read budget state: %w", err)
+	}
+	return decodeState(data)
+}
+
+// Update applies fn to the current state under an exclusive lock and saves
+// the result. The state is left unchanged if fn returns an error.
+func (f *FileStore) Update(ctx context.Context, fn func(*State) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(f.path), dirMode); err != nil {
+		return fmt.Errorf("create budget directory: %w", err)
+	}
+
+	// Lock a separate file so the state file can be replaced atomically
+	lock, err := os.OpenFile(f.path+".lock", os.O_CREATE|os.O_RDWR, fileMode)
+	if err != nil {
+		return fmt.Errorf("open budget lock: %w", err)
+	}
+	defer lock.Close()
+	if err := syscall.Flock(int(lock.Fd()), syscall.LOCK_EX); err != nil {
+		return fmt.Errorf("lock budget state: %w", err)
+	}
+	//nolint:errcheck // Unlock errors are not actionable; closing releases the lock anyway
+	defer syscall.Flock(int(lock.Fd()), syscall.LOCK_UN)
+
+	state, err := f.Load(ctx)
+	if err != nil {
+		return err
+	}
+	if err := fn(state); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal budget state: %w", err)
+	}
+	tmp := f.path + ".tmp"
+	if err := os.WriteFile(tmp, data, fileMode); err != nil {
+		return fmt.Errorf("write budget state: %w", err)
+	}
+	if err := os.Rename(tmp, f.path); err != nil {
+		return fmt.Errorf("replace budget state: %w", err)
+	}
+	return nil
+}
+
+func decodeState(data []byte) (*State, error) {
+	var state State
+	if len(data) == 0 {
+		return &state, nil
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("decode budget state: %w", err)
+	}
+	return &state, nil
+}
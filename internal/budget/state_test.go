@@ -0,0 +1,138 @@
+package budget
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmgilman/headjack/internal/meter"
+)
+
+func TestState(t *testing.T) {
+	now := time.Date(2026, 1, 2, 15, 0, 0, 0, time.UTC)
+
+	t.Run("blocks an exhausted agent until the next day", func(t *testing.T) {
+		var s State
+		s.Exhaust("claude", now)
+
+		until, blocked := s.Blocked("claude", now.Add(time.Hour))
+		assert.True(t, blocked)
+		assert.Equal(t, NextDay(now), until)
+
+		_, blocked = s.Blocked("claude", NextDay(now))
+		assert.False(t, blocked)
+		_, blocked = s.Blocked("codex", now)
+		assert.False(t, blocked)
+	})
+
+	t.Run("warns once per key", func(t *testing.T) {
+		var s State
+		key := WarningKey(ScopeSession, "claude", "sess1", now)
+
+		assert.True(t, s.Warn(key, now))
+		assert.False(t, s.Warn(key, now.Add(time.Minute)))
+	})
+
+	t.Run("keys daily warnings by day", func(t *testing.T) {
+		today := WarningKey(ScopeDaily, "claude", "ignored", now)
+		tomorrow := WarningKey(ScopeDaily, "claude", "ignored", NextDay(now))
+
+		assert.Equal(t, "daily/claude/2026-01-02", today)
+		assert.NotEqual(t, today, tomorrow)
+	})
+
+	t.Run("counts only usage from the day", func(t *testing.T) {
+		var s State
+		yesterday := now.Add(-24 * time.Hour)
+
+		assert.Equal(t, meter.Usage{CostUSD: 4}, s.DailyUsage("new", now.Add(-time.Hour), meter.Usage{CostUSD: 4}, now))
+		assert.Equal(t, meter.Usage{}, s.DailyUsage("old", yesterday, meter.Usage{CostUSD: 10}, now))
+		assert.Equal(t, meter.Usage{CostUSD: 3}, s.DailyUsage("old", yesterday, meter.Usage{CostUSD: 13}, now.Add(time.Hour)))
+		baseline, ok := s.StartOfDay("old", now)
+		assert.True(t, ok)
+		assert.Equal(t, meter.Usage{CostUSD: 10}, baseline)
+		_, ok = s.StartOfDay("new", now)
+		assert.False(t, ok)
+
+		tomorrow := NextDay(now)
+		assert.Equal(t, meter.Usage{}, s.DailyUsage("old", yesterday, meter.Usage{CostUSD: 15}, tomorrow))
+		assert.Equal(t, meter.Usage{CostUSD: 1}, s.DailyUsage("old", yesterday, meter.Usage{CostUSD: 16}, tomorrow))
+	})
+
+	t.Run("prunes reset limits, old warnings and baselines", func(t *testing.T) {
+		var s State
+		s.Exhaust("claude", now)
+		s.Warn("old", now.Add(-warningTTL-time.Hour))
+		s.Warn("recent", now)
+		s.DailyUsage("sess1", now.Add(-24*time.Hour), meter.Usage{CostUSD: 1}, now)
+
+		s.Prune(NextDay(now))
+
+		assert.Empty(t, s.Exhausted)
+		assert.NotContains(t, s.Warned, "old")
+		assert.Contains(t, s.Warned, "recent")
+		assert.Empty(t, s.Baselines)
+	})
+}
+
+func TestFileStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 1, 2, 15, 0, 0, 0, time.UTC)
+
+	t.Run("missing file yields empty state", func(t *testing.T) {
+		store := NewFileStore(filepath.Join(t.TempDir(), "budget.json"))
+
+		state, err := store.Load(ctx)
+
+		require.NoError(t, err)
+		assert.Empty(t, state.Warned)
+		assert.Empty(t, state.Exhausted)
+	})
+
+	t.Run("persists updates", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "nested", "budget.json")
+		store := NewFileStore(path)
+
+		require.NoError(t, store.Update(ctx, func(s *State) error {
+			s.Exhaust("claude", now)
+			s.Warn("session/claude/sess1", now)
+			return nil
+		}))
+
+		state, err := NewFileStore(path).Load(ctx)
+		require.NoError(t, err)
+		_, blocked := state.Blocked("claude", now)
+		assert.True(t, blocked)
+		assert.Contains(t, state.Warned, "session/claude/sess1")
+
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(fileMode), info.Mode().Perm())
+	})
+
+	t.Run("discards the update when fn fails", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "budget.json")
+		store := NewFileStore(path)
+		require.NoError(t, store.Update(ctx, func(s *State) error {
+			s.Warn("kept", now)
+			return nil
+		}))
+
+		err := store.Update(ctx, func(s *State) error {
+			s.Warn("dropped", now)
+			return errors.New("boom")
+		})
+
+		require.Error(t, err)
+		state, err := store.Load(ctx)
+		require.NoError(t, err)
+		assert.Contains(t, state.Warned, "kept")
+		assert.NotContains(t, state.Warned, "dropped")
+	})
+}
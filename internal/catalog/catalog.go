@@ -5,6 +5,8 @@ import (
 	"context"
 	"errors"
 	"time"
+
+	"github.com/jmgilman/headjack/internal/meter"
 )
 
 // Sentinel errors for catalog operations.
@@ -26,9 +28,11 @@ const (
 )
 
 // SessionType represents the type of session running within an instance.
+// Agent sessions use the agent's name, so custom agents from configuration
+// are session types too.
 type SessionType string
 
-// SessionType constants for session types.
+// SessionType constants for the shell and the built-in agents.
 const (
 	SessionTypeShell  SessionType = "shell"
 	SessionTypeClaude SessionType = "claude"
@@ -38,12 +42,16 @@ const (
 
 // Session represents a persistent, attachable process running within an instance.
 type Session struct {
-	ID           string      `json:"id"`             // Unique session identifier
-	Name         string      `json:"name"`           // Human-readable name (e.g., "happy-panda")
-	Type         SessionType `json:"type"`           // Session type (shell, claude, gemini, codex)
-	MuxSessionID string      `json:"mux_session_id"` // Multiplexer session identifier
-	CreatedAt    time.Time   `json:"created_at"`     // Creation timestamp
-	LastAccessed time.Time   `json:"last_accessed"`  // Last access timestamp (for MRU tracking)
+	ID              string        `json:"id"`                        // Unique session identifier
+	Name            string        `json:"name"`                      // Human-readable name (e.g., "happy-panda")
+	Type            SessionType   `json:"type"`                      // Session type (shell or an agent name)
+	MuxSessionID    string        `json:"mux_session_id"`            // Multiplexer session identifier
+	CreatedAt       time.Time     `json:"created_at"`                // Creation timestamp
+	LastAccessed    time.Time     `json:"last_accessed"`             // Last access timestamp (for MRU tracking)
+	LastInteraction time.Time     `json:"last_interaction,omitzero"` // Last attach or input sent (zero if never)
+	MaxRuntime      time.Duration `json:"max_runtime,omitempty"`     // Runtime after which the session is killed (0 = unlimited)
+	AuthProfile     string        `json:"auth_profile,omitempty"`    // Credential profile the agent was started with
+	Usage           meter.Usage   `json:"usage,omitzero"`            // Model usage read from the agent's session files
 }
 
 // Entry represents a persisted instance record.
@@ -57,6 +65,11 @@ type Entry struct {
 	CreatedAt   time.Time `json:"created_at"`
 	Status      Status    `json:"status"`
 	Sessions    []Session `json:"sessions"` // Sessions running within this instance
+
+	// UsageFiles maps each agent session file in the container to the ID of
+	// the session it was attributed to, so files keep their owner after the
+	// session ends.
+	UsageFiles map[string]string `json:"usage_files,omitempty"`
 }
 
 // ListFilter filters catalog queries.
@@ -85,6 +98,13 @@ type Store interface {
 	// Returns ErrNotFound if not found.
 	Update(ctx context.Context, entry *Entry) error
 
+	// Modify applies fn to the current entry with the given ID and saves the
+	// result, with no other change to the catalog in between. Use it instead
+	// of Get and Update when the change is made from an older copy of the
+	// entry. The entry is left unchanged if fn returns an error.
+	// Returns ErrNotFound if not found.
+	Modify(ctx context.Context, id string, fn func(*Entry) error) error
+
 	// Remove deletes an entry by ID.
 	// Returns ErrNotFound if not found.
 	Remove(ctx context.Context, id string) error
@@ -32,6 +32,9 @@ var _ catalog.Store = &StoreMock{}
 //			ListFunc: func(ctx context.Context, filter catalog.ListFilter) ([]catalog.Entry, error) {
 //				panic("mock out the List method")
 //			},
+//			ModifyFunc: func(ctx context.Context, id string, fn func(*catalog.Entry) error) error {
+//				panic("mock out the Modify method")
+//			},
 //			RemoveFunc: func(ctx context.Context, id string) error {
 //				panic("mock out the Remove method")
 //			},
@@ -57,6 +60,9 @@ type StoreMock struct {
 	// ListFunc mocks the List method.
 	ListFunc func(ctx context.Context, filter catalog.ListFilter) ([]catalog.Entry, error)
 
+	// ModifyFunc mocks the Modify method.
+	ModifyFunc func(ctx context.Context, id string, fn func(*catalog.Entry) error) error
+
 	// RemoveFunc mocks the Remove method.
 	RemoveFunc func(ctx context.Context, id string) error
 
@@ -95,6 +101,15 @@ type StoreMock struct {
 			// Filter is the filter argument value.
 			Filter catalog.ListFilter
 		}
+		// Modify holds details about calls to the Modify method.
+		Modify []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID string
+			// Fn is the fn argument value.
+			Fn func(*catalog.Entry) error
+		}
 		// Remove holds details about calls to the Remove method.
 		Remove []struct {
 			// Ctx is the ctx argument value.
@@ -114,6 +129,7 @@ type StoreMock struct {
 	lockGet             sync.RWMutex
 	lockGetByRepoBranch sync.RWMutex
 	lockList            sync.RWMutex
+	lockModify          sync.RWMutex
 	lockRemove          sync.RWMutex
 	lockUpdate          sync.RWMutex
 }
@@ -266,6 +282,46 @@ func (mock *StoreMock) ListCalls() []struct {
 	return calls
 }
 
+// Modify calls ModifyFunc.
+func (mock *StoreMock) Modify(ctx context.Context, id string, fn func(*catalog.Entry) error) error {
+	if mock.ModifyFunc == nil {
+		panic("StoreMock.ModifyFunc: method is nil but Store.Modify was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		ID  string
+		Fn  func(*catalog.Entry) error
+	}{
+		Ctx: ctx,
+		ID:  id,
+		Fn:  fn,
+	}
+	mock.lockModify.Lock()
+	mock.calls.Modify = append(mock.calls.Modify, callInfo)
+	mock.lockModify.Unlock()
+	return mock.ModifyFunc(ctx, id, fn)
+}
+
+// ModifyCalls gets all the calls that were made to Modify.
+// Check the length with:
+//
+//	len(mockedStore.ModifyCalls())
+func (mock *StoreMock) ModifyCalls() []struct {
+	Ctx context.Context
+	ID  string
+	Fn  func(*catalog.Entry) error
+} {
+	var calls []struct {
+		Ctx context.Context
+		ID  string
+		Fn  func(*catalog.Entry) error
+	}
+	mock.lockModify.RLock()
+	calls = mock.calls.Modify
+	mock.lockModify.RUnlock()
+	return calls
+}
+
 // Remove calls RemoveFunc.
 func (mock *StoreMock) Remove(ctx context.Context, id string) error {
 	if mock.RemoveFunc == nil {
@@ -94,6 +94,22 @@ func (s *jsonStore) Update(ctx context.Context, entry *Entry) error {
 	})
 }
 
+func (s *jsonStore) Modify(ctx context.Context, id string, fn func(*Entry) error) error {
+	return s.withExclusiveLock(ctx, func(cf *catalogFile) error {
+		for i := range cf.Entries {
+			if cf.Entries[i].ID == id {
+				entry := cf.Entries[i]
+				if err := fn(&entry); err != nil {
+					return err
+				}
+				cf.Entries[i] = entry
+				return nil
+			}
+		}
+		return ErrNotFound
+	})
+}
+
 func (s *jsonStore) Remove(ctx context.Context, id string) error {
 	return s.withExclusiveLock(ctx, func(cf *catalogFile) error {
 		for i := range cf.Entries {
@@ -2,6 +2,7 @@ package catalog
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -165,6 +166,56 @@ func TestStore_Update(t *testing.T) {
 	})
 }
 
+func TestStore_Modify(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("applies fn to the current entry", func(t *testing.T) {
+		store := NewStore(filepath.Join(t.TempDir(), "catalog.json"))
+		entry := Entry{ID: "abc123", RepoID: "myrepo", Branch: "main", Status: StatusRunning}
+		require.NoError(t, store.Add(ctx, &entry))
+
+		// A change made meanwhile from another copy is kept
+		other := entry
+		other.Sessions = []Session{{ID: "sess1"}}
+		require.NoError(t, store.Update(ctx, &other))
+
+		err := store.Modify(ctx, "abc123", func(e *Entry) error {
+			e.ContainerID = "container-xyz"
+			return nil
+		})
+
+		require.NoError(t, err)
+		got, err := store.Get(ctx, "abc123")
+		require.NoError(t, err)
+		assert.Equal(t, "container-xyz", got.ContainerID)
+		assert.Len(t, got.Sessions, 1)
+	})
+
+	t.Run("leaves the entry unchanged when fn fails", func(t *testing.T) {
+		store := NewStore(filepath.Join(t.TempDir(), "catalog.json"))
+		entry := Entry{ID: "abc123", RepoID: "myrepo", Branch: "main", Status: StatusRunning}
+		require.NoError(t, store.Add(ctx, &entry))
+
+		err := store.Modify(ctx, "abc123", func(e *Entry) error {
+			e.Status = StatusError
+			return errors.New("boom")
+		})
+
+		require.Error(t, err)
+		got, err := store.Get(ctx, "abc123")
+		require.NoError(t, err)
+		assert.Equal(t, StatusRunning, got.Status)
+	})
+
+	t.Run("returns ErrNotFound for missing entry", func(t *testing.T) {
+		store := NewStore(filepath.Join(t.TempDir(), "catalog.json"))
+
+		err := store.Modify(ctx, "nonexistent", func(*Entry) error { return nil })
+
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+}
+
 func TestStore_Remove(t *testing.T) {
 	ctx := context.Background()
 
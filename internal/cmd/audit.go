@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jmgilman/headjack/internal/audit"
+)
+
+// maxAuditPromptWidth truncates prompts in the table view.
+const maxAuditPromptWidth = 40
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Show the audit log of headjack actions",
+	Long: `Show the audit log of headjack actions.
+
+Every instance and session lifecycle action (create, recreate, stop, remove,
+session create, session kill) and every credential change made with 'hjk auth'
+is appended to the audit log, along with the user, time, image, prompt and
+credential type. Secret values in session environment variables are redacted
+before they are written.
+
+The log is stored as JSON lines at storage.audit
+(default: ~/.local/share/headjack/audit.jsonl).`,
+	Example: `  # Show all audit events
+  headjack audit
+
+  # Show events from the last 24 hours
+  headjack audit --since 24h
+
+  # Show events for a branch in the last week
+  headjack audit --since 7d --branch feat/auth
+
+  # Emit raw JSON lines for further processing
+  headjack audit --json`,
+	Args: cobra.NoArgs,
+	RunE: runAuditCmd,
+}
+
+func runAuditCmd(cmd *cobra.Command, _ []string) error {
+	sinceFlag, err := cmd.Flags().GetString("since")
+	if err != nil {
+		return fmt.Errorf("get since flag: %w", err)
+	}
+	branch, err := cmd.Flags().GetString("branch")
+	if err != nil {
+		return fmt.Errorf("get branch flag: %w", err)
+	}
+	asJSON, err := cmd.Flags().GetBool("json")
+	if err != nil {
+		return fmt.Errorf("get json flag: %w", err)
+	}
+
+	since, err := parseSince(sinceFlag, time.Now())
+	if err != nil {
+		return err
+	}
+
+	auditLog, err := openAuditLog(ConfigFromContext(cmd.Context()))
+	if err != nil {
+		return err
+	}
+
+	events, err := auditLog.Query(audit.Filter{Since: since, Branch: branch})
+	if errors.Is(err, audit.ErrCorruptLines) {
+		fmt.Fprintf(os.Stderr, "warning: skipped %v\n", err)
+	} else if err != nil {
+		return fmt.Errorf("read audit log: %w", err)
+	}
+
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		for i := range events {
+			if err := enc.Encode(&events[i]); err != nil {
+				return fmt.Errorf("write event: %w", err)
+			}
+		}
+		return nil
+	}
+
+	if len(events) == 0 {
+		fmt.Println("No audit events found")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	if _, err := fmt.Fprintln(w, "TIME\tUSER\tACTION\tBRANCH\tSESSION\tDETAILS"); err != nil {
+		return fmt.Errorf("write header: %w", err)
+	}
+	for i := range events {
+		ev := &events[i]
+		if _, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			ev.Time.Local().Format(time.DateTime),
+			ev.User,
+			ev.Action,
+			valueOrDash(ev.Branch),
+			valueOrDash(ev.Session),
+			formatAuditDetails(ev),
+		); err != nil {
+			return fmt.Errorf("write event: %w", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("flush output: %w", err)
+	}
+
+	return nil
+}
+
+// formatAuditDetails summarizes the optional fields of an event.
+func formatAuditDetails(ev *audit.Event) string {
+	var parts []string
+	if ev.Agent != "" {
+		parts = append(parts, "agent="+ev.Agent)
+	}
+	if ev.CredentialType != "" {
+		parts = append(parts, "credential="+ev.CredentialType)
+	}
+	if ev.AuthProfile != "" {
+		parts = append(parts, "profile="+ev.AuthProfile)
+	}
+	if ev.Secret != "" {
+		parts = append(parts, "secret="+ev.Secret)
+	}
+	if ev.Image != "" {
+		parts = append(parts, "image="+ev.Image)
+	}
+	if ev.Prompt != "" {
+		parts = append(parts, fmt.Sprintf("prompt=%q", truncate(ev.Prompt, maxAuditPromptWidth)))
+	}
+	if ev.Reason != "" {
+		parts = append(parts, fmt.Sprintf("reason=%q", ev.Reason))
+	}
+	if len(parts) == 0 {
+		return "-"
+	}
+	return strings.Join(parts, " ")
+}
+
+// valueOrDash returns "-" for empty table cells.
+func valueOrDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// truncate shortens s to at most n runes, adding an ellipsis when cut.
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
+
+func init() {
+	rootCmd.AddCommand(auditCmd)
+
+	auditCmd.Flags().String("since", "", "only show events since a duration ago (e.g., 24h, 7d) or a date")
+	auditCmd.Flags().String("branch", "", "only show events for this branch")
+	auditCmd.Flags().Bool("json", false, "output events as JSON lines")
+}
@@ -1,12 +1,18 @@
 package cmd
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/jmgilman/headjack/internal/audit"
 	"github.com/jmgilman/headjack/internal/auth"
+	"github.com/jmgilman/headjack/internal/instance"
 	"github.com/jmgilman/headjack/internal/keychain"
 )
 
@@ -16,7 +22,8 @@ var authCmd = &cobra.Command{
 	Long: `Configure authentication for supported agent CLIs.
 
 Prompts for authentication method (subscription or API key) and stores
-credentials securely in the system keychain.`,
+credentials securely in the system keychain. Use --profile to keep several
+named credentials per agent, e.g. a personal subscription and a work API key.`,
 }
 
 var authClaudeCmd = &cobra.Command{
@@ -28,7 +35,10 @@ Choose between:
   1. Subscription: Uses your Claude Pro/Max subscription via OAuth token
   2. API Key: Uses an Anthropic API key for pay-per-use billing`,
 	Example: `  # Set up Claude Code authentication
-  headjack auth claude`,
+  headjack auth claude
+
+  # Store a work API key in a separate profile
+  headjack auth claude --profile work`,
 	RunE: runAuthClaude,
 }
 
@@ -54,11 +64,18 @@ Choose between:
   1. Subscription: Uses your ChatGPT Plus/Pro/Team subscription via OAuth
   2. API Key: Uses an OpenAI API key for pay-per-use billing`,
 	Example: `  # Set up Codex CLI authentication
-  headjack auth codex`,
+  headjack auth codex
+
+  # Re-import refreshed credentials into running instances too
+  headjack auth codex --propagate`,
 	RunE: runAuthCodex,
 }
 
-var authStatusFlag bool
+var (
+	authStatusFlag    bool
+	authProfileFlag   string
+	authPropagateFlag bool
+)
 
 func init() {
 	rootCmd.AddCommand(authCmd)
@@ -69,69 +86,173 @@ func init() {
 	// Add --status flag to all auth subcommands
 	for _, cmd := range []*cobra.Command{authClaudeCmd, authGeminiCmd, authCodexCmd} {
 		cmd.Flags().BoolVar(&authStatusFlag, "status", false, "Show current authentication status")
+		cmd.Flags().StringVar(&authProfileFlag, "profile", auth.DefaultProfile, "Credential profile to configure")
+		cmd.Flags().BoolVar(&authPropagateFlag, "propagate", false, "Rewrite the new credential in running instances")
 	}
 }
 
-func runAuthClaude(_ *cobra.Command, _ []string) error {
-	return runAuth(auth.NewClaudeProvider())
+func runAuthClaude(cmd *cobra.Command, _ []string) error {
+	return runAuth(cmd.Context(), auth.NewClaudeProvider())
 }
 
-func runAuthGemini(_ *cobra.Command, _ []string) error {
-	return runAuth(auth.NewGeminiProvider())
+func runAuthGemini(cmd *cobra.Command, _ []string) error {
+	return runAuth(cmd.Context(), auth.NewGeminiProvider())
 }
 
-func runAuthCodex(_ *cobra.Command, _ []string) error {
-	return runAuth(auth.NewCodexProvider())
+func runAuthCodex(cmd *cobra.Command, _ []string) error {
+	return runAuth(cmd.Context(), auth.NewCodexProvider())
 }
 
 // runAuth handles both --status checks and interactive auth flows.
-func runAuth(provider auth.Provider) error {
+func runAuth(ctx context.Context, provider auth.Provider) error {
+	if err := auth.ValidateProfile(authProfileFlag); err != nil {
+		return err
+	}
 	if authStatusFlag {
-		return showAuthStatus(provider)
+		return showAuthStatus(provider, authProfileFlag)
+	}
+	if err := runAuthFlow(ctx, provider, authProfileFlag); err != nil {
+		return err
+	}
+	if authPropagateFlag {
+		return propagateCredential(ctx, provider, authProfileFlag)
 	}
-	return runAuthFlow(provider)
+	return nil
 }
 
-// showAuthStatus displays the current authentication status for a provider.
-func showAuthStatus(provider auth.Provider) error {
-	storage, err := keychain.New()
+// propagateCredential rewrites a provider profile's stored credential in
+// every running instance and reports the sessions that need restarting.
+func propagateCredential(ctx context.Context, provider auth.Provider, profile string) error {
+	mgr, err := requireManager(ctx)
 	if err != nil {
-		return fmt.Errorf("initialize credential storage: %w", err)
+		return err
+	}
+	storage, err := openCredentialStorage()
+	if err != nil {
+		return err
+	}
+	cred, err := provider.Load(storage, profile)
+	if err != nil {
+		return fmt.Errorf("load credential: %w", err)
 	}
-
 	info := provider.Info()
-	cred, err := provider.Load(storage)
+	env, requiresSetup, err := credentialEnv(info, cred)
+	if err != nil {
+		return err
+	}
+
+	updates, err := mgr.PropagateCredential(ctx, &instance.PropagateCredentialConfig{
+		Auth:               info.Name,
+		Profile:            profile,
+		Env:                env,
+		RequiresAgentSetup: requiresSetup,
+	})
+	if err != nil {
+		return fmt.Errorf("propagate credential: %w", err)
+	}
+
+	fmt.Println()
+	if len(updates) == 0 {
+		fmt.Println("No running instances")
+		return nil
+	}
+	var failed int
+	for i := range updates {
+		u := &updates[i]
+		name := fmt.Sprintf("%s (%s)", u.Branch, u.InstanceID)
+		switch {
+		case u.Err != nil:
+			failed++
+			fmt.Fprintf(os.Stderr, "%s: failed: %v\n", name, u.Err)
+		case u.Skipped != "":
+			fmt.Printf("%s: skipped, %s\n", name, u.Skipped)
+		case u.Updated:
+			fmt.Printf("%s: updated\n", name)
+		default:
+			fmt.Printf("%s: nothing to update\n", name)
+		}
+		if len(u.Restart) > 0 {
+			fmt.Printf("  restart to use the new credential: %s\n", strings.Join(u.Restart, ", "))
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to update %d instance(s)", failed)
+	}
+	return nil
+}
+
+// profileLabel returns the provider name, qualified with the profile unless
+// it is the default profile.
+func profileLabel(provider, profile string) string {
+	if profile == auth.DefaultProfile {
+		return provider
+	}
+	return provider + " (" + profile + ")"
+}
+
+// showAuthStatus displays the current authentication status for a provider profile.
+func showAuthStatus(provider auth.Provider, profile string) error {
+	storage, err := openCredentialStorage()
+	if err != nil {
+		return err
+	}
+
+	label := profileLabel(provider.Info().Name, profile)
+	cred, err := provider.Load(storage, profile)
 	if errors.Is(err, keychain.ErrNotFound) {
-		fmt.Printf("%s: not configured\n", info.Name)
+		fmt.Printf("%s: not configured\n", label)
 		return nil
 	}
 	if err != nil {
 		return fmt.Errorf("load credential: %w", err)
 	}
 
+	var status string
 	switch cred.Type {
 	case auth.CredentialTypeSubscription:
-		fmt.Printf("%s: subscription\n", info.Name)
+		status = "subscription"
 	case auth.CredentialTypeAPIKey:
-		fmt.Printf("%s: api key\n", info.Name)
+		status = "api key"
 	default:
-		fmt.Printf("%s: configured (unknown type)\n", info.Name)
+		status = "configured (unknown type)"
+	}
+	if meta, metaErr := provider.Metadata(cred); metaErr == nil {
+		status += formatCredentialExpiry(meta, time.Now())
 	}
+	fmt.Printf("%s: %s\n", label, status)
 
 	return nil
 }
 
-// runAuthFlow runs the interactive authentication flow for a provider.
-func runAuthFlow(provider auth.Provider) error {
-	storage, err := keychain.New()
+// formatCredentialExpiry describes when a credential expires, e.g.
+// " (expires 2025-01-15 10:30, refreshable)". Credentials without a known
+// expiry yield an empty string.
+func formatCredentialExpiry(meta *auth.CredentialMetadata, now time.Time) string {
+	if meta.ExpiresAt.IsZero() {
+		return ""
+	}
+	verb := "expires"
+	if meta.Expired(now) {
+		verb = "expired"
+	}
+	suffix := ""
+	if meta.Refreshable {
+		suffix = ", refreshable"
+	}
+	return fmt.Sprintf(" (%s %s%s)", verb, meta.ExpiresAt.Local().Format("2006-01-02 15:04"), suffix)
+}
+
+// runAuthFlow runs the interactive authentication flow for a provider profile.
+func runAuthFlow(ctx context.Context, provider auth.Provider, profile string) error {
+	storage, err := openCredentialStorage()
 	if err != nil {
-		return fmt.Errorf("initialize credential storage: %w", err)
+		return err
 	}
 
 	prompter := auth.NewTerminalPrompter()
 	info := provider.Info()
 
-	prompter.Print(fmt.Sprintf("Configure %s authentication", info.Name))
+	prompter.Print(fmt.Sprintf("Configure %s authentication", profileLabel(info.Name, profile)))
 	prompter.Print("")
 
 	choice, err := prompter.PromptChoice("Authentication method:", []string{
@@ -157,20 +278,44 @@ func runAuthFlow(provider auth.Provider) error {
 		return err
 	}
 
-	if err := provider.Store(storage, cred); err != nil {
+	if err := provider.Store(storage, profile, cred); err != nil {
 		return fmt.Errorf("store credential: %w", err)
 	}
 
+	recordAuthAudit(ctx, audit.ActionAuthConfigure, info.Name, profile, cred.Type)
+
 	prompter.Print("")
 	prompter.Print("Credentials stored securely.")
 	return nil
 }
 
+// recordAuthAudit records a credential change in the audit trail (best-effort).
+// Only the provider and credential type are recorded, never the credential itself.
+func recordAuthAudit(ctx context.Context, action audit.Action, provider, profile string, credType auth.CredentialType) {
+	auditLog, err := openAuditLog(ConfigFromContext(ctx))
+	if err == nil {
+		err = auditLog.Record(ctx, &audit.Event{
+			Action:         action,
+			Agent:          provider,
+			CredentialType: string(credType),
+			AuthProfile:    profile,
+		})
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to write audit event: %v\n", err)
+	}
+}
+
 // handleSubscriptionAuth handles subscription-based authentication.
 // For Claude, prompts for manual token entry.
 // For Gemini/Codex, attempts to read existing credentials from config files.
 func handleSubscriptionAuth(provider auth.Provider, prompter auth.Prompter) (auth.Credential, error) {
-	// Try to auto-detect existing credentials
+	// Try to auto-detect existing credentials. Their modification time is
+	// read first so a later change on the host is always picked up by hjk run.
+	modTime, err := provider.SubscriptionModTime()
+	if err != nil {
+		return auth.Credential{}, fmt.Errorf("check host credentials: %w", err)
+	}
 	value, err := provider.CheckSubscription()
 	if err == nil {
 		// Found existing credentials
@@ -179,8 +324,9 @@ func handleSubscriptionAuth(provider auth.Provider, prompter auth.Prompter) (aut
 			return auth.Credential{}, fmt.Errorf("invalid credentials: %w", validateErr)
 		}
 		return auth.Credential{
-			Type:  auth.CredentialTypeSubscription,
-			Value: value,
+			Type:          auth.CredentialTypeSubscription,
+			Value:         value,
+			SourceModTime: modTime,
 		}, nil
 	}
 
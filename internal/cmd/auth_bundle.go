@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/jmgilman/headjack/internal/audit"
+	"github.com/jmgilman/headjack/internal/auth"
+)
+
+// envBundlePassphrase supplies the bundle passphrase without prompting.
+const envBundlePassphrase = "HEADJACK_BUNDLE_PASSPHRASE"
+
+var authExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export stored credentials to an encrypted bundle",
+	Long: `Export the stored credentials of every agent and profile to a bundle file
+encrypted with a passphrase, to set up another machine with 'hjk auth import'.
+
+The passphrase is prompted for, or read from HEADJACK_BUNDLE_PASSPHRASE.
+The bundle is written readable only by you.`,
+	Example: `  # Export all credentials
+  headjack auth export --out bundle.age`,
+	Args: cobra.NoArgs,
+	RunE: runAuthExport,
+}
+
+var authImportCmd = &cobra.Command{
+	Use:   "import <bundle>",
+	Short: "Import credentials from an encrypted bundle",
+	Long: `Import the credentials of a bundle written by 'hjk auth export'.
+
+Every credential is validated before any is stored. Imported credentials
+replace stored credentials of the same agent and profile. The passphrase is
+prompted for, or read from HEADJACK_BUNDLE_PASSPHRASE.`,
+	Example: `  # Import credentials exported on another machine
+  headjack auth import bundle.age`,
+	Args: cobra.ExactArgs(1),
+	RunE: runAuthImport,
+}
+
+func init() {
+	authCmd.AddCommand(authExportCmd, authImportCmd)
+	authExportCmd.Flags().StringP("out", "o", "", "bundle file to write")
+	//nolint:errcheck // MarkFlagRequired only fails for unknown flags
+	authExportCmd.MarkFlagRequired("out")
+}
+
+// allProviders returns every auth provider.
+func allProviders() []auth.Provider {
+	var providers []auth.Provider
+	for _, name := range auth.ProviderNames() {
+		provider, err := auth.NewProvider(name)
+		if err == nil {
+			providers = append(providers, provider)
+		}
+	}
+	return providers
+}
+
+func runAuthExport(cmd *cobra.Command, _ []string) error {
+	out, err := cmd.Flags().GetString("out")
+	if err != nil {
+		return fmt.Errorf("get out flag: %w", err)
+	}
+
+	storage, err := openCredentialStorage()
+	if err != nil {
+		return err
+	}
+	if err := backfillCredentialIndex(cmd.Context(), storage); err != nil {
+		return err
+	}
+	entries, err := auth.ExportCredentials(storage, allProviders())
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return errors.New("no credentials to export: run 'hjk auth <agent>' first")
+	}
+
+	passphrase, err := bundlePassphrase(true)
+	if err != nil {
+		return err
+	}
+	data, err := auth.SealBundle(entries, passphrase)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(out, data, 0o600); err != nil {
+		return fmt.Errorf("write bundle: %w", err)
+	}
+
+	for _, entry := range entries {
+		recordAuthAudit(cmd.Context(), audit.ActionAuthExport, entry.Provider, entry.Profile, entry.Credential.Type)
+		fmt.Printf("Exported %s\n", profileLabel(entry.Provider, entry.Profile))
+	}
+	fmt.Printf("Wrote %d credential(s) to %s\n", len(entries), out)
+	return nil
+}
+
+func runAuthImport(cmd *cobra.Command, args []string) error {
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		return fmt.Errorf("read bundle: %w", err)
+	}
+	passphrase, err := bundlePassphrase(false)
+	if err != nil {
+		return err
+	}
+	entries, err := auth.OpenBundle(data, passphrase)
+	if err != nil {
+		return err
+	}
+
+	storage, err := openCredentialStorage()
+	if err != nil {
+		return err
+	}
+	if err := auth.ImportCredentials(storage, entries); err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		recordAuthAudit(cmd.Context(), audit.ActionAuthImport, entry.Provider, entry.Profile, entry.Credential.Type)
+		fmt.Printf("Imported %s\n", profileLabel(entry.Provider, entry.Profile))
+	}
+	return nil
+}
+
+// bundlePassphrase returns the passphrase from HEADJACK_BUNDLE_PASSPHRASE, or
+// prompts for it, asking twice when confirm is set.
+func bundlePassphrase(confirm bool) (string, error) {
+	if passphrase := os.Getenv(envBundlePassphrase); passphrase != "" {
+		return passphrase, nil
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return "", fmt.Errorf("no passphrase: set %s or run in an interactive terminal", envBundlePassphrase)
+	}
+
+	prompter := auth.NewTerminalPrompter()
+	passphrase, err := prompter.PromptSecret("Bundle passphrase: ")
+	if err != nil {
+		return "", fmt.Errorf("read passphrase: %w", err)
+	}
+	if passphrase == "" {
+		return "", errors.New("passphrase cannot be empty")
+	}
+	if confirm {
+		again, err := prompter.PromptSecret("Confirm passphrase: ")
+		if err != nil {
+			return "", fmt.Errorf("read passphrase: %w", err)
+		}
+		if again != passphrase {
+			return "", errors.New("passphrases do not match")
+		}
+	}
+	return passphrase, nil
+}
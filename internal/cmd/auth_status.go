@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jmgilman/headjack/internal/audit"
+	"github.com/jmgilman/headjack/internal/auth"
+	"github.com/jmgilman/headjack/internal/credstore"
+	"github.com/jmgilman/headjack/internal/keychain"
+)
+
+// authCheckTimeout bounds each validation request of hjk auth status --check.
+const authCheckTimeout = 10 * time.Second
+
+var authStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show stored credentials of all agents",
+	Long: `Show every stored credential: its agent, profile, type, storage backend,
+and when it was stored and expires.
+
+With --check, each credential is also validated with a lightweight request
+to the provider's API. Set auth.endpoints.<agent> in config to send these
+requests to another base URL, such as a proxy.`,
+	Example: `  # List stored credentials
+  headjack auth status
+
+  # Also check that the providers accept them
+  headjack auth status --check`,
+	Args: cobra.NoArgs,
+	RunE: runAuthStatus,
+}
+
+var authLogoutCmd = &cobra.Command{
+	Use:   "logout <agent>",
+	Short: "Remove a stored credential",
+	Long: `Remove the stored credential of an agent's profile. Running sessions keep
+the credential they were started with.`,
+	Example: `  # Remove the default Claude credential
+  headjack auth logout claude
+
+  # Remove the work profile of Codex
+  headjack auth logout codex --profile work`,
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: auth.ProviderNames(),
+	RunE:      runAuthLogout,
+}
+
+func init() {
+	authCmd.AddCommand(authStatusCmd, authLogoutCmd)
+	authStatusCmd.Flags().Bool("check", false, "validate each credential with the provider's API")
+	authLogoutCmd.Flags().String("profile", auth.DefaultProfile, "credential profile to remove")
+}
+
+// credentialStorageName describes the backend credentials are stored in.
+func credentialStorageName() string {
+	if appConfig != nil && appConfig.Auth.Storage.Backend != "" && appConfig.Auth.Storage.Backend != credstore.BackendKeychain {
+		return appConfig.Auth.Storage.Backend
+	}
+	return fmt.Sprintf("%s (%s)", credstore.BackendKeychain, keychain.ActiveBackend(keychain.Config{}))
+}
+
+func runAuthStatus(cmd *cobra.Command, _ []string) error {
+	check, err := cmd.Flags().GetBool("check")
+	if err != nil {
+		return fmt.Errorf("get check flag: %w", err)
+	}
+
+	storage, err := openCredentialStorage()
+	if err != nil {
+		return err
+	}
+	if err := backfillCredentialIndex(cmd.Context(), storage); err != nil {
+		return err
+	}
+	checker := &auth.Checker{Client: &http.Client{Timeout: authCheckTimeout}}
+	if appConfig != nil {
+		checker.BaseURLs = appConfig.Auth.Endpoints
+	}
+
+	fmt.Printf("Storage: %s\n\n", credentialStorageName())
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	header := "AGENT\tPROFILE\tTYPE\tSTORED\tEXPIRES"
+	if check {
+		header += "\tCHECK"
+	}
+	if _, err := fmt.Fprintln(w, header); err != nil {
+		return fmt.Errorf("write header: %w", err)
+	}
+
+	now := time.Now()
+	for _, provider := range allProviders() {
+		name := provider.Info().Name
+		profiles, err := auth.Profiles(storage, provider)
+		if err != nil {
+			return fmt.Errorf("list %s profiles: %w", name, err)
+		}
+		if len(profiles) == 0 {
+			if _, err := fmt.Fprintf(w, "%s\t-\tnot configured\t-\t-\n", name); err != nil {
+				return fmt.Errorf("write credential: %w", err)
+			}
+			continue
+		}
+
+		for _, profile := range profiles {
+			cred, err := provider.Load(storage, profile)
+			if err != nil {
+				return fmt.Errorf("load %s credential (%s): %w", name, profile, err)
+			}
+			row := fmt.Sprintf("%s\t%s\t%s\t%s\t%s", name, profile, formatCredentialType(cred.Type),
+				formatStoredAt(cred.StoredAt), formatExpires(provider, cred, now))
+			if check {
+				row += "\t" + checkCredential(cmd.Context(), checker, name, cred)
+			}
+			if _, err := fmt.Fprintln(w, row); err != nil {
+				return fmt.Errorf("write credential: %w", err)
+			}
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("flush output: %w", err)
+	}
+	return nil
+}
+
+// formatCredentialType describes a credential type.
+func formatCredentialType(t auth.CredentialType) string {
+	switch t {
+	case auth.CredentialTypeSubscription:
+		return "subscription"
+	case auth.CredentialTypeAPIKey:
+		return "api key"
+	default:
+		return "unknown"
+	}
+}
+
+// formatStoredAt describes how long ago a credential was stored.
+func formatStoredAt(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return formatTimeAgo(t)
+}
+
+// formatExpires describes when a credential's access token expires.
+func formatExpires(provider auth.Provider, cred *auth.Credential, now time.Time) string {
+	meta, err := provider.Metadata(cred)
+	if err != nil || meta.ExpiresAt.IsZero() {
+		return "-"
+	}
+	s := meta.ExpiresAt.Local().Format("2006-01-02 15:04")
+	switch {
+	case meta.Expired(now) && meta.Refreshable:
+		s += " (expired, refreshable)"
+	case meta.Expired(now):
+		s += " (expired)"
+	}
+	return s
+}
+
+// checkCredential validates a credential with the provider's API and
+// describes the result.
+func checkCredential(ctx context.Context, checker *auth.Checker, provider string, cred *auth.Credential) string {
+	err := checker.Check(ctx, provider, cred)
+	switch {
+	case err == nil:
+		return "ok"
+	case errors.Is(err, auth.ErrCredentialRejected):
+		return "rejected"
+	default:
+		return "error: " + err.Error()
+	}
+}
+
+func runAuthLogout(cmd *cobra.Command, args []string) error {
+	profile, err := cmd.Flags().GetString("profile")
+	if err != nil {
+		return fmt.Errorf("get profile flag: %w", err)
+	}
+	if err := auth.ValidateProfile(profile); err != nil {
+		return err
+	}
+	provider, err := auth.NewProvider(args[0])
+	if err != nil {
+		return err
+	}
+
+	storage, err := openCredentialStorage()
+	if err != nil {
+		return err
+	}
+	info := provider.Info()
+	label := profileLabel(info.Name, profile)
+	cred, err := provider.Load(storage, profile)
+	if errors.Is(err, keychain.ErrNotFound) {
+		return fmt.Errorf("%s: not configured", label)
+	}
+	if err != nil {
+		return fmt.Errorf("load credential: %w", err)
+	}
+
+	if err := auth.DeleteCredential(storage, auth.ProfileAccount(info.KeychainAccount, profile)); err != nil {
+		return fmt.Errorf("delete credential: %w", err)
+	}
+
+	recordAuthAudit(cmd.Context(), audit.ActionAuthLogout, info.Name, profile, cred.Type)
+	fmt.Printf("Removed %s credential\n", label)
+	return nil
+}
@@ -6,9 +6,25 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/jmgilman/headjack/internal/audit"
+	"github.com/jmgilman/headjack/internal/auth"
+	"github.com/jmgilman/headjack/internal/budget"
+	"github.com/jmgilman/headjack/internal/catalog"
 	"github.com/jmgilman/headjack/internal/config"
+	"github.com/jmgilman/headjack/internal/credstore"
+	hjexec "github.com/jmgilman/headjack/internal/exec"
+	"github.com/jmgilman/headjack/internal/git"
 	"github.com/jmgilman/headjack/internal/instance"
+	"github.com/jmgilman/headjack/internal/keychain"
+	"github.com/jmgilman/headjack/internal/registry"
+	"github.com/jmgilman/headjack/internal/secret"
+	"github.com/jmgilman/headjack/internal/usage"
 )
 
 func requireManager(ctx context.Context) (*instance.Manager, error) {
@@ -35,6 +51,233 @@ func defaultDataDir() (string, error) {
 	return filepath.Join(home, config.DefaultDataDir), nil
 }
 
+// openAuditLog returns the audit log at storage.audit, or the default location if unset.
+func openAuditLog(cfg *config.Config) (*audit.Log, error) {
+	if cfg != nil && cfg.Storage.Audit != "" {
+		return audit.NewLog(cfg.Storage.Audit), nil
+	}
+	dataDir, err := defaultDataDir()
+	if err != nil {
+		return nil, err
+	}
+	return audit.NewLog(filepath.Join(dataDir, "audit.jsonl")), nil
+}
+
+// openUsageLedger returns the usage ledger at storage.usage, or the default location if unset.
+func openUsageLedger(cfg *config.Config) (*usage.Ledger, error) {
+	if cfg != nil && cfg.Storage.Usage != "" {
+		return usage.NewLedger(cfg.Storage.Usage), nil
+	}
+	dataDir, err := defaultDataDir()
+	if err != nil {
+		return nil, err
+	}
+	return usage.NewLedger(filepath.Join(dataDir, "usage.jsonl")), nil
+}
+
+// openBudgetState returns the budget state file in the data directory.
+func openBudgetState() (*budget.FileStore, error) {
+	dataDir, err := defaultDataDir()
+	if err != nil {
+		return nil, err
+	}
+	return budget.NewFileStore(filepath.Join(dataDir, "budget.json")), nil
+}
+
+// openCredentialStorage returns the credential backend selected by
+// auth.storage, or the system keychain if unset.
+func openCredentialStorage() (keychain.Keychain, error) {
+	var cfg credstore.Config
+	if appConfig != nil {
+		s := appConfig.Auth.Storage
+		cfg = credstore.Config{
+			Backend:  s.Backend,
+			Vault:    s.Vault,
+			Field:    s.Field,
+			Prefix:   s.Prefix,
+			Path:     s.Path,
+			Commands: credstore.Commands{Get: s.Get, Set: s.Set, Delete: s.Delete},
+		}
+	}
+	kc, err := credstore.Open(hjexec.New(), cfg)
+	if err != nil {
+		return nil, fmt.Errorf("initialize credential storage: %w", err)
+	}
+	dataDir, err := defaultDataDir()
+	if err != nil {
+		return nil, err
+	}
+	return credstore.WithLocks(kc, filepath.Join(dataDir, "locks")), nil
+}
+
+// backfillCredentialIndex adds credentials of profiles headjack knows about,
+// from config and from sessions in the catalog, to the credential index, so
+// profiles stored before the index existed are listed. Read-only backends
+// cannot hold the index, so nothing is backfilled with them.
+func backfillCredentialIndex(ctx context.Context, storage auth.Storage) error {
+	var profiles []string
+	if appConfig != nil && appConfig.Auth.Profile != "" {
+		profiles = append(profiles, appConfig.Auth.Profile)
+	}
+
+	catalogPath := ""
+	if appConfig != nil {
+		catalogPath = appConfig.Storage.Catalog
+	} else if dataDir, err := defaultDataDir(); err == nil {
+		catalogPath = filepath.Join(dataDir, "catalog.json")
+	}
+	if catalogPath != "" {
+		// The catalog only adds hints; an unreadable catalog is not an error
+		entries, _ := catalog.NewStore(catalogPath).List(ctx, catalog.ListFilter{}) //nolint:errcheck // see above
+		for _, entry := range entries {
+			for _, session := range entry.Sessions {
+				if session.AuthProfile != "" && !slices.Contains(profiles, session.AuthProfile) {
+					profiles = append(profiles, session.AuthProfile)
+				}
+			}
+		}
+	}
+
+	err := auth.BackfillIndex(storage, profiles)
+	if err != nil && !errors.Is(err, credstore.ErrReadOnly) {
+		return fmt.Errorf("backfill credential index: %w", err)
+	}
+	return nil
+}
+
+// registryClient returns the client image metadata is fetched with. Metadata
+// is cached in storage.images, or the default location if unset.
+func registryClient(cfg *config.Config, executor hjexec.Executor) (registry.Client, error) {
+	var cacheCfg registry.CacheConfig
+	if cfg != nil {
+		ttl, err := parseAge(cfg.Registry.Cache.TTL)
+		if err != nil {
+			return nil, fmt.Errorf("registry.cache.ttl: %w", err)
+		}
+		maxAge, err := parseAge(cfg.Registry.Cache.MaxAge)
+		if err != nil {
+			return nil, fmt.Errorf("registry.cache.max_age: %w", err)
+		}
+		cacheCfg = registry.CacheConfig{Dir: cfg.Storage.Images, TTL: ttl, MaxAge: maxAge}
+	}
+	if cacheCfg.Dir == "" {
+		dataDir, err := defaultDataDir()
+		if err != nil {
+			return nil, err
+		}
+		cacheCfg.Dir = filepath.Join(dataDir, "images")
+	}
+	return registry.NewCachingClient(registry.NewClient(registryClientConfig(cfg, executor)), cacheCfg), nil
+}
+
+// registryClientConfig returns the registry client settings from the
+// registry section of the configuration.
+func registryClientConfig(cfg *config.Config, executor hjexec.Executor) registry.ClientConfig {
+	clientCfg := registry.ClientConfig{
+		Exec:    executor,
+		Secrets: &keychainSecrets{},
+	}
+	if cfg == nil {
+		return clientCfg
+	}
+	clientCfg.CAFile = cfg.Registry.CAFile
+	clientCfg.Insecure = cfg.Registry.Insecure
+	for _, h := range cfg.Registry.Hosts {
+		clientCfg.Hosts = append(clientCfg.Hosts, registry.HostConfig{
+			Host:             h.Host,
+			Mirrors:          h.Mirrors,
+			Username:         h.Username,
+			Password:         h.Password,
+			CredentialHelper: h.CredentialHelper,
+			Secret:           h.Secret,
+			CAFile:           h.CAFile,
+			Insecure:         h.Insecure,
+		})
+	}
+	return clientCfg
+}
+
+// openSecretStore returns the secret store backed by the credential storage.
+func openSecretStore() (*secret.Store, error) {
+	kc, err := openCredentialStorage()
+	if err != nil {
+		return nil, err
+	}
+	return secret.NewStore(kc), nil
+}
+
+// keychainSecrets resolves secrets from the credential storage. The storage is
+// opened on first use, so commands that never start a session referencing a
+// secret do not unlock it.
+type keychainSecrets struct {
+	once  sync.Once
+	store *secret.Store
+	err   error
+}
+
+// Resolve implements secret.Resolver.
+func (k *keychainSecrets) Resolve(repoID, name string) (string, error) {
+	k.once.Do(func() {
+		k.store, k.err = openSecretStore()
+	})
+	if k.err != nil {
+		return "", k.err
+	}
+	return k.store.Resolve(repoID, name)
+}
+
+// currentRepo opens the git repository containing the working directory.
+func currentRepo(ctx context.Context) (git.Repository, error) {
+	path, err := repoPath()
+	if err != nil {
+		return nil, err
+	}
+	repo, err := git.NewOpener(hjexec.New()).Open(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("open repository: %w", err)
+	}
+	return repo, nil
+}
+
+// parseSince converts a --since value into an absolute time.
+// Accepts durations relative to now (e.g., "90m", "24h", "7d"), dates ("2006-01-02"),
+// and RFC 3339 timestamps. An empty value yields the zero time.
+func parseSince(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if n, ok := parseDays(value); ok {
+		return now.AddDate(0, 0, -n), nil
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation(time.DateOnly, value, now.Location()); err == nil {
+		return t, nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid --since value %q (use a duration like 24h or 7d, a date, or an RFC 3339 time)", value)
+}
+
+// parseDays parses a day count such as "7d", which time.ParseDuration does
+// not support.
+func parseDays(value string) (int, bool) {
+	days, ok := strings.CutSuffix(value, "d")
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.Atoi(days)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return n, true
+}
+
 func resolveBaseImage(ctx context.Context, override string) string {
 	if override != "" {
 		return override
@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jmgilman/headjack/internal/instance"
+)
+
+var imageCmd = &cobra.Command{
+	Use:   "image",
+	Short: "Inspect container images",
+}
+
+var imageInspectCmd = &cobra.Command{
+	Use:   "inspect <ref>",
+	Short: "Show the headjack labels of an image",
+	Long: `Show the io.headjack.* labels of an image, such as its init command and
+runtime flags, and where they were read from, along with its platform and
+build time. For images in the local image store, the runtime defaults of the
+image config (user, working directory, entrypoint, command, and environment)
+are shown too.
+
+Labels of an image in the container runtime's local image store, such as
+one built locally, are read from there. Other images are looked up in their
+registry, and their labels cached in storage.images. Cached labels are used
+without asking the registry for registry.cache.ttl, and in place of an
+unreachable registry for registry.cache.max_age.`,
+	Example: `  # Show the labels of the systemd base image
+  headjack image inspect ghcr.io/gilmanlab/headjack:systemd
+
+  # Show the labels of a locally built image
+  headjack image inspect headjack:dev`,
+	Args: cobra.ExactArgs(1),
+	RunE: runImageInspect,
+}
+
+func init() {
+	rootCmd.AddCommand(imageCmd)
+	imageCmd.AddCommand(imageInspectCmd)
+}
+
+func runImageInspect(cmd *cobra.Command, args []string) error {
+	mgr, err := requireManager(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	info, err := mgr.InspectImage(cmd.Context(), args[0])
+	if err != nil {
+		return fmt.Errorf("inspect image: %w", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	for _, field := range imageFields(info) {
+		if _, err := fmt.Fprintf(w, "%s:\t%s\n", field[0], field[1]); err != nil {
+			return fmt.Errorf("write image: %w", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("flush output: %w", err)
+	}
+	fmt.Println()
+
+	if len(info.Labels) == 0 {
+		fmt.Println("No headjack labels")
+		return nil
+	}
+
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	if _, err := fmt.Fprintln(w, "LABEL\tVALUE"); err != nil {
+		return fmt.Errorf("write header: %w", err)
+	}
+	keys := make([]string, 0, len(info.Labels))
+	for k := range info.Labels {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		if _, err := fmt.Fprintf(w, "%s\t%s\n", k, info.Labels[k]); err != nil {
+			return fmt.Errorf("write label: %w", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("flush output: %w", err)
+	}
+	return nil
+}
+
+// imageFields returns the name and value of each known property of an image.
+func imageFields(info *instance.ImageInfo) [][2]string {
+	fields := [][2]string{{"Image", info.Ref}}
+	if info.Digest != "" {
+		fields = append(fields, [2]string{"Digest", info.Digest})
+	}
+	fields = append(fields, [2]string{"Source", formatImageSource(info)})
+	if info.OS != "" && info.Architecture != "" {
+		fields = append(fields, [2]string{"Platform", info.OS + "/" + info.Architecture})
+	}
+	if !info.Created.IsZero() {
+		fields = append(fields, [2]string{"Created", formatTimeAgo(info.Created)})
+	}
+	if info.User != "" {
+		fields = append(fields, [2]string{"User", info.User})
+	}
+	if info.WorkingDir != "" {
+		fields = append(fields, [2]string{"Workdir", info.WorkingDir})
+	}
+	if len(info.Entrypoint) > 0 {
+		fields = append(fields, [2]string{"Entrypoint", strings.Join(info.Entrypoint, " ")})
+	}
+	if len(info.Cmd) > 0 {
+		fields = append(fields, [2]string{"Cmd", strings.Join(info.Cmd, " ")})
+	}
+	for _, env := range info.Env {
+		fields = append(fields, [2]string{"Env", env})
+	}
+	return fields
+}
+
+// formatImageSource describes where an image's labels were read from.
+func formatImageSource(info *instance.ImageInfo) string {
+	switch info.Source {
+	case instance.ImageSourceCache:
+		return fmt.Sprintf("cache (fetched %s)", formatTimeAgo(info.FetchedAt))
+	case instance.ImageSourceLocal:
+		return "local image store"
+	default:
+		return string(info.Source)
+	}
+}
@@ -2,45 +2,67 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"time"
 
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 
+	"github.com/jmgilman/headjack/internal/instance"
 	"github.com/jmgilman/headjack/internal/logging"
 )
 
-// Default poll interval for following logs.
+// Poll interval for following logs where filesystem notifications are unavailable.
 const defaultLogPollInterval = 100 * time.Millisecond
 
 var logsCmd = &cobra.Command{
-	Use:   "logs <branch> <session>",
+	Use:   "logs [branch] [session]",
 	Short: "View output from a session",
 	Long: `View output from a session without attaching.
 
 Reads from the session's log file, useful for checking on detached agents
-without interrupting them.`,
+without interrupting them.
+
+Without a session, shows every session of the instance; with --all, every
+session of every running instance. Each line is prefixed with a colored
+"branch/session |" label, and with --follow new output from all sessions is
+merged as it arrives.
+
+The log is the raw terminal output stream, including the escape sequences
+agents use to redraw the screen. Use --plain to replay the stream through a
+virtual terminal and print clean text, or --html to export it as a standalone
+HTML page with colors preserved.`,
 	Example: `  # View recent output (last 100 lines)
   headjack logs feat/auth happy-panda
 
   # Follow output in real-time
   headjack logs feat/auth happy-panda -f
 
+  # Show recent output from every session of an instance
+  headjack logs feat/auth
+
+  # Follow every running session
+  headjack logs --all -f
+
   # Show last 500 lines
   headjack logs feat/auth happy-panda -n 500
 
   # Show entire log from session start
-  headjack logs feat/auth happy-panda --full`,
-	Args: cobra.ExactArgs(2),
+  headjack logs feat/auth happy-panda --full
+
+  # Show the last 50 lines as clean text
+  headjack logs feat/auth happy-panda --plain -n 50
+
+  # Export the whole session as HTML
+  headjack logs feat/auth happy-panda --html --full > session.html`,
+	Args: cobra.RangeArgs(0, 2),
 	RunE: runLogsCmd,
 }
 
 func runLogsCmd(cmd *cobra.Command, args []string) error {
-	branch := args[0]
-	sessionName := args[1]
-
 	follow, err := cmd.Flags().GetBool("follow")
 	if err != nil {
 		return fmt.Errorf("get follow flag: %w", err)
@@ -56,21 +78,31 @@ func runLogsCmd(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("get full flag: %w", err)
 	}
 
-	// Get the instance for this branch
-	mgr, err := requireManager(cmd.Context())
+	all, err := cmd.Flags().GetBool("all")
 	if err != nil {
-		return err
+		return fmt.Errorf("get all flag: %w", err)
 	}
 
-	inst, err := getInstanceByBranch(cmd.Context(), mgr, branch, "")
+	render, err := logRenderFormat(cmd)
 	if err != nil {
 		return err
 	}
+	if render != "" && follow {
+		return errors.New("--plain and --html cannot be used with --follow")
+	}
 
-	// Get the session to verify it exists and get its ID
-	session, err := mgr.GetSession(cmd.Context(), inst.ID, sessionName)
+	switch {
+	case all && len(args) > 0:
+		return errors.New("--all cannot be combined with a branch or session")
+	case !all && len(args) == 0:
+		return errors.New("specify a branch, or use --all to show every running session")
+	case render != "" && len(args) < 2:
+		return errors.New("--plain and --html require a single session")
+	}
+
+	mgr, err := requireManager(cmd.Context())
 	if err != nil {
-		return fmt.Errorf("get session: %w", err)
+		return err
 	}
 
 	// Create log reader
@@ -81,14 +113,86 @@ func runLogsCmd(cmd *cobra.Command, args []string) error {
 	pathMgr := logging.NewPathManager(logsDir)
 	reader := logging.NewReader(pathMgr)
 
+	if len(args) < 2 {
+		noColor, err := cmd.Flags().GetBool("no-color")
+		if err != nil {
+			return fmt.Errorf("get no-color flag: %w", err)
+		}
+
+		var instances []instance.Instance
+		if all {
+			instances, err = mgr.List(cmd.Context(), instance.ListFilter{Status: instance.StatusRunning})
+			if err != nil {
+				return fmt.Errorf("list instances: %w", err)
+			}
+		} else {
+			inst, err := getInstanceByBranch(cmd.Context(), mgr, args[0], "")
+			if err != nil {
+				return err
+			}
+			instances = []instance.Instance{*inst}
+		}
+
+		targets, err := sessionLogTargets(cmd.Context(), mgr, instances, !noColor && term.IsTerminal(int(os.Stdout.Fd())))
+		if err != nil {
+			return err
+		}
+		if full {
+			lines = 0
+		}
+		return outputMultiLogs(cmd.Context(), reader, targets, follow, lines)
+	}
+
+	inst, err := getInstanceByBranch(cmd.Context(), mgr, args[0], "")
+	if err != nil {
+		return err
+	}
+	sessionName := args[1]
+
+	// Get the session to verify it exists and get its ID
+	session, err := mgr.GetSession(cmd.Context(), inst.ID, sessionName)
+	if err != nil {
+		return fmt.Errorf("get session: %w", err)
+	}
+
 	// Check if log file exists
 	if !pathMgr.LogExists(inst.ID, session.ID) {
 		return fmt.Errorf("no log file found for session %s", sessionName)
 	}
 
+	if render != "" {
+		opts := &logging.RenderOpts{Format: render}
+		if !full {
+			opts.Lines = lines
+		}
+		return reader.Render(inst.ID, session.ID, os.Stdout, opts)
+	}
+
 	return outputLogs(cmd.Context(), reader, inst.ID, session.ID, follow, lines, full)
 }
 
+// logRenderFormat returns the render format selected by --plain or --html,
+// or "" to print the raw log.
+func logRenderFormat(cmd *cobra.Command) (logging.RenderFormat, error) {
+	plain, err := cmd.Flags().GetBool("plain")
+	if err != nil {
+		return "", fmt.Errorf("get plain flag: %w", err)
+	}
+	html, err := cmd.Flags().GetBool("html")
+	if err != nil {
+		return "", fmt.Errorf("get html flag: %w", err)
+	}
+
+	switch {
+	case plain:
+		return logging.RenderPlain, nil
+	case html:
+		return logging.RenderHTML, nil
+	default:
+		return "", nil
+	}
+}
+
 func outputLogs(ctx context.Context, reader *logging.Reader, instanceID, sessionID string, follow bool, lines int, full bool) error {
 	if follow {
 		// Follow mode: show last N lines then stream new output
@@ -122,6 +226,11 @@ func init() {
 	logsCmd.Flags().BoolP("follow", "f", false, "follow log output in real-time")
 	logsCmd.Flags().IntP("lines", "n", logging.DefaultTailLines, "number of lines to show")
 	logsCmd.Flags().Bool("full", false, "show entire log from session start")
+	logsCmd.Flags().Bool("plain", false, "render terminal output as clean text")
+	logsCmd.Flags().Bool("html", false, "render terminal output as an HTML page")
+	logsCmd.Flags().Bool("all", false, "show every session of every running instance")
+	logsCmd.Flags().Bool("no-color", false, "disable colored session prefixes")
+	logsCmd.MarkFlagsMutuallyExclusive("plain", "html")
 }
 
 // getLogsDir returns the logs directory from config, or the default if config is nil.
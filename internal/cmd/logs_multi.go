@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/jmgilman/headjack/internal/instance"
+	"github.com/jmgilman/headjack/internal/logging"
+)
+
+// logPrefixColors are the ANSI colors cycled through for session prefixes,
+// in the spirit of docker compose logs.
+var logPrefixColors = []string{"36", "33", "32", "35", "34", "96", "93", "92", "95", "94"}
+
+// sessionLogTargets returns a log target for every session of the given
+// instances, labeled "<branch>/<session> | " with labels padded to a common
+// width and, if color is set, colored per session.
+func sessionLogTargets(ctx context.Context, mgr *instance.Manager, instances []instance.Instance, color bool) ([]logging.FollowTarget, error) {
+	var targets []logging.FollowTarget
+	var labels []string
+	for i := range instances {
+		inst := &instances[i]
+		sessions, err := mgr.ListSessions(ctx, inst.ID)
+		if err != nil {
+			return nil, fmt.Errorf("list sessions for %s: %w", inst.Branch, err)
+		}
+		for _, s := range sessions {
+			targets = append(targets, logging.FollowTarget{InstanceID: inst.ID, SessionID: s.ID})
+			labels = append(labels, inst.Branch+"/"+s.Name)
+		}
+	}
+	if len(targets) == 0 {
+		return nil, errors.New("no sessions found")
+	}
+
+	width := 0
+	for _, label := range labels {
+		width = max(width, len(label))
+	}
+	for i, label := range labels {
+		prefix := fmt.Sprintf("%-*s |", width, label)
+		if color {
+			prefix = "\x1b[" + logPrefixColors[i%len(logPrefixColors)] + "m" + prefix + "\x1b[0m"
+		}
+		targets[i].Prefix = prefix + " "
+	}
+	return targets, nil
+}
+
+// outputMultiLogs shows the last n lines (or, if n <= 0, all) of every
+// target's log, then follows them all if follow is set.
+func outputMultiLogs(ctx context.Context, reader *logging.Reader, targets []logging.FollowTarget, follow bool, n int) error {
+	if follow {
+		return reader.FollowMany(ctx, targets, os.Stdout, n, defaultLogPollInterval)
+	}
+	if err := reader.TailMany(targets, os.Stdout, n); err != nil {
+		return fmt.Errorf("read log: %w", err)
+	}
+	return nil
+}
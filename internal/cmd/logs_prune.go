@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jmgilman/headjack/internal/instance"
+	"github.com/jmgilman/headjack/internal/logging"
+)
+
+var logsPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Delete old session logs according to the retention policy",
+	Long: `Delete old session logs according to the retention policy.
+
+Log files not written to within logging.max_age are removed first. If the
+logs directory still exceeds logging.max_total_size, the least recently
+written files are removed until it fits. The live log of a session that still
+exists is never removed, though its rotated segments are.
+
+Retention also runs automatically whenever a session is created. Use the
+flags to override the configured limits for a single run.`,
+	Example: `  # Apply the configured retention policy
+  headjack logs prune
+
+  # Preview what would be deleted
+  headjack logs prune --dry-run
+
+  # Remove logs older than a week
+  headjack logs prune --max-age 7d`,
+	Args: cobra.NoArgs,
+	RunE: runLogsPruneCmd,
+}
+
+func runLogsPruneCmd(cmd *cobra.Command, _ []string) error {
+	dryRun, err := cmd.Flags().GetBool("dry-run")
+	if err != nil {
+		return fmt.Errorf("get dry-run flag: %w", err)
+	}
+	maxAge, err := cmd.Flags().GetString("max-age")
+	if err != nil {
+		return fmt.Errorf("get max-age flag: %w", err)
+	}
+	maxTotalSize, err := cmd.Flags().GetString("max-total-size")
+	if err != nil {
+		return fmt.Errorf("get max-total-size flag: %w", err)
+	}
+
+	retention, err := logRetentionPolicy(ConfigFromContext(cmd.Context()))
+	if err != nil {
+		return err
+	}
+	if cmd.Flags().Changed("max-age") {
+		if retention.MaxAge, err = parseAge(maxAge); err != nil {
+			return err
+		}
+	}
+	if cmd.Flags().Changed("max-total-size") {
+		if retention.MaxTotalSize, err = logging.ParseSize(maxTotalSize); err != nil {
+			return err
+		}
+	}
+	if retention.MaxAge == 0 && retention.MaxTotalSize == 0 {
+		return errors.New("no retention policy set (configure logging.max_age or logging.max_total_size, or pass --max-age or --max-total-size)")
+	}
+
+	mgr, err := requireManager(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	pruned, err := mgr.PruneLogs(cmd.Context(), &instance.PruneLogsConfig{
+		Retention: retention,
+		DryRun:    dryRun,
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(pruned) == 0 {
+		fmt.Println("No logs to prune.")
+		return nil
+	}
+
+	var freed int64
+	for _, f := range pruned {
+		freed += f.Size
+		fmt.Printf("%-5s %8s  %s\n", f.Reason, formatSize(f.Size), f.Path)
+	}
+
+	verb := "Pruned"
+	if dryRun {
+		verb = "Would prune"
+	}
+	fmt.Printf("%s %d file(s), %s\n", verb, len(pruned), formatSize(freed))
+	return nil
+}
+
+// formatSize formats a byte count with a binary unit, e.g. "1.5MB".
+func formatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+func init() {
+	logsCmd.AddCommand(logsPruneCmd)
+
+	logsPruneCmd.Flags().Bool("dry-run", false, "list files that would be deleted without deleting them")
+	logsPruneCmd.Flags().String("max-age", "", "delete logs not written to within this age (e.g., 7d, 72h)")
+	logsPruneCmd.Flags().String("max-total-size", "", "delete the oldest logs beyond this total size (e.g., 5GB)")
+}
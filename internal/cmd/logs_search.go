@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jmgilman/headjack/internal/instance"
+)
+
+// defaultSearchContext is the number of context lines shown around matches.
+const defaultSearchContext = 2
+
+var logsSearchCmd = &cobra.Command{
+	Use:   "search <pattern>",
+	Short: "Search every session log for a pattern",
+	Long: `Search every session log for a regular expression.
+
+Searches the logs of all instances, including rotated segments and logs of
+sessions that have since exited, and prints each match with surrounding
+context under its branch and session name. Terminal escape sequences are
+removed before matching, so patterns match the text as it appeared on screen.
+
+The pattern uses Go regular expression syntax. --since skips log segments
+not written to since the given time; matched lines themselves carry no
+timestamps.`,
+	Example: `  # Find which session hit a migration error
+  headjack logs search 'migration .* failed'
+
+  # Search Claude sessions on one branch, case-insensitively
+  headjack logs search -i 'panic' --branch feat/auth --agent claude
+
+  # Only logs written in the last day, with 5 lines of context
+  headjack logs search 'ECONNREFUSED' --since 24h -C 5`,
+	Args: cobra.ExactArgs(1),
+	RunE: runLogsSearchCmd,
+}
+
+func runLogsSearchCmd(cmd *cobra.Command, args []string) error {
+	branch, err := cmd.Flags().GetString("branch")
+	if err != nil {
+		return fmt.Errorf("get branch flag: %w", err)
+	}
+	agent, err := cmd.Flags().GetString("agent")
+	if err != nil {
+		return fmt.Errorf("get agent flag: %w", err)
+	}
+	sinceFlag, err := cmd.Flags().GetString("since")
+	if err != nil {
+		return fmt.Errorf("get since flag: %w", err)
+	}
+	contextLines, err := cmd.Flags().GetInt("context")
+	if err != nil {
+		return fmt.Errorf("get context flag: %w", err)
+	}
+	ignoreCase, err := cmd.Flags().GetBool("ignore-case")
+	if err != nil {
+		return fmt.Errorf("get ignore-case flag: %w", err)
+	}
+
+	expr := args[0]
+	if ignoreCase {
+		expr = "(?i)" + expr
+	}
+	pattern, err := regexp.Compile(expr)
+	if err != nil {
+		return fmt.Errorf("invalid pattern: %w", err)
+	}
+
+	since, err := parseSince(sinceFlag, time.Now())
+	if err != nil {
+		return err
+	}
+
+	mgr, err := requireManager(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	p := &searchPrinter{}
+	err = mgr.SearchLogs(cmd.Context(), &instance.SearchLogsConfig{
+		Pattern: pattern,
+		Branch:  branch,
+		Agent:   agent,
+		Context: max(contextLines, 0),
+		Since:   since,
+	}, p.print)
+	if err != nil {
+		return err
+	}
+
+	if p.matches == 0 {
+		fmt.Println("No matches found.")
+		return nil
+	}
+	fmt.Printf("\n%d match(es) in %d session(s)\n", p.matches, p.sessions)
+	return nil
+}
+
+// searchPrinter prints matches grouped under a header per session, with
+// grep-style line markers: ":" for matches and "-" for context. Groups that
+// are not contiguous are separated by "--".
+type searchPrinter struct {
+	session  string // Instance and session ID of the last match printed
+	lastLine int    // Last line number printed for the current session
+	matches  int
+	sessions int
+}
+
+func (p *searchPrinter) print(m *instance.LogMatch) error {
+	key := m.InstanceID + "/" + m.SessionID
+	first := m.Line - len(m.Before)
+
+	switch {
+	case key != p.session:
+		if p.session != "" {
+			fmt.Println()
+		}
+		header := m.Branch + "/" + m.Session
+		if m.Type != "" {
+			header += " (" + m.Type + ")"
+		}
+		fmt.Println(header)
+		p.session = key
+		p.sessions++
+	case first > p.lastLine+1:
+		fmt.Println("--")
+	}
+
+	for i, line := range m.Before {
+		fmt.Printf("%6d- %s\n", first+i, line)
+	}
+	fmt.Printf("%6d: %s\n", m.Line, m.Text)
+	for i, line := range m.After {
+		fmt.Printf("%6d- %s\n", m.Line+1+i, line)
+	}
+
+	p.lastLine = m.Line + len(m.After)
+	p.matches++
+	return nil
+}
+
+func init() {
+	logsCmd.AddCommand(logsSearchCmd)
+
+	logsSearchCmd.Flags().String("branch", "", "only search instances on this branch")
+	logsSearchCmd.Flags().String("agent", "", "only search sessions of this type (claude, gemini, codex, shell)")
+	logsSearchCmd.Flags().String("since", "", "only search logs written since a duration (24h, 7d), date, or RFC 3339 time")
+	logsSearchCmd.Flags().IntP("context", "C", defaultSearchContext, "lines of context to show around each match")
+	logsSearchCmd.Flags().BoolP("ignore-case", "i", false, "match case-insensitively")
+}
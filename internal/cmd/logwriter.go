@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jmgilman/headjack/internal/config"
+	"github.com/jmgilman/headjack/internal/logging"
+	"github.com/jmgilman/headjack/internal/transcript"
+)
+
+var logWriterCmd = &cobra.Command{
+	Use:    "log-writer <path>",
+	Short:  "Write session output from stdin to a rotating log",
+	Hidden: true,
+	Long: `Write session output from stdin to a log file, rotating it by size.
+
+Multiplexers pipe session output into this command when logging.max_size is
+set; it is not normally run by hand. Rotated segments are named <path>.1
+(newest) through <path>.N, gzipped when --compress is set.`,
+	Args: cobra.ExactArgs(1),
+	// The writer needs neither the container runtime nor the instance manager
+	PersistentPreRunE: func(*cobra.Command, []string) error { return nil },
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runLogWriter(cmd, args[0], nil)
+	},
+}
+
+var transcriptWriterCmd = &cobra.Command{
+	Use:    "transcript-writer <format> <transcript-path> <log-path>",
+	Short:  "Write session output from stdin to a log and record its transcript",
+	Hidden: true,
+	Long: `Write session output from stdin to a log file like log-writer, and record
+the agent's structured events, which are in the given transcript format, in
+a transcript file as they arrive.
+
+Multiplexers pipe the output of transcript mode sessions into this command;
+it is not normally run by hand.`,
+	Args: cobra.ExactArgs(3),
+	// The writer needs neither the container runtime nor the instance manager
+	PersistentPreRunE: func(*cobra.Command, []string) error { return nil },
+	RunE: func(cmd *cobra.Command, args []string) error {
+		recorder, err := transcript.NewRecorder(args[1], args[0])
+		if err != nil {
+			return err
+		}
+		return runLogWriter(cmd, args[2], recorder)
+	},
+}
+
+// runLogWriter copies stdin to the log at path, rotating it per the command's
+// flags, and to recorder if it is not nil.
+func runLogWriter(cmd *cobra.Command, path string, recorder io.WriteCloser) error {
+	maxSize, err := cmd.Flags().GetInt64("max-size")
+	if err != nil {
+		return fmt.Errorf("get max-size flag: %w", err)
+	}
+	maxFiles, err := cmd.Flags().GetInt("max-files")
+	if err != nil {
+		return fmt.Errorf("get max-files flag: %w", err)
+	}
+	compress, err := cmd.Flags().GetBool("compress")
+	if err != nil {
+		return fmt.Errorf("get compress flag: %w", err)
+	}
+
+	w, err := logging.NewRotatingWriter(path, logging.RotationPolicy{
+		MaxSize:  maxSize,
+		MaxFiles: maxFiles,
+		Compress: compress,
+	})
+	if err != nil {
+		if recorder != nil {
+			_ = recorder.Close()
+		}
+		return err
+	}
+
+	if recorder == nil {
+		_, copyErr := io.Copy(w, os.Stdin)
+		return errors.Join(copyErr, w.Close())
+	}
+	_, copyErr := io.Copy(io.MultiWriter(w, recorder), os.Stdin)
+	return errors.Join(copyErr, w.Close(), recorder.Close())
+}
+
+// logWriterCommand returns the command prefix multiplexers use to pipe session
+// output through log-writer, or nil when log rotation is disabled.
+func logWriterCommand(policy logging.RotationPolicy) ([]string, error) {
+	if policy.MaxSize <= 0 {
+		return nil, nil
+	}
+	return writerCommand("log-writer", policy)
+}
+
+// transcriptWriterCommand returns the command prefix multiplexers use to pipe
+// the output of transcript mode sessions through transcript-writer.
+func transcriptWriterCommand(policy logging.RotationPolicy) ([]string, error) {
+	return writerCommand("transcript-writer", policy)
+}
+
+// writerCommand returns the command prefix running a writer subcommand with
+// the given rotation policy.
+func writerCommand(name string, policy logging.RotationPolicy) ([]string, error) {
+	self, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("locate headjack executable: %w", err)
+	}
+
+	return []string{
+		self, name,
+		"--max-size", strconv.FormatInt(policy.MaxSize, 10),
+		"--max-files", strconv.Itoa(policy.MaxFiles),
+		"--compress=" + strconv.FormatBool(policy.Compress),
+	}, nil
+}
+
+// logRotationPolicy returns the session log rotation policy from config.
+func logRotationPolicy(cfg *config.Config) (logging.RotationPolicy, error) {
+	if cfg == nil {
+		return logging.RotationPolicy{}, nil
+	}
+
+	maxSize, err := logging.ParseSize(cfg.Logging.MaxSize)
+	if err != nil {
+		return logging.RotationPolicy{}, fmt.Errorf("logging.max_size: %w", err)
+	}
+	return logging.RotationPolicy{
+		MaxSize:  maxSize,
+		MaxFiles: cfg.Logging.MaxFiles,
+		Compress: cfg.Logging.Compress,
+	}, nil
+}
+
+// logRetentionPolicy returns the session log retention policy from config.
+func logRetentionPolicy(cfg *config.Config) (logging.RetentionPolicy, error) {
+	if cfg == nil {
+		return logging.RetentionPolicy{}, nil
+	}
+
+	maxAge, err := parseAge(cfg.Logging.MaxAge)
+	if err != nil {
+		return logging.RetentionPolicy{}, fmt.Errorf("logging.max_age: %w", err)
+	}
+	maxTotal, err := logging.ParseSize(cfg.Logging.MaxTotalSize)
+	if err != nil {
+		return logging.RetentionPolicy{}, fmt.Errorf("logging.max_total_size: %w", err)
+	}
+	return logging.RetentionPolicy{MaxAge: maxAge, MaxTotalSize: maxTotal}, nil
+}
+
+// parseAge parses an age such as "720h" or "30d". An empty value yields 0.
+func parseAge(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	if n, ok := parseDays(value); ok {
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid age %q (use a duration like 720h or 30d)", value)
+	}
+	return d, nil
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{logWriterCmd, transcriptWriterCmd} {
+		cmd.Flags().Int64("max-size", 0, "rotate the log once it reaches this many bytes (0 disables rotation)")
+		cmd.Flags().Int("max-files", 0, "number of rotated segments to keep")
+		cmd.Flags().Bool("compress", false, "gzip rotated segments")
+		rootCmd.AddCommand(cmd)
+	}
+}
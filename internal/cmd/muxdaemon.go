@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jmgilman/headjack/internal/ptyd"
+)
+
+var muxDaemonCmd = &cobra.Command{
+	Use:    "mux-daemon",
+	Short:  "Run the built-in multiplexer session daemon",
+	Hidden: true,
+	Long: `Run the built-in multiplexer session daemon in the foreground.
+
+The daemon is started automatically when multiplexer.name is "builtin" and a
+session is created; it is not normally run by hand. It owns a PTY for every
+session, writes session logs, and serves attach requests on a Unix socket.`,
+	Args: cobra.NoArgs,
+	// The daemon needs neither the container runtime nor the instance manager
+	PersistentPreRunE: func(*cobra.Command, []string) error { return nil },
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		socketPath, err := cmd.Flags().GetString("socket")
+		if err != nil {
+			return fmt.Errorf("get socket flag: %w", err)
+		}
+		if socketPath == "" {
+			socketPath, err = muxSocketPath()
+			if err != nil {
+				return err
+			}
+		}
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+
+		return ptyd.NewServer(socketPath).Serve(ctx)
+	},
+}
+
+// muxSocketPath returns the built-in multiplexer's socket path from config or the default.
+func muxSocketPath() (string, error) {
+	if appConfig != nil && appConfig.Multiplexer.Socket != "" {
+		return appConfig.Multiplexer.Socket, nil
+	}
+	dataDir, err := defaultDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataDir, "mux.sock"), nil
+}
+
+// startMuxDaemon returns a starter that launches the session daemon as a
+// detached background process. Daemon output is written next to the socket.
+func startMuxDaemon(socketPath string) func(context.Context) error {
+	return func(context.Context) error {
+		self, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf("locate headjack executable: %w", err)
+		}
+
+		if err := os.MkdirAll(filepath.Dir(socketPath), 0o700); err != nil {
+			return fmt.Errorf("create socket directory: %w", err)
+		}
+		//nolint:gosec // G304: path is derived from configuration
+		logFile, err := os.OpenFile(socketPath+".log", os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+		if err != nil {
+			return fmt.Errorf("open daemon log: %w", err)
+		}
+		defer logFile.Close()
+
+		// Not bound to the command context: the daemon must outlive this process
+		//nolint:gosec,noctx // G204: re-executes the running headjack binary
+		daemon := exec.Command(self, "mux-daemon", "--socket", socketPath)
+		daemon.Stdout = logFile
+		daemon.Stderr = logFile
+		// A new session detaches the daemon from this terminal's signals
+		daemon.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+
+		if err := daemon.Start(); err != nil {
+			return fmt.Errorf("start daemon: %w", err)
+		}
+		return daemon.Process.Release()
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(muxDaemonCmd)
+
+	muxDaemonCmd.Flags().String("socket", "", "socket path (default: multiplexer.socket)")
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/jmgilman/headjack/internal/instance"
+)
+
+var peekCmd = &cobra.Command{
+	Use:   "peek <branch> <session>",
+	Short: "Print what a session is currently displaying",
+	Long: `Print what a session is currently displaying, without attaching.
+
+Unlike 'hjk logs', which replays the raw output stream including every cursor
+movement and redraw, peek prints the session's screen exactly as it would
+appear if you attached now. Use --history to include the scrollback above the
+visible screen.
+
+Colors are preserved when writing to a terminal. Use --plain to print text
+only.`,
+	Example: `  # See what an agent is showing right now
+  hjk peek feat/auth claude-main
+
+  # Include the scrollback
+  hjk peek feat/auth claude-main --history
+
+  # Save the screen as plain text
+  hjk peek feat/auth claude-main --plain > screen.txt`,
+	Args: cobra.ExactArgs(2),
+	RunE: runPeekCmd,
+}
+
+func runPeekCmd(cmd *cobra.Command, args []string) error {
+	branch, sessionName := args[0], args[1]
+
+	history, err := cmd.Flags().GetBool("history")
+	if err != nil {
+		return fmt.Errorf("get history flag: %w", err)
+	}
+	plain, err := cmd.Flags().GetBool("plain")
+	if err != nil {
+		return fmt.Errorf("get plain flag: %w", err)
+	}
+
+	mgr, err := requireManager(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	inst, err := getInstanceByBranch(cmd.Context(), mgr, branch, "")
+	if err != nil {
+		return err
+	}
+
+	screen, err := mgr.CaptureSession(cmd.Context(), inst.ID, sessionName, &instance.CaptureConfig{
+		History: history,
+		Escapes: !plain && term.IsTerminal(int(os.Stdout.Fd())),
+	})
+	if err != nil {
+		if errors.Is(err, instance.ErrSessionNotFound) {
+			return fmt.Errorf("session %q not found in instance for branch %q", sessionName, branch)
+		}
+		return fmt.Errorf("capture session: %w", err)
+	}
+
+	fmt.Print(screen)
+	return nil
+}
+
+func init() {
+	peekCmd.Flags().Bool("history", false, "include scrollback above the visible screen")
+	peekCmd.Flags().Bool("plain", false, "print text without colors")
+	rootCmd.AddCommand(peekCmd)
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/jmgilman/headjack/internal/exec"
 	"github.com/jmgilman/headjack/internal/git"
 	"github.com/jmgilman/headjack/internal/instance"
+	"github.com/jmgilman/headjack/internal/meter"
 )
 
 var psCmd = &cobra.Command{
@@ -23,7 +24,10 @@ By default, lists instances for the current repository.
 If a branch is specified, lists sessions for that instance instead.
 
 Use --all to list instances across all repositories (only applies when
-listing instances, not sessions).`,
+listing instances, not sessions).
+
+Use --usage to add the tokens and cost used by agent sessions, read from
+the agents' session files in each running instance.`,
 	Example: `  # List instances for current repo
   headjack ps
 
@@ -31,22 +35,40 @@ listing instances, not sessions).`,
   headjack ps --all
 
   # List sessions for a specific instance
-  headjack ps feat/auth`,
+  headjack ps feat/auth
+
+  # Show token usage and cost of each session
+  headjack ps feat/auth --usage`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runPsCmd,
 }
 
 func runPsCmd(cmd *cobra.Command, args []string) error {
+	showUsage, err := cmd.Flags().GetBool("usage")
+	if err != nil {
+		return fmt.Errorf("get usage flag: %w", err)
+	}
+	if showUsage {
+		mgr, mgrErr := requireManager(cmd.Context())
+		if mgrErr != nil {
+			return mgrErr
+		}
+		// Best effort - stale usage is still worth showing
+		if refreshErr := mgr.RefreshUsage(cmd.Context()); refreshErr != nil {
+			fmt.Fprintf(os.Stderr, "warning: failed to refresh usage: %v\n", refreshErr)
+		}
+	}
+
 	// If a branch is specified, list sessions for that instance
 	if len(args) == 1 {
-		return listSessions(cmd, args[0])
+		return listSessions(cmd, args[0], showUsage)
 	}
 
 	// Otherwise, list instances
-	return listInstances(cmd)
+	return listInstances(cmd, showUsage)
 }
 
-func listInstances(cmd *cobra.Command) error {
+func listInstances(cmd *cobra.Command, showUsage bool) error {
 	all, err := cmd.Flags().GetBool("all")
 	if err != nil {
 		return fmt.Errorf("get all flag: %w", err)
@@ -90,22 +112,34 @@ func listInstances(cmd *cobra.Command) error {
 	}
 
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	if _, err := fmt.Fprintln(w, "BRANCH\tSTATUS\tSESSIONS\tCREATED"); err != nil {
+	header := "BRANCH\tSTATUS\tSESSIONS\tCREATED"
+	if showUsage {
+		header += "\tTOKENS\tCOST"
+	}
+	if _, err := fmt.Fprintln(w, header); err != nil {
 		return fmt.Errorf("write header: %w", err)
 	}
 	for i := range instances {
 		inst := &instances[i]
-		sessionCount, countErr := getSessionCount(cmd, mgr, inst.ID)
-		if countErr != nil {
-			// Best effort - show 0 if we can't get the count
-			sessionCount = 0
+		sessions, listErr := mgr.ListSessions(cmd.Context(), inst.ID)
+		if listErr != nil {
+			// Best effort - show 0 sessions if we can't list them
+			sessions = nil
 		}
-		if _, err := fmt.Fprintf(w, "%s\t%s\t%d\t%s\n",
+		row := fmt.Sprintf("%s\t%s\t%d\t%s",
 			inst.Branch,
 			inst.Status,
-			sessionCount,
+			len(sessions),
 			formatTimeAgo(inst.CreatedAt),
-		); err != nil {
+		)
+		if showUsage {
+			var total meter.Usage
+			for j := range sessions {
+				total.Add(sessions[j].Usage)
+			}
+			row += fmt.Sprintf("\t%s\t%s", formatTokens(total.TotalTokens()), formatCost(total.CostUSD))
+		}
+		if _, err := fmt.Fprintln(w, row); err != nil {
 			return fmt.Errorf("write instance: %w", err)
 		}
 	}
@@ -116,7 +150,7 @@ func listInstances(cmd *cobra.Command) error {
 	return nil
 }
 
-func listSessions(cmd *cobra.Command, branch string) error {
+func listSessions(cmd *cobra.Command, branch string, showUsage bool) error {
 	mgr, err := requireManager(cmd.Context())
 	if err != nil {
 		return err
@@ -138,19 +172,29 @@ func listSessions(cmd *cobra.Command, branch string) error {
 	}
 
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	if _, err := fmt.Fprintln(w, "SESSION\tTYPE\tSTATUS\tCREATED\tACCESSED"); err != nil {
+	header := "SESSION\tTYPE\tPROFILE\tSTATUS\tCREATED\tACCESSED"
+	if showUsage {
+		header += "\tINPUT\tOUTPUT\tCACHED\tCOST"
+	}
+	if _, err := fmt.Fprintln(w, header); err != nil {
 		return fmt.Errorf("write header: %w", err)
 	}
-	for _, sess := range sessions {
+	for i := range sessions {
+		sess := &sessions[i]
 		// Sessions in headjack are always detached when not actively attached
 		status := "detached"
-		if _, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+		row := fmt.Sprintf("%s\t%s\t%s\t%s\t%s\t%s",
 			sess.Name,
 			sess.Type,
+			valueOrDash(sess.AuthProfile),
 			status,
 			formatTimeAgo(sess.CreatedAt),
 			formatTimeAgo(sess.LastAccessed),
-		); err != nil {
+		)
+		if showUsage {
+			row += "\t" + formatUsageColumns(sess.Usage)
+		}
+		if _, err := fmt.Fprintln(w, row); err != nil {
 			return fmt.Errorf("write session: %w", err)
 		}
 	}
@@ -161,14 +205,6 @@ func listSessions(cmd *cobra.Command, branch string) error {
 	return nil
 }
 
-func getSessionCount(cmd *cobra.Command, mgr *instance.Manager, instanceID string) (int, error) {
-	sessions, err := mgr.ListSessions(cmd.Context(), instanceID)
-	if err != nil {
-		return 0, err
-	}
-	return len(sessions), nil
-}
-
 // formatTimeAgo formats a time as a human-readable relative time.
 func formatTimeAgo(t time.Time) string {
 	d := time.Since(t)
@@ -213,4 +249,5 @@ func init() {
 	rootCmd.AddCommand(psCmd)
 
 	psCmd.Flags().BoolP("all", "a", false, "list instances across all repositories")
+	psCmd.Flags().Bool("usage", false, "show token usage and cost of agent sessions")
 }
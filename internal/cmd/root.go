@@ -21,7 +21,7 @@ import (
 	"github.com/jmgilman/headjack/internal/git"
 	"github.com/jmgilman/headjack/internal/instance"
 	"github.com/jmgilman/headjack/internal/multiplexer"
-	"github.com/jmgilman/headjack/internal/registry"
+	"github.com/jmgilman/headjack/internal/notify"
 )
 
 // baseDeps lists the external binaries that must always be available.
@@ -36,6 +36,15 @@ const runtimeNameDocker = "docker"
 // runtimeBinaryDocker is the binary name for Docker.
 const runtimeBinaryDocker = "docker"
 
+// muxNameTmux is the multiplexer name (and binary) for tmux.
+const muxNameTmux = "tmux"
+
+// muxNameZellij is the multiplexer name (and binary) for Zellij.
+const muxNameZellij = "zellij"
+
+// muxNameBuiltin is the multiplexer name for headjack's own session daemon.
+const muxNameBuiltin = "builtin"
+
 // mgr is the instance manager, initialized in PersistentPreRunE.
 var mgr *instance.Manager
 
@@ -121,6 +130,13 @@ func checkDependencies() error {
 		missing = append(missing, runtimeBin)
 	}
 
+	// Check multiplexer dependency (the built-in multiplexer needs no binary)
+	if muxBin := getMultiplexerName(); muxBin != muxNameBuiltin {
+		if _, err := exec.LookPath(muxBin); err != nil {
+			missing = append(missing, muxBin)
+		}
+	}
+
 	if len(missing) > 0 {
 		return errors.New("missing required dependencies: " + formatList(missing))
 	}
@@ -141,6 +157,21 @@ func getRuntimeBinary() string {
 	return runtimeBinaryDocker
 }
 
+// getMultiplexerName returns the configured multiplexer name.
+// For external multiplexers this is also the binary name.
+func getMultiplexerName() string {
+	if appConfig != nil {
+		switch appConfig.Multiplexer.Name {
+		case muxNameZellij:
+			return muxNameZellij
+		case muxNameBuiltin:
+			return muxNameBuiltin
+		}
+	}
+	// Default to tmux
+	return muxNameTmux
+}
+
 // initManager initializes the instance manager with all dependencies.
 func initManager() error {
 	var worktreesDir string
@@ -183,11 +214,26 @@ func initManager() error {
 
 	opener := git.NewOpener(executor)
 
-	// Use tmux as the terminal multiplexer
-	mux := multiplexer.NewTmux(executor)
+	// Select multiplexer: config > default (tmux)
+	var mux multiplexer.Multiplexer
+	switch getMultiplexerName() {
+	case muxNameZellij:
+		mux = multiplexer.NewZellij(executor)
+	case muxNameBuiltin:
+		socketPath, err := muxSocketPath()
+		if err != nil {
+			return err
+		}
+		mux = multiplexer.NewBuiltin(socketPath, startMuxDaemon(socketPath))
+	default:
+		mux = multiplexer.NewTmux(executor)
+	}
 
 	// Create registry client for fetching image metadata
-	regClient := registry.NewClient(registry.ClientConfig{})
+	regClient, err := registryClient(appConfig, executor)
+	if err != nil {
+		return err
+	}
 
 	// Map runtime name to RuntimeType
 	runtimeType := runtimeNameToType(runtimeName)
@@ -198,16 +244,76 @@ func initManager() error {
 		return err
 	}
 
+	auditLog, err := openAuditLog(appConfig)
+	if err != nil {
+		return err
+	}
+
+	usageLedger, err := openUsageLedger(appConfig)
+	if err != nil {
+		return err
+	}
+
+	agents, err := agentRegistry(appConfig)
+	if err != nil {
+		return err
+	}
+	budgets, err := agentBudgets(appConfig)
+	if err != nil {
+		return err
+	}
+	budgetState, err := openBudgetState()
+	if err != nil {
+		return err
+	}
+
+	rotation, err := logRotationPolicy(appConfig)
+	if err != nil {
+		return err
+	}
+	logCommand, err := logWriterCommand(rotation)
+	if err != nil {
+		return err
+	}
+	transcriptCommand, err := transcriptWriterCommand(rotation)
+	if err != nil {
+		return err
+	}
+	retention, err := logRetentionPolicy(appConfig)
+	if err != nil {
+		return err
+	}
+
 	mgr = instance.NewManager(store, runtime, opener, mux, regClient, instance.ManagerConfig{
-		WorktreesDir: worktreesDir,
-		LogsDir:      logsDir,
-		RuntimeType:  runtimeType,
-		ConfigFlags:  configFlags,
+		WorktreesDir:      worktreesDir,
+		LogsDir:           logsDir,
+		RuntimeType:       runtimeType,
+		ConfigFlags:       configFlags,
+		Auditor:           auditLog,
+		LogCommand:        logCommand,
+		TranscriptCommand: transcriptCommand,
+		LogRetention:      retention,
+		Notifier:          sessionNotifier(executor, appConfig),
+		UsageLedger:       usageLedger,
+		Agents:            agents,
+		Secrets:           &keychainSecrets{},
+		Budgets:           budgets,
+		BudgetState:       budgetState,
+		StartWatchdog:     startWatchdog,
 	})
 
 	return nil
 }
 
+// sessionNotifier returns the notifier for sessions headjack kills on its
+// own, or nil if sessions.notify_command is not configured.
+func sessionNotifier(executor hjexec.Executor, cfg *config.Config) notify.Notifier {
+	if cfg == nil || cfg.Sessions.NotifyCommand == "" {
+		return nil
+	}
+	return notify.NewCommand(executor, cfg.Sessions.NotifyCommand)
+}
+
 // runtimeNameToType converts a runtime name string to RuntimeType.
 func runtimeNameToType(name string) instance.RuntimeType {
 	switch name {
@@ -1,15 +1,21 @@
 package cmd
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/jmgilman/headjack/internal/agent"
+	"github.com/jmgilman/headjack/internal/audit"
 	"github.com/jmgilman/headjack/internal/auth"
 	"github.com/jmgilman/headjack/internal/config"
 	"github.com/jmgilman/headjack/internal/instance"
 	"github.com/jmgilman/headjack/internal/keychain"
+	"github.com/jmgilman/headjack/internal/transcript"
 )
 
 // agentDefaultSentinel is the sentinel value used when --agent flag is specified without a value.
@@ -29,7 +35,22 @@ the agent is started (with an optional prompt). Otherwise, the default shell
 is started.
 
 Unless --detached is specified, the terminal attaches to the session.
-All session output is captured to a log file regardless of attached/detached mode.`,
+All session output is captured to a log file regardless of attached/detached mode.
+
+With --transcript, a detached agent runs headless on the prompt and streams
+structured JSON events instead of drawing its interactive UI. Use
+'hjk transcript' to summarize the tool calls, edited files, and final message.
+Setting agents.<agent>.transcript enables this for every detached agent
+session started with a prompt.
+
+With --timeout, the session is killed once it has run that long. Agent
+sessions otherwise use agents.<agent>.max_runtime or sessions.max_runtime
+from config; --timeout 0 disables the limit.
+
+With --auth-profile, the agent's credential is loaded from the named profile
+configured with 'hjk auth <agent> --profile'. Without it, the profile set by
+auth.profile in the repository's .headjack.yaml is used, then auth.profile
+from config, then the default profile.`,
 	Example: `  # New instance with shell session
   headjack run feat/auth
 
@@ -46,6 +67,15 @@ All session output is captured to a log file regardless of attached/detached mod
   headjack run feat/auth --agent claude -d "Refactor the auth module"
   headjack run feat/auth --agent claude -d "Write tests for auth module"
 
+  # Detached session with a structured transcript
+  headjack run feat/auth --agent claude -d --transcript "Fix the login bug"
+
+  # Kill the agent if it is still running after two hours
+  headjack run feat/auth --agent claude -d --timeout 2h "Migrate the database layer"
+
+  # Use the work credentials instead of the default profile
+  headjack run feat/auth --agent claude --auth-profile work
+
   # Use a custom base image
   headjack run feat/auth --base my-registry.io/custom-image:latest`,
 	Args: cobra.RangeArgs(1, 2),
@@ -58,6 +88,10 @@ type runFlags struct {
 	agent       string
 	sessionName string
 	detached    bool
+	transcript  bool
+	timeout     time.Duration
+	hasTimeout  bool // --timeout was given, overriding the configured limit
+	authProfile string
 }
 
 // parseRunFlags extracts and validates flags from the command.
@@ -78,6 +112,36 @@ func parseRunFlags(cmd *cobra.Command) (*runFlags, error) {
 	if err != nil {
 		return nil, fmt.Errorf("get detached flag: %w", err)
 	}
+	transcriptMode, err := cmd.Flags().GetBool("transcript")
+	if err != nil {
+		return nil, fmt.Errorf("get transcript flag: %w", err)
+	}
+	if transcriptMode && agent == "" {
+		return nil, errors.New("--transcript requires --agent")
+	}
+	if transcriptMode && !detached {
+		return nil, errors.New("--transcript requires --detached")
+	}
+	timeout, err := cmd.Flags().GetDuration("timeout")
+	if err != nil {
+		return nil, fmt.Errorf("get timeout flag: %w", err)
+	}
+	if timeout < 0 {
+		return nil, errors.New("--timeout cannot be negative")
+	}
+
+	authProfile, err := cmd.Flags().GetString("auth-profile")
+	if err != nil {
+		return nil, fmt.Errorf("get auth-profile flag: %w", err)
+	}
+	if authProfile != "" {
+		if agent == "" {
+			return nil, errors.New("--auth-profile requires --agent")
+		}
+		if err := auth.ValidateProfile(authProfile); err != nil {
+			return nil, err
+		}
+	}
 
 	image = resolveBaseImage(cmd.Context(), image)
 
@@ -86,104 +150,201 @@ func parseRunFlags(cmd *cobra.Command) (*runFlags, error) {
 		agent:       agent,
 		sessionName: sessionName,
 		detached:    detached,
+		transcript:  transcriptMode,
+		timeout:     timeout,
+		hasTimeout:  cmd.Flags().Changed("timeout"),
+		authProfile: authProfile,
 	}, nil
 }
 
 // buildSessionConfig builds a session configuration from flags and args.
-func buildSessionConfig(cmd *cobra.Command, flags *runFlags, args []string) (*instance.CreateSessionConfig, error) {
+// repo is the instance's repository, whose configuration may select the
+// credential profile.
+func buildSessionConfig(cmd *cobra.Command, flags *runFlags, repo string, args []string) (*instance.CreateSessionConfig, error) {
 	cfg := &instance.CreateSessionConfig{
-		Type: "shell",
-		Name: flags.sessionName,
+		Type:       "shell",
+		Name:       flags.sessionName,
+		MaxRuntime: flags.timeout,
 	}
 
 	if flags.agent == "" {
 		return cfg, nil
 	}
 
-	agent, err := resolveAgent(cmd, flags.agent)
+	agents, err := agentRegistry(ConfigFromContext(cmd.Context()))
+	if err != nil {
+		return nil, err
+	}
+	agentName, err := resolveAgent(cmd, agents, flags.agent)
+	if err != nil {
+		return nil, err
+	}
+	def, err := agents.Get(agentName)
 	if err != nil {
 		return nil, err
 	}
 
-	cfg.Type = agent
-	cfg.Command = buildAgentCommand(agent, args)
+	if !flags.hasTimeout {
+		cfg.MaxRuntime, err = sessionMaxRuntime(ConfigFromContext(cmd.Context()), agentName)
+		if err != nil {
+			return nil, err
+		}
+	}
 
-	// Inject agent-specific environment variables from config
-	if loader := LoaderFromContext(cmd.Context()); loader != nil {
-		for k, v := range loader.GetAgentEnv(agent) {
-			cfg.Env = append(cfg.Env, k+"="+v)
+	cfg.Type = agentName
+	if len(args) > 1 {
+		cfg.Prompt = args[1]
+	}
+	cfg.Command = def.BuildCommand(cfg.Prompt)
+
+	if useTranscript(cmd, flags, agentName, cfg.Prompt) {
+		command, err := transcript.Command(def, cfg.Prompt)
+		if err != nil {
+			return nil, fmt.Errorf("transcript mode: %w", err)
 		}
+		cfg.Command = command
+		cfg.Transcript = true
+	}
+
+	// Inject agent-specific environment variables from config
+	for k, v := range def.Env {
+		cfg.Env = append(cfg.Env, k+"="+v)
 	}
 
 	// Inject authentication credentials from keychain
-	if err := injectAuthCredential(agent, cfg); err != nil {
-		return nil, err
+	if def.Auth != "" {
+		repoCfg, err := config.LoadRepoConfig(repo)
+		if err != nil {
+			return nil, err
+		}
+		profile := config.AuthProfile(flags.authProfile, repoCfg, ConfigFromContext(cmd.Context()))
+		if err := injectAuthCredential(cmd.Context(), def, profile, cfg); err != nil {
+			return nil, err
+		}
 	}
 
 	return cfg, nil
 }
 
-// agentAuthSpec maps agent names to their providers.
-type agentAuthSpec struct {
-	provider         func() auth.Provider
-	notConfiguredMsg string
-}
-
-var agentAuthSpecs = map[string]agentAuthSpec{
-	"claude": {
-		provider:         func() auth.Provider { return auth.NewClaudeProvider() },
-		notConfiguredMsg: "claude auth not configured: run 'hjk auth claude' first",
-	},
-	"gemini": {
-		provider:         func() auth.Provider { return auth.NewGeminiProvider() },
-		notConfiguredMsg: "gemini auth not configured: run 'hjk auth gemini' first",
-	},
-	"codex": {
-		provider:         func() auth.Provider { return auth.NewCodexProvider() },
-		notConfiguredMsg: "codex auth not configured: run 'hjk auth codex' first",
-	},
+// useTranscript reports whether a session should run in transcript mode:
+// either --transcript was given, or the agent enables transcripts in config
+// and the session is detached with a prompt to run headless.
+func useTranscript(cmd *cobra.Command, flags *runFlags, agent, prompt string) bool {
+	if flags.transcript {
+		return true
+	}
+	if !flags.detached || prompt == "" {
+		return false
+	}
+	cfg := ConfigFromContext(cmd.Context())
+	return cfg != nil && cfg.Agents[agent].Transcript
 }
 
-// injectAuthCredential retrieves the credential for the agent and configures the session.
-func injectAuthCredential(agent string, cfg *instance.CreateSessionConfig) error {
-	spec, ok := agentAuthSpecs[agent]
-	if !ok {
+// injectAuthCredential retrieves the credential of the agent's auth provider
+// from the given profile and configures the session. Subscription credentials
+// are re-imported first if they changed on the host, and expired credentials
+// the agent cannot refresh are refused. Agents without an auth provider are
+// skipped.
+func injectAuthCredential(ctx context.Context, def *agent.Definition, profile string, cfg *instance.CreateSessionConfig) error {
+	if def.Auth == "" {
 		return nil
 	}
+	provider, err := auth.NewProvider(def.Auth)
+	if err != nil {
+		return fmt.Errorf("agent %s: %w", def.Name, err)
+	}
 
-	storage, err := keychain.New()
+	storage, err := openCredentialStorage()
 	if err != nil {
-		return fmt.Errorf("initialize credential storage: %w", err)
+		return err
 	}
 
-	provider := spec.provider()
-	cred, err := provider.Load(storage)
+	cred, err := provider.Load(storage, profile)
 	if err != nil {
 		if errors.Is(err, keychain.ErrNotFound) {
-			return errors.New(spec.notConfiguredMsg)
+			if profile != auth.DefaultProfile {
+				return fmt.Errorf("%s auth profile %q not configured: run '%s' first", def.Name, profile, authCommand(def.Auth, profile))
+			}
+			return fmt.Errorf("%s auth not configured: run '%s' first", def.Name, authCommand(def.Auth, profile))
 		}
-		return fmt.Errorf("load %s credential: %w", agent, err)
+		return fmt.Errorf("load %s credential: %w", def.Name, err)
 	}
 
-	info := provider.Info()
+	cred, reimported, err := auth.ReimportSubscription(provider, storage, profile, cred)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to re-import %s credentials from host: %v\n", def.Auth, err)
+	}
+	if reimported {
+		fmt.Printf("Re-imported %s credentials from host\n", def.Auth)
+		recordAuthAudit(ctx, audit.ActionAuthConfigure, def.Auth, profile, cred.Type)
+	}
+	if err := checkCredentialExpiry(provider, def, profile, cred); err != nil {
+		return err
+	}
+	cfg.AuthProfile = profile
 
-	// Set environment variable based on credential type
+	env, requiresSetup, err := credentialEnv(provider.Info(), cred)
+	if err != nil {
+		return err
+	}
+	cfg.Env = append(cfg.Env, env...)
+	cfg.CredentialType = string(cred.Type)
+	cfg.RequiresAgentSetup = requiresSetup
+
+	return nil
+}
+
+// credentialEnv returns the environment variables passing a credential to
+// the agent, and whether the agent's auth setup must write it to files.
+func credentialEnv(info auth.ProviderInfo, cred *auth.Credential) ([]string, bool, error) {
 	switch cred.Type {
 	case auth.CredentialTypeSubscription:
-		cfg.Env = append(cfg.Env, info.SubscriptionEnvVar+"="+cred.Value)
-		cfg.CredentialType = string(auth.CredentialTypeSubscription)
-		cfg.RequiresAgentSetup = info.RequiresContainerSetup
+		return []string{info.SubscriptionEnvVar + "=" + cred.Value}, info.RequiresContainerSetup, nil
 	case auth.CredentialTypeAPIKey:
-		cfg.Env = append(cfg.Env, info.APIKeyEnvVar+"="+cred.Value)
-		cfg.CredentialType = string(auth.CredentialTypeAPIKey)
-		cfg.RequiresAgentSetup = false // API keys don't need file setup
+		// API keys don't need file setup
+		return []string{info.APIKeyEnvVar + "=" + cred.Value}, false, nil
 	default:
-		return fmt.Errorf("unknown credential type: %s", cred.Type)
+		return nil, false, fmt.Errorf("unknown credential type: %s", cred.Type)
+	}
+}
+
+// credentialExpiryWarning is how long before a credential the agent cannot
+// refresh expires that hjk run starts warning about it.
+const credentialExpiryWarning = 24 * time.Hour
+
+// checkCredentialExpiry refuses an expired credential and warns about one
+// expiring soon. Credentials with a refresh token are left to the agent,
+// which refreshes its access token on its own.
+func checkCredentialExpiry(provider auth.Provider, def *agent.Definition, profile string, cred *auth.Credential) error {
+	meta, err := provider.Metadata(cred)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to read %s credential expiry: %v\n", def.Auth, err)
+		return nil
+	}
+	if meta.ExpiresAt.IsZero() || meta.Refreshable {
+		return nil
 	}
 
+	now := time.Now()
+	if meta.Expired(now) {
+		return fmt.Errorf("%s %w at %s: run '%s' to renew it",
+			def.Name, auth.ErrCredentialExpired, meta.ExpiresAt.Local().Format(time.DateTime), authCommand(def.Auth, profile))
+	}
+	if remaining := meta.ExpiresAt.Sub(now); remaining < credentialExpiryWarning {
+		fmt.Fprintf(os.Stderr, "warning: %s credential expires in %s; run '%s' to renew it\n",
+			def.Name, remaining.Round(time.Minute), authCommand(def.Auth, profile))
+	}
 	return nil
 }
 
+// authCommand returns the hjk auth command that configures a provider profile.
+func authCommand(provider, profile string) string {
+	if profile == auth.DefaultProfile {
+		return "hjk auth " + provider
+	}
+	return "hjk auth " + provider + " --profile " + profile
+}
+
 func runRunCmd(cmd *cobra.Command, args []string) error {
 	branch := args[0]
 
@@ -207,7 +368,7 @@ func runRunCmd(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	sessionCfg, err := buildSessionConfig(cmd, flags, args)
+	sessionCfg, err := buildSessionConfig(cmd, flags, inst.Repo, args)
 	if err != nil {
 		return err
 	}
@@ -229,6 +390,9 @@ func runRunCmd(cmd *cobra.Command, args []string) error {
 
 	if flags.detached {
 		fmt.Printf("Created session %s in instance %s (detached)\n", session.Name, inst.ID)
+		if session.MaxRuntime > 0 {
+			fmt.Printf("Session will be killed after %s\n", session.MaxRuntime)
+		}
 		return nil
 	}
 
@@ -299,8 +463,8 @@ func getOrCreateInstance(cmd *cobra.Command, mgr *instance.Manager, repoPath, br
 }
 
 // resolveAgent resolves the agent name, handling the default sentinel.
-func resolveAgent(cmd *cobra.Command, agent string) (string, error) {
-	if agent == agentDefaultSentinel {
+func resolveAgent(cmd *cobra.Command, agents *agent.Registry, name string) (string, error) {
+	if name == agentDefaultSentinel {
 		cfg := ConfigFromContext(cmd.Context())
 		if cfg != nil && cfg.Default.Agent != "" {
 			return cfg.Default.Agent, nil
@@ -309,29 +473,92 @@ func resolveAgent(cmd *cobra.Command, agent string) (string, error) {
 	}
 
 	// Validate agent name
-	if !config.IsValidAgent(agent) {
-		return "", fmt.Errorf("invalid agent %q (valid: %s)", agent, formatList(config.ValidAgentNames()))
+	if !agents.Has(name) {
+		return "", fmt.Errorf("invalid agent %q (valid: %s)", name, formatList(agents.Names()))
 	}
 
-	return agent, nil
+	return name, nil
 }
 
-// buildAgentCommand builds the command for launching an agent.
-func buildAgentCommand(agent string, args []string) []string {
-	cmd := []string{agent}
-	if len(args) > 1 {
-		cmd = append(cmd, args[1])
+// agentRegistry returns the built-in agents combined with the agents defined
+// in config. Fields set for a built-in agent override its defaults.
+func agentRegistry(cfg *config.Config) (*agent.Registry, error) {
+	if cfg == nil {
+		return agent.DefaultRegistry(), nil
+	}
+
+	builtins := agent.DefaultRegistry()
+	defs := make([]agent.Definition, 0, len(cfg.Agents))
+	for name, agentCfg := range cfg.Agents {
+		def := agent.Definition{Name: name}
+		if builtin, err := builtins.Get(name); err == nil {
+			def = *builtin
+		}
+		if len(agentCfg.Command) > 0 {
+			def.Command = agentCfg.Command
+		}
+		if agentCfg.Prompt != "" {
+			def.Prompt = agent.PromptStyle(agentCfg.Prompt)
+		}
+		if agentCfg.PromptFlag != "" {
+			def.PromptFlag = agentCfg.PromptFlag
+		}
+		if agentCfg.Auth != "" {
+			if _, err := auth.NewProvider(agentCfg.Auth); err != nil {
+				return nil, fmt.Errorf("agents.%s.auth: %w", name, err)
+			}
+			def.Auth = agentCfg.Auth
+			// Subscription credentials are written to the files the
+			// provider's own CLI reads, so default to its setup
+			if builtin, err := builtins.Get(agentCfg.Auth); err == nil {
+				def.AuthSetup = builtin.AuthSetup
+			}
+		}
+		// auth_setup names a built-in agent whose setup to run, or is a script
+		switch builtin, err := builtins.Get(agentCfg.AuthSetup); {
+		case agentCfg.AuthSetup == "none":
+			def.AuthSetup = ""
+		case err == nil:
+			def.AuthSetup = builtin.AuthSetup
+		case agentCfg.AuthSetup != "":
+			def.AuthSetup = agentCfg.AuthSetup
+		}
+		if agentCfg.Setup != "" {
+			def.Setup = agentCfg.Setup
+		}
+		if len(agentCfg.HeadlessArgs) > 0 {
+			def.HeadlessArgs = agentCfg.HeadlessArgs
+		}
+		if agentCfg.TranscriptFormat != "" {
+			def.TranscriptFormat = agentCfg.TranscriptFormat
+		}
+		if agentCfg.UsageFiles != "" {
+			def.UsageFiles = agentCfg.UsageFiles
+		}
+		if agentCfg.UsageFormat != "" {
+			def.UsageFormat = agentCfg.UsageFormat
+		}
+		def.Env = agentCfg.Env
+		defs = append(defs, def)
+	}
+
+	agents, err := agent.NewRegistry(defs...)
+	if err != nil {
+		return nil, fmt.Errorf("load agents: %w", err)
 	}
-	return cmd
+	return agents, nil
 }
 
 func init() {
 	rootCmd.AddCommand(runCmd)
 
-	runCmd.Flags().String("agent", "", "start an agent (claude, gemini, codex, or 'default' for configured default)")
+	runCmd.Flags().String("agent", "", "start an agent (claude, gemini, codex, a custom agent from config, or 'default' for configured default)")
 	runCmd.Flags().String("name", "", "override auto-generated session name")
 	runCmd.Flags().String("base", "", "override the default base image")
 	runCmd.Flags().BoolP("detached", "d", false, "create session but don't attach (run in background)")
+	runCmd.Flags().Bool("transcript", false, "run a detached agent headless and capture a structured transcript")
+	runCmd.Flags().Duration("timeout", 0, "kill the session after this duration (e.g., 90m, 2h; 0 = no limit)")
+	runCmd.Flags().String("auth-profile", "", "credential profile to start the agent with (default: repository or configured auth.profile)")
 
 	agentFlag := runCmd.Flags().Lookup("agent")
 	if agentFlag != nil {
@@ -0,0 +1,249 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/jmgilman/headjack/internal/audit"
+	"github.com/jmgilman/headjack/internal/auth"
+	"github.com/jmgilman/headjack/internal/secret"
+)
+
+var secretCmd = &cobra.Command{
+	Use:   "secret",
+	Short: "Manage secrets injected into agent sessions",
+	Long: `Manage secrets such as GITHUB_TOKEN, NPM_TOKEN, or database URLs that
+agent sessions need besides model credentials.
+
+Secrets are stored in the system keychain, either globally or scoped to the
+repository in the current directory with --repo. Reference a secret from an
+agent's environment in config instead of writing its value there:
+
+  agents:
+    claude:
+      env:
+        GITHUB_TOKEN: secret://github
+
+References are resolved when a session starts. A secret scoped to the
+instance's repository takes precedence over a global secret of the same name.`,
+}
+
+var secretSetCmd = &cobra.Command{
+	Use:   "set <name> [value]",
+	Short: "Store a secret",
+	Long: `Store a secret, replacing any existing value with the same name and scope.
+
+If the value is omitted, it is read from standard input, or prompted for
+without echo when standard input is a terminal. Prefer this over passing the
+value as an argument, which leaves it in your shell history.`,
+	Example: `  # Prompt for a global secret
+  headjack secret set github
+
+  # Store a secret for the current repository only
+  headjack secret set database-url --repo
+
+  # Read the value from another command
+  gh auth token | headjack secret set github`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runSecretSet,
+}
+
+var secretGetCmd = &cobra.Command{
+	Use:   "get <name>",
+	Short: "Print a secret",
+	Example: `  # Print a global secret
+  headjack secret get github
+
+  # Print a secret of the current repository
+  headjack secret get database-url --repo`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSecretGet,
+}
+
+var secretRmCmd = &cobra.Command{
+	Use:   "rm <name>",
+	Short: "Remove a secret",
+	Example: `  # Remove a global secret
+  headjack secret rm github
+
+  # Remove a secret of the current repository
+  headjack secret rm database-url --repo`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSecretRm,
+}
+
+var secretLsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List secrets",
+	Long:  `List the names and scopes of stored secrets. Values are never shown.`,
+	Args:  cobra.NoArgs,
+	RunE:  runSecretLs,
+}
+
+func init() {
+	rootCmd.AddCommand(secretCmd)
+	secretCmd.AddCommand(secretSetCmd, secretGetCmd, secretRmCmd, secretLsCmd)
+
+	for _, cmd := range []*cobra.Command{secretSetCmd, secretGetCmd, secretRmCmd} {
+		cmd.Flags().Bool("repo", false, "scope the secret to the repository in the current directory")
+	}
+}
+
+// secretScope returns the scope selected by the --repo flag, along with the
+// repository root for repository scopes.
+func secretScope(cmd *cobra.Command) (secret.Scope, string, error) {
+	repoScoped, err := cmd.Flags().GetBool("repo")
+	if err != nil {
+		return secret.Scope{}, "", fmt.Errorf("get repo flag: %w", err)
+	}
+	if !repoScoped {
+		return secret.Global(), "", nil
+	}
+	repo, err := currentRepo(cmd.Context())
+	if err != nil {
+		return secret.Scope{}, "", err
+	}
+	return secret.Repo(repo.Identifier()), repo.Root(), nil
+}
+
+func runSecretSet(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	if err := secret.ValidateName(name); err != nil {
+		return err
+	}
+	scope, repoRoot, err := secretScope(cmd)
+	if err != nil {
+		return err
+	}
+
+	var value string
+	if len(args) > 1 {
+		value = args[1]
+	} else {
+		value, err = readSecretValue(name)
+		if err != nil {
+			return err
+		}
+	}
+	if value == "" {
+		return errors.New("secret value cannot be empty")
+	}
+
+	store, err := openSecretStore()
+	if err != nil {
+		return err
+	}
+	if err := store.Set(scope, name, value); err != nil {
+		return err
+	}
+
+	recordSecretAudit(cmd.Context(), audit.ActionSecretSet, name, repoRoot)
+	fmt.Printf("Stored secret %s (%s)\n", name, scope)
+	return nil
+}
+
+// readSecretValue reads a secret value from standard input, prompting for it
+// if standard input is a terminal.
+func readSecretValue(name string) (string, error) {
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		return auth.NewTerminalPrompter().PromptSecret(fmt.Sprintf("Value for %s: ", name))
+	}
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return "", fmt.Errorf("read secret value: %w", err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+func runSecretGet(cmd *cobra.Command, args []string) error {
+	scope, _, err := secretScope(cmd)
+	if err != nil {
+		return err
+	}
+	store, err := openSecretStore()
+	if err != nil {
+		return err
+	}
+
+	value, err := store.Get(scope, args[0])
+	if err != nil {
+		return err
+	}
+	fmt.Println(value)
+	return nil
+}
+
+func runSecretRm(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	scope, repoRoot, err := secretScope(cmd)
+	if err != nil {
+		return err
+	}
+	store, err := openSecretStore()
+	if err != nil {
+		return err
+	}
+
+	if err := store.Delete(scope, name); err != nil {
+		return err
+	}
+
+	recordSecretAudit(cmd.Context(), audit.ActionSecretRemove, name, repoRoot)
+	fmt.Printf("Removed secret %s (%s)\n", name, scope)
+	return nil
+}
+
+func runSecretLs(_ *cobra.Command, _ []string) error {
+	store, err := openSecretStore()
+	if err != nil {
+		return err
+	}
+	entries, err := store.List()
+	if err != nil {
+		return err
+	}
+
+	if len(entries) == 0 {
+		fmt.Println("No secrets found")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	if _, err := fmt.Fprintln(w, "NAME\tSCOPE"); err != nil {
+		return fmt.Errorf("write header: %w", err)
+	}
+	for _, entry := range entries {
+		if _, err := fmt.Fprintf(w, "%s\t%s\n", entry.Name, entry.Scope); err != nil {
+			return fmt.Errorf("write secret: %w", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("flush output: %w", err)
+	}
+
+	return nil
+}
+
+// recordSecretAudit records a secret change in the audit trail (best-effort).
+// Only the name and repository are recorded, never the value.
+func recordSecretAudit(ctx context.Context, action audit.Action, name, repo string) {
+	auditLog, err := openAuditLog(ConfigFromContext(ctx))
+	if err == nil {
+		err = auditLog.Record(ctx, &audit.Event{
+			Action: action,
+			Repo:   repo,
+			Secret: name,
+		})
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to write audit event: %v\n", err)
+	}
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jmgilman/headjack/internal/instance"
+)
+
+var sendCmd = &cobra.Command{
+	Use:   "send <branch> <session> [text]",
+	Short: "Send input to a running session without attaching",
+	Long: `Send input to a running session without attaching.
+
+The text is typed into the session's terminal exactly as if it had been
+entered at the keyboard. Use --enter to press Enter after the text, for
+example to submit a prompt to an agent.
+
+If text is omitted or is "-", it is read from stdin, which allows scripts to
+drive sessions headlessly. A single trailing newline is removed from stdin
+input; use --enter to submit it.`,
+	Example: `  # Nudge an agent to keep going
+  hjk send feat/auth claude-main "continue" --enter
+
+  # Press Enter without typing anything
+  hjk send feat/auth claude-main "" --enter
+
+  # Send a prompt from a file
+  hjk send feat/auth claude-main --enter < prompt.txt`,
+	Args: cobra.RangeArgs(2, 3),
+	RunE: runSendCmd,
+}
+
+func runSendCmd(cmd *cobra.Command, args []string) error {
+	branch, sessionName := args[0], args[1]
+
+	enter, err := cmd.Flags().GetBool("enter")
+	if err != nil {
+		return fmt.Errorf("get enter flag: %w", err)
+	}
+
+	text, err := sendText(cmd, args)
+	if err != nil {
+		return err
+	}
+	if text == "" && !enter {
+		return errors.New("nothing to send (provide text or use --enter)")
+	}
+
+	mgr, err := requireManager(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	inst, err := getInstanceByBranch(cmd.Context(), mgr, branch, "")
+	if err != nil {
+		return err
+	}
+
+	err = mgr.SendInput(cmd.Context(), inst.ID, sessionName, &instance.SendInputConfig{
+		Text:  text,
+		Enter: enter,
+	})
+	if err != nil {
+		if errors.Is(err, instance.ErrSessionNotFound) {
+			return fmt.Errorf("session %q not found in instance for branch %q", sessionName, branch)
+		}
+		return fmt.Errorf("send input: %w", err)
+	}
+
+	return nil
+}
+
+// sendText returns the text argument, reading it from stdin when omitted or "-".
+func sendText(cmd *cobra.Command, args []string) (string, error) {
+	if len(args) == 3 && args[2] != "-" {
+		return args[2], nil
+	}
+
+	data, err := io.ReadAll(cmd.InOrStdin())
+	if err != nil {
+		return "", fmt.Errorf("read stdin: %w", err)
+	}
+
+	text := strings.TrimSuffix(string(data), "\n")
+	return strings.TrimSuffix(text, "\r"), nil
+}
+
+func init() {
+	sendCmd.Flags().Bool("enter", false, "press Enter after sending the text")
+	rootCmd.AddCommand(sendCmd)
+}
@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jmgilman/headjack/internal/logging"
+	"github.com/jmgilman/headjack/internal/transcript"
+)
+
+var transcriptCmd = &cobra.Command{
+	Use:   "transcript <branch> <session>",
+	Short: "Summarize a session's structured agent transcript",
+	Long: `Summarize a session's structured agent transcript.
+
+Sessions started with 'hjk run --transcript' (or with agents.<agent>.transcript
+enabled) run the agent headless, streaming structured JSON events. As the
+session runs, those events are normalized and recorded next to the session
+log as <session-id>.transcript.jsonl. This command prints a summary of the
+tool calls, files edited, and the agent's final message.
+
+The transcript grows as the agent works, so this can be run while the agent
+is still working.`,
+	Example: `  # Summarize what an agent did
+  headjack transcript feat/auth happy-panda
+
+  # Print the normalized events as JSON lines
+  headjack transcript feat/auth happy-panda --json`,
+	Args: cobra.ExactArgs(2),
+	RunE: runTranscriptCmd,
+}
+
+func runTranscriptCmd(cmd *cobra.Command, args []string) error {
+	branch, sessionName := args[0], args[1]
+
+	asJSON, err := cmd.Flags().GetBool("json")
+	if err != nil {
+		return fmt.Errorf("get json flag: %w", err)
+	}
+
+	mgr, err := requireManager(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	inst, err := getInstanceByBranch(cmd.Context(), mgr, branch, "")
+	if err != nil {
+		return err
+	}
+
+	session, err := mgr.GetSession(cmd.Context(), inst.ID, sessionName)
+	if err != nil {
+		return fmt.Errorf("get session: %w", err)
+	}
+	agents, err := agentRegistry(ConfigFromContext(cmd.Context()))
+	if err != nil {
+		return err
+	}
+	if def, getErr := agents.Get(session.Type); getErr != nil || !transcript.Supported(def) {
+		return fmt.Errorf("session %q is a %s session; transcripts are only captured for agents with a transcript format", sessionName, session.Type)
+	}
+
+	logsDir, err := getLogsDir(cmd.Context())
+	if err != nil {
+		return fmt.Errorf("get logs directory: %w", err)
+	}
+	pathMgr := logging.NewPathManager(logsDir)
+
+	events, err := transcript.Read(pathMgr.TranscriptPath(inst.ID, session.ID))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if len(events) == 0 {
+		return fmt.Errorf("no transcript events found for session %s (was it started with --transcript?)", sessionName)
+	}
+
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		for i := range events {
+			if err := enc.Encode(&events[i]); err != nil {
+				return fmt.Errorf("write event: %w", err)
+			}
+		}
+		return nil
+	}
+
+	printTranscriptSummary(transcript.Summarize(events))
+	return nil
+}
+
+func printTranscriptSummary(s *transcript.Summary) {
+	status := "running"
+	switch {
+	case s.Failed:
+		status = "failed"
+	case s.Finished:
+		status = "finished"
+	}
+	fmt.Printf("Status:  %s\n", status)
+	if s.Model != "" {
+		fmt.Printf("Model:   %s\n", s.Model)
+	}
+
+	fmt.Printf("\nTool calls (%d", s.TotalToolCalls())
+	if s.ToolErrors > 0 {
+		fmt.Printf(", %d failed", s.ToolErrors)
+	}
+	fmt.Println("):")
+	tools := make([]string, 0, len(s.ToolCalls))
+	for tool := range s.ToolCalls {
+		tools = append(tools, tool)
+	}
+	// Most used first, then by name
+	sort.Slice(tools, func(i, j int) bool {
+		if s.ToolCalls[tools[i]] != s.ToolCalls[tools[j]] {
+			return s.ToolCalls[tools[i]] > s.ToolCalls[tools[j]]
+		}
+		return tools[i] < tools[j]
+	})
+	for _, tool := range tools {
+		fmt.Printf("  %-20s %d\n", tool, s.ToolCalls[tool])
+	}
+
+	fmt.Printf("\nFiles edited (%d):\n", len(s.FilesEdited))
+	for _, f := range s.FilesEdited {
+		fmt.Printf("  %s\n", f)
+	}
+
+	fmt.Println("\nFinal message:")
+	if s.FinalMessage == "" {
+		fmt.Println("  (none yet)")
+		return
+	}
+	for line := range strings.SplitSeq(strings.TrimSpace(s.FinalMessage), "\n") {
+		fmt.Printf("  %s\n", line)
+	}
+}
+
+func init() {
+	transcriptCmd.Flags().Bool("json", false, "print normalized transcript events as JSON lines")
+	rootCmd.AddCommand(transcriptCmd)
+}
@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jmgilman/headjack/internal/instance"
+	"github.com/jmgilman/headjack/internal/meter"
+)
+
+var usageCmd = &cobra.Command{
+	Use:   "usage",
+	Short: "Show token usage and cost of agent sessions",
+	Long: `Show token usage and cost of agent sessions, grouped by repository,
+branch, and agent.
+
+Usage is read from the session files each agent writes in its container
+(~/.claude/projects for Claude Code, ~/.codex/sessions for Codex, and
+~/.gemini/tmp for Gemini CLI). Running sessions are refreshed on every call.
+When a session ends, its final usage is appended to the usage ledger at
+storage.usage (default: ~/.local/share/headjack/usage.jsonl), so usage
+remains available after instances are removed.
+
+Costs are shown only when an agent reports them, for example Claude Code
+in headless mode. Cached tokens include both cache reads and cache writes.`,
+	Example: `  # Show usage of running sessions and all ended sessions
+  headjack usage
+
+  # Show usage from the last week
+  headjack usage --since 7d
+
+  # Emit one JSON object per group for further processing
+  headjack usage --since 2026-01-01 --json`,
+	Args: cobra.NoArgs,
+	RunE: runUsageCmd,
+}
+
+// usageGroup is the usage of all sessions of one agent on one branch.
+type usageGroup struct {
+	Repo     string `json:"repo"`
+	Branch   string `json:"branch"`
+	Agent    string `json:"agent"`
+	Sessions int    `json:"sessions"`
+	meter.Usage
+}
+
+func runUsageCmd(cmd *cobra.Command, _ []string) error {
+	sinceFlag, err := cmd.Flags().GetString("since")
+	if err != nil {
+		return fmt.Errorf("get since flag: %w", err)
+	}
+	asJSON, err := cmd.Flags().GetBool("json")
+	if err != nil {
+		return fmt.Errorf("get json flag: %w", err)
+	}
+
+	since, err := parseSince(sinceFlag, time.Now())
+	if err != nil {
+		return err
+	}
+
+	mgr, err := requireManager(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	report, err := mgr.Usage(cmd.Context(), &instance.UsageConfig{Since: since})
+	if err != nil {
+		return fmt.Errorf("get usage: %w", err)
+	}
+	groups := groupUsage(report)
+
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		for i := range groups {
+			if err := enc.Encode(&groups[i]); err != nil {
+				return fmt.Errorf("write usage: %w", err)
+			}
+		}
+		return nil
+	}
+
+	if len(groups) == 0 {
+		fmt.Println("No usage found")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	if _, err := fmt.Fprintln(w, "REPO\tBRANCH\tAGENT\tSESSIONS\tINPUT\tOUTPUT\tCACHED\tCOST"); err != nil {
+		return fmt.Errorf("write header: %w", err)
+	}
+	var total meter.Usage
+	sessions := 0
+	for i := range groups {
+		g := &groups[i]
+		total.Add(g.Usage)
+		sessions += g.Sessions
+		if _, err := fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\n",
+			filepath.Base(g.Repo),
+			g.Branch,
+			g.Agent,
+			g.Sessions,
+			formatUsageColumns(g.Usage),
+		); err != nil {
+			return fmt.Errorf("write usage: %w", err)
+		}
+	}
+	if len(groups) > 1 {
+		if _, err := fmt.Fprintf(w, "TOTAL\t\t\t%d\t%s\n", sessions, formatUsageColumns(total)); err != nil {
+			return fmt.Errorf("write total: %w", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("flush output: %w", err)
+	}
+
+	return nil
+}
+
+// groupUsage sums session usage by repository, branch, and agent, sorted in that order.
+func groupUsage(report []instance.SessionUsage) []usageGroup {
+	type key struct{ repo, branch, agent string }
+	index := make(map[key]int)
+	var groups []usageGroup
+	for i := range report {
+		su := &report[i]
+		if su.Usage.IsZero() {
+			continue
+		}
+		k := key{su.Repo, su.Branch, su.Type}
+		idx, ok := index[k]
+		if !ok {
+			idx = len(groups)
+			index[k] = idx
+			groups = append(groups, usageGroup{Repo: su.Repo, Branch: su.Branch, Agent: su.Type})
+		}
+		groups[idx].Sessions++
+		groups[idx].Usage.Add(su.Usage)
+	}
+
+	slices.SortFunc(groups, func(a, b usageGroup) int {
+		return cmp.Or(
+			cmp.Compare(a.Repo, b.Repo),
+			cmp.Compare(a.Branch, b.Branch),
+			cmp.Compare(a.Agent, b.Agent),
+		)
+	})
+	return groups
+}
+
+// formatUsageColumns formats usage as tab-separated input, output, cached, and cost columns.
+func formatUsageColumns(u meter.Usage) string {
+	return fmt.Sprintf("%s\t%s\t%s\t%s",
+		formatTokens(u.InputTokens),
+		formatTokens(u.OutputTokens),
+		formatTokens(u.CacheReadTokens+u.CacheWriteTokens),
+		formatCost(u.CostUSD),
+	)
+}
+
+// formatTokens formats a token count in a human-readable form (e.g., 950, 12.3k, 1.2M).
+func formatTokens(n int64) string {
+	switch {
+	case n >= 1_000_000:
+		return fmt.Sprintf("%.1fM", float64(n)/1_000_000)
+	case n >= 1_000:
+		return fmt.Sprintf("%.1fk", float64(n)/1_000)
+	default:
+		return fmt.Sprintf("%d", n)
+	}
+}
+
+// formatCost formats a reported cost in US dollars, or "-" if none was reported.
+func formatCost(usd float64) string {
+	if usd == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("$%.2f", usd)
+}
+
+func init() {
+	rootCmd.AddCommand(usageCmd)
+
+	usageCmd.Flags().String("since", "", "only include sessions active since a duration ago (e.g., 24h, 7d), date, or RFC 3339 time")
+	usageCmd.Flags().Bool("json", false, "output usage groups as JSON lines")
+}
@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jmgilman/headjack/internal/budget"
+	"github.com/jmgilman/headjack/internal/config"
+	"github.com/jmgilman/headjack/internal/instance"
+)
+
+// defaultWatchdogInterval is how often the watchdog checks session limits and budgets.
+const defaultWatchdogInterval = 30 * time.Second
+
+var watchdogCmd = &cobra.Command{
+	Use:    "watchdog",
+	Short:  "Enforce session runtime limits and budgets",
+	Hidden: true,
+	Long: `Enforce session runtime limits and budgets in the foreground.
+
+The watchdog is started automatically in the background when a session with a
+runtime limit or budget is created; it is not normally run by hand. It kills
+sessions that exceed their limit or budget, warns about budgets that are
+nearly used up, and exits once no session has a limit or budget left to
+enforce. Only one watchdog runs at a time.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		interval, err := cmd.Flags().GetDuration("interval")
+		if err != nil {
+			return fmt.Errorf("get interval flag: %w", err)
+		}
+		if interval <= 0 {
+			return errors.New("--interval must be positive")
+		}
+
+		mgr, err := requireManager(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		dataDir, err := defaultDataDir()
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(dataDir, 0o700); err != nil {
+			return fmt.Errorf("create data directory: %w", err)
+		}
+		//nolint:gosec // G304: path is derived from the data directory
+		lockFile, err := os.OpenFile(filepath.Join(dataDir, "watchdog.lock"), os.O_CREATE|os.O_RDWR, 0o600)
+		if err != nil {
+			return fmt.Errorf("open lock file: %w", err)
+		}
+		defer lockFile.Close()
+		if err := syscall.Flock(int(lockFile.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+			// Another watchdog is already enforcing limits
+			return nil
+		}
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+
+		return mgr.Watchdog(ctx, &instance.WatchdogConfig{
+			Interval:     interval,
+			ExitWhenIdle: true,
+			OnTimeout: func(t *instance.TimedOutSession) {
+				fmt.Printf("%s killed session %s on %s after %s (limit %s)\n",
+					time.Now().Format(time.DateTime), t.Session, t.Branch, t.Runtime.Round(time.Second), t.MaxRuntime)
+			},
+			OnBudget: func(ev *instance.BudgetEvent) {
+				if ev.Killed {
+					fmt.Printf("%s killed session %s on %s: %s budget of %s used up\n",
+						time.Now().Format(time.DateTime), ev.Session, ev.Branch, ev.Scope, ev.Limit)
+					return
+				}
+				fmt.Printf("%s session %s on %s has used %.0f%% of its %s budget of %s\n",
+					time.Now().Format(time.DateTime), ev.Session, ev.Branch, ev.Limit.Used(ev.Usage)*100, ev.Scope, ev.Limit)
+			},
+		})
+	},
+}
+
+// startWatchdog launches the watchdog as a detached background process.
+// Its output is appended to watchdog.log in the data directory.
+func startWatchdog(context.Context) error {
+	self, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("locate headjack executable: %w", err)
+	}
+
+	dataDir, err := defaultDataDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dataDir, 0o700); err != nil {
+		return fmt.Errorf("create data directory: %w", err)
+	}
+	//nolint:gosec // G304: path is derived from the data directory
+	logFile, err := os.OpenFile(filepath.Join(dataDir, "watchdog.log"), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("open watchdog log: %w", err)
+	}
+	defer logFile.Close()
+
+	// Not bound to the command context: the watchdog must outlive this process
+	//nolint:gosec,noctx // G204: re-executes the running headjack binary
+	watchdog := exec.Command(self, "watchdog")
+	watchdog.Stdout = logFile
+	watchdog.Stderr = logFile
+	// A new session detaches the watchdog from this terminal's signals
+	watchdog.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+
+	if err := watchdog.Start(); err != nil {
+		return fmt.Errorf("start watchdog: %w", err)
+	}
+	return watchdog.Process.Release()
+}
+
+// sessionMaxRuntime returns the configured runtime limit for an agent's
+// sessions: agents.<agent>.max_runtime, falling back to sessions.max_runtime.
+func sessionMaxRuntime(cfg *config.Config, agent string) (time.Duration, error) {
+	if cfg == nil {
+		return 0, nil
+	}
+	value := cfg.Agents[agent].MaxRuntime
+	if value == "" {
+		value = cfg.Sessions.MaxRuntime
+	}
+	d, err := parseAge(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid max runtime %q (use a duration like 2h or 1d)", value)
+	}
+	return d, nil
+}
+
+// agentBudgets returns the configured budget of each agent that has one.
+func agentBudgets(cfg *config.Config) (map[string]budget.Policy, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	budgets := make(map[string]budget.Policy)
+	for agent, agentCfg := range cfg.Agents {
+		var policy budget.Policy
+		limits := []struct {
+			key   string
+			value string
+			limit *budget.Limit
+		}{
+			{"session", agentCfg.Budget.Session, &policy.Session},
+			{"instance", agentCfg.Budget.Instance, &policy.Instance},
+			{"daily", agentCfg.Budget.Daily, &policy.Daily},
+		}
+		for _, l := range limits {
+			limit, err := budget.ParseLimit(l.value)
+			if err != nil {
+				return nil, fmt.Errorf("agents.%s.budget.%s: %w", agent, l.key, err)
+			}
+			*l.limit = limit
+		}
+		if policy.IsZero() {
+			continue
+		}
+		policy.WarnAt = agentCfg.Budget.WarnAt
+		budgets[agent] = policy
+	}
+	return budgets, nil
+}
+
+func init() {
+	watchdogCmd.Flags().Duration("interval", defaultWatchdogInterval, "how often to check session limits and budgets")
+	rootCmd.AddCommand(watchdogCmd)
+}
@@ -7,11 +7,15 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"slices"
 	"strings"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/go-viper/mapstructure/v2"
 	"github.com/spf13/viper"
+
+	"github.com/jmgilman/headjack/internal/agent"
+	"github.com/jmgilman/headjack/internal/auth"
 )
 
 // Default configuration values.
@@ -19,6 +23,7 @@ const (
 	DefaultConfigDir  = ".config/headjack"
 	DefaultConfigFile = "config.yaml"
 	DefaultDataDir    = ".local/share/headjack"
+	RepoConfigFile    = ".headjack.yaml"
 )
 
 // DefaultBaseImage is the default container image.
@@ -27,19 +32,13 @@ const DefaultBaseImage = "ghcr.io/gilmanlab/headjack:base"
 
 // Sentinel errors for configuration operations.
 var (
-	ErrInvalidKey     = errors.New("invalid configuration key")
-	ErrInvalidAgent   = errors.New("invalid agent name")
-	ErrInvalidRuntime = errors.New("invalid runtime name")
-	ErrNoEditor       = errors.New("$EDITOR environment variable not set")
+	ErrInvalidKey         = errors.New("invalid configuration key")
+	ErrInvalidAgent       = errors.New("invalid agent name")
+	ErrInvalidRuntime     = errors.New("invalid runtime name")
+	ErrInvalidMultiplexer = errors.New("invalid multiplexer name")
+	ErrNoEditor           = errors.New("$EDITOR environment variable not set")
 )
 
-// validAgents contains the allowed agent names (unexported).
-var validAgents = map[string]bool{
-	"claude": true,
-	"gemini": true,
-	"codex":  true,
-}
-
 // validRuntimes contains the allowed runtime names (unexported).
 var validRuntimes = map[string]bool{
 	"podman": true,
@@ -47,6 +46,13 @@ var validRuntimes = map[string]bool{
 	"docker": true,
 }
 
+// validMultiplexers contains the allowed multiplexer names (unexported).
+var validMultiplexers = map[string]bool{
+	"tmux":    true,
+	"zellij":  true,
+	"builtin": true,
+}
+
 // validKeys is built once from Config struct reflection.
 var validKeys = buildValidKeys()
 
@@ -55,21 +61,70 @@ var validate = validator.New()
 
 // Config represents the full Headjack configuration.
 type Config struct {
-	Default DefaultConfig          `mapstructure:"default" validate:"required"`
-	Agents  map[string]AgentConfig `mapstructure:"agents" validate:"dive,keys,oneof=claude gemini codex,endkeys"`
-	Storage StorageConfig          `mapstructure:"storage" validate:"required"`
-	Runtime RuntimeConfig          `mapstructure:"runtime"`
+	Default     DefaultConfig          `mapstructure:"default" validate:"required"`
+	Agents      map[string]AgentConfig `mapstructure:"agents" validate:"dive"`
+	Auth        AuthConfig             `mapstructure:"auth"`
+	Storage     StorageConfig          `mapstructure:"storage" validate:"required"`
+	Runtime     RuntimeConfig          `mapstructure:"runtime"`
+	Multiplexer MultiplexerConfig      `mapstructure:"multiplexer"`
+	Logging     LoggingConfig          `mapstructure:"logging"`
+	Sessions    SessionsConfig         `mapstructure:"sessions"`
+	Registry    RegistryConfig         `mapstructure:"registry"`
 }
 
 // DefaultConfig holds default values for new instances.
 type DefaultConfig struct {
-	Agent     string `mapstructure:"agent" validate:"omitempty,oneof=claude gemini codex"`
+	Agent     string `mapstructure:"agent"`
 	BaseImage string `mapstructure:"base_image" validate:"required"`
 }
 
-// AgentConfig holds agent-specific configuration.
+// AgentConfig holds agent-specific configuration. Agents other than the
+// built-in claude, gemini, and codex are defined by setting Command; for a
+// built-in, the definition fields override its defaults.
 type AgentConfig struct {
-	Env map[string]string `mapstructure:"env"`
+	Command          []string          `mapstructure:"command"`                                         // Command and arguments that start the agent
+	Prompt           string            `mapstructure:"prompt" validate:"omitempty,oneof=arg flag none"` // How the prompt is passed
+	PromptFlag       string            `mapstructure:"prompt_flag"`                                     // Flag preceding the prompt when prompt is "flag"
+	Auth             string            `mapstructure:"auth"`                                            // Auth provider whose credential is injected
+	AuthSetup        string            `mapstructure:"auth_setup"`                                      // Built-in agent whose credential file setup is run, "none", or a shell script
+	Setup            string            `mapstructure:"setup"`                                           // Shell script run in the container before each session
+	Env              map[string]string `mapstructure:"env"`
+	Transcript       bool              `mapstructure:"transcript"`                                                       // Capture structured transcripts for detached sessions
+	HeadlessArgs     []string          `mapstructure:"headless_args"`                                                    // Arguments after command that run the agent headless
+	TranscriptFormat string            `mapstructure:"transcript_format" validate:"omitempty,oneof=claude codex gemini"` // Format of the headless agent's JSON events
+	UsageFiles       string            `mapstructure:"usage_files"`                                                      // Shell glob of the agent's session files, relative to $HOME
+	UsageFormat      string            `mapstructure:"usage_format" validate:"omitempty,oneof=claude codex gemini"`      // Format of the session files
+	MaxRuntime       string            `mapstructure:"max_runtime"`                                                      // Kill sessions after this duration (overrides sessions.max_runtime)
+	Budget           BudgetConfig      `mapstructure:"budget"`
+}
+
+// BudgetConfig holds an agent's usage limits. Each limit is a cost ("$5")
+// or a token count ("2M"); empty means no limit.
+type BudgetConfig struct {
+	Session  string  `mapstructure:"session"`                                // Limit per session
+	Instance string  `mapstructure:"instance"`                               // Limit per instance across the agent's sessions
+	Daily    string  `mapstructure:"daily"`                                  // Limit per calendar day across all instances
+	WarnAt   float64 `mapstructure:"warn_at" validate:"omitempty,gt=0,lt=1"` // Fraction of a limit at which to warn (default 0.8)
+}
+
+// AuthConfig holds agent credential settings.
+type AuthConfig struct {
+	Profile   string            `mapstructure:"profile"` // Credential profile used when hjk run --auth-profile is not given
+	Storage   AuthStorageConfig `mapstructure:"storage"`
+	Endpoints map[string]string `mapstructure:"endpoints"` // Base URL of each provider's API used by hjk auth status --check
+}
+
+// AuthStorageConfig selects where credentials and secrets are stored. Only
+// the fields of the selected backend are used.
+type AuthStorageConfig struct {
+	Backend string `mapstructure:"backend" validate:"omitempty,oneof=keychain 1password pass command dotenv"`
+	Vault   string `mapstructure:"vault"`  // 1Password vault holding the items
+	Field   string `mapstructure:"field"`  // 1Password item field read (default "password")
+	Prefix  string `mapstructure:"prefix"` // pass directory credentials are kept under (default "headjack")
+	Path    string `mapstructure:"path"`   // dotenv file holding the credentials
+	Get     string `mapstructure:"get"`    // Shell command printing the credential of $HEADJACK_ACCOUNT
+	Set     string `mapstructure:"set"`    // Shell command storing standard input as $HEADJACK_ACCOUNT
+	Delete  string `mapstructure:"delete"` // Shell command removing $HEADJACK_ACCOUNT
 }
 
 // StorageConfig holds storage location configuration.
@@ -77,6 +132,9 @@ type StorageConfig struct {
 	Worktrees string `mapstructure:"worktrees" validate:"required"`
 	Catalog   string `mapstructure:"catalog" validate:"required"`
 	Logs      string `mapstructure:"logs" validate:"required"`
+	Audit     string `mapstructure:"audit"`
+	Usage     string `mapstructure:"usage"`
+	Images    string `mapstructure:"images"` // Image metadata cache directory
 }
 
 // RuntimeConfig holds container runtime configuration.
@@ -85,14 +143,156 @@ type RuntimeConfig struct {
 	Flags map[string]any `mapstructure:"flags"`
 }
 
-// Validate checks the configuration for errors using struct tags.
+// MultiplexerConfig holds terminal multiplexer configuration.
+type MultiplexerConfig struct {
+	Name   string `mapstructure:"name" validate:"omitempty,oneof=tmux zellij builtin"`
+	Socket string `mapstructure:"socket"`
+}
+
+// LoggingConfig holds session log rotation and retention configuration.
+// Sizes are byte counts such as "100MB"; ages are durations such as "720h"
+// or "30d". Empty sizes and ages disable the corresponding limit.
+type LoggingConfig struct {
+	MaxSize      string `mapstructure:"max_size"`                   // Rotate a session log once it reaches this size
+	MaxFiles     int    `mapstructure:"max_files" validate:"gte=0"` // Rotated segments kept per session
+	Compress     bool   `mapstructure:"compress"`                   // Gzip rotated segments
+	MaxAge       string `mapstructure:"max_age"`                    // Prune log files not written to within this age
+	MaxTotalSize string `mapstructure:"max_total_size"`             // Prune the oldest log files beyond this total size
+}
+
+// SessionsConfig holds session limits and notifications. Durations are
+// values such as "90m", "2h", or "1d"; an empty duration means no limit.
+type SessionsConfig struct {
+	MaxRuntime    string `mapstructure:"max_runtime"`    // Kill sessions after this duration
+	NotifyCommand string `mapstructure:"notify_command"` // Shell command run when headjack kills a session
+}
+
+// RegistryConfig holds access settings for the registries image metadata is
+// fetched from. Docker's config.json and credential helpers are used for
+// registries without credentials here.
+type RegistryConfig struct {
+	CAFile   string               `mapstructure:"ca_file"`  // PEM bundle of CAs trusted for every registry
+	Insecure bool                 `mapstructure:"insecure"` // Allow HTTP and unverified TLS for every registry
+	Hosts    []RegistryHostConfig `mapstructure:"hosts" validate:"dive"`
+	Cache    RegistryCacheConfig  `mapstructure:"cache"`
+}
+
+// RegistryCacheConfig holds the lifetimes of cached image metadata. Durations
+// are values such as "1h" or "30d".
+type RegistryCacheConfig struct {
+	TTL    string `mapstructure:"ttl"`     // Reuse the digest a tag resolved to for this long without asking the registry
+	MaxAge string `mapstructure:"max_age"` // Keep metadata for offline use for this long after it was fetched
+}
+
+// RegistryHostConfig holds access settings for one registry. At most one of
+// the static credentials, credential_helper, and secret may be set.
+type RegistryHostConfig struct {
+	Host             string   `mapstructure:"host" validate:"required"`                                   // Registry host, e.g. ghcr.io or registry.example.com:5000
+	Mirrors          []string `mapstructure:"mirrors"`                                                    // Hosts with optional path prefix tried before the registry
+	Username         string   `mapstructure:"username" validate:"required_with=Password"`                 // Static credentials
+	Password         string   `mapstructure:"password"`                                                   // Static credentials
+	CredentialHelper string   `mapstructure:"credential_helper" validate:"excluded_with=Username Secret"` // Docker credential helper, run as docker-credential-<name>
+	Secret           string   `mapstructure:"secret" validate:"excluded_with=Username CredentialHelper"`  // Global secret holding username:password or a token
+	CAFile           string   `mapstructure:"ca_file"`                                                    // PEM bundle of CAs trusted for this registry
+	Insecure         bool     `mapstructure:"insecure"`                                                   // Allow HTTP and unverified TLS for this registry
+}
+
+// Validate checks the configuration for errors using struct tags, then checks
+// that every configured agent is a built-in or defines a command.
 func (c *Config) Validate() error {
 	if err := validate.Struct(c); err != nil {
 		return fmt.Errorf("config validation failed: %w", err)
 	}
+
+	for name, agentCfg := range c.Agents {
+		if !agent.ValidName(name) {
+			return fmt.Errorf("config validation failed: %w: %s", ErrInvalidAgent, name)
+		}
+		if !agent.IsBuiltin(name) && len(agentCfg.Command) == 0 {
+			return fmt.Errorf("config validation failed: %w: %s is not a built-in agent and sets no command", ErrInvalidAgent, name)
+		}
+	}
+	if c.Default.Agent != "" && !c.IsAgent(c.Default.Agent) {
+		return fmt.Errorf("config validation failed: default.Agent: %w: %s (valid: %s)",
+			ErrInvalidAgent, c.Default.Agent, strings.Join(c.AgentNames(), ", "))
+	}
+	if c.Auth.Profile != "" {
+		if err := auth.ValidateProfile(c.Auth.Profile); err != nil {
+			return fmt.Errorf("config validation failed: auth.profile: %w", err)
+		}
+	}
 	return nil
 }
 
+// RepoConfig holds settings a repository overrides for its own instances,
+// read from RepoConfigFile at the repository root.
+type RepoConfig struct {
+	Auth AuthConfig `mapstructure:"auth"`
+}
+
+// LoadRepoConfig reads the repository configuration of the repository at
+// repoPath. A repository without a configuration file yields an empty
+// RepoConfig.
+func LoadRepoConfig(repoPath string) (*RepoConfig, error) {
+	path := filepath.Join(repoPath, RepoConfigFile)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return &RepoConfig{}, nil
+	}
+
+	v := viper.New()
+	v.SetConfigFile(path)
+	v.SetConfigType("yaml")
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("read repository config: %w", err)
+	}
+
+	var cfg RepoConfig
+	if err := v.Unmarshal(&cfg); err != nil {
+		return nil, fmt.Errorf("unmarshal repository config: %w", err)
+	}
+	if cfg.Auth.Profile != "" {
+		if err := auth.ValidateProfile(cfg.Auth.Profile); err != nil {
+			return nil, fmt.Errorf("%s: auth.profile: %w", path, err)
+		}
+	}
+	return &cfg, nil
+}
+
+// AuthProfile returns the credential profile for a session: the explicitly
+// requested profile, else the repository's, else the global default, else
+// auth.DefaultProfile.
+func AuthProfile(requested string, repo *RepoConfig, cfg *Config) string {
+	switch {
+	case requested != "":
+		return requested
+	case repo != nil && repo.Auth.Profile != "":
+		return repo.Auth.Profile
+	case cfg != nil && cfg.Auth.Profile != "":
+		return cfg.Auth.Profile
+	default:
+		return auth.DefaultProfile
+	}
+}
+
+// IsAgent reports whether name is a built-in agent or a custom agent defined
+// in the configuration.
+func (c *Config) IsAgent(name string) bool {
+	return agent.IsBuiltin(name) || len(c.Agents[name].Command) > 0
+}
+
+// AgentNames returns the built-in agents followed by the custom agents, sorted.
+func (c *Config) AgentNames() []string {
+	names := agent.BuiltinNames()
+	var custom []string
+	for name, agentCfg := range c.Agents {
+		if !agent.IsBuiltin(name) && len(agentCfg.Command) > 0 {
+			custom = append(custom, name)
+		}
+	}
+	slices.Sort(custom)
+	return append(names, custom...)
+}
+
 // Loader provides configuration loading and saving.
 type Loader struct {
 	v       *viper.Viper
@@ -146,11 +346,30 @@ func (l *Loader) setDefaults() {
 	l.v.SetDefault("storage.worktrees", "~/.local/share/headjack/git")
 	l.v.SetDefault("storage.catalog", "~/.local/share/headjack/catalog.json")
 	l.v.SetDefault("storage.logs", "~/.local/share/headjack/logs")
+	l.v.SetDefault("storage.audit", "~/.local/share/headjack/audit.jsonl")
+	l.v.SetDefault("storage.usage", "~/.local/share/headjack/usage.jsonl")
+	l.v.SetDefault("storage.images", "~/.local/share/headjack/images")
 	l.v.SetDefault("agents.claude.env", map[string]string{"CLAUDE_CODE_MAX_TURNS": "100"})
 	l.v.SetDefault("agents.gemini.env", map[string]string{})
 	l.v.SetDefault("agents.codex.env", map[string]string{})
+	l.v.SetDefault("agents.claude.transcript", false)
+	l.v.SetDefault("agents.gemini.transcript", false)
+	l.v.SetDefault("agents.codex.transcript", false)
+	l.v.SetDefault("auth.profile", "")
+	l.v.SetDefault("auth.storage.backend", "keychain")
 	l.v.SetDefault("runtime.name", "docker")
 	l.v.SetDefault("runtime.flags", map[string]any{})
+	l.v.SetDefault("multiplexer.name", "tmux")
+	l.v.SetDefault("multiplexer.socket", "~/.local/share/headjack/mux.sock")
+	l.v.SetDefault("logging.max_size", "")
+	l.v.SetDefault("logging.max_files", 5)
+	l.v.SetDefault("logging.compress", true)
+	l.v.SetDefault("logging.max_age", "")
+	l.v.SetDefault("logging.max_total_size", "")
+	l.v.SetDefault("sessions.max_runtime", "")
+	l.v.SetDefault("sessions.notify_command", "")
+	l.v.SetDefault("registry.cache.ttl", "1h")
+	l.v.SetDefault("registry.cache.max_age", "30d")
 }
 
 // Load reads the configuration file, creating defaults if it doesn't exist.
@@ -176,6 +395,15 @@ func (l *Loader) Load() (*Config, error) {
 	cfg.Storage.Worktrees = l.expandPath(cfg.Storage.Worktrees)
 	cfg.Storage.Catalog = l.expandPath(cfg.Storage.Catalog)
 	cfg.Storage.Logs = l.expandPath(cfg.Storage.Logs)
+	cfg.Storage.Audit = l.expandPath(cfg.Storage.Audit)
+	cfg.Storage.Usage = l.expandPath(cfg.Storage.Usage)
+	cfg.Storage.Images = l.expandPath(cfg.Storage.Images)
+	cfg.Multiplexer.Socket = l.expandPath(cfg.Multiplexer.Socket)
+	cfg.Auth.Storage.Path = l.expandPath(cfg.Auth.Storage.Path)
+	cfg.Registry.CAFile = l.expandPath(cfg.Registry.CAFile)
+	for i := range cfg.Registry.Hosts {
+		cfg.Registry.Hosts[i].CAFile = l.expandPath(cfg.Registry.Hosts[i].CAFile)
+	}
 
 	return &cfg, nil
 }
@@ -212,8 +440,15 @@ func (l *Loader) Set(key, value string) error {
 
 	// Validate agent name if setting default.agent
 	if key == "default.agent" && value != "" {
-		if !validAgents[value] {
-			return fmt.Errorf("%w: %s (valid: claude, gemini, codex)", ErrInvalidAgent, value)
+		if !agent.IsBuiltin(value) && len(l.v.GetStringSlice("agents."+value+".command")) == 0 {
+			return fmt.Errorf("%w: %s (use a built-in agent or define agents.%s.command first)", ErrInvalidAgent, value, value)
+		}
+	}
+
+	// Validate profile name if setting auth.profile
+	if key == "auth.profile" && value != "" {
+		if err := auth.ValidateProfile(value); err != nil {
+			return err
 		}
 	}
 
@@ -224,6 +459,13 @@ func (l *Loader) Set(key, value string) error {
 		}
 	}
 
+	// Validate multiplexer name if setting multiplexer.name
+	if key == "multiplexer.name" && value != "" {
+		if !validMultiplexers[value] {
+			return fmt.Errorf("%w: %s (valid: tmux, zellij, builtin)", ErrInvalidMultiplexer, value)
+		}
+	}
+
 	l.v.Set(key, value)
 	return l.v.WriteConfig()
 }
@@ -260,16 +502,17 @@ func ValidateKey(key string) error {
 		return nil
 	}
 
-	// Check for agents.<name> pattern (map type needs special handling)
+	// Check for agents.<name> pattern (map type needs special handling).
+	// Any well-formed name is accepted so custom agents can be defined.
 	if strings.HasPrefix(key, "agents.") {
 		parts := strings.SplitN(key, ".", 3)
 		if len(parts) >= 2 {
 			agentName := parts[1]
-			if validAgents[agentName] {
-				// Valid patterns: agents.claude, agents.claude.env
+			if agent.ValidName(agentName) {
+				// Valid patterns: agents.claude, agents.claude.env, agents.aider.command
 				return nil
 			}
-			return fmt.Errorf("%w: %s (valid: claude, gemini, codex)", ErrInvalidAgent, agentName)
+			return fmt.Errorf("%w: %s (use lowercase letters, digits, '-' and '_')", ErrInvalidAgent, agentName)
 		}
 	}
 
@@ -305,14 +548,16 @@ func addKeysFromType(t reflect.Type, prefix string, keys map[string]bool) {
 	}
 }
 
-// IsValidAgent is a package-level helper for checking agent validity.
+// IsValidAgent reports whether name is a built-in agent. Use Config.IsAgent
+// to include custom agents.
 func IsValidAgent(name string) bool {
-	return validAgents[name]
+	return agent.IsBuiltin(name)
 }
 
-// ValidAgentNames returns the list of valid agent names.
+// ValidAgentNames returns the names of the built-in agents. Use
+// Config.AgentNames to include custom agents.
 func ValidAgentNames() []string {
-	return []string{"claude", "gemini", "codex"}
+	return agent.BuiltinNames()
 }
 
 // IsValidRuntime is a package-level helper for checking runtime validity.
@@ -324,3 +569,13 @@ func IsValidRuntime(name string) bool {
 func ValidRuntimeNames() []string {
 	return []string{"podman", "apple", "docker"}
 }
+
+// IsValidMultiplexer is a package-level helper for checking multiplexer validity.
+func IsValidMultiplexer(name string) bool {
+	return validMultiplexers[name]
+}
+
+// ValidMultiplexerNames returns the list of valid multiplexer names.
+func ValidMultiplexerNames() []string {
+	return []string{"tmux", "zellij", "builtin"}
+}
@@ -7,6 +7,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/jmgilman/headjack/internal/auth"
 )
 
 func TestLoader_Load_CreatesDefaultIfMissing(t *testing.T) {
@@ -25,6 +27,14 @@ func TestLoader_Load_CreatesDefaultIfMissing(t *testing.T) {
 	assert.Contains(t, cfg.Storage.Worktrees, "headjack")
 	assert.Contains(t, cfg.Storage.Catalog, "catalog.json")
 	assert.Contains(t, cfg.Storage.Logs, "logs")
+	assert.Contains(t, cfg.Storage.Audit, "audit.jsonl")
+	assert.Contains(t, cfg.Storage.Usage, "usage.jsonl")
+	assert.Equal(t, filepath.Join(tmpHome, ".local/share/headjack/images"), cfg.Storage.Images)
+	assert.Equal(t, RegistryCacheConfig{TTL: "1h", MaxAge: "30d"}, cfg.Registry.Cache)
+	assert.Equal(t, "tmux", cfg.Multiplexer.Name)
+	assert.Equal(t, filepath.Join(tmpHome, ".local/share/headjack/mux.sock"), cfg.Multiplexer.Socket)
+	assert.Equal(t, LoggingConfig{MaxFiles: 5, Compress: true}, cfg.Logging)
+	assert.Equal(t, SessionsConfig{}, cfg.Sessions)
 
 	// Verify file was created
 	_, err = os.Stat(loader.Path())
@@ -76,6 +86,36 @@ agents:
 	assert.Equal(t, "bar", env["foo"])
 }
 
+func TestLoader_Load_RegistryHosts(t *testing.T) {
+	tmpHome := t.TempDir()
+	t.Setenv("HOME", tmpHome)
+
+	configDir := filepath.Join(tmpHome, ".config", "headjack")
+	require.NoError(t, os.MkdirAll(configDir, 0o750))
+	configContent := `
+registry:
+  ca_file: ~/certs/corp.pem
+  hosts:
+    - host: ghcr.io
+      mirrors: [mirror.example.com/ghcr]
+    - host: registry.example.com:5000
+      credential_helper: corp
+`
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "config.yaml"), []byte(configContent), 0o600))
+
+	loader, err := NewLoader()
+	require.NoError(t, err)
+	cfg, err := loader.Load()
+	require.NoError(t, err)
+
+	assert.Equal(t, filepath.Join(tmpHome, "certs", "corp.pem"), cfg.Registry.CAFile)
+	require.Len(t, cfg.Registry.Hosts, 2)
+	assert.Equal(t, "ghcr.io", cfg.Registry.Hosts[0].Host)
+	assert.Equal(t, []string{"mirror.example.com/ghcr"}, cfg.Registry.Hosts[0].Mirrors)
+	assert.Equal(t, "corp", cfg.Registry.Hosts[1].CredentialHelper)
+	assert.NoError(t, cfg.Validate())
+}
+
 func TestLoader_Load_EnvVarOverride(t *testing.T) {
 	tmpHome := t.TempDir()
 	t.Setenv("HOME", tmpHome)
@@ -155,10 +195,82 @@ func TestLoader_Set(t *testing.T) {
 		assert.ErrorIs(t, err, ErrInvalidAgent)
 	})
 
+	t.Run("allows custom agent with a command", func(t *testing.T) {
+		require.NoError(t, loader.Set("agents.aider.command", "aider"))
+
+		err := loader.Set("default.agent", "aider")
+		assert.NoError(t, err)
+	})
+
 	t.Run("allows empty agent", func(t *testing.T) {
 		err := loader.Set("default.agent", "")
 		assert.NoError(t, err)
 	})
+
+	t.Run("sets valid multiplexer", func(t *testing.T) {
+		err := loader.Set("multiplexer.name", "zellij")
+		require.NoError(t, err)
+
+		val, err := loader.Get("multiplexer.name")
+		require.NoError(t, err)
+		assert.Equal(t, "zellij", val)
+	})
+
+	t.Run("rejects invalid multiplexer", func(t *testing.T) {
+		err := loader.Set("multiplexer.name", "screen")
+		assert.ErrorIs(t, err, ErrInvalidMultiplexer)
+	})
+
+	t.Run("sets auth profile", func(t *testing.T) {
+		require.NoError(t, loader.Set("auth.profile", "work"))
+
+		val, err := loader.Get("auth.profile")
+		require.NoError(t, err)
+		assert.Equal(t, "work", val)
+	})
+
+	t.Run("rejects invalid auth profile", func(t *testing.T) {
+		err := loader.Set("auth.profile", "Work Account")
+		assert.ErrorIs(t, err, auth.ErrInvalidProfile)
+	})
+}
+
+func TestLoadRepoConfig(t *testing.T) {
+	t.Run("missing file yields empty config", func(t *testing.T) {
+		cfg, err := LoadRepoConfig(t.TempDir())
+
+		require.NoError(t, err)
+		assert.Equal(t, &RepoConfig{}, cfg)
+	})
+
+	t.Run("reads auth profile", func(t *testing.T) {
+		repo := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(repo, RepoConfigFile), []byte("auth:\n  profile: work\n"), 0o600))
+
+		cfg, err := LoadRepoConfig(repo)
+
+		require.NoError(t, err)
+		assert.Equal(t, "work", cfg.Auth.Profile)
+	})
+
+	t.Run("rejects invalid auth profile", func(t *testing.T) {
+		repo := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(repo, RepoConfigFile), []byte("auth:\n  profile: Work Account\n"), 0o600))
+
+		_, err := LoadRepoConfig(repo)
+
+		assert.ErrorIs(t, err, auth.ErrInvalidProfile)
+	})
+}
+
+func TestAuthProfile(t *testing.T) {
+	global := &Config{Auth: AuthConfig{Profile: "personal"}}
+	repo := &RepoConfig{Auth: AuthConfig{Profile: "work"}}
+
+	assert.Equal(t, "ci", AuthProfile("ci", repo, global), "requested profile wins")
+	assert.Equal(t, "work", AuthProfile("", repo, global), "repository overrides global")
+	assert.Equal(t, "personal", AuthProfile("", &RepoConfig{}, global))
+	assert.Equal(t, auth.DefaultProfile, AuthProfile("", nil, nil))
 }
 
 func TestConfig_Validate(t *testing.T) {
@@ -199,6 +311,101 @@ func TestConfig_Validate(t *testing.T) {
 		require.Error(t, err)
 	})
 
+	t.Run("invalid auth profile", func(t *testing.T) {
+		cfg := &Config{
+			Default: DefaultConfig{BaseImage: "test:latest"},
+			Auth:    AuthConfig{Profile: "Work Account"},
+			Storage: StorageConfig{Worktrees: "/tmp/worktrees", Catalog: "/tmp/catalog.json", Logs: "/tmp/logs"},
+		}
+		assert.ErrorIs(t, cfg.Validate(), auth.ErrInvalidProfile)
+	})
+
+	t.Run("invalid auth storage backend", func(t *testing.T) {
+		cfg := &Config{
+			Default: DefaultConfig{BaseImage: "test:latest"},
+			Auth:    AuthConfig{Storage: AuthStorageConfig{Backend: "vault"}},
+			Storage: StorageConfig{Worktrees: "/tmp/worktrees", Catalog: "/tmp/catalog.json", Logs: "/tmp/logs"},
+		}
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Backend")
+	})
+
+	t.Run("registry host with two credential sources", func(t *testing.T) {
+		cfg := &Config{
+			Default:  DefaultConfig{BaseImage: "test:latest"},
+			Storage:  StorageConfig{Worktrees: "/tmp/worktrees", Catalog: "/tmp/catalog.json", Logs: "/tmp/logs"},
+			Registry: RegistryConfig{Hosts: []RegistryHostConfig{{Host: "ghcr.io", Secret: "ghcr", CredentialHelper: "corp"}}},
+		}
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "CredentialHelper")
+	})
+
+	t.Run("invalid budget warning threshold", func(t *testing.T) {
+		cfg := &Config{
+			Default: DefaultConfig{BaseImage: "test:latest"},
+			Agents:  map[string]AgentConfig{"claude": {Budget: BudgetConfig{Daily: "$50", WarnAt: 1.5}}},
+			Storage: StorageConfig{Worktrees: "/tmp/worktrees", Catalog: "/tmp/catalog.json", Logs: "/tmp/logs"},
+		}
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "WarnAt")
+	})
+
+	t.Run("valid custom agent", func(t *testing.T) {
+		cfg := &Config{
+			Default: DefaultConfig{Agent: "aider", BaseImage: "test:latest"},
+			Agents:  map[string]AgentConfig{"aider": {Command: []string{"aider"}, Prompt: "flag", PromptFlag: "--message"}},
+			Storage: StorageConfig{Worktrees: "/tmp/worktrees", Catalog: "/tmp/catalog.json", Logs: "/tmp/logs"},
+		}
+		require.NoError(t, cfg.Validate())
+		assert.True(t, cfg.IsAgent("aider"))
+		assert.Equal(t, []string{"claude", "gemini", "codex", "aider"}, cfg.AgentNames())
+	})
+
+	t.Run("auth setup script", func(t *testing.T) {
+		cfg := &Config{
+			Default: DefaultConfig{BaseImage: "test:latest"},
+			Agents:  map[string]AgentConfig{"aider": {Command: []string{"aider"}, Auth: "codex", AuthSetup: `echo "$CODEX_AUTH_JSON" > ~/.aider.json`}},
+			Storage: StorageConfig{Worktrees: "/tmp/worktrees", Catalog: "/tmp/catalog.json", Logs: "/tmp/logs"},
+		}
+		require.NoError(t, cfg.Validate())
+	})
+
+	t.Run("invalid transcript format", func(t *testing.T) {
+		cfg := &Config{
+			Default: DefaultConfig{BaseImage: "test:latest"},
+			Agents:  map[string]AgentConfig{"aider": {Command: []string{"aider"}, TranscriptFormat: "aider"}},
+			Storage: StorageConfig{Worktrees: "/tmp/worktrees", Catalog: "/tmp/catalog.json", Logs: "/tmp/logs"},
+		}
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "TranscriptFormat")
+	})
+
+	t.Run("invalid prompt style", func(t *testing.T) {
+		cfg := &Config{
+			Default: DefaultConfig{BaseImage: "test:latest"},
+			Agents:  map[string]AgentConfig{"aider": {Command: []string{"aider"}, Prompt: "stdin"}},
+			Storage: StorageConfig{Worktrees: "/tmp/worktrees", Catalog: "/tmp/catalog.json", Logs: "/tmp/logs"},
+		}
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Prompt")
+	})
+
+	t.Run("invalid multiplexer", func(t *testing.T) {
+		cfg := &Config{
+			Default:     DefaultConfig{BaseImage: "test:latest"},
+			Storage:     StorageConfig{Worktrees: "/tmp/worktrees", Catalog: "/tmp/catalog.json", Logs: "/tmp/logs"},
+			Multiplexer: MultiplexerConfig{Name: "screen"},
+		}
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Multiplexer")
+	})
+
 	t.Run("missing required base_image", func(t *testing.T) {
 		cfg := &Config{
 			Default: DefaultConfig{Agent: ""},
@@ -227,6 +434,14 @@ func TestValidAgentNames(t *testing.T) {
 	assert.Len(t, names, 3)
 }
 
+func TestIsValidMultiplexer(t *testing.T) {
+	assert.True(t, IsValidMultiplexer("tmux"))
+	assert.True(t, IsValidMultiplexer("zellij"))
+	assert.True(t, IsValidMultiplexer("builtin"))
+	assert.False(t, IsValidMultiplexer("screen"))
+	assert.False(t, IsValidMultiplexer(""))
+}
+
 func TestValidateKey(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -238,14 +453,24 @@ func TestValidateKey(t *testing.T) {
 		{"storage.worktrees is valid", "storage.worktrees", nil},
 		{"storage.catalog is valid", "storage.catalog", nil},
 		{"storage.logs is valid", "storage.logs", nil},
+		{"storage.audit is valid", "storage.audit", nil},
+		{"storage.usage is valid", "storage.usage", nil},
+		{"multiplexer.name is valid", "multiplexer.name", nil},
+		{"multiplexer.socket is valid", "multiplexer.socket", nil},
+		{"logging.max_size is valid", "logging.max_size", nil},
+		{"logging.max_total_size is valid", "logging.max_total_size", nil},
+		{"sessions.max_runtime is valid", "sessions.max_runtime", nil},
+		{"sessions.notify_command is valid", "sessions.notify_command", nil},
 		{"agents is valid", "agents", nil},
 		{"default is valid", "default", nil},
 		{"storage is valid", "storage", nil},
 		{"agents.claude is valid", "agents.claude", nil},
 		{"agents.claude.env is valid", "agents.claude.env", nil},
+		{"agents.claude.budget.daily is valid", "agents.claude.budget.daily", nil},
 		{"agents.gemini is valid", "agents.gemini", nil},
 		{"agents.codex is valid", "agents.codex", nil},
-		{"agents.invalid returns error", "agents.invalid", ErrInvalidAgent},
+		{"agents.aider.command is valid for custom agents", "agents.aider.command", nil},
+		{"malformed agent name returns error", "agents.Bad Agent", ErrInvalidAgent},
 		{"unknown.key returns error", "unknown.key", ErrInvalidKey},
 		{"empty key returns error", "", ErrInvalidKey},
 		{"random key returns error", "foo", ErrInvalidKey},
@@ -3,6 +3,7 @@ package container
 import (
 	"encoding/json"
 	"fmt"
+	"runtime"
 	"time"
 
 	"github.com/jmgilman/headjack/internal/exec"
@@ -135,3 +136,75 @@ func (p *appleParser) parseList(data []byte) ([]Container, error) {
 
 	return containers, nil
 }
+
+// appleImageInspect represents the JSON output of `container image inspect`.
+// Images are stored as an index with one variant per platform, each holding
+// an OCI image config.
+type appleImageInspect struct {
+	Name  string `json:"name"`
+	Index struct {
+		Digest string `json:"digest"`
+	} `json:"index"`
+	Variants []appleImageVariant `json:"variants"`
+}
+
+// appleImageVariant is the image of one platform.
+type appleImageVariant struct {
+	Platform struct {
+		Architecture string `json:"architecture"`
+		OS           string `json:"os"`
+	} `json:"platform"`
+	Config struct {
+		Created string `json:"created"`
+		Config  struct {
+			Labels     map[string]string `json:"Labels"`
+			User       string            `json:"User"`
+			WorkingDir string            `json:"WorkingDir"`
+			Env        []string          `json:"Env"`
+			Entrypoint []string          `json:"Entrypoint"`
+			Cmd        []string          `json:"Cmd"`
+		} `json:"config"`
+	} `json:"config"`
+}
+
+// parseImageInspect parses the JSON output of `container image inspect`.
+// The config is read from the variant of the host's architecture, or the
+// first variant if there is none.
+func (p *appleParser) parseImageInspect(data []byte) (*Image, error) {
+	var infos []appleImageInspect
+	if err := json.Unmarshal(data, &infos); err != nil {
+		return nil, fmt.Errorf("parse image info: %w", err)
+	}
+
+	if len(infos) == 0 {
+		return nil, ErrImageNotFound
+	}
+
+	info := infos[0]
+	image := &Image{
+		ID:     info.Index.Digest,
+		Digest: info.Index.Digest,
+	}
+	if len(info.Variants) == 0 {
+		return image, nil
+	}
+
+	variant := info.Variants[0]
+	for _, v := range info.Variants {
+		if v.Platform.OS == "linux" && v.Platform.Architecture == runtime.GOARCH {
+			variant = v
+			break
+		}
+	}
+	image.Labels = variant.Config.Config.Labels
+	image.User = variant.Config.Config.User
+	image.WorkingDir = variant.Config.Config.WorkingDir
+	image.Env = variant.Config.Config.Env
+	image.Entrypoint = variant.Config.Config.Entrypoint
+	image.Cmd = variant.Config.Config.Cmd
+	image.Architecture = variant.Platform.Architecture
+	image.OS = variant.Platform.OS
+	image.Created = parseTimestamp(variant.Config.Created)
+
+	return image, nil
+}
@@ -3,7 +3,9 @@ package container
 import (
 	"context"
 	"errors"
+	goruntime "runtime"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -553,6 +555,77 @@ func TestAppleRuntime_List(t *testing.T) {
 	})
 }
 
+func TestAppleRuntime_InspectImage(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("returns image labels and config", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				assert.Equal(t, "container", opts.Name)
+				assert.Equal(t, []string{"image", "inspect", "ghcr.io/gilmanlab/headjack:systemd"}, opts.Args)
+
+				return &exec.Result{
+					Stdout: []byte(`[{"name":"ghcr.io/gilmanlab/headjack:systemd","index":{"digest":"sha256:9a8b"},"variants":[{"platform":{"architecture":"arm64","os":"linux"},"config":{"created":"2026-01-15T10:30:00.123456789Z","config":{"User":"developer","WorkingDir":"/workspace","Env":["PATH=/usr/bin"],"Cmd":["/bin/bash"],"Labels":{"io.headjack.init":"/lib/systemd/systemd"}}}}]}]`),
+				}, nil
+			},
+		}
+
+		runtime := NewAppleRuntime(mockExec, AppleConfig{})
+		image, err := runtime.InspectImage(ctx, "ghcr.io/gilmanlab/headjack:systemd")
+
+		require.NoError(t, err)
+		assert.Equal(t, "sha256:9a8b", image.ID)
+		assert.Equal(t, "sha256:9a8b", image.Digest)
+		assert.Equal(t, "/lib/systemd/systemd", image.Labels["io.headjack.init"])
+		assert.Equal(t, "developer", image.User)
+		assert.Equal(t, "/workspace", image.WorkingDir)
+		assert.Equal(t, []string{"PATH=/usr/bin"}, image.Env)
+		assert.Nil(t, image.Entrypoint)
+		assert.Equal(t, []string{"/bin/bash"}, image.Cmd)
+		assert.Equal(t, "arm64", image.Architecture)
+		assert.Equal(t, "linux", image.OS)
+		assert.Equal(t, time.Date(2026, 1, 15, 10, 30, 0, 123456789, time.UTC), image.Created)
+	})
+
+	t.Run("returns ErrImageNotFound when image missing", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, _ *exec.RunOptions) (*exec.Result, error) {
+				return &exec.Result{
+					Stderr:   []byte("Error: image not found: missing:latest"),
+					ExitCode: 1,
+				}, errors.New("exit code 1")
+			},
+		}
+
+		runtime := NewAppleRuntime(mockExec, AppleConfig{})
+		_, err := runtime.InspectImage(ctx, "missing:latest")
+
+		assert.ErrorIs(t, err, ErrImageNotFound)
+	})
+
+	t.Run("reads labels of the host platform variant", func(t *testing.T) {
+		other := "amd64"
+		if goruntime.GOARCH == "amd64" {
+			other = "arm64"
+		}
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, _ *exec.RunOptions) (*exec.Result, error) {
+				return &exec.Result{
+					Stdout: []byte(`[{"index":{"digest":"sha256:9a8b"},"variants":[` +
+						`{"platform":{"architecture":"` + other + `","os":"linux"},"config":{"config":{"Labels":{"arch":"other"}}}},` +
+						`{"platform":{"architecture":"` + goruntime.GOARCH + `","os":"linux"},"config":{"config":{"Labels":{"arch":"host"}}}}]}]`),
+				}, nil
+			},
+		}
+
+		runtime := NewAppleRuntime(mockExec, AppleConfig{})
+		image, err := runtime.InspectImage(ctx, "headjack:dev")
+
+		require.NoError(t, err)
+		assert.Equal(t, "host", image.Labels["arch"])
+	})
+}
+
 func TestAppleRuntime_Build(t *testing.T) {
 	ctx := context.Background()
 
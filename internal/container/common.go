@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"slices"
 	"strings"
 	"syscall"
 	"time"
@@ -22,6 +23,8 @@ type containerParser interface {
 	parseInspect(data []byte) (*Container, error)
 	// parseList parses the JSON output of the list command.
 	parseList(data []byte) ([]Container, error)
+	// parseImageInspect parses the JSON output of the image inspect command.
+	parseImageInspect(data []byte) (*Image, error)
 }
 
 // baseRuntime provides shared functionality for container runtimes.
@@ -92,8 +95,9 @@ func (r *baseRuntime) Exec(ctx context.Context, id string, cfg ExecConfig) error
 	}
 
 	result, err := r.exec.Run(ctx, &exec.RunOptions{
-		Name: r.binaryName,
-		Args: args,
+		Name:   r.binaryName,
+		Args:   args,
+		Stdout: cfg.Stdout,
 	})
 	if err != nil {
 		return cliError("exec in container", result, err)
@@ -233,6 +237,26 @@ func (r *baseRuntime) Build(ctx context.Context, cfg *BuildConfig) error {
 	return nil
 }
 
+// InspectImage retrieves the labels and config of an image in the local image store.
+func (r *baseRuntime) InspectImage(ctx context.Context, ref string) (*Image, error) {
+	if r.parser == nil {
+		return nil, ErrNoParser
+	}
+
+	result, err := r.exec.Run(ctx, &exec.RunOptions{
+		Name: r.binaryName,
+		Args: []string{"image", "inspect", ref},
+	})
+	if err != nil {
+		if result != nil && isImageNotFoundError(string(result.Stderr)) {
+			return nil, ErrImageNotFound
+		}
+		return nil, cliError("inspect image", result, err)
+	}
+
+	return r.parser.parseImageInspect(result.Stdout)
+}
+
 // execInteractive runs a container exec command with TTY support.
 func (r *baseRuntime) execInteractive(ctx context.Context, args []string) error {
 	stdinFd := int(os.Stdin.Fd())
@@ -361,10 +385,52 @@ func isAlreadyExistsError(stderr string) bool {
 	return strings.Contains(stderr, "already in use") || strings.Contains(stderr, "already exists")
 }
 
+// imageNotFoundMessages are the runtime messages for a missing local image:
+// Docker's "No such image", Podman's "image not known" and Apple's
+// "image not found".
+var imageNotFoundMessages = []string{"no such image", "image not known", "image not found"}
+
+// containerNotFoundMessages are the runtime messages for a missing container.
+var containerNotFoundMessages = []string{"no such", "no container", "not found"}
+
+// isImageNotFoundError checks if stderr indicates an image is not present locally.
+func isImageNotFoundError(stderr string) bool {
+	return containsMessage(stderr, imageNotFoundMessages)
+}
+
+// parseTimestamp parses an RFC 3339 timestamp, returning the zero time if it
+// is empty or malformed.
+func parseTimestamp(s string) time.Time {
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t
+	}
+	return time.Time{}
+}
+
+// repoDigest returns the digest of the first repository digest, such as
+// "ghcr.io/foo/bar@sha256:...", or an empty string if there is none.
+func repoDigest(repoDigests []string) string {
+	for _, d := range repoDigests {
+		if _, digest, ok := strings.Cut(d, "@"); ok {
+			return digest
+		}
+	}
+	return ""
+}
+
 // isNotFoundError checks if stderr indicates container not found.
 func isNotFoundError(stderr string) bool {
+	return containsMessage(stderr, containerNotFoundMessages)
+}
+
+// containsMessage reports whether stderr contains any of messages, ignoring
+// case.
+func containsMessage(stderr string, messages []string) bool {
 	normalized := strings.ToLower(stderr)
-	return strings.Contains(normalized, "no such") ||
-		strings.Contains(normalized, "no container") ||
-		strings.Contains(normalized, "not found")
+	return slices.ContainsFunc(messages, func(message string) bool {
+		return strings.Contains(normalized, message)
+	})
 }
@@ -4,6 +4,7 @@ package container
 import (
 	"context"
 	"errors"
+	"io"
 	"time"
 )
 
@@ -14,6 +15,7 @@ var (
 	ErrAlreadyExists = errors.New("container already exists")
 	ErrBuildFailed   = errors.New("image build failed")
 	ErrNoParser      = errors.New("runtime has no parser configured")
+	ErrImageNotFound = errors.New("image not found locally")
 )
 
 // Status represents the container state.
@@ -43,6 +45,21 @@ type Container struct {
 	CreatedAt time.Time
 }
 
+// Image holds metadata of an image in the runtime's local image store.
+type Image struct {
+	ID           string            // Image ID
+	Digest       string            // Repository digest if the image was pulled (e.g., "sha256:..."), else empty
+	Labels       map[string]string // Labels from the image config
+	User         string            // User the image runs as (empty = root)
+	WorkingDir   string            // Working directory (empty = /)
+	Env          []string          // Environment variables (KEY=VALUE format)
+	Entrypoint   []string          // Entrypoint
+	Cmd          []string          // Default command
+	Architecture string            // CPU architecture (e.g., "amd64", "arm64")
+	OS           string            // Operating system (e.g., "linux")
+	Created      time.Time         // When the image was built (zero if unknown)
+}
+
 // Mount defines a host-to-container volume mount.
 type Mount struct {
 	Source   string // Host path
@@ -62,10 +79,11 @@ type RunConfig struct {
 
 // ExecConfig configures command execution in a container.
 type ExecConfig struct {
-	Command     []string // Command and arguments (required)
-	Env         []string // Additional environment variables
-	Interactive bool     // If true, sets up TTY with raw mode and signal forwarding
-	Workdir     string   // Working directory (empty = container default)
+	Command     []string  // Command and arguments (required)
+	Env         []string  // Additional environment variables
+	Interactive bool      // If true, sets up TTY with raw mode and signal forwarding
+	Workdir     string    // Working directory (empty = container default)
+	Stdout      io.Writer // Receives the command's stdout when not interactive (nil = discard)
 }
 
 // BuildConfig configures image builds.
@@ -122,6 +140,11 @@ type Runtime interface {
 	// Returns ErrBuildFailed if the build fails.
 	Build(ctx context.Context, cfg *BuildConfig) error
 
+	// InspectImage retrieves the labels and config of an image in the local
+	// image store, such as an image built with Build. The image is never pulled.
+	// Returns ErrImageNotFound if the image is not present locally.
+	InspectImage(ctx context.Context, ref string) (*Image, error)
+
 	// ExecCommand returns the command prefix for executing commands in a container.
 	// This is used by the multiplexer to build commands that run inside containers.
 	// For example, Apple returns ["container", "exec"] and Podman returns ["podman", "exec"].
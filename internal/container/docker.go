@@ -143,3 +143,50 @@ func (p *dockerParser) parseList(data []byte) ([]Container, error) {
 
 	return containers, nil
 }
+
+// dockerImageInspect represents the JSON output of `docker image inspect`.
+type dockerImageInspect struct {
+	ID           string   `json:"Id"`
+	RepoDigests  []string `json:"RepoDigests"`
+	Created      string   `json:"Created"`
+	Architecture string   `json:"Architecture"`
+	OS           string   `json:"Os"`
+	Config       struct {
+		Labels     map[string]string `json:"Labels"`
+		User       string            `json:"User"`
+		WorkingDir string            `json:"WorkingDir"`
+		Env        []string          `json:"Env"`
+		Entrypoint []string          `json:"Entrypoint"`
+		Cmd        []string          `json:"Cmd"`
+	} `json:"Config"`
+}
+
+func (i *dockerImageInspect) toImage() *Image {
+	return &Image{
+		ID:           i.ID,
+		Digest:       repoDigest(i.RepoDigests),
+		Labels:       i.Config.Labels,
+		User:         i.Config.User,
+		WorkingDir:   i.Config.WorkingDir,
+		Env:          i.Config.Env,
+		Entrypoint:   i.Config.Entrypoint,
+		Cmd:          i.Config.Cmd,
+		Architecture: i.Architecture,
+		OS:           i.OS,
+		Created:      parseTimestamp(i.Created),
+	}
+}
+
+// parseImageInspect parses the JSON output of `docker image inspect`.
+func (p *dockerParser) parseImageInspect(data []byte) (*Image, error) {
+	var infos []dockerImageInspect
+	if err := json.Unmarshal(data, &infos); err != nil {
+		return nil, fmt.Errorf("parse image info: %w", err)
+	}
+
+	if len(infos) == 0 {
+		return nil, ErrImageNotFound
+	}
+
+	return infos[0].toImage(), nil
+}
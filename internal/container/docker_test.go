@@ -1,9 +1,11 @@
 package container
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -296,6 +298,35 @@ func TestDockerRuntime_Exec(t *testing.T) {
 		require.NoError(t, err)
 	})
 
+	t.Run("streams stdout to the configured writer", func(t *testing.T) {
+		var stdout bytes.Buffer
+		callCount := 0
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				callCount++
+				if callCount == 1 {
+					// Get call - Docker format
+					return &exec.Result{
+						Stdout: []byte(`[{"Id":"abc123","Name":"/test","State":{"Status":"running"},"Config":{"Image":"ubuntu"}}]`),
+					}, nil
+				}
+				assert.Same(t, &stdout, opts.Stdout)
+				_, _ = opts.Stdout.Write([]byte("hello\n"))
+
+				return &exec.Result{ExitCode: 0}, nil
+			},
+		}
+
+		runtime := NewDockerRuntime(mockExec, DockerConfig{})
+		err := runtime.Exec(ctx, "abc123", ExecConfig{
+			Command: []string{"echo", "hello"},
+			Stdout:  &stdout,
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "hello\n", stdout.String())
+	})
+
 	t.Run("returns ErrNotFound when container missing", func(t *testing.T) {
 		mockExec := &mocks.ExecutorMock{
 			RunFunc: func(_ context.Context, _ *exec.RunOptions) (*exec.Result, error) {
@@ -691,6 +722,89 @@ func TestDockerRuntime_List(t *testing.T) {
 	})
 }
 
+func TestDockerRuntime_InspectImage(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("returns image labels and config", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				assert.Equal(t, "docker", opts.Name)
+				assert.Equal(t, []string{"image", "inspect", "ghcr.io/gilmanlab/headjack:systemd"}, opts.Args)
+
+				return &exec.Result{
+					Stdout: []byte(`[{"Id":"sha256:1f2e","RepoTags":["ghcr.io/gilmanlab/headjack:systemd"],"RepoDigests":["ghcr.io/gilmanlab/headjack@sha256:9a8b"],"Created":"2026-01-15T10:30:00.123456789Z","Architecture":"arm64","Os":"linux","Config":{"User":"developer","WorkingDir":"/workspace","Env":["PATH=/usr/bin"],"Entrypoint":null,"Cmd":["/bin/bash"],"Labels":{"io.headjack.init":"/lib/systemd/systemd"}}}]`),
+				}, nil
+			},
+		}
+
+		runtime := NewDockerRuntime(mockExec, DockerConfig{})
+		image, err := runtime.InspectImage(ctx, "ghcr.io/gilmanlab/headjack:systemd")
+
+		require.NoError(t, err)
+		assert.Equal(t, "sha256:1f2e", image.ID)
+		assert.Equal(t, "sha256:9a8b", image.Digest)
+		assert.Equal(t, "/lib/systemd/systemd", image.Labels["io.headjack.init"])
+		assert.Equal(t, "developer", image.User)
+		assert.Equal(t, "/workspace", image.WorkingDir)
+		assert.Equal(t, []string{"PATH=/usr/bin"}, image.Env)
+		assert.Nil(t, image.Entrypoint)
+		assert.Equal(t, []string{"/bin/bash"}, image.Cmd)
+		assert.Equal(t, "arm64", image.Architecture)
+		assert.Equal(t, "linux", image.OS)
+		assert.Equal(t, time.Date(2026, 1, 15, 10, 30, 0, 123456789, time.UTC), image.Created)
+	})
+
+	t.Run("returns ErrImageNotFound when image missing", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, _ *exec.RunOptions) (*exec.Result, error) {
+				return &exec.Result{
+					Stderr:   []byte("Error response from daemon: No such image: missing:latest"),
+					ExitCode: 1,
+				}, errors.New("exit code 1")
+			},
+		}
+
+		runtime := NewDockerRuntime(mockExec, DockerConfig{})
+		_, err := runtime.InspectImage(ctx, "missing:latest")
+
+		assert.ErrorIs(t, err, ErrImageNotFound)
+	})
+
+	t.Run("does not treat other not found errors as a missing image", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, _ *exec.RunOptions) (*exec.Result, error) {
+				return &exec.Result{
+					Stderr:   []byte("docker: 'image' is not a docker command.\nsh: credential helper: command not found"),
+					ExitCode: 127,
+				}, errors.New("exit code 127")
+			},
+		}
+
+		runtime := NewDockerRuntime(mockExec, DockerConfig{})
+		_, err := runtime.InspectImage(ctx, "missing:latest")
+
+		require.Error(t, err)
+		assert.NotErrorIs(t, err, ErrImageNotFound)
+	})
+
+	t.Run("returns empty digest for locally built image", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, _ *exec.RunOptions) (*exec.Result, error) {
+				return &exec.Result{
+					Stdout: []byte(`[{"Id":"sha256:1f2e","RepoTags":["headjack:dev"],"RepoDigests":[],"Config":{"Labels":null}}]`),
+				}, nil
+			},
+		}
+
+		runtime := NewDockerRuntime(mockExec, DockerConfig{})
+		image, err := runtime.InspectImage(ctx, "headjack:dev")
+
+		require.NoError(t, err)
+		assert.Empty(t, image.Digest)
+		assert.Nil(t, image.Labels)
+	})
+}
+
 func TestDockerRuntime_Build(t *testing.T) {
 	ctx := context.Background()
 
@@ -32,6 +32,9 @@ var _ container.Runtime = &RuntimeMock{}
 //			GetFunc: func(ctx context.Context, id string) (*container.Container, error) {
 //				panic("mock out the Get method")
 //			},
+//			InspectImageFunc: func(ctx context.Context, ref string) (*container.Image, error) {
+//				panic("mock out the InspectImage method")
+//			},
 //			ListFunc: func(ctx context.Context, filter container.ListFilter) ([]container.Container, error) {
 //				panic("mock out the List method")
 //			},
@@ -66,6 +69,9 @@ type RuntimeMock struct {
 	// GetFunc mocks the Get method.
 	GetFunc func(ctx context.Context, id string) (*container.Container, error)
 
+	// InspectImageFunc mocks the InspectImage method.
+	InspectImageFunc func(ctx context.Context, ref string) (*container.Image, error)
+
 	// ListFunc mocks the List method.
 	ListFunc func(ctx context.Context, filter container.ListFilter) ([]container.Container, error)
 
@@ -109,6 +115,13 @@ type RuntimeMock struct {
 			// ID is the id argument value.
 			ID string
 		}
+		// InspectImage holds details about calls to the InspectImage method.
+		InspectImage []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Ref is the ref argument value.
+			Ref string
+		}
 		// List holds details about calls to the List method.
 		List []struct {
 			// Ctx is the ctx argument value.
@@ -145,15 +158,16 @@ type RuntimeMock struct {
 			ID string
 		}
 	}
-	lockBuild       sync.RWMutex
-	lockExec        sync.RWMutex
-	lockExecCommand sync.RWMutex
-	lockGet         sync.RWMutex
-	lockList        sync.RWMutex
-	lockRemove      sync.RWMutex
-	lockRun         sync.RWMutex
-	lockStart       sync.RWMutex
-	lockStop        sync.RWMutex
+	lockBuild        sync.RWMutex
+	lockExec         sync.RWMutex
+	lockExecCommand  sync.RWMutex
+	lockGet          sync.RWMutex
+	lockInspectImage sync.RWMutex
+	lockList         sync.RWMutex
+	lockRemove       sync.RWMutex
+	lockRun          sync.RWMutex
+	lockStart        sync.RWMutex
+	lockStop         sync.RWMutex
 }
 
 // Build calls BuildFunc.
@@ -295,6 +309,42 @@ func (mock *RuntimeMock) GetCalls() []struct {
 	return calls
 }
 
+// InspectImage calls InspectImageFunc.
+func (mock *RuntimeMock) InspectImage(ctx context.Context, ref string) (*container.Image, error) {
+	if mock.InspectImageFunc == nil {
+		panic("RuntimeMock.InspectImageFunc: method is nil but Runtime.InspectImage was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		Ref string
+	}{
+		Ctx: ctx,
+		Ref: ref,
+	}
+	mock.lockInspectImage.Lock()
+	mock.calls.InspectImage = append(mock.calls.InspectImage, callInfo)
+	mock.lockInspectImage.Unlock()
+	return mock.InspectImageFunc(ctx, ref)
+}
+
+// InspectImageCalls gets all the calls that were made to InspectImage.
+// Check the length with:
+//
+//	len(mockedRuntime.InspectImageCalls())
+func (mock *RuntimeMock) InspectImageCalls() []struct {
+	Ctx context.Context
+	Ref string
+} {
+	var calls []struct {
+		Ctx context.Context
+		Ref string
+	}
+	mock.lockInspectImage.RLock()
+	calls = mock.calls.InspectImage
+	mock.lockInspectImage.RUnlock()
+	return calls
+}
+
 // List calls ListFunc.
 func (mock *RuntimeMock) List(ctx context.Context, filter container.ListFilter) ([]container.Container, error) {
 	if mock.ListFunc == nil {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -637,6 +638,55 @@ func TestPodmanRuntime_List(t *testing.T) {
 	})
 }
 
+func TestPodmanRuntime_InspectImage(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("returns image labels and config", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				assert.Equal(t, "podman", opts.Name)
+				assert.Equal(t, []string{"image", "inspect", "ghcr.io/gilmanlab/headjack:systemd"}, opts.Args)
+
+				return &exec.Result{
+					Stdout: []byte(`[{"Id":"1f2e","Digest":"sha256:9a8b","RepoDigests":["ghcr.io/gilmanlab/headjack@sha256:9a8b"],"Created":"2026-01-15T10:30:00.123456789Z","Architecture":"arm64","Os":"linux","Config":{"User":"developer","WorkingDir":"/workspace","Env":["PATH=/usr/bin"],"Cmd":["/bin/bash"],"Labels":{"io.headjack.init":"/lib/systemd/systemd"}}}]`),
+				}, nil
+			},
+		}
+
+		runtime := NewPodmanRuntime(mockExec, PodmanConfig{})
+		image, err := runtime.InspectImage(ctx, "ghcr.io/gilmanlab/headjack:systemd")
+
+		require.NoError(t, err)
+		assert.Equal(t, "1f2e", image.ID)
+		assert.Equal(t, "sha256:9a8b", image.Digest)
+		assert.Equal(t, "/lib/systemd/systemd", image.Labels["io.headjack.init"])
+		assert.Equal(t, "developer", image.User)
+		assert.Equal(t, "/workspace", image.WorkingDir)
+		assert.Equal(t, []string{"PATH=/usr/bin"}, image.Env)
+		assert.Nil(t, image.Entrypoint)
+		assert.Equal(t, []string{"/bin/bash"}, image.Cmd)
+		assert.Equal(t, "arm64", image.Architecture)
+		assert.Equal(t, "linux", image.OS)
+		assert.Equal(t, time.Date(2026, 1, 15, 10, 30, 0, 123456789, time.UTC), image.Created)
+	})
+
+	t.Run("returns ErrImageNotFound when image missing", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, _ *exec.RunOptions) (*exec.Result, error) {
+				return &exec.Result{
+					Stderr:   []byte("Error: missing:latest: image not known"),
+					ExitCode: 1,
+				}, errors.New("exit code 1")
+			},
+		}
+
+		runtime := NewPodmanRuntime(mockExec, PodmanConfig{})
+		_, err := runtime.InspectImage(ctx, "missing:latest")
+
+		assert.ErrorIs(t, err, ErrImageNotFound)
+	})
+}
+
 func TestPodmanRuntime_Build(t *testing.T) {
 	ctx := context.Background()
 
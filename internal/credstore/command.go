@@ -0,0 +1,77 @@
+package credstore
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jmgilman/headjack/internal/exec"
+	"github.com/jmgilman/headjack/internal/keychain"
+)
+
+// Commands are the shell commands of a command backend. Set and Delete are
+// optional; without them the backend is read-only.
+type Commands struct {
+	// Get prints the credential of $HEADJACK_ACCOUNT on standard output,
+	// printing nothing if it does not exist.
+	Get string
+
+	// Set stores the credential read from standard input as $HEADJACK_ACCOUNT.
+	Set string
+
+	// Delete removes the credential of $HEADJACK_ACCOUNT.
+	Delete string
+}
+
+// command keeps credentials with user-supplied shell commands.
+type command struct {
+	exec     exec.Executor
+	commands Commands
+}
+
+// NewCommand creates a backend that runs shell commands to read, store, and
+// delete credentials, for secret managers without a dedicated backend. Each
+// command receives the account in HEADJACK_ACCOUNT.
+func NewCommand(e exec.Executor, commands Commands) keychain.Keychain {
+	return &command{exec: e, commands: commands}
+}
+
+func (c *command) run(script, account, stdin string) (string, error) {
+	opts := &exec.RunOptions{
+		Name: "sh",
+		Args: []string{"-c", script},
+		Env:  []string{"HEADJACK_ACCOUNT=" + account},
+	}
+	if stdin != "" {
+		opts.Stdin = strings.NewReader(stdin)
+	}
+	return run(context.Background(), c.exec, opts)
+}
+
+func (c *command) Get(account string) (string, error) {
+	out, err := c.run(c.commands.Get, account, "")
+	if err != nil {
+		return "", err
+	}
+	out = strings.TrimRight(out, "\r\n")
+	if out == "" {
+		return "", fmt.Errorf("%w: %s", keychain.ErrNotFound, account)
+	}
+	return out, nil
+}
+
+func (c *command) Set(account, secret string) error {
+	if c.commands.Set == "" {
+		return fmt.Errorf("%w: no set command configured", ErrReadOnly)
+	}
+	_, err := c.run(c.commands.Set, account, secret)
+	return err
+}
+
+func (c *command) Delete(account string) error {
+	if c.commands.Delete == "" {
+		return fmt.Errorf("%w: no delete command configured", ErrReadOnly)
+	}
+	_, err := c.run(c.commands.Delete, account, "")
+	return err
+}
@@ -0,0 +1,104 @@
+package credstore
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmgilman/headjack/internal/exec"
+	"github.com/jmgilman/headjack/internal/exec/mocks"
+	"github.com/jmgilman/headjack/internal/keychain"
+)
+
+func TestCommand_Get(t *testing.T) {
+	commands := Commands{Get: `vault kv get -field=value "secret/$HEADJACK_ACCOUNT"`}
+
+	t.Run("reads standard output", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				assert.Equal(t, "sh", opts.Name)
+				assert.Equal(t, []string{"-c", commands.Get}, opts.Args)
+				assert.Equal(t, []string{"HEADJACK_ACCOUNT=secret:github"}, opts.Env)
+				return &exec.Result{Stdout: []byte("ghp_test\n")}, nil
+			},
+		}
+
+		value, err := NewCommand(mockExec, commands).Get("secret:github")
+
+		require.NoError(t, err)
+		assert.Equal(t, "ghp_test", value)
+	})
+
+	t.Run("empty output means not found", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(context.Context, *exec.RunOptions) (*exec.Result, error) {
+				return &exec.Result{}, nil
+			},
+		}
+
+		_, err := NewCommand(mockExec, commands).Get("secret:github")
+
+		assert.ErrorIs(t, err, keychain.ErrNotFound)
+	})
+
+	t.Run("includes stderr when the command fails", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(context.Context, *exec.RunOptions) (*exec.Result, error) {
+				return &exec.Result{Stderr: []byte("permission denied"), ExitCode: 2}, errors.New("exit status 2")
+			},
+		}
+
+		_, err := NewCommand(mockExec, commands).Get("secret:github")
+
+		assert.ErrorContains(t, err, "permission denied")
+	})
+}
+
+func TestCommand_Set(t *testing.T) {
+	t.Run("passes the value on stdin", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				assert.Equal(t, []string{"-c", "store-secret"}, opts.Args)
+				stdin, err := io.ReadAll(opts.Stdin)
+				require.NoError(t, err)
+				assert.Equal(t, "ghp_test", string(stdin))
+				return &exec.Result{}, nil
+			},
+		}
+
+		err := NewCommand(mockExec, Commands{Get: "get-secret", Set: "store-secret"}).Set("secret:github", "ghp_test")
+
+		require.NoError(t, err)
+		assert.Len(t, mockExec.RunCalls(), 1)
+	})
+
+	t.Run("read-only without a set command", func(t *testing.T) {
+		err := NewCommand(&mocks.ExecutorMock{}, Commands{Get: "get-secret"}).Set("secret:github", "ghp_test")
+		assert.ErrorIs(t, err, ErrReadOnly)
+	})
+}
+
+func TestCommand_Delete(t *testing.T) {
+	t.Run("runs the delete command", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				assert.Equal(t, []string{"-c", "delete-secret"}, opts.Args)
+				assert.Nil(t, opts.Stdin)
+				return &exec.Result{}, nil
+			},
+		}
+
+		err := NewCommand(mockExec, Commands{Get: "get-secret", Delete: "delete-secret"}).Delete("secret:github")
+
+		require.NoError(t, err)
+	})
+
+	t.Run("read-only without a delete command", func(t *testing.T) {
+		err := NewCommand(&mocks.ExecutorMock{}, Commands{Get: "get-secret"}).Delete("secret:github")
+		assert.ErrorIs(t, err, ErrReadOnly)
+	})
+}
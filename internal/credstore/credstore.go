@@ -0,0 +1,105 @@
+// Package credstore provides credential storage backends that keep
+// credentials outside the system keychain: 1Password, pass, a user-supplied
+// command, and a dotenv file. Every backend satisfies keychain.Keychain, and
+// so auth.Storage and secret.Backend, and reports missing credentials with
+// keychain.ErrNotFound so callers handle all backends alike.
+//
+// Backends address credentials by the account names headjack uses, such as
+// "claude-credential" or "secret:github", mapped to each tool's naming rules.
+package credstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jmgilman/headjack/internal/exec"
+	"github.com/jmgilman/headjack/internal/keychain"
+)
+
+// ErrReadOnly is returned when storing or deleting a credential in a backend
+// that can only read credentials.
+var ErrReadOnly = errors.New("credential backend is read-only")
+
+// ErrAmbiguousAccount is returned when a backend cannot tell an account
+// apart from another account whose name it stores under the same key.
+var ErrAmbiguousAccount = errors.New("credential account is ambiguous in backend")
+
+// run executes a command and returns its standard output. Errors include the
+// command's standard error, if any.
+func run(ctx context.Context, e exec.Executor, opts *exec.RunOptions) (string, error) {
+	result, err := e.Run(ctx, opts)
+	if err != nil {
+		if result != nil && len(result.Stderr) > 0 {
+			return "", fmt.Errorf("run %s: %w: %s", opts.Name, err, strings.TrimSpace(string(result.Stderr)))
+		}
+		return "", fmt.Errorf("run %s: %w", opts.Name, err)
+	}
+	return string(result.Stdout), nil
+}
+
+// Backend names selectable in configuration.
+const (
+	BackendKeychain    = "keychain"
+	BackendOnePassword = "1password"
+	BackendPass        = "pass"
+	BackendCommand     = "command"
+	BackendDotenv      = "dotenv"
+)
+
+// ErrInvalidBackend is returned for an unknown or incompletely configured
+// backend.
+var ErrInvalidBackend = errors.New("invalid credential backend")
+
+// Config selects and configures a credential backend.
+type Config struct {
+	// Backend is one of the Backend* names. Empty selects BackendKeychain.
+	Backend string
+
+	// Vault and Field address 1Password items.
+	Vault string
+	Field string
+
+	// Prefix is the pass directory credentials are kept under.
+	Prefix string
+
+	// Path is the dotenv file.
+	Path string
+
+	// Commands are the shell commands of the command backend.
+	Commands Commands
+}
+
+// Open returns the backend selected by cfg. The system keychain is opened
+// with keychain.New, honoring HEADJACK_KEYRING_BACKEND.
+func Open(e exec.Executor, cfg Config) (keychain.Keychain, error) {
+	switch cfg.Backend {
+	case "", BackendKeychain:
+		return keychain.New()
+	case BackendOnePassword:
+		if cfg.Vault == "" {
+			return nil, fmt.Errorf("%w: %s requires a vault", ErrInvalidBackend, cfg.Backend)
+		}
+		return NewOnePassword(e, cfg.Vault, cfg.Field), nil
+	case BackendPass:
+		return NewPass(e, cfg.Prefix), nil
+	case BackendCommand:
+		if cfg.Commands.Get == "" {
+			return nil, fmt.Errorf("%w: %s requires a get command", ErrInvalidBackend, cfg.Backend)
+		}
+		return NewCommand(e, cfg.Commands), nil
+	case BackendDotenv:
+		if cfg.Path == "" {
+			return nil, fmt.Errorf("%w: %s requires a path", ErrInvalidBackend, cfg.Backend)
+		}
+		return NewDotenv(cfg.Path), nil
+	default:
+		return nil, fmt.Errorf("%w: %s (valid: %s)", ErrInvalidBackend, cfg.Backend, strings.Join(BackendNames(), ", "))
+	}
+}
+
+// BackendNames returns the names of the selectable backends.
+func BackendNames() []string {
+	return []string{BackendKeychain, BackendOnePassword, BackendPass, BackendCommand, BackendDotenv}
+}
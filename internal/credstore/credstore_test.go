@@ -0,0 +1,44 @@
+package credstore
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmgilman/headjack/internal/exec/mocks"
+)
+
+func TestOpen(t *testing.T) {
+	mockExec := &mocks.ExecutorMock{}
+
+	t.Run("selects configured backend", func(t *testing.T) {
+		tests := []struct {
+			cfg  Config
+			want any
+		}{
+			{Config{Backend: BackendOnePassword, Vault: "Dev"}, &onePassword{}},
+			{Config{Backend: BackendPass}, &pass{}},
+			{Config{Backend: BackendCommand, Commands: Commands{Get: "cat"}}, &command{}},
+			{Config{Backend: BackendDotenv, Path: filepath.Join(t.TempDir(), ".env")}, &dotenv{}},
+		}
+		for _, tt := range tests {
+			kc, err := Open(mockExec, tt.cfg)
+			require.NoError(t, err, tt.cfg.Backend)
+			assert.IsType(t, tt.want, kc, tt.cfg.Backend)
+		}
+	})
+
+	t.Run("rejects incomplete configuration", func(t *testing.T) {
+		for _, cfg := range []Config{
+			{Backend: BackendOnePassword},
+			{Backend: BackendCommand},
+			{Backend: BackendDotenv},
+			{Backend: "vault"},
+		} {
+			_, err := Open(mockExec, cfg)
+			assert.ErrorIs(t, err, ErrInvalidBackend, cfg.Backend)
+		}
+	})
+}
@@ -0,0 +1,228 @@
+package credstore
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/jmgilman/headjack/internal/keychain"
+)
+
+// dotenv keeps credentials as variables in a dotenv file.
+type dotenv struct {
+	path string
+}
+
+// NewDotenv creates a backend that keeps each credential as a variable in
+// the dotenv file at path, named after the account in upper case with every
+// other character than letters and digits replaced by '_', e.g.
+// CLAUDE_CREDENTIAL or SECRET_GITHUB. Values may be unquoted, single-quoted,
+// or double-quoted with \n, \" and \\ escapes. Storing a credential rewrites
+// only its own lines, keeping comments and other variables.
+//
+// Since different accounts can share a variable name, each stored variable
+// is preceded by an "# account: <account>" comment, and a variable claimed
+// by another account is rejected with ErrAmbiguousAccount. Variables without
+// the comment are taken to belong to whichever account asks for them.
+func NewDotenv(path string) keychain.Keychain {
+	return &dotenv{path: path}
+}
+
+// envKey returns the variable holding an account's credential.
+func envKey(account string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, account)
+}
+
+// accountComment marks the account a variable belongs to.
+const accountComment = "# account: "
+
+// find returns the index of the line holding an account's variable, or -1
+// if there is none, and whether the line is preceded by the account's
+// comment. Returns ErrAmbiguousAccount if the variable belongs to another
+// account.
+func (d *dotenv) find(lines []string, account string) (int, bool, error) {
+	key := envKey(account)
+	for i, line := range lines {
+		if k, _, ok := parseDotenvLine(line); !ok || k != key {
+			continue
+		}
+		if i == 0 {
+			return i, false, nil
+		}
+		owner, ok := strings.CutPrefix(strings.TrimSpace(lines[i-1]), accountComment)
+		if !ok {
+			return i, false, nil
+		}
+		if owner != account {
+			return -1, false, fmt.Errorf("%w: %s in %s belongs to %s", ErrAmbiguousAccount, key, d.path, owner)
+		}
+		return i, true, nil
+	}
+	return -1, false, nil
+}
+
+func (d *dotenv) Get(account string) (string, error) {
+	lines, err := d.read()
+	if err != nil {
+		return "", err
+	}
+	i, _, err := d.find(lines, account)
+	if err != nil {
+		return "", err
+	}
+	if i < 0 {
+		return "", fmt.Errorf("%w: %s in %s", keychain.ErrNotFound, envKey(account), d.path)
+	}
+	_, value, _ := parseDotenvLine(lines[i])
+	return value, nil
+}
+
+func (d *dotenv) Set(account, secret string) error {
+	lines, err := d.read()
+	if err != nil {
+		return err
+	}
+	i, marked, err := d.find(lines, account)
+	if err != nil {
+		return err
+	}
+	entry := envKey(account) + "=" + quoteDotenv(secret)
+
+	switch {
+	case i < 0:
+		lines = append(lines, accountComment+account, entry)
+	case marked:
+		lines[i] = entry
+	default:
+		lines = slices.Replace(lines, i, i+1, accountComment+account, entry)
+	}
+	return d.write(lines)
+}
+
+func (d *dotenv) Delete(account string) error {
+	lines, err := d.read()
+	if err != nil {
+		return err
+	}
+	i, marked, err := d.find(lines, account)
+	if err != nil {
+		return err
+	}
+	if i < 0 {
+		return nil
+	}
+	start := i
+	if marked {
+		start--
+	}
+	return d.write(slices.Delete(lines, start, i+1))
+}
+
+// read returns the lines of the file. A missing file has no lines.
+func (d *dotenv) read() ([]string, error) {
+	data, err := os.ReadFile(d.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read dotenv file: %w", err)
+	}
+	content := strings.TrimSuffix(string(data), "\n")
+	if content == "" {
+		return nil, nil
+	}
+	return strings.Split(content, "\n"), nil
+}
+
+// write replaces the file with the given lines, readable only by the owner.
+func (d *dotenv) write(lines []string) error {
+	if err := os.MkdirAll(filepath.Dir(d.path), 0o700); err != nil {
+		return fmt.Errorf("create dotenv directory: %w", err)
+	}
+	data := strings.Join(lines, "\n")
+	if data != "" {
+		data += "\n"
+	}
+	tmp := d.path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(data), 0o600); err != nil {
+		return fmt.Errorf("write dotenv file: %w", err)
+	}
+	if err := os.Rename(tmp, d.path); err != nil {
+		return fmt.Errorf("replace dotenv file: %w", err)
+	}
+	return nil
+}
+
+// parseDotenvLine returns the variable assigned by a line and its value.
+// Blank lines, comments, and lines without an assignment yield ok false.
+func parseDotenvLine(line string) (key, value string, ok bool) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", "", false
+	}
+	line = strings.TrimPrefix(line, "export ")
+	key, value, ok = strings.Cut(line, "=")
+	if !ok {
+		return "", "", false
+	}
+	key = strings.TrimSpace(key)
+	value = strings.TrimSpace(value)
+
+	switch {
+	case len(value) >= 2 && value[0] == '\'':
+		if end := strings.IndexByte(value[1:], '\''); end >= 0 {
+			return key, value[1 : end+1], true
+		}
+	case len(value) >= 2 && value[0] == '"':
+		return key, unquoteDotenv(value[1:]), true
+	}
+	if i := strings.Index(value, " #"); i >= 0 {
+		value = strings.TrimSpace(value[:i])
+	}
+	return key, value, true
+}
+
+// unquoteDotenv decodes a double-quoted value up to its closing quote.
+func unquoteDotenv(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '"':
+			return b.String()
+		case c == '\\' && i+1 < len(s):
+			i++
+			switch s[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			default:
+				b.WriteByte(s[i])
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// quoteDotenv encodes a value as a double-quoted dotenv value.
+func quoteDotenv(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`)
+	return `"` + r.Replace(s) + `"`
+}
@@ -0,0 +1,97 @@
+package credstore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmgilman/headjack/internal/keychain"
+)
+
+func TestEnvKey(t *testing.T) {
+	assert.Equal(t, "CLAUDE_CREDENTIAL", envKey("claude-credential"))
+	assert.Equal(t, "CLAUDE_CREDENTIAL_WORK", envKey("claude-credential:work"))
+	assert.Equal(t, "SECRET_MYPROJECT_ABC1234_NPM_TOKEN", envKey("secret:myproject-abc1234:npm.token"))
+}
+
+func TestParseDotenvLine(t *testing.T) {
+	tests := []struct {
+		line  string
+		key   string
+		value string
+		ok    bool
+	}{
+		{line: "SECRET_GITHUB=ghp_test", key: "SECRET_GITHUB", value: "ghp_test", ok: true},
+		{line: "export SECRET_GITHUB = ghp_test # work token", key: "SECRET_GITHUB", value: "ghp_test", ok: true},
+		{line: `SECRET_NPM='npm #1 \n'`, key: "SECRET_NPM", value: `npm #1 \n`, ok: true},
+		{line: `CLAUDE_CREDENTIAL="{\"a\":\"b\"}\nline"`, key: "CLAUDE_CREDENTIAL", value: "{\"a\":\"b\"}\nline", ok: true},
+		{line: "# comment"},
+		{line: "   "},
+		{line: "not an assignment"},
+	}
+	for _, tt := range tests {
+		key, value, ok := parseDotenvLine(tt.line)
+		assert.Equal(t, tt.ok, ok, tt.line)
+		assert.Equal(t, tt.key, key, tt.line)
+		assert.Equal(t, tt.value, value, tt.line)
+	}
+}
+
+func TestDotenv(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "headjack", "credentials.env")
+	store := NewDotenv(path)
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := store.Get("secret:github")
+		assert.ErrorIs(t, err, keychain.ErrNotFound)
+		assert.NoError(t, store.Delete("secret:github"))
+	})
+
+	t.Run("round trips values", func(t *testing.T) {
+		value := "{\"token\":\"a\\\"b\"}\nsecond line"
+		require.NoError(t, store.Set("claude-credential", value))
+
+		got, err := store.Get("claude-credential")
+
+		require.NoError(t, err)
+		assert.Equal(t, value, got)
+
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+	})
+
+	t.Run("keeps other lines", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(path, []byte("# team secrets\nSECRET_GITHUB=old\nSECRET_NPM=npm_test\n"), 0o600))
+
+		require.NoError(t, store.Set("secret:github", "new"))
+		require.NoError(t, store.Delete("secret:npm"))
+		require.NoError(t, store.Set("secret:db", "postgres://"))
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "# team secrets\n# account: secret:github\nSECRET_GITHUB=\"new\"\n# account: secret:db\nSECRET_DB=\"postgres://\"\n", string(data))
+	})
+
+	t.Run("rejects accounts sharing a variable", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(path, nil, 0o600))
+		require.NoError(t, store.Set("secret:github.com-a-b-x:token", "a"))
+
+		_, err := store.Get("secret:github.com-a_b-x:token")
+		assert.ErrorIs(t, err, ErrAmbiguousAccount)
+		assert.ErrorIs(t, store.Set("secret:github.com-a_b-x:token", "b"), ErrAmbiguousAccount)
+		assert.ErrorIs(t, store.Delete("secret:github.com-a_b-x:token"), ErrAmbiguousAccount)
+
+		got, err := store.Get("secret:github.com-a-b-x:token")
+		require.NoError(t, err)
+		assert.Equal(t, "a", got)
+
+		require.NoError(t, store.Delete("secret:github.com-a-b-x:token"))
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Empty(t, data)
+	})
+}
@@ -0,0 +1,159 @@
+package credstore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"syscall"
+
+	"github.com/jmgilman/headjack/internal/keychain"
+)
+
+// Lock file permissions.
+const (
+	lockDirMode  = 0o750
+	lockFileMode = 0o600
+)
+
+// Locker serializes updates of a backend account across processes. Backends
+// returned by WithLocks implement it.
+type Locker interface {
+	// Lock acquires an exclusive lock on account, blocking until it is
+	// available, and returns the function releasing it.
+	Lock(account string) (unlock func(), err error)
+}
+
+// lockingBackend adds file locks to a backend.
+type lockingBackend struct {
+	keychain.Keychain
+	dir string
+}
+
+// WithLocks returns backend with Index updates serialized across processes
+// by flock(2) on <dir>/<account>.lock.
+func WithLocks(backend keychain.Keychain, dir string) keychain.Keychain {
+	return &lockingBackend{Keychain: backend, dir: dir}
+}
+
+// Lock implements Locker.
+func (b *lockingBackend) Lock(account string) (func(), error) {
+	if err := os.MkdirAll(b.dir, lockDirMode); err != nil {
+		return nil, fmt.Errorf("create lock directory: %w", err)
+	}
+	//nolint:gosec // G304: path is built from the trusted data directory and a fixed account name
+	lock, err := os.OpenFile(filepath.Join(b.dir, account+".lock"), os.O_CREATE|os.O_RDWR, lockFileMode)
+	if err != nil {
+		return nil, fmt.Errorf("open %s lock: %w", account, err)
+	}
+	if err := syscall.Flock(int(lock.Fd()), syscall.LOCK_EX); err != nil {
+		_ = lock.Close()
+		return nil, fmt.Errorf("lock %s: %w", account, err)
+	}
+	return func() {
+		//nolint:errcheck // Unlock errors are not actionable; closing releases the lock anyway
+		syscall.Flock(int(lock.Fd()), syscall.LOCK_UN)
+		_ = lock.Close()
+	}, nil
+}
+
+// Index is a list of entries kept as JSON in a backend account, since
+// backends cannot enumerate their accounts. Updates are serialized across
+// processes if the backend implements Locker.
+type Index[T comparable] struct {
+	backend keychain.Keychain
+	account string
+}
+
+// NewIndex returns the index stored in account of backend.
+func NewIndex[T comparable](backend keychain.Keychain, account string) *Index[T] {
+	return &Index[T]{backend: backend, account: account}
+}
+
+// Load returns the entries of the index, or nil if it does not exist.
+func (x *Index[T]) Load() ([]T, error) {
+	data, err := x.backend.Get(x.account)
+	if errors.Is(err, keychain.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load %s: %w", x.account, err)
+	}
+
+	var entries []T
+	if err := json.Unmarshal([]byte(data), &entries); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", x.account, err)
+	}
+	return entries, nil
+}
+
+// Add adds the entries not already in the index.
+func (x *Index[T]) Add(entries ...T) error {
+	return x.update(func(current []T) ([]T, bool) {
+		changed := false
+		for _, entry := range entries {
+			if !slices.Contains(current, entry) {
+				current = append(current, entry)
+				changed = true
+			}
+		}
+		return current, changed
+	})
+}
+
+// Remove removes an entry from the index, reporting whether it was there.
+func (x *Index[T]) Remove(entry T) (bool, error) {
+	found := false
+	err := x.update(func(current []T) ([]T, bool) {
+		i := slices.Index(current, entry)
+		if i < 0 {
+			return current, false
+		}
+		found = true
+		return slices.Delete(current, i, i+1), true
+	})
+	return found, err
+}
+
+// update applies fn to the entries under the backend's lock, saving them if
+// fn reports a change.
+func (x *Index[T]) update(fn func([]T) ([]T, bool)) error {
+	if locker, ok := x.backend.(Locker); ok {
+		unlock, err := locker.Lock(x.account)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+	}
+
+	entries, err := x.Load()
+	if err != nil {
+		return err
+	}
+	entries, changed := fn(entries)
+	if !changed {
+		return nil
+	}
+	return x.save(entries)
+}
+
+// save writes the entries, deleting the index once it is empty.
+func (x *Index[T]) save(entries []T) error {
+	if len(entries) == 0 {
+		if err := x.backend.Delete(x.account); err != nil {
+			return fmt.Errorf("save %s: %w", x.account, err)
+		}
+		return nil
+	}
+
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return fmt.Errorf("marshal %s: %w", x.account, err)
+	}
+	if err := x.backend.Set(x.account, string(data)); err != nil {
+		return fmt.Errorf("save %s: %w", x.account, err)
+	}
+	return nil
+}
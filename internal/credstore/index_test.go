@@ -0,0 +1,114 @@
+package credstore
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmgilman/headjack/internal/keychain"
+)
+
+// memoryBackend is an in-memory backend.
+type memoryBackend struct {
+	mu       sync.Mutex
+	accounts map[string]string
+}
+
+func (b *memoryBackend) Set(account, secret string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.accounts == nil {
+		b.accounts = make(map[string]string)
+	}
+	b.accounts[account] = secret
+	return nil
+}
+
+func (b *memoryBackend) Get(account string) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	secret, ok := b.accounts[account]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", keychain.ErrNotFound, account)
+	}
+	return secret, nil
+}
+
+func (b *memoryBackend) Delete(account string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.accounts, account)
+	return nil
+}
+
+func TestIndex(t *testing.T) {
+	t.Run("adds and removes entries", func(t *testing.T) {
+		backend := &memoryBackend{}
+		index := NewIndex[string](backend, "test-index")
+
+		entries, err := index.Load()
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+
+		require.NoError(t, index.Add("a", "b"))
+		require.NoError(t, index.Add("b", "c"))
+		entries, err = index.Load()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a", "b", "c"}, entries)
+
+		found, err := index.Remove("b")
+		require.NoError(t, err)
+		assert.True(t, found)
+		found, err = index.Remove("missing")
+		require.NoError(t, err)
+		assert.False(t, found)
+		entries, err = index.Load()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a", "c"}, entries)
+	})
+
+	t.Run("deletes the account once empty", func(t *testing.T) {
+		backend := &memoryBackend{}
+		index := NewIndex[string](backend, "test-index")
+
+		require.NoError(t, index.Add("a"))
+		_, err := index.Remove("a")
+		require.NoError(t, err)
+
+		assert.Empty(t, backend.accounts)
+	})
+
+	t.Run("rejects a corrupt index", func(t *testing.T) {
+		backend := &memoryBackend{accounts: map[string]string{"test-index": "not json"}}
+
+		_, err := NewIndex[string](backend, "test-index").Load()
+
+		assert.ErrorContains(t, err, "parse test-index")
+	})
+
+	t.Run("serializes concurrent updates", func(t *testing.T) {
+		dir := t.TempDir()
+		backend := &memoryBackend{}
+
+		var wg sync.WaitGroup
+		for i := range 20 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				// Separate wrappers stand in for separate processes
+				index := NewIndex[int](WithLocks(backend, dir), "test-index")
+				assert.NoError(t, index.Add(i))
+			}()
+		}
+		wg.Wait()
+
+		entries, err := NewIndex[int](backend, "test-index").Load()
+		require.NoError(t, err)
+		assert.Len(t, entries, 20)
+		assert.FileExists(t, filepath.Join(dir, "test-index.lock"))
+	})
+}
@@ -0,0 +1,70 @@
+package credstore
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jmgilman/headjack/internal/exec"
+	"github.com/jmgilman/headjack/internal/keychain"
+)
+
+// DefaultOnePasswordField is the item field read when none is configured.
+const DefaultOnePasswordField = "password"
+
+// onePassword reads credentials with the 1Password CLI.
+type onePassword struct {
+	exec  exec.Executor
+	vault string
+	field string
+}
+
+// NewOnePassword creates a read-only backend that reads each credential with
+// `op read op://<vault>/<item>/<field>`. The item is named after the account
+// with its first ':' replaced by '-' and a second one by a space, e.g.
+// "claude-credential", "secret-github" or "secret-myproject-abc1234 github".
+// An empty field selects DefaultOnePasswordField.
+func NewOnePassword(e exec.Executor, vault, field string) keychain.Keychain {
+	if field == "" {
+		field = DefaultOnePasswordField
+	}
+	return &onePassword{exec: e, vault: vault, field: field}
+}
+
+// itemName returns the item holding an account's credential. Accounts with
+// a space or more than two ':' are rejected with ErrAmbiguousAccount, since
+// their item names could also belong to other accounts.
+func itemName(account string) (string, error) {
+	if strings.Contains(account, " ") || strings.Count(account, ":") > 2 {
+		return "", fmt.Errorf("%w: %q has no unique 1Password item name", ErrAmbiguousAccount, account)
+	}
+	name := strings.Replace(account, ":", "-", 1)
+	return strings.Replace(name, ":", " ", 1), nil
+}
+
+func (o *onePassword) Get(account string) (string, error) {
+	item, err := itemName(account)
+	if err != nil {
+		return "", err
+	}
+	ref := fmt.Sprintf("op://%s/%s/%s", o.vault, item, o.field)
+	out, err := run(context.Background(), o.exec, &exec.RunOptions{
+		Name: "op",
+		Args: []string{"read", "--no-newline", ref},
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "isn't an item") || strings.Contains(err.Error(), "isn't a field") {
+			return "", fmt.Errorf("%w: %s", keychain.ErrNotFound, ref)
+		}
+		return "", err
+	}
+	return out, nil
+}
+
+func (o *onePassword) Set(string, string) error {
+	return fmt.Errorf("%w: store credentials in the 1Password vault %q instead", ErrReadOnly, o.vault)
+}
+
+func (o *onePassword) Delete(string) error {
+	return fmt.Errorf("%w: remove credentials from the 1Password vault %q instead", ErrReadOnly, o.vault)
+}
@@ -0,0 +1,104 @@
+package credstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmgilman/headjack/internal/exec"
+	"github.com/jmgilman/headjack/internal/exec/mocks"
+	"github.com/jmgilman/headjack/internal/keychain"
+)
+
+func TestOnePassword_Get(t *testing.T) {
+	t.Run("reads the item field", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				assert.Equal(t, "op", opts.Name)
+				assert.Equal(t, []string{"read", "--no-newline", "op://Dev/secret-github/password"}, opts.Args)
+				return &exec.Result{Stdout: []byte("ghp_test")}, nil
+			},
+		}
+
+		value, err := NewOnePassword(mockExec, "Dev", "").Get("secret:github")
+
+		require.NoError(t, err)
+		assert.Equal(t, "ghp_test", value)
+	})
+
+	t.Run("uses the configured field", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				assert.Equal(t, "op://Dev/claude-credential-work/credential", opts.Args[2])
+				return &exec.Result{Stdout: []byte("{}")}, nil
+			},
+		}
+
+		_, err := NewOnePassword(mockExec, "Dev", "credential").Get("claude-credential:work")
+
+		require.NoError(t, err)
+	})
+
+	t.Run("separates repository secrets from global ones", func(t *testing.T) {
+		var refs []string
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				refs = append(refs, opts.Args[2])
+				return &exec.Result{Stdout: []byte("value")}, nil
+			},
+		}
+		store := NewOnePassword(mockExec, "Dev", "")
+
+		_, err := store.Get("secret:repo-x")
+		require.NoError(t, err)
+		_, err = store.Get("secret:repo:x")
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"op://Dev/secret-repo-x/password", "op://Dev/secret-repo x/password"}, refs)
+	})
+
+	t.Run("rejects ambiguous accounts", func(t *testing.T) {
+		store := NewOnePassword(&mocks.ExecutorMock{}, "Dev", "")
+
+		for _, account := range []string{"secret:my repo:x", "secret:a:b:c"} {
+			_, err := store.Get(account)
+			assert.ErrorIs(t, err, ErrAmbiguousAccount, account)
+		}
+	})
+
+	t.Run("missing item", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(context.Context, *exec.RunOptions) (*exec.Result, error) {
+				return &exec.Result{Stderr: []byte(`[ERROR] "secret-npm" isn't an item in the "Dev" vault`), ExitCode: 1}, errors.New("exit status 1")
+			},
+		}
+
+		_, err := NewOnePassword(mockExec, "Dev", "").Get("secret:npm")
+
+		assert.ErrorIs(t, err, keychain.ErrNotFound)
+	})
+
+	t.Run("other failures", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(context.Context, *exec.RunOptions) (*exec.Result, error) {
+				return &exec.Result{Stderr: []byte("[ERROR] not signed in"), ExitCode: 1}, errors.New("exit status 1")
+			},
+		}
+
+		_, err := NewOnePassword(mockExec, "Dev", "").Get("secret:npm")
+
+		require.Error(t, err)
+		assert.NotErrorIs(t, err, keychain.ErrNotFound)
+		assert.ErrorContains(t, err, "not signed in")
+	})
+}
+
+func TestOnePassword_ReadOnly(t *testing.T) {
+	store := NewOnePassword(&mocks.ExecutorMock{}, "Dev", "")
+
+	assert.ErrorIs(t, store.Set("secret:github", "ghp_test"), ErrReadOnly)
+	assert.ErrorIs(t, store.Delete("secret:github"), ErrReadOnly)
+}
@@ -0,0 +1,73 @@
+package credstore
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jmgilman/headjack/internal/exec"
+	"github.com/jmgilman/headjack/internal/keychain"
+)
+
+// DefaultPassPrefix is the directory of the password store credentials are
+// kept under when none is configured.
+const DefaultPassPrefix = "headjack"
+
+// passNotFound is printed by pass for entries that do not exist.
+const passNotFound = "is not in the password store"
+
+// pass keeps credentials in the standard Unix password manager.
+type pass struct {
+	exec   exec.Executor
+	prefix string
+}
+
+// NewPass creates a backend that keeps each credential in a pass entry under
+// prefix, named after the account with ':' as a directory separator, e.g.
+// "headjack/claude-credential" or "headjack/secret/github". An empty prefix
+// selects DefaultPassPrefix.
+func NewPass(e exec.Executor, prefix string) keychain.Keychain {
+	if prefix == "" {
+		prefix = DefaultPassPrefix
+	}
+	return &pass{exec: e, prefix: strings.TrimSuffix(prefix, "/")}
+}
+
+func (p *pass) path(account string) string {
+	return p.prefix + "/" + strings.ReplaceAll(account, ":", "/")
+}
+
+func (p *pass) Get(account string) (string, error) {
+	path := p.path(account)
+	out, err := run(context.Background(), p.exec, &exec.RunOptions{
+		Name: "pass",
+		Args: []string{"show", path},
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), passNotFound) {
+			return "", fmt.Errorf("%w: %s", keychain.ErrNotFound, path)
+		}
+		return "", err
+	}
+	return strings.TrimSuffix(out, "\n"), nil
+}
+
+func (p *pass) Set(account, secret string) error {
+	_, err := run(context.Background(), p.exec, &exec.RunOptions{
+		Name:  "pass",
+		Args:  []string{"insert", "--multiline", "--force", p.path(account)},
+		Stdin: strings.NewReader(secret),
+	})
+	return err
+}
+
+func (p *pass) Delete(account string) error {
+	_, err := run(context.Background(), p.exec, &exec.RunOptions{
+		Name: "pass",
+		Args: []string{"rm", "--force", p.path(account)},
+	})
+	if err != nil && strings.Contains(err.Error(), passNotFound) {
+		return nil
+	}
+	return err
+}
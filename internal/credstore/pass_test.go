@@ -0,0 +1,87 @@
+package credstore
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmgilman/headjack/internal/exec"
+	"github.com/jmgilman/headjack/internal/exec/mocks"
+	"github.com/jmgilman/headjack/internal/keychain"
+)
+
+// notInStore is the failure pass reports for missing entries.
+func notInStore(path string) (*exec.Result, error) {
+	return &exec.Result{Stderr: []byte("Error: " + path + " is not in the password store."), ExitCode: 1}, errors.New("exit status 1")
+}
+
+func TestPass_Get(t *testing.T) {
+	t.Run("shows the entry", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				assert.Equal(t, "pass", opts.Name)
+				assert.Equal(t, []string{"show", "headjack/secret/repo-abc/github"}, opts.Args)
+				return &exec.Result{Stdout: []byte("ghp_test\n")}, nil
+			},
+		}
+
+		value, err := NewPass(mockExec, "").Get("secret:repo-abc:github")
+
+		require.NoError(t, err)
+		assert.Equal(t, "ghp_test", value)
+	})
+
+	t.Run("missing entry", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(context.Context, *exec.RunOptions) (*exec.Result, error) {
+				return notInStore("team/claude-credential")
+			},
+		}
+
+		_, err := NewPass(mockExec, "team/").Get("claude-credential")
+
+		assert.ErrorIs(t, err, keychain.ErrNotFound)
+	})
+}
+
+func TestPass_Set(t *testing.T) {
+	mockExec := &mocks.ExecutorMock{
+		RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+			assert.Equal(t, []string{"insert", "--multiline", "--force", "headjack/claude-credential"}, opts.Args)
+			stdin, err := io.ReadAll(opts.Stdin)
+			require.NoError(t, err)
+			assert.Equal(t, `{"type":"apikey"}`, string(stdin))
+			return &exec.Result{}, nil
+		},
+	}
+
+	require.NoError(t, NewPass(mockExec, "").Set("claude-credential", `{"type":"apikey"}`))
+	assert.Len(t, mockExec.RunCalls(), 1)
+}
+
+func TestPass_Delete(t *testing.T) {
+	t.Run("removes the entry", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				assert.Equal(t, []string{"rm", "--force", "headjack/secret/github"}, opts.Args)
+				return &exec.Result{}, nil
+			},
+		}
+
+		require.NoError(t, NewPass(mockExec, "").Delete("secret:github"))
+	})
+
+	t.Run("missing entry", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(context.Context, *exec.RunOptions) (*exec.Result, error) {
+				return notInStore("headjack/secret/github")
+			},
+		}
+
+		assert.NoError(t, NewPass(mockExec, "").Delete("secret:github"))
+	})
+}
@@ -4,9 +4,13 @@ package instance
 import (
 	"errors"
 	"fmt"
+	"regexp"
 	"time"
 
+	"github.com/jmgilman/headjack/internal/budget"
 	"github.com/jmgilman/headjack/internal/container"
+	"github.com/jmgilman/headjack/internal/logging"
+	"github.com/jmgilman/headjack/internal/meter"
 )
 
 // Sentinel errors for instance operations.
@@ -17,6 +21,8 @@ var (
 	ErrSessionExists       = errors.New("session already exists")
 	ErrInstanceNotRunning  = errors.New("instance is not running")
 	ErrNoSessionsAvailable = errors.New("no sessions available")
+	ErrBudgetExceeded      = errors.New("budget exceeded")
+	ErrNoImageMetadata     = errors.New("image metadata unavailable")
 )
 
 // NotRunningError describes an instance whose container is not running.
@@ -44,6 +50,35 @@ const (
 	StatusError   Status = "error"
 )
 
+// ImageSource identifies where an image's metadata was read from.
+type ImageSource string
+
+// Image source constants.
+const (
+	ImageSourceRegistry ImageSource = "registry" // Fetched from the image's registry
+	ImageSourceCache    ImageSource = "cache"    // Read from the metadata cache
+	ImageSourceLocal    ImageSource = "local"    // Read from the container runtime's local image store
+)
+
+// ImageInfo describes an image and its headjack labels.
+type ImageInfo struct {
+	Ref          string            // Image reference as given
+	Digest       string            // Image digest (empty for local images that were never pushed)
+	Source       ImageSource       // Where the metadata was read from
+	FetchedAt    time.Time         // When cached metadata was fetched from the registry (zero unless Source is cache)
+	Labels       map[string]string // io.headjack.* labels of the image
+	Architecture string            // CPU architecture (e.g., "amd64", "arm64")
+	OS           string            // Operating system (e.g., "linux")
+	Created      time.Time         // When the image was built (zero if unknown)
+
+	// Runtime defaults of the image config, only known for local images.
+	User       string   // User the image runs as (empty = root)
+	WorkingDir string   // Working directory (empty = /)
+	Entrypoint []string // Entrypoint
+	Cmd        []string // Default command
+	Env        []string // Environment variables (KEY=VALUE format)
+}
+
 // Instance represents a managed development environment.
 type Instance struct {
 	ID          string               // Unique instance identifier
@@ -80,20 +115,138 @@ type ListFilter struct {
 // Session represents a session within an instance (returned by Manager methods).
 // This mirrors catalog.Session but is part of the instance package's public API.
 type Session struct {
-	ID           string    // Unique session identifier
-	Name         string    // Human-readable name (e.g., "happy-panda")
-	Type         string    // Session type (shell, claude, gemini, codex)
-	MuxSessionID string    // Multiplexer session identifier
-	CreatedAt    time.Time // Creation timestamp
-	LastAccessed time.Time // Last access timestamp (for MRU tracking)
+	ID              string        // Unique session identifier
+	Name            string        // Human-readable name (e.g., "happy-panda")
+	Type            string        // Session type (shell, claude, gemini, codex)
+	MuxSessionID    string        // Multiplexer session identifier
+	CreatedAt       time.Time     // Creation timestamp
+	LastAccessed    time.Time     // Last access timestamp (for MRU tracking)
+	LastInteraction time.Time     // Last attach or input sent (zero if never)
+	MaxRuntime      time.Duration // Runtime after which the session is killed (0 = unlimited)
+	AuthProfile     string        // Credential profile the agent was started with (empty for shell)
+	Usage           meter.Usage   // Model usage as of the last refresh (see Manager.RefreshUsage)
 }
 
 // CreateSessionConfig configures session creation.
 type CreateSessionConfig struct {
-	Type               string   // Session type (shell, claude, gemini, codex)
-	Name               string   // Optional session name (auto-generated if empty)
-	Command            []string // Initial command to run (optional, defaults to shell)
-	Env                []string // Additional environment variables
-	CredentialType     string   // Credential type: "subscription" or "apikey" (empty for shell)
-	RequiresAgentSetup bool     // Whether agent needs file setup in container
+	Type               string        // Session type (shell, claude, gemini, codex)
+	Name               string        // Optional session name (auto-generated if empty)
+	Command            []string      // Initial command to run (optional, defaults to shell)
+	Prompt             string        // Initial agent prompt, if any (recorded in the audit trail)
+	Env                []string      // Additional environment variables
+	CredentialType     string        // Credential type: "subscription" or "apikey" (empty for shell)
+	AuthProfile        string        // Credential profile the credential was loaded from (empty for shell)
+	RequiresAgentSetup bool          // Whether agent needs file setup in container
+	MaxRuntime         time.Duration // Kill the session once it has run this long (0 = unlimited)
+	Transcript         bool          // Record the agent's structured events (Command must run it headless)
+}
+
+// PropagateCredentialConfig configures Manager.PropagateCredential.
+type PropagateCredentialConfig struct {
+	Auth               string   // Auth provider whose credential changed
+	Profile            string   // Credential profile that changed
+	Env                []string // Credential environment variables, as passed to CreateSessionConfig.Env
+	RequiresAgentSetup bool     // Whether the credential is written to files in the container
+}
+
+// CredentialUpdate describes how a changed credential reached a running instance.
+type CredentialUpdate struct {
+	InstanceID string
+	Repo       string
+	Branch     string
+	Updated    bool     // The credential files in the container were rewritten
+	Skipped    string   // Why the instance was left unchanged (empty if it was not skipped)
+	Err        error    // Why rewriting the credential files failed, if it did
+	Restart    []string // Sessions still running with the previous credential
+}
+
+// SendInputConfig configures input sent to a session without attaching.
+type SendInputConfig struct {
+	Text  string // Literal text to type into the session
+	Enter bool   // Press Enter after the text
+}
+
+// CaptureConfig configures a capture of a session's screen.
+type CaptureConfig struct {
+	History bool // Include scrollback above the visible screen
+	Escapes bool // Preserve colors and text attributes as ANSI escape sequences
+}
+
+// PruneLogsConfig configures a session log prune.
+type PruneLogsConfig struct {
+	Retention logging.RetentionPolicy // Limits to enforce
+	DryRun    bool                    // Report what would be removed without deleting anything
+}
+
+// SearchLogsConfig configures a search across session logs.
+type SearchLogsConfig struct {
+	Pattern *regexp.Regexp // Pattern to match against log lines (required)
+	Branch  string         // Only search instances on this branch (empty = all)
+	Agent   string         // Only search sessions of this type, e.g. "claude" (empty = all)
+	Context int            // Lines of context to include around each match
+	Since   time.Time      // Skip log segments last written before this time (zero = all)
+}
+
+// LogMatch is a search match within a session log.
+type LogMatch struct {
+	logging.Match
+
+	InstanceID string
+	Repo       string
+	Branch     string
+	SessionID  string
+	Session    string // Session name, or the session ID if the session no longer exists
+	Type       string // Session type, empty if the session no longer exists
+}
+
+// TimedOutSession describes a session killed for exceeding its runtime limit.
+type TimedOutSession struct {
+	InstanceID string
+	Repo       string
+	Branch     string
+	SessionID  string
+	Session    string        // Session name
+	Type       string        // Session type
+	MaxRuntime time.Duration // The limit that was exceeded
+	Runtime    time.Duration // How long the session had run when killed
+}
+
+// BudgetEvent describes a session that has nearly used up, or used up, a budget.
+type BudgetEvent struct {
+	InstanceID string
+	Repo       string
+	Branch     string
+	SessionID  string
+	Session    string       // Session name
+	Type       string       // Session type
+	Scope      budget.Scope // The scope of the limit
+	Limit      budget.Limit // The limit that was reached
+	Usage      meter.Usage  // Usage counted against the limit
+	Killed     bool         // True if the session was killed, false for a warning
+}
+
+// WatchdogConfig configures Manager.Watchdog.
+type WatchdogConfig struct {
+	Interval     time.Duration          // How often to check session limits
+	ExitWhenIdle bool                   // Return once no session has a runtime limit or budget left to enforce
+	OnTimeout    func(*TimedOutSession) // Called for each session killed for its runtime (optional)
+	OnBudget     func(*BudgetEvent)     // Called for each budget warning or kill (optional)
+}
+
+// UsageConfig configures a usage report.
+type UsageConfig struct {
+	Since time.Time // Only include ended sessions that ended at or after this time (zero = all)
+}
+
+// SessionUsage is the model usage of a single session.
+type SessionUsage struct {
+	InstanceID string
+	Repo       string
+	Branch     string
+	SessionID  string
+	Session    string    // Session name
+	Type       string    // Session type
+	Started    time.Time // When the session was created
+	Ended      time.Time // When the session ended (zero while it is running)
+	Usage      meter.Usage
 }
@@ -1,6 +1,7 @@
 package instance
 
 import (
+	"bytes"
 	"context"
 	"crypto/rand"
 	"encoding/hex"
@@ -9,17 +10,28 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/jmgilman/headjack/internal/agent"
+	"github.com/jmgilman/headjack/internal/audit"
+	"github.com/jmgilman/headjack/internal/auth"
+	"github.com/jmgilman/headjack/internal/budget"
 	"github.com/jmgilman/headjack/internal/catalog"
 	"github.com/jmgilman/headjack/internal/container"
 	"github.com/jmgilman/headjack/internal/flags"
 	"github.com/jmgilman/headjack/internal/git"
 	"github.com/jmgilman/headjack/internal/logging"
+	"github.com/jmgilman/headjack/internal/meter"
 	"github.com/jmgilman/headjack/internal/multiplexer"
 	"github.com/jmgilman/headjack/internal/names"
+	"github.com/jmgilman/headjack/internal/notify"
 	"github.com/jmgilman/headjack/internal/registry"
+	"github.com/jmgilman/headjack/internal/secret"
+	"github.com/jmgilman/headjack/internal/transcript"
+	"github.com/jmgilman/headjack/internal/usage"
 )
 
 // containerNamePrefix is the prefix for all managed containers.
@@ -31,6 +43,7 @@ type catalogStore interface {
 	Get(ctx context.Context, id string) (*catalog.Entry, error)
 	GetByRepoBranch(ctx context.Context, repoID, branch string) (*catalog.Entry, error)
 	Update(ctx context.Context, entry *catalog.Entry) error
+	Modify(ctx context.Context, id string, fn func(*catalog.Entry) error) error
 	Remove(ctx context.Context, id string) error
 	List(ctx context.Context, filter catalog.ListFilter) ([]catalog.Entry, error)
 }
@@ -44,6 +57,7 @@ type containerRuntime interface {
 	Remove(ctx context.Context, id string) error
 	Get(ctx context.Context, id string) (*container.Container, error)
 	List(ctx context.Context, filter container.ListFilter) ([]container.Container, error)
+	InspectImage(ctx context.Context, ref string) (*container.Image, error)
 	ExecCommand() []string
 }
 
@@ -58,6 +72,8 @@ type sessionMultiplexer interface {
 	AttachSession(ctx context.Context, sessionName string) error
 	ListSessions(ctx context.Context) ([]multiplexer.Session, error)
 	KillSession(ctx context.Context, sessionName string) error
+	SendKeys(ctx context.Context, sessionName string, opts *multiplexer.SendKeysOpts) error
+	CapturePane(ctx context.Context, sessionName string, opts *multiplexer.CaptureOpts) (string, error)
 }
 
 // registryClient is the internal interface for fetching image metadata.
@@ -81,19 +97,91 @@ type ManagerConfig struct {
 	LogsDir      string      // Directory for storing logs (e.g., ~/.local/share/headjack/logs)
 	RuntimeType  RuntimeType // Container runtime type (docker, podman, or apple)
 	ConfigFlags  flags.Flags // Flags from config file (take precedence over image labels)
+
+	// LogCommand is a command prefix that receives session output on stdin
+	// and writes it to the log path appended as its final argument, e.g. to
+	// rotate logs (optional, nil = append output to the log directly).
+	LogCommand []string
+
+	// TranscriptCommand is a command prefix that receives the output of
+	// transcript mode sessions on stdin, writes it to the log like
+	// LogCommand, and records the agent's events in the session's transcript.
+	// The agent's transcript format, transcript path, and log path are
+	// appended as its final arguments (optional, nil = transcripts are not
+	// recorded).
+	TranscriptCommand []string
+
+	// LogRetention limits the session log history kept across all instances.
+	// It is enforced after each new session is created.
+	LogRetention logging.RetentionPolicy
+
+	// Auditor records lifecycle events to the audit trail (optional, nil = disabled).
+	Auditor audit.Recorder
+
+	// Notifier is told about sessions headjack kills on its own, such as on
+	// timeout (optional, nil = no notifications).
+	Notifier notify.Notifier
+
+	// UsageLedger records the final usage of sessions as they end
+	// (optional, nil = usage is only tracked for running sessions).
+	UsageLedger usage.Recorder
+
+	// Agents defines the agents sessions can run (optional, nil = built-in
+	// agents only).
+	Agents *agent.Registry
+
+	// Secrets resolves secret:// references in session environments
+	// (optional, nil = sessions referencing secrets fail to start).
+	Secrets secret.Resolver
+
+	// Budgets limits the usage of each agent, keyed by session type
+	// (optional, nil = no budgets).
+	Budgets map[string]budget.Policy
+
+	// BudgetState persists budget warnings and used-up daily budgets across
+	// processes (optional, nil = state is not persisted).
+	BudgetState budget.Store
+
+	// StartWatchdog launches a background process that runs Watchdog. It is
+	// called whenever a session with a runtime limit or budget is created and
+	// must be safe to call while a watchdog is already running (optional,
+	// nil = limits are only enforced by explicit calls to EnforceTimeouts
+	// and EnforceBudgets).
+	StartWatchdog func(context.Context) error
 }
 
 // Manager orchestrates instance lifecycle operations.
 type Manager struct {
-	catalog      catalogStore
-	runtime      containerRuntime
-	git          gitOpener
-	mux          sessionMultiplexer
-	registry     registryClient
-	logPaths     *logging.PathManager
-	worktreesDir string
-	runtimeType  RuntimeType
-	configFlags  flags.Flags
+	catalog       catalogStore
+	runtime       containerRuntime
+	git           gitOpener
+	mux           sessionMultiplexer
+	registry      registryClient
+	logPaths      *logging.PathManager
+	worktreesDir  string
+	runtimeType   RuntimeType
+	configFlags   flags.Flags
+	auditor       audit.Recorder
+	logCommand    []string
+	transcriptCmd []string
+	logRetention  logging.RetentionPolicy
+	notifier      notify.Notifier
+	usageLedger   usage.Recorder
+	agents        *agent.Registry
+	secrets       secret.Resolver
+	budgets       map[string]budget.Policy
+	budgetState   budget.Store
+	startWatchdog func(context.Context) error
+
+	costMu sync.Mutex
+	costs  map[string]reportedCost // Costs read from each session's transcript so far
+}
+
+// reportedCost is the cost an agent reported in a session's transcript up to
+// an offset, so later reads only need the events after it.
+type reportedCost struct {
+	offset int64
+	cost   float64
 }
 
 // NewManager creates a new instance manager.
@@ -103,16 +191,43 @@ func NewManager(store catalogStore, runtime containerRuntime, opener gitOpener,
 		runtimeType = RuntimeDocker
 	}
 
+	agents := cfg.Agents
+	if agents == nil {
+		agents = agent.DefaultRegistry()
+	}
+
 	return &Manager{
-		catalog:      store,
-		runtime:      runtime,
-		git:          opener,
-		mux:          mux,
-		registry:     reg,
-		logPaths:     logging.NewPathManager(cfg.LogsDir),
-		worktreesDir: cfg.WorktreesDir,
-		runtimeType:  runtimeType,
-		configFlags:  cfg.ConfigFlags,
+		catalog:       store,
+		runtime:       runtime,
+		git:           opener,
+		mux:           mux,
+		registry:      reg,
+		logPaths:      logging.NewPathManager(cfg.LogsDir),
+		worktreesDir:  cfg.WorktreesDir,
+		runtimeType:   runtimeType,
+		configFlags:   cfg.ConfigFlags,
+		auditor:       cfg.Auditor,
+		logCommand:    cfg.LogCommand,
+		transcriptCmd: cfg.TranscriptCommand,
+		logRetention:  cfg.LogRetention,
+		notifier:      cfg.Notifier,
+		usageLedger:   cfg.UsageLedger,
+		agents:        agents,
+		secrets:       cfg.Secrets,
+		budgets:       cfg.Budgets,
+		budgetState:   cfg.BudgetState,
+		startWatchdog: cfg.StartWatchdog,
+	}
+}
+
+// recordAudit writes an event to the audit trail if auditing is enabled.
+// Audit failures never fail the audited operation; they are reported as warnings.
+func (m *Manager) recordAudit(ctx context.Context, event *audit.Event) {
+	if m.auditor == nil {
+		return
+	}
+	if err := m.auditor.Record(ctx, event); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to write audit event %s: %v\n", event.Action, err)
 	}
 }
 
@@ -124,14 +239,84 @@ type imageRuntimeConfig struct {
 
 // Label constants for image runtime configuration.
 const (
+	labelPrefix      = "io.headjack."
 	labelInit        = "io.headjack.init"
 	labelPodmanFlags = "io.headjack.podman.flags"
 	labelAppleFlags  = "io.headjack.apple.flags"
 	labelDockerFlags = "io.headjack.docker.flags"
 )
 
-// getImageRuntimeConfig fetches image metadata and extracts runtime configuration from labels.
-// Returns default values if the registry client is nil or metadata cannot be fetched.
+// InspectImage resolves the headjack labels of an image. An image present in
+// the runtime's local image store is what a new container runs, so its labels
+// are preferred; this also covers images that were built locally and never
+// pushed. Other images are looked up with the registry client, which may
+// serve them from its metadata cache.
+// Returns ErrNoImageMetadata if no source has metadata of the image.
+func (m *Manager) InspectImage(ctx context.Context, image string) (*ImageInfo, error) {
+	var errs []error
+
+	if m.runtime != nil {
+		local, err := m.runtime.InspectImage(ctx, image)
+		if err == nil {
+			return &ImageInfo{
+				Ref:          image,
+				Digest:       local.Digest,
+				Source:       ImageSourceLocal,
+				Labels:       headjackLabels(local.Labels),
+				Architecture: local.Architecture,
+				OS:           local.OS,
+				Created:      local.Created,
+				User:         local.User,
+				WorkingDir:   local.WorkingDir,
+				Entrypoint:   local.Entrypoint,
+				Cmd:          local.Cmd,
+				Env:          local.Env,
+			}, nil
+		}
+		errs = append(errs, fmt.Errorf("local image: %w", err))
+	}
+
+	if m.registry != nil {
+		metadata, err := m.registry.GetMetadata(ctx, image)
+		if err == nil {
+			info := &ImageInfo{
+				Ref:          image,
+				Digest:       metadata.Digest,
+				Source:       ImageSourceRegistry,
+				Labels:       headjackLabels(metadata.Labels),
+				Architecture: metadata.Architecture,
+				OS:           metadata.OS,
+				Created:      metadata.Created,
+			}
+			if metadata.Cached {
+				info.Source = ImageSourceCache
+				info.FetchedAt = metadata.FetchedAt
+			}
+			return info, nil
+		}
+		errs = append(errs, fmt.Errorf("registry: %w", err))
+	}
+
+	if len(errs) == 0 {
+		return nil, ErrNoImageMetadata
+	}
+	return nil, fmt.Errorf("%w: %w", ErrNoImageMetadata, errors.Join(errs...))
+}
+
+// headjackLabels returns the io.headjack.* labels of an image.
+func headjackLabels(labels map[string]string) map[string]string {
+	result := make(map[string]string)
+	for k, v := range labels {
+		if strings.HasPrefix(k, labelPrefix) {
+			result[k] = v
+		}
+	}
+	return result
+}
+
+// getImageRuntimeConfig resolves image metadata and extracts runtime configuration from labels.
+// Returns default values if the image has no metadata in the local image store, the registry,
+// or the metadata cache.
 // Runtime-specific flags are extracted based on the configured runtime type:
 // - Podman: io.headjack.podman.flags
 // - Apple: io.headjack.apple.flags
@@ -141,11 +326,11 @@ func (m *Manager) getImageRuntimeConfig(ctx context.Context, image string) image
 		Init: "", // Empty means runtime will use default "sleep infinity"
 	}
 
-	if m.registry == nil {
+	if m.registry == nil && m.runtime == nil {
 		return cfg
 	}
 
-	metadata, err := m.registry.GetMetadata(ctx, image)
+	info, err := m.InspectImage(ctx, image)
 	if err != nil {
 		// Log warning - image will run with defaults (sleep infinity, no special flags)
 		// This may cause systemd images to fail if they require --systemd=always
@@ -153,29 +338,27 @@ func (m *Manager) getImageRuntimeConfig(ctx context.Context, image string) image
 		return cfg
 	}
 
-	if metadata.Labels != nil {
-		if v, ok := metadata.Labels[labelInit]; ok {
-			cfg.Init = v
-		}
-		// Extract runtime-specific flags based on runtime type
-		var flagsLabel string
-		switch m.runtimeType {
-		case RuntimePodman:
-			flagsLabel = labelPodmanFlags
-		case RuntimeApple:
-			flagsLabel = labelAppleFlags
-		case RuntimeDocker:
-			flagsLabel = labelDockerFlags
-		}
-		if flagsLabel != "" {
-			if v, ok := metadata.Labels[flagsLabel]; ok {
-				parsedFlags, parseErr := flags.FromLabel(v)
-				if parseErr != nil {
-					fmt.Fprintf(os.Stderr, "warning: failed to parse %s flags from image %s: %v\n",
-						m.runtimeType, image, parseErr)
-				} else {
-					cfg.Flags = parsedFlags
-				}
+	if v, ok := info.Labels[labelInit]; ok {
+		cfg.Init = v
+	}
+	// Extract runtime-specific flags based on runtime type
+	var flagsLabel string
+	switch m.runtimeType {
+	case RuntimePodman:
+		flagsLabel = labelPodmanFlags
+	case RuntimeApple:
+		flagsLabel = labelAppleFlags
+	case RuntimeDocker:
+		flagsLabel = labelDockerFlags
+	}
+	if flagsLabel != "" {
+		if v, ok := info.Labels[flagsLabel]; ok {
+			parsedFlags, parseErr := flags.FromLabel(v)
+			if parseErr != nil {
+				fmt.Fprintf(os.Stderr, "warning: failed to parse %s flags from image %s: %v\n",
+					m.runtimeType, image, parseErr)
+			} else {
+				cfg.Flags = parsedFlags
 			}
 		}
 	}
@@ -286,6 +469,14 @@ func (m *Manager) Create(ctx context.Context, repoPath string, cfg CreateConfig)
 		return nil, fmt.Errorf("update catalog entry: %w", updateErr)
 	}
 
+	m.recordAudit(ctx, &audit.Event{
+		Action:     audit.ActionInstanceCreate,
+		InstanceID: id,
+		Repo:       entry.Repo,
+		Branch:     entry.Branch,
+		Image:      cfg.Image,
+	})
+
 	return &Instance{
 		ID:          id,
 		Repo:        repo.Root(),
@@ -375,6 +566,13 @@ func (m *Manager) Stop(ctx context.Context, id string) error {
 		return fmt.Errorf("update catalog entry: %w", err)
 	}
 
+	m.recordAudit(ctx, &audit.Event{
+		Action:     audit.ActionInstanceStop,
+		InstanceID: entry.ID,
+		Repo:       entry.Repo,
+		Branch:     entry.Branch,
+	})
+
 	return nil
 }
 
@@ -461,6 +659,12 @@ func (m *Manager) shutdownContainer(ctx context.Context, entry *catalog.Entry, o
 	// The container stop will fail with "Resource busy" if there are active
 	// multiplexer sessions connected to processes inside the container.
 	if m.mux != nil && len(entry.Sessions) > 0 {
+		ended := make([]string, 0, len(entry.Sessions))
+		for _, sess := range entry.Sessions {
+			ended = append(ended, sess.ID)
+		}
+		m.recordEndedUsage(ctx, entry, ended)
+
 		for _, sess := range entry.Sessions {
 			// Best-effort kill - session may already be dead
 			_ = m.mux.KillSession(ctx, sess.MuxSessionID) //nolint:errcheck
@@ -577,6 +781,13 @@ func (m *Manager) Remove(ctx context.Context, id string) error {
 		return fmt.Errorf("remove catalog entry: %w", err)
 	}
 
+	m.recordAudit(ctx, &audit.Event{
+		Action:     audit.ActionInstanceRemove,
+		InstanceID: entry.ID,
+		Repo:       entry.Repo,
+		Branch:     entry.Branch,
+	})
+
 	return nil
 }
 
@@ -624,6 +835,14 @@ func (m *Manager) Recreate(ctx context.Context, id, image string) (*Instance, er
 		return nil, fmt.Errorf("update catalog entry: %w", err)
 	}
 
+	m.recordAudit(ctx, &audit.Event{
+		Action:     audit.ActionInstanceRecreate,
+		InstanceID: entry.ID,
+		Repo:       entry.Repo,
+		Branch:     entry.Branch,
+		Image:      image,
+	})
+
 	return &Instance{
 		ID:          entry.ID,
 		Repo:        entry.Repo,
@@ -783,11 +1002,15 @@ func resolveSessionName(sessions []catalog.Session, name string) (string, error)
 // The session is created in detached mode within the container's multiplexer.
 // If cfg.Name is empty, a unique name is auto-generated.
 func (m *Manager) CreateSession(ctx context.Context, instanceID string, cfg *CreateSessionConfig) (*Session, error) {
-	entry, err := m.getRunningInstance(ctx, instanceID)
+	entry, ctr, err := m.getRunningInstance(ctx, instanceID)
 	if err != nil {
 		return nil, err
 	}
 
+	if budgetErr := m.checkBudget(ctx, entry, cfg.Type, time.Now()); budgetErr != nil {
+		return nil, budgetErr
+	}
+
 	sessionID, err := generateID()
 	if err != nil {
 		return nil, fmt.Errorf("generate session ID: %w", err)
@@ -814,15 +1037,22 @@ func (m *Manager) CreateSession(ctx context.Context, instanceID string, cfg *Cre
 		sessionType = catalog.SessionTypeShell
 	}
 
+	// Resolve secret references only now, so their values never reach the
+	// catalog or the audit trail
+	env, err := secret.ResolveEnv(m.secrets, entry.RepoID, cfg.Env)
+	if err != nil {
+		return nil, fmt.Errorf("resolve session environment: %w", err)
+	}
+
 	// Run agent-specific setup before starting the session
-	if setupErr := m.runAgentSetup(ctx, entry.ContainerID, sessionType, cfg.Env, cfg.RequiresAgentSetup); setupErr != nil {
+	if setupErr := m.runAgentSetup(ctx, entry.ContainerID, sessionType, env, cfg.RequiresAgentSetup); setupErr != nil {
 		return nil, fmt.Errorf("agent setup: %w", setupErr)
 	}
 
 	// Build the command to execute inside the container
 	// The multiplexer runs on the host, so we wrap the command with the runtime's exec command
 	execCmd := append(m.runtime.ExecCommand(), "-it", "-w", "/workspace")
-	for _, e := range cfg.Env {
+	for _, e := range env {
 		execCmd = append(execCmd, "-e", e)
 	}
 	execCmd = append(execCmd, entry.ContainerID)
@@ -835,11 +1065,24 @@ func (m *Manager) CreateSession(ctx context.Context, instanceID string, cfg *Cre
 
 	// Create multiplexer session with logging
 	// The multiplexer runs on the host, executing the runtime's exec command to run inside the container
+	var logCommand []string
+	var transcriptFormat string
+	if def, defErr := m.agents.Get(string(sessionType)); defErr == nil {
+		transcriptFormat = def.TranscriptFormat
+	}
+	switch {
+	case cfg.Transcript && transcriptFormat != "" && len(m.transcriptCmd) > 0:
+		transcriptPath := m.logPaths.TranscriptPath(instanceID, sessionID)
+		logCommand = append(slices.Clone(m.transcriptCmd), transcriptFormat, transcriptPath, logPath)
+	case len(m.logCommand) > 0:
+		logCommand = append(slices.Clone(m.logCommand), logPath)
+	}
 	_, err = m.mux.CreateSession(ctx, &multiplexer.CreateSessionOpts{
-		Name:    muxSessionName,
-		Command: execCmd,
-		Cwd:     entry.Worktree,
-		LogPath: logPath,
+		Name:       muxSessionName,
+		Command:    execCmd,
+		Cwd:        entry.Worktree,
+		LogPath:    logPath,
+		LogCommand: logCommand,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("create multiplexer session: %w", err)
@@ -853,6 +1096,8 @@ func (m *Manager) CreateSession(ctx context.Context, instanceID string, cfg *Cre
 		MuxSessionID: muxSessionName,
 		CreatedAt:    now,
 		LastAccessed: now,
+		MaxRuntime:   cfg.MaxRuntime,
+		AuthProfile:  cfg.AuthProfile,
 	}
 
 	entry.Sessions = append(entry.Sessions, catSession)
@@ -867,6 +1112,33 @@ func (m *Manager) CreateSession(ctx context.Context, instanceID string, cfg *Cre
 		return nil, fmt.Errorf("update catalog entry: %w", updateErr)
 	}
 
+	m.recordAudit(ctx, &audit.Event{
+		Action:         audit.ActionSessionCreate,
+		InstanceID:     entry.ID,
+		Repo:           entry.Repo,
+		Branch:         entry.Branch,
+		SessionID:      sessionID,
+		Session:        sessionName,
+		Agent:          string(sessionType),
+		Image:          ctr.Image,
+		Prompt:         cfg.Prompt,
+		CredentialType: cfg.CredentialType,
+		AuthProfile:    cfg.AuthProfile,
+		Env:            cfg.Env,
+	})
+
+	// Enforce retention now that the log directory has grown
+	if m.logRetention.MaxAge > 0 || m.logRetention.MaxTotalSize > 0 {
+		_, _ = m.PruneLogs(ctx, &PruneLogsConfig{Retention: m.logRetention}) //nolint:errcheck // retention is best-effort
+	}
+
+	// Make sure something is around to enforce the runtime limit and budget
+	if (cfg.MaxRuntime > 0 || !m.budgets[cfg.Type].IsZero()) && m.startWatchdog != nil {
+		if err := m.startWatchdog(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: failed to start session watchdog: %v\n", err)
+		}
+	}
+
 	return &Session{
 		ID:           sessionID,
 		Name:         sessionName,
@@ -874,87 +1146,545 @@ func (m *Manager) CreateSession(ctx context.Context, instanceID string, cfg *Cre
 		MuxSessionID: muxSessionName,
 		CreatedAt:    now,
 		LastAccessed: now,
+		MaxRuntime:   cfg.MaxRuntime,
+		AuthProfile:  cfg.AuthProfile,
 	}, nil
 }
 
-// runAgentSetup performs agent-specific setup before starting a session.
-// For Claude, this creates the config file needed to skip onboarding.
-// For Gemini/Codex with subscription auth, this writes OAuth credentials to file locations.
-// API key auth skips file setup since credentials are passed via environment variables.
-func (m *Manager) runAgentSetup(ctx context.Context, containerID string, sessionType catalog.SessionType, env []string, requiresSetup bool) error {
-	switch sessionType {
-	case catalog.SessionTypeClaude:
-		// Always create ~/.claude.json with hasCompletedOnboarding to skip interactive setup.
-		// This is required for both OAuth token and API key authentication in headless environments.
-		// See: https://github.com/anthropics/claude-code/issues/8938
-		setupCmd := `mkdir -p ~/.claude && echo '{"hasCompletedOnboarding":true}' > ~/.claude.json`
-		return m.runtime.Exec(ctx, containerID, container.ExecConfig{
-			Command: []string{"sh", "-c", setupCmd},
+// PruneLogs applies a retention policy to session logs. The live logs of
+// sessions still in the catalog are never removed, only their rotated segments.
+func (m *Manager) PruneLogs(ctx context.Context, cfg *PruneLogsConfig) ([]logging.PrunedFile, error) {
+	entries, err := m.catalog.List(ctx, catalog.ListFilter{})
+	if err != nil {
+		return nil, fmt.Errorf("list catalog entries: %w", err)
+	}
+
+	active := make(map[string]bool)
+	for i := range entries {
+		for _, sess := range entries[i].Sessions {
+			active[entries[i].ID+"/"+sess.ID] = true
+		}
+	}
+
+	pruned, err := m.logPaths.Prune(&logging.PruneOpts{
+		Policy: cfg.Retention,
+		Active: func(instanceID, sessionID string) bool {
+			return active[instanceID+"/"+sessionID]
+		},
+		DryRun: cfg.DryRun,
+	})
+	if err != nil {
+		return pruned, fmt.Errorf("prune logs: %w", err)
+	}
+	return pruned, nil
+}
+
+// SearchLogs searches the logs of every instance in the catalog, calling fn
+// for each match in instance and session order. Logs left behind by sessions
+// that no longer exist are searched too, unless filtering by agent. Returning
+// an error from fn stops the search.
+func (m *Manager) SearchLogs(ctx context.Context, cfg *SearchLogsConfig, fn func(*LogMatch) error) error {
+	entries, err := m.catalog.List(ctx, catalog.ListFilter{})
+	if err != nil {
+		return fmt.Errorf("list catalog entries: %w", err)
+	}
+
+	reader := logging.NewReader(m.logPaths)
+	opts := &logging.SearchOpts{Pattern: cfg.Pattern, Context: cfg.Context, Since: cfg.Since}
+
+	for i := range entries {
+		entry := &entries[i]
+		if cfg.Branch != "" && entry.Branch != cfg.Branch {
+			continue
+		}
+
+		sessionIDs, err := m.logPaths.ListSessionLogs(entry.ID)
+		if err != nil {
+			return err
+		}
+
+		for _, sess := range logSearchSessions(entry, sessionIDs) {
+			if cfg.Agent != "" && string(sess.Type) != cfg.Agent {
+				continue
+			}
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			err := reader.Search(entry.ID, sess.ID, opts, func(match *logging.Match) error {
+				return fn(&LogMatch{
+					Match:      *match,
+					InstanceID: entry.ID,
+					Repo:       entry.Repo,
+					Branch:     entry.Branch,
+					SessionID:  sess.ID,
+					Session:    sess.Name,
+					Type:       string(sess.Type),
+				})
+			})
+			if err != nil {
+				return fmt.Errorf("search session %s: %w", sess.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// logSearchSessions pairs the sessions with logs on disk with their catalog
+// records: catalog sessions first, in creation order, then sessions that no
+// longer exist, named by ID.
+func logSearchSessions(entry *catalog.Entry, sessionIDs []string) []catalog.Session {
+	onDisk := make(map[string]bool, len(sessionIDs))
+	for _, id := range sessionIDs {
+		onDisk[id] = true
+	}
+
+	sessions := make([]catalog.Session, 0, len(sessionIDs))
+	for _, sess := range entry.Sessions {
+		if onDisk[sess.ID] {
+			sessions = append(sessions, sess)
+			delete(onDisk, sess.ID)
+		}
+	}
+
+	gone := make([]string, 0, len(onDisk))
+	for id := range onDisk {
+		gone = append(gone, id)
+	}
+	slices.Sort(gone)
+	for _, id := range gone {
+		sessions = append(sessions, catalog.Session{ID: id, Name: id})
+	}
+	return sessions
+}
+
+// usageClockSkew is how far an agent session file may appear to start before
+// the headjack session that ran it, to allow for clock differences between
+// the host and the container.
+const usageClockSkew = 30 * time.Second
+
+// RefreshUsage reads the usage of every agent session in running instances
+// from the agents' session files and stores it on the sessions. Instances
+// that cannot be read are skipped and reported in the returned error.
+func (m *Manager) RefreshUsage(ctx context.Context) error {
+	entries, err := m.catalog.List(ctx, catalog.ListFilter{Status: catalog.StatusRunning})
+	if err != nil {
+		return fmt.Errorf("list catalog entries: %w", err)
+	}
+
+	var errs []error
+	for i := range entries {
+		entry := &entries[i]
+		changed, refreshErr := m.refreshUsage(ctx, entry)
+		if refreshErr != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", entry.Branch, refreshErr))
+		}
+		if !changed {
+			continue
+		}
+		if updateErr := m.saveUsage(ctx, entry); updateErr != nil {
+			errs = append(errs, fmt.Errorf("%s: update catalog entry: %w", entry.Branch, updateErr))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Usage returns the usage of running sessions, refreshed first, and of ended
+// sessions from the usage ledger. A failed refresh is reported as a warning
+// and the last known usage is returned.
+func (m *Manager) Usage(ctx context.Context, cfg *UsageConfig) ([]SessionUsage, error) {
+	if err := m.RefreshUsage(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to refresh usage: %v\n", err)
+	}
+
+	entries, err := m.catalog.List(ctx, catalog.ListFilter{})
+	if err != nil {
+		return nil, fmt.Errorf("list catalog entries: %w", err)
+	}
+
+	var report []SessionUsage
+	for _, entry := range entries {
+		for _, s := range entry.Sessions {
+			if m.usageAgent(s.Type) == nil && s.Usage.IsZero() {
+				continue
+			}
+			report = append(report, SessionUsage{
+				InstanceID: entry.ID,
+				Repo:       entry.Repo,
+				Branch:     entry.Branch,
+				SessionID:  s.ID,
+				Session:    s.Name,
+				Type:       string(s.Type),
+				Started:    s.CreatedAt,
+				Usage:      s.Usage,
+			})
+		}
+	}
+
+	if m.usageLedger == nil {
+		return report, nil
+	}
+	records, err := m.usageLedger.Query(usage.Filter{Since: cfg.Since})
+	if err != nil {
+		return nil, fmt.Errorf("read usage ledger: %w", err)
+	}
+	for _, r := range records {
+		report = append(report, SessionUsage{
+			InstanceID: r.InstanceID,
+			Repo:       r.Repo,
+			Branch:     r.Branch,
+			SessionID:  r.SessionID,
+			Session:    r.Session,
+			Type:       r.Agent,
+			Started:    r.Started,
+			Ended:      r.Time,
+			Usage:      r.Usage,
 		})
+	}
+	return report, nil
+}
 
-	case catalog.SessionTypeGemini:
-		// Skip file setup for API key auth - credentials are in GEMINI_API_KEY env var
-		if !requiresSetup {
-			return nil
+// refreshUsage updates the usage of entry's agent sessions in place from the
+// agents' session files in its container, reporting whether entry changed.
+//
+// Each file is attributed to the session of the same agent that was created
+// most recently before the file started, and keeps that owner from then on.
+// Files owned by sessions that have ended are no longer counted.
+func (m *Manager) refreshUsage(ctx context.Context, entry *catalog.Entry) (bool, error) {
+	if entry.Status != catalog.StatusRunning || entry.ContainerID == "" {
+		return false, nil
+	}
+
+	var agents []string
+	for _, s := range entry.Sessions {
+		if m.usageAgent(s.Type) != nil && !slices.Contains(agents, string(s.Type)) {
+			agents = append(agents, string(s.Type))
 		}
-		// Write Gemini config files from env vars for subscription auth.
-		// GEMINI_OAUTH_CREDS contains JSON with oauth_creds and google_accounts.
-		// We also write a minimal settings.json to set the auth type.
-		setupCmd := `mkdir -p ~/.gemini && \
-echo "$GEMINI_OAUTH_CREDS" | jq -r '.oauth_creds' > ~/.gemini/oauth_creds.json && \
-echo "$GEMINI_OAUTH_CREDS" | jq -r '.google_accounts' > ~/.gemini/google_accounts.json && \
-echo '{"security":{"auth":{"selectedType":"oauth-personal"}}}' > ~/.gemini/settings.json`
-		return m.runtime.Exec(ctx, containerID, container.ExecConfig{
-			Command: []string{"sh", "-c", setupCmd},
-			Env:     env,
+	}
+	if len(agents) == 0 {
+		return false, nil
+	}
+
+	changed := false
+	totals := make(map[string]meter.Usage)
+	for _, name := range agents {
+		files, err := m.readUsageFiles(ctx, entry.ContainerID, m.usageAgent(catalog.SessionType(name)))
+		if err != nil {
+			return false, err
+		}
+		for _, f := range files {
+			owner, claimed := entry.UsageFiles[f.Path]
+			if !claimed {
+				owner = usageOwner(entry.Sessions, name, f.Start)
+				if owner == "" {
+					continue
+				}
+				if entry.UsageFiles == nil {
+					entry.UsageFiles = make(map[string]string)
+				}
+				entry.UsageFiles[f.Path] = owner
+				changed = true
+			}
+			total := totals[owner]
+			total.Add(f.Usage)
+			totals[owner] = total
+		}
+	}
+
+	for i := range entry.Sessions {
+		s := &entry.Sessions[i]
+		if !slices.Contains(agents, string(s.Type)) {
+			continue
+		}
+		total := totals[s.ID]
+		// Headless agents report their cost only in their output
+		if total.CostUSD == 0 {
+			total.CostUSD = m.transcriptCost(entry.ID, s.ID)
+		}
+		if total != s.Usage {
+			s.Usage = total
+			changed = true
+		}
+	}
+	return changed, nil
+}
+
+// usageAgent returns the definition of a session type's agent if its usage
+// can be read, or nil.
+func (m *Manager) usageAgent(sessionType catalog.SessionType) *agent.Definition {
+	def, err := m.agents.Get(string(sessionType))
+	if err != nil || !usage.Supported(def) {
+		return nil
+	}
+	return def
+}
+
+// readUsageFiles reads the usage recorded in an agent's session files inside a container.
+func (m *Manager) readUsageFiles(ctx context.Context, containerID string, def *agent.Definition) ([]usage.File, error) {
+	command, err := usage.DumpCommand(def)
+	if err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	if err := m.runtime.Exec(ctx, containerID, container.ExecConfig{Command: command, Stdout: &out}); err != nil {
+		return nil, fmt.Errorf("read %s session files: %w", def.Name, err)
+	}
+	files, err := usage.ParseDump(&out, def.UsageFormat)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s session files: %w", def.Name, err)
+	}
+	return files, nil
+}
+
+// transcriptCost sums the costs an agent reported in a session's transcript.
+// Only the events recorded since the last call are read.
+func (m *Manager) transcriptCost(instanceID, sessionID string) float64 {
+	m.costMu.Lock()
+	defer m.costMu.Unlock()
+
+	seen := m.costs[sessionID]
+	events, offset, err := transcript.ReadFrom(m.logPaths.TranscriptPath(instanceID, sessionID), seen.offset)
+	if err != nil {
+		// A missing or unreadable transcript simply has no new reported cost
+		return seen.cost
+	}
+	for _, e := range events {
+		if e.Kind == transcript.KindResult {
+			seen.cost += e.CostUSD
+		}
+	}
+	seen.offset = offset
+
+	if m.costs == nil {
+		m.costs = make(map[string]reportedCost)
+	}
+	m.costs[sessionID] = seen
+	return seen.cost
+}
+
+// forgetCosts drops the cached transcript costs of ended sessions.
+func (m *Manager) forgetCosts(sessionIDs []string) {
+	m.costMu.Lock()
+	defer m.costMu.Unlock()
+	for _, id := range sessionIDs {
+		delete(m.costs, id)
+	}
+}
+
+// usageOwner returns the ID of the agent session created most recently before
+// start, or "" if there is none.
+func usageOwner(sessions []catalog.Session, agent string, start time.Time) string {
+	if start.IsZero() {
+		return ""
+	}
+	var owner *catalog.Session
+	for i := range sessions {
+		s := &sessions[i]
+		if string(s.Type) != agent || s.CreatedAt.After(start.Add(usageClockSkew)) {
+			continue
+		}
+		if owner == nil || s.CreatedAt.After(owner.CreatedAt) {
+			owner = s
+		}
+	}
+	if owner == nil {
+		return ""
+	}
+	return owner.ID
+}
+
+// saveUsage stores the usage refreshed into entry in the catalog. Only the
+// usage is merged into the current entry, so sessions created or ended by
+// other processes since entry was read are kept as they are.
+func (m *Manager) saveUsage(ctx context.Context, entry *catalog.Entry) error {
+	return m.catalog.Modify(ctx, entry.ID, func(current *catalog.Entry) error {
+		mergeUsage(current, entry)
+		return nil
+	})
+}
+
+// mergeUsage copies the usage of from's sessions to the sessions of to with
+// the same IDs, and adds the usage file owners to does not have yet.
+func mergeUsage(to, from *catalog.Entry) {
+	for path, owner := range from.UsageFiles {
+		if _, ok := to.UsageFiles[path]; ok {
+			continue
+		}
+		if to.UsageFiles == nil {
+			to.UsageFiles = make(map[string]string)
+		}
+		to.UsageFiles[path] = owner
+	}
+	for i := range to.Sessions {
+		j := slices.IndexFunc(from.Sessions, func(s catalog.Session) bool { return s.ID == to.Sessions[i].ID })
+		if j >= 0 {
+			to.Sessions[i].Usage = from.Sessions[j].Usage
+		}
+	}
+}
+
+// recordEndedUsage refreshes entry's usage and appends the final usage of the
+// sessions about to be removed from it to the usage ledger. It is
+// best-effort: if the refresh fails, the last known usage is recorded.
+// The caller is responsible for persisting entry.
+func (m *Manager) recordEndedUsage(ctx context.Context, entry *catalog.Entry, sessionIDs []string) {
+	defer m.forgetCosts(sessionIDs)
+	if m.usageLedger == nil {
+		return
+	}
+	if _, err := m.refreshUsage(ctx, entry); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to refresh usage for %s: %v\n", entry.Branch, err)
+	}
+
+	for _, s := range entry.Sessions {
+		if !slices.Contains(sessionIDs, s.ID) || s.Usage.IsZero() {
+			continue
+		}
+		err := m.usageLedger.Record(ctx, &usage.Record{
+			InstanceID: entry.ID,
+			Repo:       entry.Repo,
+			Branch:     entry.Branch,
+			SessionID:  s.ID,
+			Session:    s.Name,
+			Agent:      string(s.Type),
+			Started:    s.CreatedAt,
+			Usage:      s.Usage,
 		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: failed to record usage for session %s: %v\n", s.Name, err)
+		}
+	}
+}
 
-	case catalog.SessionTypeCodex:
-		// Skip file setup for API key auth - credentials are in OPENAI_API_KEY env var
-		if !requiresSetup {
-			return nil
+// runAgentSetup runs the agent's setup scripts in the container before
+// starting a session. The setup script always runs; the auth setup script
+// runs only when the session's credential must be written to files (API keys
+// are passed via environment variables and need no setup). Shell sessions and
+// unknown agents need no setup.
+func (m *Manager) runAgentSetup(ctx context.Context, containerID string, sessionType catalog.SessionType, env []string, requiresSetup bool) error {
+	def, err := m.agents.Get(string(sessionType))
+	if err != nil {
+		return nil
+	}
+
+	if def.Setup != "" {
+		if setupErr := m.runtime.Exec(ctx, containerID, container.ExecConfig{
+			Command: []string{"sh", "-c", def.Setup},
+			Env:     env,
+		}); setupErr != nil {
+			return setupErr
 		}
-		// Write Codex auth.json from env var for subscription auth.
-		// CODEX_AUTH_JSON contains the contents of ~/.codex/auth.json.
-		setupCmd := `mkdir -p ~/.codex && echo "$CODEX_AUTH_JSON" > ~/.codex/auth.json`
+	}
+
+	if requiresSetup && def.AuthSetup != "" {
 		return m.runtime.Exec(ctx, containerID, container.ExecConfig{
-			Command: []string{"sh", "-c", setupCmd},
+			Command: []string{"sh", "-c", def.AuthSetup},
 			Env:     env,
 		})
+	}
+	return nil
+}
 
-	default:
-		return nil
+// PropagateCredential rewrites a changed credential in every running instance
+// by running the auth setup of each agent using the credential's provider,
+// as CreateSession does before starting a session. Instances with sessions of
+// those agents on another profile are skipped, since the credential files are
+// shared by all sessions of an instance. Credentials passed only through
+// environment variables need no setup; their sessions are reported for a
+// restart like those of every other updated instance.
+func (m *Manager) PropagateCredential(ctx context.Context, cfg *PropagateCredentialConfig) ([]CredentialUpdate, error) {
+	entries, err := m.catalog.List(ctx, catalog.ListFilter{Status: catalog.StatusRunning})
+	if err != nil {
+		return nil, fmt.Errorf("list catalog entries: %w", err)
+	}
+
+	// agents use the provider; setupAgents also write its credential to files
+	var agents, setupAgents []string
+	for _, name := range m.agents.Names() {
+		def, getErr := m.agents.Get(name)
+		if getErr != nil || def.Auth != cfg.Auth {
+			continue
+		}
+		agents = append(agents, name)
+		if def.AuthSetup != "" {
+			setupAgents = append(setupAgents, name)
+		}
 	}
+
+	var updates []CredentialUpdate
+	for i := range entries {
+		entry, _, getErr := m.getRunningInstance(ctx, entries[i].ID)
+		if getErr != nil {
+			continue
+		}
+		update := CredentialUpdate{InstanceID: entry.ID, Repo: entry.Repo, Branch: entry.Branch}
+
+		for j := range entry.Sessions {
+			s := &entry.Sessions[j]
+			if !slices.Contains(agents, string(s.Type)) {
+				continue
+			}
+			if profile := sessionAuthProfile(s); profile != cfg.Profile {
+				update.Skipped = fmt.Sprintf("session %s uses auth profile %s", s.Name, profile)
+				update.Restart = nil
+				break
+			}
+			update.Restart = append(update.Restart, s.Name)
+		}
+
+		if update.Skipped == "" && cfg.RequiresAgentSetup {
+			for _, name := range setupAgents {
+				if setupErr := m.runAgentSetup(ctx, entry.ContainerID, catalog.SessionType(name), cfg.Env, true); setupErr != nil {
+					update.Err = fmt.Errorf("%s setup: %w", name, setupErr)
+					break
+				}
+				update.Updated = true
+			}
+		}
+		updates = append(updates, update)
+	}
+	return updates, nil
 }
 
-// getRunningInstance retrieves an instance and verifies its container is running.
-func (m *Manager) getRunningInstance(ctx context.Context, instanceID string) (*catalog.Entry, error) {
+// sessionAuthProfile returns the credential profile a session was started
+// with. Sessions started before profiles existed used the default profile.
+func sessionAuthProfile(s *catalog.Session) string {
+	if s.AuthProfile == "" {
+		return auth.DefaultProfile
+	}
+	return s.AuthProfile
+}
+
+// getRunningInstance retrieves an instance and its container, verifying the
+// container is running.
+func (m *Manager) getRunningInstance(ctx context.Context, instanceID string) (*catalog.Entry, *container.Container, error) {
 	entry, err := m.catalog.Get(ctx, instanceID)
 	if err != nil {
 		if errors.Is(err, catalog.ErrNotFound) {
-			return nil, ErrNotFound
+			return nil, nil, ErrNotFound
 		}
-		return nil, fmt.Errorf("get catalog entry: %w", err)
+		return nil, nil, fmt.Errorf("get catalog entry: %w", err)
 	}
 
 	if entry.ContainerID == "" {
-		return nil, errors.New("instance has no container")
+		return nil, nil, errors.New("instance has no container")
 	}
 
 	c, err := m.runtime.Get(ctx, entry.ContainerID)
 	if err != nil {
-		return nil, fmt.Errorf("get container: %w", err)
+		return nil, nil, fmt.Errorf("get container: %w", err)
 	}
 
 	if c.Status != container.StatusRunning {
-		return nil, &NotRunningError{
+		return nil, nil, &NotRunningError{
 			InstanceID:  entry.ID,
 			ContainerID: entry.ContainerID,
 			Status:      c.Status,
 		}
 	}
 
-	return entry, nil
+	return entry, c, nil
 }
 
 // GetSession retrieves a session by name within an instance.
@@ -970,12 +1700,16 @@ func (m *Manager) GetSession(ctx context.Context, instanceID, sessionName string
 	for _, s := range entry.Sessions {
 		if s.Name == sessionName {
 			return &Session{
-				ID:           s.ID,
-				Name:         s.Name,
-				Type:         string(s.Type),
-				MuxSessionID: s.MuxSessionID,
-				CreatedAt:    s.CreatedAt,
-				LastAccessed: s.LastAccessed,
+				ID:              s.ID,
+				Name:            s.Name,
+				Type:            string(s.Type),
+				MuxSessionID:    s.MuxSessionID,
+				CreatedAt:       s.CreatedAt,
+				LastAccessed:    s.LastAccessed,
+				LastInteraction: s.LastInteraction,
+				MaxRuntime:      s.MaxRuntime,
+				AuthProfile:     s.AuthProfile,
+				Usage:           s.Usage,
 			}, nil
 		}
 	}
@@ -996,12 +1730,16 @@ func (m *Manager) ListSessions(ctx context.Context, instanceID string) ([]Sessio
 	sessions := make([]Session, len(entry.Sessions))
 	for i, s := range entry.Sessions {
 		sessions[i] = Session{
-			ID:           s.ID,
-			Name:         s.Name,
-			Type:         string(s.Type),
-			MuxSessionID: s.MuxSessionID,
-			CreatedAt:    s.CreatedAt,
-			LastAccessed: s.LastAccessed,
+			ID:              s.ID,
+			Name:            s.Name,
+			Type:            string(s.Type),
+			MuxSessionID:    s.MuxSessionID,
+			CreatedAt:       s.CreatedAt,
+			LastAccessed:    s.LastAccessed,
+			LastInteraction: s.LastInteraction,
+			MaxRuntime:      s.MaxRuntime,
+			AuthProfile:     s.AuthProfile,
+			Usage:           s.Usage,
 		}
 	}
 
@@ -1032,6 +1770,8 @@ func (m *Manager) KillSession(ctx context.Context, instanceID, sessionName strin
 		return ErrSessionNotFound
 	}
 
+	m.recordEndedUsage(ctx, entry, []string{session.ID})
+
 	// Kill the multiplexer session (best-effort)
 	if killErr := m.mux.KillSession(ctx, session.MuxSessionID); killErr != nil {
 		// Only return error if it's not "session not found" (already dead)
@@ -1049,9 +1789,543 @@ func (m *Manager) KillSession(ctx context.Context, instanceID, sessionName strin
 		return fmt.Errorf("update catalog entry: %w", updateErr)
 	}
 
+	m.recordAudit(ctx, &audit.Event{
+		Action:     audit.ActionSessionKill,
+		InstanceID: entry.ID,
+		Repo:       entry.Repo,
+		Branch:     entry.Branch,
+		SessionID:  session.ID,
+		Session:    session.Name,
+		Agent:      string(session.Type),
+	})
+
 	return nil
 }
 
+// EnforceTimeouts kills every session that has run longer than its
+// MaxRuntime as of now. Each kill is recorded in the audit trail with its
+// reason and, if a Notifier is configured, announced. Unlike KillSession, the
+// session's log is kept so its output can still be searched until pruned.
+func (m *Manager) EnforceTimeouts(ctx context.Context, now time.Time) ([]TimedOutSession, error) {
+	timedOut, _, err := m.enforceTimeouts(ctx, now)
+	return timedOut, err
+}
+
+// enforceTimeouts implements EnforceTimeouts, additionally returning how many
+// sessions still have a runtime limit pending.
+func (m *Manager) enforceTimeouts(ctx context.Context, now time.Time) ([]TimedOutSession, int, error) {
+	entries, err := m.catalog.List(ctx, catalog.ListFilter{})
+	if err != nil {
+		return nil, 0, fmt.Errorf("list catalog entries: %w", err)
+	}
+
+	var timedOut []TimedOutSession
+	var errs []error
+	pending := 0
+	for i := range entries {
+		entry := &entries[i]
+		var expired []string
+		for _, s := range entry.Sessions {
+			if s.MaxRuntime > 0 && now.Sub(s.CreatedAt) >= s.MaxRuntime {
+				expired = append(expired, s.ID)
+			}
+		}
+
+		if len(expired) > 0 {
+			ended, endErr := m.endSessions(ctx, entry, expired)
+			if endErr != nil {
+				errs = append(errs, endErr)
+			}
+			for _, s := range ended {
+				t := TimedOutSession{
+					InstanceID: entry.ID,
+					Repo:       entry.Repo,
+					Branch:     entry.Branch,
+					SessionID:  s.ID,
+					Session:    s.Name,
+					Type:       string(s.Type),
+					MaxRuntime: s.MaxRuntime,
+					Runtime:    now.Sub(s.CreatedAt),
+				}
+				m.reportTimeout(ctx, &t)
+				timedOut = append(timedOut, t)
+			}
+		}
+
+		for _, s := range entry.Sessions {
+			if s.MaxRuntime > 0 {
+				pending++
+			}
+		}
+	}
+
+	return timedOut, pending, errors.Join(errs...)
+}
+
+// endSessions kills the given sessions of entry, records their final usage,
+// and removes them from the catalog. Unlike KillSession, their logs are kept.
+// Sessions that cannot be killed are left in place and reported in the
+// returned error; the sessions that were ended are returned.
+func (m *Manager) endSessions(ctx context.Context, entry *catalog.Entry, sessionIDs []string) ([]catalog.Session, error) {
+	var errs []error
+	var endedIDs []string
+	for _, s := range entry.Sessions {
+		if !slices.Contains(sessionIDs, s.ID) {
+			continue
+		}
+		if killErr := m.mux.KillSession(ctx, s.MuxSessionID); killErr != nil && !errors.Is(killErr, multiplexer.ErrSessionNotFound) {
+			errs = append(errs, fmt.Errorf("kill session %s: %w", s.Name, killErr))
+			continue
+		}
+		endedIDs = append(endedIDs, s.ID)
+	}
+	if len(endedIDs) == 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	m.recordEndedUsage(ctx, entry, endedIDs)
+
+	var ended []catalog.Session
+	kept := make([]catalog.Session, 0, len(entry.Sessions))
+	for _, s := range entry.Sessions {
+		if slices.Contains(endedIDs, s.ID) {
+			ended = append(ended, s)
+		} else {
+			kept = append(kept, s)
+		}
+	}
+	entry.Sessions = kept
+
+	// Other processes may have changed the entry since it was read
+	updateErr := m.catalog.Modify(ctx, entry.ID, func(current *catalog.Entry) error {
+		mergeUsage(current, entry)
+		current.Sessions = slices.DeleteFunc(current.Sessions, func(s catalog.Session) bool {
+			return slices.Contains(endedIDs, s.ID)
+		})
+		return nil
+	})
+	if updateErr != nil {
+		errs = append(errs, fmt.Errorf("update catalog entry: %w", updateErr))
+	}
+	return ended, errors.Join(errs...)
+}
+
+// reportTimeout records a timed-out session in the audit trail and sends a
+// notification. Both are best-effort.
+func (m *Manager) reportTimeout(ctx context.Context, t *TimedOutSession) {
+	reason := fmt.Sprintf("exceeded max runtime of %s", t.MaxRuntime)
+	m.recordAudit(ctx, &audit.Event{
+		Action:     audit.ActionSessionTimeout,
+		InstanceID: t.InstanceID,
+		Repo:       t.Repo,
+		Branch:     t.Branch,
+		SessionID:  t.SessionID,
+		Session:    t.Session,
+		Agent:      t.Type,
+		Reason:     reason,
+	})
+
+	if m.notifier == nil {
+		return
+	}
+	err := m.notifier.Notify(ctx, &notify.Notification{
+		Kind:       notify.KindSessionTimeout,
+		Message:    fmt.Sprintf("Session %s on %s was killed: %s", t.Session, t.Branch, reason),
+		InstanceID: t.InstanceID,
+		Repo:       t.Repo,
+		Branch:     t.Branch,
+		Session:    t.Session,
+		Agent:      t.Type,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to send timeout notification: %v\n", err)
+	}
+}
+
+// budgetSpend is the usage of one agent counted against its budgets.
+type budgetSpend struct {
+	daily     meter.Usage            // Usage today of sessions running now or ended today
+	instances map[string]meter.Usage // All sessions, by instance ID
+}
+
+// of returns the usage counted against a scope for a session of an instance.
+func (b *budgetSpend) of(scope budget.Scope, instanceID string, session meter.Usage) meter.Usage {
+	switch scope {
+	case budget.ScopeSession:
+		return session
+	case budget.ScopeInstance:
+		return b.instances[instanceID]
+	default:
+		return b.daily
+	}
+}
+
+// agentSpend sums an agent's usage from running sessions in entries and
+// ended sessions in records. Sessions started before today count toward the
+// daily spend only with their usage since their baseline in state. Ended
+// sessions never seen running today count in full.
+func agentSpend(state *budget.State, entries []catalog.Entry, records []usage.Record, agent string, now time.Time) *budgetSpend {
+	spend := &budgetSpend{instances: make(map[string]meter.Usage)}
+	add := func(instanceID string, u meter.Usage) {
+		total := spend.instances[instanceID]
+		total.Add(u)
+		spend.instances[instanceID] = total
+	}
+
+	for i := range entries {
+		for _, s := range entries[i].Sessions {
+			if string(s.Type) != agent {
+				continue
+			}
+			spend.daily.Add(state.DailyUsage(s.ID, s.CreatedAt, s.Usage, now))
+			add(entries[i].ID, s.Usage)
+		}
+	}
+
+	dayStart := budget.DayStart(now)
+	for i := range records {
+		r := &records[i]
+		if r.Agent != agent {
+			continue
+		}
+		if !r.Time.Before(dayStart) {
+			today := r.Usage
+			if baseline, ok := state.StartOfDay(r.SessionID, now); ok && r.Started.Before(dayStart) {
+				today = r.Usage.Since(baseline)
+			}
+			spend.daily.Add(today)
+		}
+		add(r.InstanceID, r.Usage)
+	}
+	return spend
+}
+
+// budgetInputs returns everything needed to compute budget spend: all catalog
+// entries and all ended sessions in the usage ledger.
+func (m *Manager) budgetInputs(ctx context.Context) ([]catalog.Entry, []usage.Record, error) {
+	entries, err := m.catalog.List(ctx, catalog.ListFilter{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("list catalog entries: %w", err)
+	}
+	if m.usageLedger == nil {
+		return entries, nil, nil
+	}
+	records, err := m.usageLedger.Query(usage.Filter{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("read usage ledger: %w", err)
+	}
+	return entries, records, nil
+}
+
+// checkBudget returns ErrBudgetExceeded if a new session of agent would
+// start over budget in entry: the agent's daily budget or its budget for
+// entry is used up. Budgets that are nearly used up are reported as warnings.
+func (m *Manager) checkBudget(ctx context.Context, entry *catalog.Entry, agent string, now time.Time) error {
+	policy := m.budgets[agent]
+	if policy.IsZero() {
+		return nil
+	}
+
+	state := &budget.State{}
+	if m.budgetState != nil {
+		var err error
+		state, err = m.budgetState.Load(ctx)
+		if err != nil {
+			return fmt.Errorf("load budget state: %w", err)
+		}
+		if until, blocked := state.Blocked(agent, now); blocked {
+			return fmt.Errorf("%w: %s daily budget of %s is used up until %s",
+				ErrBudgetExceeded, agent, policy.Daily, until.Format(time.DateTime))
+		}
+	}
+
+	entries, records, err := m.budgetInputs(ctx)
+	if err != nil {
+		return err
+	}
+	// Baselines recorded here are not saved; the watchdog records them
+	spend := agentSpend(state, entries, records, agent, now)
+
+	for _, scope := range []budget.Scope{budget.ScopeInstance, budget.ScopeDaily} {
+		limit := policy.Limit(scope)
+		if limit.IsZero() {
+			continue
+		}
+		used := limit.Used(spend.of(scope, entry.ID, meter.Usage{}))
+		switch {
+		case used >= 1 && scope == budget.ScopeDaily:
+			return fmt.Errorf("%w: %s daily budget of %s is used up until %s",
+				ErrBudgetExceeded, agent, limit, budget.NextDay(now).Format(time.DateTime))
+		case used >= 1:
+			return fmt.Errorf("%w: %s budget of %s for %s is used up", ErrBudgetExceeded, agent, limit, entry.Branch)
+		case used >= policy.WarnThreshold():
+			fmt.Fprintf(os.Stderr, "warning: %.0f%% of the %s %s budget of %s is used\n", used*100, agent, scope, limit)
+		}
+	}
+	return nil
+}
+
+// EnforceBudgets refreshes the usage of running agent sessions and applies
+// the configured budgets as of now. Sessions that have used up a budget are
+// killed; budgets that are nearly used up are warned about once. When an
+// agent's daily budget is used up, new sessions of that agent are refused
+// until the next day. Kills are recorded in the audit trail, and both kills
+// and warnings are announced if a Notifier is configured. As with
+// EnforceTimeouts, the logs of killed sessions are kept.
+func (m *Manager) EnforceBudgets(ctx context.Context, now time.Time) ([]BudgetEvent, error) {
+	events, _, err := m.enforceBudgets(ctx, now)
+	return events, err
+}
+
+// enforceBudgets implements EnforceBudgets, additionally returning how many
+// sessions are still subject to a budget.
+func (m *Manager) enforceBudgets(ctx context.Context, now time.Time) ([]BudgetEvent, int, error) {
+	if len(m.budgets) == 0 {
+		return nil, 0, nil
+	}
+
+	var errs []error
+	if err := m.refreshBudgetedUsage(ctx); err != nil {
+		// Enforce against the last known usage
+		errs = append(errs, fmt.Errorf("refresh usage: %w", err))
+	}
+
+	entries, records, err := m.budgetInputs(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Sessions are ended after the state is saved so the lock is not held
+	// while waiting on the multiplexer and catalog
+	var events, exceeded []BudgetEvent
+	pending := 0
+	evaluate := func(state *budget.State) error {
+		events, exceeded, pending = nil, nil, 0
+		state.Prune(now)
+		spends := make(map[string]*budgetSpend, len(m.budgets))
+		for agent, policy := range m.budgets {
+			spends[agent] = agentSpend(state, entries, records, agent, now)
+			if !policy.Daily.IsZero() && policy.Daily.Used(spends[agent].daily) >= 1 {
+				if _, blocked := state.Blocked(agent, now); !blocked {
+					state.Exhaust(agent, now)
+				}
+			}
+		}
+
+		for i := range entries {
+			entry := &entries[i]
+			for _, s := range entry.Sessions {
+				policy := m.budgets[string(s.Type)]
+				if policy.IsZero() {
+					continue
+				}
+				ev, over := m.checkSessionBudget(state, entry, &s, policy, spends[string(s.Type)], now)
+				if over {
+					exceeded = append(exceeded, ev...)
+					continue
+				}
+				events = append(events, ev...)
+				pending++
+			}
+		}
+		return nil
+	}
+
+	if m.budgetState == nil {
+		err = evaluate(&budget.State{})
+	} else {
+		err = m.budgetState.Update(ctx, evaluate)
+	}
+	if err != nil {
+		errs = append(errs, fmt.Errorf("update budget state: %w", err))
+	}
+
+	for i := range entries {
+		entry := &entries[i]
+		var ids []string
+		for _, ev := range exceeded {
+			if ev.InstanceID == entry.ID {
+				ids = append(ids, ev.SessionID)
+			}
+		}
+		if len(ids) == 0 {
+			continue
+		}
+
+		ended, endErr := m.endSessions(ctx, entry, ids)
+		if endErr != nil {
+			errs = append(errs, endErr)
+		}
+		for _, ev := range exceeded {
+			if ev.InstanceID != entry.ID {
+				continue
+			}
+			if !slices.ContainsFunc(ended, func(s catalog.Session) bool { return s.ID == ev.SessionID }) {
+				pending++
+				continue
+			}
+			events = append(events, ev)
+		}
+	}
+
+	for i := range events {
+		m.reportBudget(ctx, &events[i])
+	}
+	return events, pending, errors.Join(errs...)
+}
+
+// checkSessionBudget compares a session against each of its agent's limits,
+// narrowest first. It returns the first used-up limit with over set, or else
+// warnings for limits past the warning threshold not yet warned about.
+func (m *Manager) checkSessionBudget(state *budget.State, entry *catalog.Entry, s *catalog.Session, policy budget.Policy, spend *budgetSpend, now time.Time) ([]BudgetEvent, bool) {
+	var warnings []BudgetEvent
+	for _, scope := range budget.Scopes {
+		limit := policy.Limit(scope)
+		if limit.IsZero() {
+			continue
+		}
+		used := spend.of(scope, entry.ID, s.Usage)
+		ev := BudgetEvent{
+			InstanceID: entry.ID,
+			Repo:       entry.Repo,
+			Branch:     entry.Branch,
+			SessionID:  s.ID,
+			Session:    s.Name,
+			Type:       string(s.Type),
+			Scope:      scope,
+			Limit:      limit,
+			Usage:      used,
+		}
+		fraction := limit.Used(used)
+		if fraction >= 1 {
+			ev.Killed = true
+			return []BudgetEvent{ev}, true
+		}
+		if fraction < policy.WarnThreshold() {
+			continue
+		}
+
+		id := s.ID
+		if scope == budget.ScopeInstance {
+			id = entry.ID
+		}
+		if state.Warn(budget.WarningKey(scope, string(s.Type), id, now), now) {
+			warnings = append(warnings, ev)
+		}
+	}
+	return warnings, false
+}
+
+// refreshBudgetedUsage refreshes the usage of running instances with sessions
+// of agents that have a budget.
+func (m *Manager) refreshBudgetedUsage(ctx context.Context) error {
+	entries, err := m.catalog.List(ctx, catalog.ListFilter{Status: catalog.StatusRunning})
+	if err != nil {
+		return fmt.Errorf("list catalog entries: %w", err)
+	}
+
+	var errs []error
+	for i := range entries {
+		entry := &entries[i]
+		if !slices.ContainsFunc(entry.Sessions, func(s catalog.Session) bool { return !m.budgets[string(s.Type)].IsZero() }) {
+			continue
+		}
+		changed, refreshErr := m.refreshUsage(ctx, entry)
+		if refreshErr != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", entry.Branch, refreshErr))
+		}
+		if !changed {
+			continue
+		}
+		if updateErr := m.saveUsage(ctx, entry); updateErr != nil {
+			errs = append(errs, fmt.Errorf("%s: update catalog entry: %w", entry.Branch, updateErr))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// reportBudget records a session killed over budget in the audit trail and
+// sends a notification for kills and warnings. All are best-effort.
+func (m *Manager) reportBudget(ctx context.Context, ev *BudgetEvent) {
+	var message string
+	kind := notify.KindBudgetWarning
+	if ev.Killed {
+		reason := fmt.Sprintf("exceeded %s budget of %s", ev.Scope, ev.Limit)
+		m.recordAudit(ctx, &audit.Event{
+			Action:     audit.ActionSessionBudget,
+			InstanceID: ev.InstanceID,
+			Repo:       ev.Repo,
+			Branch:     ev.Branch,
+			SessionID:  ev.SessionID,
+			Session:    ev.Session,
+			Agent:      ev.Type,
+			Reason:     reason,
+		})
+		kind = notify.KindBudgetExceeded
+		message = fmt.Sprintf("Session %s on %s was killed: %s", ev.Session, ev.Branch, reason)
+	} else {
+		message = fmt.Sprintf("Session %s on %s has used %.0f%% of the %s %s budget of %s",
+			ev.Session, ev.Branch, ev.Limit.Used(ev.Usage)*100, ev.Type, ev.Scope, ev.Limit)
+	}
+
+	if m.notifier == nil {
+		return
+	}
+	err := m.notifier.Notify(ctx, &notify.Notification{
+		Kind:       kind,
+		Message:    message,
+		InstanceID: ev.InstanceID,
+		Repo:       ev.Repo,
+		Branch:     ev.Branch,
+		Session:    ev.Session,
+		Agent:      ev.Type,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to send budget notification: %v\n", err)
+	}
+}
+
+// Watchdog enforces session runtime limits and budgets every cfg.Interval
+// until ctx is cancelled or, with cfg.ExitWhenIdle, no session has a limit
+// or budget left to enforce. Enforcement errors are reported as warnings and
+// retried on the next check.
+func (m *Manager) Watchdog(ctx context.Context, cfg *WatchdogConfig) error {
+	ticker := time.NewTicker(cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		timedOut, pending, err := m.enforceTimeouts(ctx, time.Now())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: enforce session timeouts: %v\n", err)
+		}
+		if cfg.OnTimeout != nil {
+			for i := range timedOut {
+				cfg.OnTimeout(&timedOut[i])
+			}
+		}
+
+		events, budgeted, budgetErr := m.enforceBudgets(ctx, time.Now())
+		if budgetErr != nil {
+			fmt.Fprintf(os.Stderr, "warning: enforce session budgets: %v\n", budgetErr)
+		}
+		if cfg.OnBudget != nil {
+			for i := range events {
+				cfg.OnBudget(&events[i])
+			}
+		}
+
+		if cfg.ExitWhenIdle && err == nil && budgetErr == nil && pending+budgeted == 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
 // AttachSession attaches to an existing session, updating the last accessed timestamp.
 // This is a blocking operation that takes over the terminal.
 func (m *Manager) AttachSession(ctx context.Context, instanceID, sessionName string) error {
@@ -1077,8 +2351,10 @@ func (m *Manager) AttachSession(ctx context.Context, instanceID, sessionName str
 		return ErrSessionNotFound
 	}
 
-	// Update last accessed timestamp
-	entry.Sessions[sessionIndex].LastAccessed = time.Now()
+	// Update last accessed and interaction timestamps
+	now := time.Now()
+	entry.Sessions[sessionIndex].LastAccessed = now
+	entry.Sessions[sessionIndex].LastInteraction = now
 	if updateErr := m.catalog.Update(ctx, entry); updateErr != nil {
 		return fmt.Errorf("update catalog entry: %w", updateErr)
 	}
@@ -1093,6 +2369,87 @@ func (m *Manager) AttachSession(ctx context.Context, instanceID, sessionName str
 	return attachErr
 }
 
+// SendInput types input into a session without attaching, recording the
+// interaction time in the catalog.
+// Returns ErrSessionNotFound if the session does not exist or has exited.
+func (m *Manager) SendInput(ctx context.Context, instanceID, sessionName string, cfg *SendInputConfig) error {
+	entry, err := m.catalog.Get(ctx, instanceID)
+	if err != nil {
+		if errors.Is(err, catalog.ErrNotFound) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("get catalog entry: %w", err)
+	}
+
+	// Find the session
+	var sessionIndex = -1
+	var session catalog.Session
+	for i, s := range entry.Sessions {
+		if s.Name == sessionName {
+			sessionIndex = i
+			session = s
+			break
+		}
+	}
+	if sessionIndex == -1 {
+		return ErrSessionNotFound
+	}
+
+	if sendErr := m.mux.SendKeys(ctx, session.MuxSessionID, &multiplexer.SendKeysOpts{
+		Text:  cfg.Text,
+		Enter: cfg.Enter,
+	}); sendErr != nil {
+		if errors.Is(sendErr, multiplexer.ErrSessionNotFound) {
+			// The session exited; drop the stale catalog record
+			m.cleanupExitedSession(ctx, instanceID, sessionName, session.MuxSessionID)
+			return ErrSessionNotFound
+		}
+		return fmt.Errorf("send input: %w", sendErr)
+	}
+
+	entry.Sessions[sessionIndex].LastInteraction = time.Now()
+	if updateErr := m.catalog.Update(ctx, entry); updateErr != nil {
+		return fmt.Errorf("update catalog entry: %w", updateErr)
+	}
+
+	m.recordAudit(ctx, &audit.Event{
+		Action:     audit.ActionSessionSend,
+		InstanceID: entry.ID,
+		Repo:       entry.Repo,
+		Branch:     entry.Branch,
+		SessionID:  session.ID,
+		Session:    session.Name,
+		Agent:      string(session.Type),
+		Prompt:     cfg.Text,
+	})
+
+	return nil
+}
+
+// CaptureSession returns what a session is currently displaying, without
+// attaching to it. Capturing is read-only and does not update MRU tracking.
+func (m *Manager) CaptureSession(ctx context.Context, instanceID, sessionName string, cfg *CaptureConfig) (string, error) {
+	session, err := m.GetSession(ctx, instanceID, sessionName)
+	if err != nil {
+		return "", err
+	}
+
+	screen, err := m.mux.CapturePane(ctx, session.MuxSessionID, &multiplexer.CaptureOpts{
+		History: cfg.History,
+		Escapes: cfg.Escapes,
+	})
+	if err != nil {
+		if errors.Is(err, multiplexer.ErrSessionNotFound) {
+			// The session exited; drop the stale catalog record
+			m.cleanupExitedSession(ctx, instanceID, sessionName, session.MuxSessionID)
+			return "", ErrSessionNotFound
+		}
+		return "", fmt.Errorf("capture pane: %w", err)
+	}
+
+	return screen, nil
+}
+
 // cleanupExitedSession removes a session from the catalog if it no longer exists in the multiplexer.
 // This handles the case where a user exits a session (vs detaching).
 func (m *Manager) cleanupExitedSession(ctx context.Context, instanceID, sessionName, muxSessionID string) {
@@ -1115,6 +2472,12 @@ func (m *Manager) cleanupExitedSession(ctx context.Context, instanceID, sessionN
 		return
 	}
 
+	for _, s := range entry.Sessions {
+		if s.Name == sessionName {
+			m.recordEndedUsage(ctx, entry, []string{s.ID})
+		}
+	}
+
 	newSessions := make([]catalog.Session, 0, len(entry.Sessions))
 	for _, s := range entry.Sessions {
 		if s.Name != sessionName {
@@ -1150,12 +2513,16 @@ func (m *Manager) GetMRUSession(ctx context.Context, instanceID string) (*Sessio
 	}
 
 	return &Session{
-		ID:           mru.ID,
-		Name:         mru.Name,
-		Type:         string(mru.Type),
-		MuxSessionID: mru.MuxSessionID,
-		CreatedAt:    mru.CreatedAt,
-		LastAccessed: mru.LastAccessed,
+		ID:              mru.ID,
+		Name:            mru.Name,
+		Type:            string(mru.Type),
+		MuxSessionID:    mru.MuxSessionID,
+		CreatedAt:       mru.CreatedAt,
+		LastAccessed:    mru.LastAccessed,
+		LastInteraction: mru.LastInteraction,
+		MaxRuntime:      mru.MaxRuntime,
+		AuthProfile:     mru.AuthProfile,
+		Usage:           mru.Usage,
 	}, nil
 }
 
@@ -1186,12 +2553,16 @@ func (m *Manager) GetGlobalMRUSession(ctx context.Context) (*GlobalMRUSession, e
 				globalMRU = &GlobalMRUSession{
 					InstanceID: entry.ID,
 					Session: Session{
-						ID:           s.ID,
-						Name:         s.Name,
-						Type:         string(s.Type),
-						MuxSessionID: s.MuxSessionID,
-						CreatedAt:    s.CreatedAt,
-						LastAccessed: s.LastAccessed,
+						ID:              s.ID,
+						Name:            s.Name,
+						Type:            string(s.Type),
+						MuxSessionID:    s.MuxSessionID,
+						CreatedAt:       s.CreatedAt,
+						LastAccessed:    s.LastAccessed,
+						LastInteraction: s.LastInteraction,
+						MaxRuntime:      s.MaxRuntime,
+						AuthProfile:     s.AuthProfile,
+						Usage:           s.Usage,
 					},
 				}
 			}
@@ -4,22 +4,37 @@ import (
 	"context"
 	"errors"
 	"os"
+	"regexp"
+	"slices"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/jmgilman/headjack/internal/agent"
+	"github.com/jmgilman/headjack/internal/audit"
+	auditmocks "github.com/jmgilman/headjack/internal/audit/mocks"
+	"github.com/jmgilman/headjack/internal/budget"
+	budgetmocks "github.com/jmgilman/headjack/internal/budget/mocks"
 	"github.com/jmgilman/headjack/internal/catalog"
 	catalogmocks "github.com/jmgilman/headjack/internal/catalog/mocks"
 	"github.com/jmgilman/headjack/internal/container"
 	containermocks "github.com/jmgilman/headjack/internal/container/mocks"
 	"github.com/jmgilman/headjack/internal/git"
 	gitmocks "github.com/jmgilman/headjack/internal/git/mocks"
+	"github.com/jmgilman/headjack/internal/logging"
+	"github.com/jmgilman/headjack/internal/meter"
 	"github.com/jmgilman/headjack/internal/multiplexer"
 	muxmocks "github.com/jmgilman/headjack/internal/multiplexer/mocks"
+	"github.com/jmgilman/headjack/internal/notify"
+	notifymocks "github.com/jmgilman/headjack/internal/notify/mocks"
 	"github.com/jmgilman/headjack/internal/registry"
 	registrymocks "github.com/jmgilman/headjack/internal/registry/mocks"
+	"github.com/jmgilman/headjack/internal/secret"
+	secretmocks "github.com/jmgilman/headjack/internal/secret/mocks"
+	"github.com/jmgilman/headjack/internal/usage"
+	usagemocks "github.com/jmgilman/headjack/internal/usage/mocks"
 )
 
 // Test constants for repeated values.
@@ -28,6 +43,28 @@ const (
 	testRepoPath = "/path/to/repo"
 )
 
+// modifyViaUpdate mocks Modify on store by applying fn to the entry returned
+// by its ListFunc and passing the result to Update, so tests can inspect the
+// saved entry through UpdateCalls.
+func modifyViaUpdate(store *catalogmocks.StoreMock) *catalogmocks.StoreMock {
+	store.ModifyFunc = func(ctx context.Context, id string, fn func(*catalog.Entry) error) error {
+		entries, err := store.ListFunc(ctx, catalog.ListFilter{})
+		if err != nil {
+			return err
+		}
+		i := slices.IndexFunc(entries, func(e catalog.Entry) bool { return e.ID == id })
+		if i < 0 {
+			return catalog.ErrNotFound
+		}
+		entry := entries[i]
+		if err := fn(&entry); err != nil {
+			return err
+		}
+		return store.Update(ctx, &entry)
+	}
+	return store
+}
+
 func TestNewManager(t *testing.T) {
 	t.Run("sets worktrees directory", func(t *testing.T) {
 		mgr := NewManager(nil, nil, nil, nil, nil, ManagerConfig{WorktreesDir: "/data/worktrees", LogsDir: "/data/logs"})
@@ -77,6 +114,9 @@ func TestManager_Create(t *testing.T) {
 			},
 		}
 		runtime := &containermocks.RuntimeMock{
+			InspectImageFunc: func(ctx context.Context, ref string) (*container.Image, error) {
+				return &container.Image{ID: "sha256:abc123"}, nil
+			},
 			RunFunc: func(ctx context.Context, cfg *container.RunConfig) (*container.Container, error) {
 				return &container.Container{
 					ID:     "container-123",
@@ -111,6 +151,53 @@ func TestManager_Create(t *testing.T) {
 		assert.Equal(t, "/workspace", runCfg.Mounts[0].Target)
 	})
 
+	t.Run("uses labels of a locally built image", func(t *testing.T) {
+		repo := &gitmocks.RepositoryMock{
+			IdentifierFunc:     func() string { return testRepoID },
+			RootFunc:           func() string { return testRepoPath },
+			CreateWorktreeFunc: func(ctx context.Context, path, branch string) error { return nil },
+		}
+		opener := &gitmocks.OpenerMock{
+			OpenFunc: func(ctx context.Context, path string) (git.Repository, error) { return repo, nil },
+		}
+		store := &catalogmocks.StoreMock{
+			GetByRepoBranchFunc: func(ctx context.Context, repoID, branch string) (*catalog.Entry, error) {
+				return nil, catalog.ErrNotFound
+			},
+			AddFunc:    func(ctx context.Context, entry *catalog.Entry) error { return nil },
+			UpdateFunc: func(ctx context.Context, entry *catalog.Entry) error { return nil },
+		}
+		runtime := &containermocks.RuntimeMock{
+			InspectImageFunc: func(ctx context.Context, ref string) (*container.Image, error) {
+				return &container.Image{
+					ID: "sha256:abc123",
+					Labels: map[string]string{
+						"io.headjack.init":         "/lib/systemd/systemd",
+						"io.headjack.docker.flags": "privileged=true",
+					},
+				}, nil
+			},
+			RunFunc: func(ctx context.Context, cfg *container.RunConfig) (*container.Container, error) {
+				return &container.Container{ID: "container-123", Name: cfg.Name, Image: cfg.Image, Status: container.StatusRunning}, nil
+			},
+		}
+		reg := &registrymocks.ClientMock{}
+
+		mgr := NewManager(store, runtime, opener, nil, reg, ManagerConfig{WorktreesDir: "/data/worktrees", LogsDir: "/data/logs"})
+
+		_, err := mgr.Create(ctx, "/path/to/repo", CreateConfig{
+			Branch: "feature/auth",
+			Image:  "headjack:dev",
+		})
+
+		require.NoError(t, err)
+		require.Len(t, runtime.RunCalls(), 1)
+		runCfg := runtime.RunCalls()[0].Cfg
+		assert.Equal(t, "/lib/systemd/systemd", runCfg.Init)
+		assert.Contains(t, runCfg.Flags, "--privileged")
+		assert.Empty(t, reg.GetMetadataCalls())
+	})
+
 	t.Run("returns ErrAlreadyExists for duplicate branch", func(t *testing.T) {
 		repo := &gitmocks.RepositoryMock{
 			IdentifierFunc: func() string { return testRepoID },
@@ -196,6 +283,9 @@ func TestManager_Create(t *testing.T) {
 			},
 		}
 		runtime := &containermocks.RuntimeMock{
+			InspectImageFunc: func(ctx context.Context, ref string) (*container.Image, error) {
+				return &container.Image{ID: "sha256:abc123"}, nil
+			},
 			RunFunc: func(ctx context.Context, cfg *container.RunConfig) (*container.Container, error) {
 				return nil, errors.New("container error")
 			},
@@ -475,6 +565,31 @@ func TestManager_Remove(t *testing.T) {
 		require.Len(t, store.RemoveCalls(), 1)
 	})
 
+	t.Run("records audit event and tolerates audit failures", func(t *testing.T) {
+		store := &catalogmocks.StoreMock{
+			GetFunc: func(ctx context.Context, id string) (*catalog.Entry, error) {
+				return &catalog.Entry{ID: "abc123", Repo: testRepoPath, Branch: "main"}, nil
+			},
+			RemoveFunc: func(ctx context.Context, id string) error {
+				return nil
+			},
+		}
+		auditor := &auditmocks.RecorderMock{
+			RecordFunc: func(ctx context.Context, event *audit.Event) error {
+				return errors.New("disk full")
+			},
+		}
+
+		mgr := NewManager(store, nil, nil, nil, nil, ManagerConfig{Auditor: auditor})
+
+		err := mgr.Remove(ctx, "abc123")
+
+		require.NoError(t, err)
+		require.Len(t, auditor.RecordCalls(), 1)
+		assert.Equal(t, audit.ActionInstanceRemove, auditor.RecordCalls()[0].Event.Action)
+		assert.Equal(t, "main", auditor.RecordCalls()[0].Event.Branch)
+	})
+
 	t.Run("returns ErrNotFound for missing instance", func(t *testing.T) {
 		store := &catalogmocks.StoreMock{
 			GetFunc: func(ctx context.Context, id string) (*catalog.Entry, error) {
@@ -517,6 +632,9 @@ func TestManager_Recreate(t *testing.T) {
 			RemoveFunc: func(ctx context.Context, id string) error {
 				return nil
 			},
+			InspectImageFunc: func(ctx context.Context, ref string) (*container.Image, error) {
+				return &container.Image{ID: "sha256:abc123"}, nil
+			},
 			RunFunc: func(ctx context.Context, cfg *container.RunConfig) (*container.Container, error) {
 				return &container.Container{
 					ID:     "new-container",
@@ -736,6 +854,194 @@ func TestManager_CreateSession(t *testing.T) {
 		require.Len(t, store.UpdateCalls(), 1)
 	})
 
+	t.Run("refuses sessions of an agent over budget", func(t *testing.T) {
+		now := time.Now()
+		entry := catalog.Entry{
+			ID:          "abc12345",
+			Branch:      "feat/auth",
+			ContainerID: "container-123",
+			Sessions: []catalog.Session{
+				{ID: "sess1", Name: "first", Type: catalog.SessionTypeClaude, Usage: meter.Usage{CostUSD: 6}},
+			},
+		}
+		newStore := func() *catalogmocks.StoreMock {
+			return &catalogmocks.StoreMock{
+				GetFunc: func(ctx context.Context, id string) (*catalog.Entry, error) {
+					e := entry
+					return &e, nil
+				},
+				ListFunc: func(ctx context.Context, filter catalog.ListFilter) ([]catalog.Entry, error) {
+					return []catalog.Entry{entry}, nil
+				},
+			}
+		}
+		runtime := &containermocks.RuntimeMock{
+			GetFunc: func(ctx context.Context, id string) (*container.Container, error) {
+				return &container.Container{ID: "container-123", Status: container.StatusRunning}, nil
+			},
+		}
+		mux := &muxmocks.MultiplexerMock{}
+
+		t.Run("instance budget used up", func(t *testing.T) {
+			mgr := NewManager(newStore(), runtime, nil, mux, nil, ManagerConfig{
+				LogsDir: t.TempDir(),
+				Budgets: map[string]budget.Policy{"claude": {Instance: budget.Limit{CostUSD: 5}}},
+			})
+
+			_, err := mgr.CreateSession(ctx, "abc12345", &CreateSessionConfig{Type: "claude"})
+
+			require.ErrorIs(t, err, ErrBudgetExceeded)
+			assert.Contains(t, err.Error(), "feat/auth")
+			assert.Empty(t, mux.CreateSessionCalls())
+		})
+
+		t.Run("daily budget used up earlier", func(t *testing.T) {
+			state := &budgetmocks.StoreMock{
+				LoadFunc: func(ctx context.Context) (*budget.State, error) {
+					return &budget.State{Exhausted: map[string]time.Time{"claude": budget.NextDay(now)}}, nil
+				},
+			}
+			mgr := NewManager(newStore(), runtime, nil, mux, nil, ManagerConfig{
+				LogsDir:     t.TempDir(),
+				Budgets:     map[string]budget.Policy{"claude": {Daily: budget.Limit{CostUSD: 50}}},
+				BudgetState: state,
+			})
+
+			_, err := mgr.CreateSession(ctx, "abc12345", &CreateSessionConfig{Type: "claude"})
+
+			require.ErrorIs(t, err, ErrBudgetExceeded)
+			assert.Contains(t, err.Error(), "daily budget")
+			assert.Empty(t, mux.CreateSessionCalls())
+		})
+	})
+
+	t.Run("records the runtime limit and starts the watchdog", func(t *testing.T) {
+		store := &catalogmocks.StoreMock{
+			GetFunc: func(ctx context.Context, id string) (*catalog.Entry, error) {
+				return &catalog.Entry{ID: "abc12345", ContainerID: "container-123"}, nil
+			},
+			UpdateFunc: func(ctx context.Context, entry *catalog.Entry) error {
+				return nil
+			},
+		}
+		runtime := &containermocks.RuntimeMock{
+			GetFunc: func(ctx context.Context, id string) (*container.Container, error) {
+				return &container.Container{ID: "container-123", Status: container.StatusRunning}, nil
+			},
+			ExecCommandFunc: func() []string {
+				return []string{"docker", "exec"}
+			},
+		}
+		mux := &muxmocks.MultiplexerMock{
+			CreateSessionFunc: func(ctx context.Context, opts *multiplexer.CreateSessionOpts) (*multiplexer.Session, error) {
+				return &multiplexer.Session{Name: opts.Name}, nil
+			},
+		}
+		watchdogStarts := 0
+
+		mgr := NewManager(store, runtime, nil, mux, nil, ManagerConfig{
+			LogsDir: t.TempDir(),
+			StartWatchdog: func(context.Context) error {
+				watchdogStarts++
+				return nil
+			},
+		})
+
+		session, err := mgr.CreateSession(ctx, "abc12345", &CreateSessionConfig{MaxRuntime: 2 * time.Hour})
+		require.NoError(t, err)
+		assert.Equal(t, 2*time.Hour, session.MaxRuntime)
+		assert.Equal(t, 2*time.Hour, store.UpdateCalls()[0].Entry.Sessions[0].MaxRuntime)
+		assert.Equal(t, 1, watchdogStarts)
+
+		_, err = mgr.CreateSession(ctx, "abc12345", &CreateSessionConfig{})
+		require.NoError(t, err)
+		assert.Equal(t, 1, watchdogStarts, "unlimited sessions should not start the watchdog")
+	})
+
+	t.Run("pipes output through the log command", func(t *testing.T) {
+		logsDir := t.TempDir()
+
+		store := &catalogmocks.StoreMock{
+			GetFunc: func(ctx context.Context, id string) (*catalog.Entry, error) {
+				return &catalog.Entry{ID: "abc12345", ContainerID: "container-123"}, nil
+			},
+			UpdateFunc: func(ctx context.Context, entry *catalog.Entry) error {
+				return nil
+			},
+		}
+		runtime := &containermocks.RuntimeMock{
+			GetFunc: func(ctx context.Context, id string) (*container.Container, error) {
+				return &container.Container{ID: "container-123", Status: container.StatusRunning}, nil
+			},
+			ExecCommandFunc: func() []string {
+				return []string{"docker", "exec"}
+			},
+		}
+		mux := &muxmocks.MultiplexerMock{
+			CreateSessionFunc: func(ctx context.Context, opts *multiplexer.CreateSessionOpts) (*multiplexer.Session, error) {
+				return &multiplexer.Session{Name: opts.Name}, nil
+			},
+		}
+
+		logCommand := []string{"/usr/bin/hjk", "log-writer", "--max-size", "1024"}
+		mgr := NewManager(store, runtime, nil, mux, nil, ManagerConfig{LogsDir: logsDir, LogCommand: logCommand})
+
+		_, err := mgr.CreateSession(ctx, "abc12345", &CreateSessionConfig{})
+		require.NoError(t, err)
+
+		opts := mux.CreateSessionCalls()[0].Opts
+		assert.Equal(t, append(logCommand, opts.LogPath), opts.LogCommand, "log path is appended to the command")
+		assert.Len(t, logCommand, 4, "configured command is not modified")
+	})
+
+	t.Run("pipes transcript sessions through the transcript command", func(t *testing.T) {
+		logsDir := t.TempDir()
+
+		store := &catalogmocks.StoreMock{
+			GetFunc: func(ctx context.Context, id string) (*catalog.Entry, error) {
+				return &catalog.Entry{ID: "abc12345", ContainerID: "container-123"}, nil
+			},
+			UpdateFunc: func(ctx context.Context, entry *catalog.Entry) error {
+				return nil
+			},
+		}
+		runtime := &containermocks.RuntimeMock{
+			GetFunc: func(ctx context.Context, id string) (*container.Container, error) {
+				return &container.Container{ID: "container-123", Status: container.StatusRunning}, nil
+			},
+			ExecCommandFunc: func() []string {
+				return []string{"docker", "exec"}
+			},
+			ExecFunc: func(ctx context.Context, id string, cfg container.ExecConfig) error {
+				return nil
+			},
+		}
+		mux := &muxmocks.MultiplexerMock{
+			CreateSessionFunc: func(ctx context.Context, opts *multiplexer.CreateSessionOpts) (*multiplexer.Session, error) {
+				return &multiplexer.Session{Name: opts.Name}, nil
+			},
+		}
+
+		logCommand := []string{"/usr/bin/hjk", "log-writer"}
+		transcriptCommand := []string{"/usr/bin/hjk", "transcript-writer"}
+		mgr := NewManager(store, runtime, nil, mux, nil, ManagerConfig{
+			LogsDir:           logsDir,
+			LogCommand:        logCommand,
+			TranscriptCommand: transcriptCommand,
+		})
+
+		session, err := mgr.CreateSession(ctx, "abc12345", &CreateSessionConfig{Type: "claude", Transcript: true})
+		require.NoError(t, err)
+		_, err = mgr.CreateSession(ctx, "abc12345", &CreateSessionConfig{Type: "claude"})
+		require.NoError(t, err)
+
+		opts := mux.CreateSessionCalls()[0].Opts
+		transcriptPath := logging.NewPathManager(logsDir).TranscriptPath("abc12345", session.ID)
+		assert.Equal(t, append(transcriptCommand, "claude", transcriptPath, opts.LogPath), opts.LogCommand)
+		opts = mux.CreateSessionCalls()[1].Opts
+		assert.Equal(t, append(logCommand, opts.LogPath), opts.LogCommand, "other sessions use the log command")
+	})
+
 	t.Run("creates session with custom name", func(t *testing.T) {
 		logsDir := t.TempDir()
 		worktreeDir := t.TempDir()
@@ -777,6 +1083,156 @@ func TestManager_CreateSession(t *testing.T) {
 		assert.Equal(t, "my-session", session.Name)
 	})
 
+	t.Run("records audit event", func(t *testing.T) {
+		logsDir := t.TempDir()
+
+		store := &catalogmocks.StoreMock{
+			GetFunc: func(ctx context.Context, id string) (*catalog.Entry, error) {
+				return &catalog.Entry{
+					ID:          "abc12345",
+					Repo:        testRepoPath,
+					Branch:      "feat/auth",
+					ContainerID: "container-123",
+					Sessions:    []catalog.Session{},
+				}, nil
+			},
+			UpdateFunc: func(ctx context.Context, entry *catalog.Entry) error {
+				return nil
+			},
+		}
+		runtime := &containermocks.RuntimeMock{
+			GetFunc: func(ctx context.Context, id string) (*container.Container, error) {
+				return &container.Container{ID: "container-123", Image: "ghcr.io/gilmanlab/headjack:base", Status: container.StatusRunning}, nil
+			},
+			ExecCommandFunc: func() []string {
+				return []string{"docker", "exec"}
+			},
+			ExecFunc: func(ctx context.Context, id string, cfg container.ExecConfig) error {
+				return nil
+			},
+		}
+		mux := &muxmocks.MultiplexerMock{
+			CreateSessionFunc: func(ctx context.Context, opts *multiplexer.CreateSessionOpts) (*multiplexer.Session, error) {
+				return &multiplexer.Session{Name: opts.Name}, nil
+			},
+		}
+		auditor := &auditmocks.RecorderMock{
+			RecordFunc: func(ctx context.Context, event *audit.Event) error {
+				return nil
+			},
+		}
+
+		mgr := NewManager(store, runtime, nil, mux, nil, ManagerConfig{LogsDir: logsDir, Auditor: auditor})
+
+		session, err := mgr.CreateSession(ctx, "abc12345", &CreateSessionConfig{
+			Type:           "claude",
+			Name:           "claude-main",
+			Command:        []string{"claude", "Implement JWT"},
+			Prompt:         "Implement JWT",
+			Env:            []string{"ANTHROPIC_API_KEY=sk-ant-api03-secret"},
+			CredentialType: "apikey",
+			AuthProfile:    "work",
+		})
+
+		require.NoError(t, err)
+		require.Len(t, auditor.RecordCalls(), 1)
+		event := auditor.RecordCalls()[0].Event
+		assert.Equal(t, audit.ActionSessionCreate, event.Action)
+		assert.Equal(t, "abc12345", event.InstanceID)
+		assert.Equal(t, "feat/auth", event.Branch)
+		assert.Equal(t, session.ID, event.SessionID)
+		assert.Equal(t, "claude-main", event.Session)
+		assert.Equal(t, "claude", event.Agent)
+		assert.Equal(t, "ghcr.io/gilmanlab/headjack:base", event.Image)
+		assert.Equal(t, "Implement JWT", event.Prompt)
+		assert.Equal(t, "apikey", event.CredentialType)
+		assert.Equal(t, "work", event.AuthProfile)
+		assert.Equal(t, "work", session.AuthProfile)
+		assert.Equal(t, []string{"ANTHROPIC_API_KEY=sk-ant-api03-secret"}, event.Env, "redaction is the recorder's job")
+	})
+
+	t.Run("resolves secret references in the environment", func(t *testing.T) {
+		store := &catalogmocks.StoreMock{
+			GetFunc: func(ctx context.Context, id string) (*catalog.Entry, error) {
+				return &catalog.Entry{
+					ID:          "abc12345",
+					RepoID:      "myproject-abc1234",
+					Branch:      "feat/auth",
+					ContainerID: "container-123",
+				}, nil
+			},
+			UpdateFunc: func(ctx context.Context, entry *catalog.Entry) error {
+				return nil
+			},
+		}
+		runtime := &containermocks.RuntimeMock{
+			GetFunc: func(ctx context.Context, id string) (*container.Container, error) {
+				return &container.Container{ID: "container-123", Status: container.StatusRunning}, nil
+			},
+			ExecCommandFunc: func() []string {
+				return []string{"docker", "exec"}
+			},
+		}
+		mux := &muxmocks.MultiplexerMock{
+			CreateSessionFunc: func(ctx context.Context, opts *multiplexer.CreateSessionOpts) (*multiplexer.Session, error) {
+				return &multiplexer.Session{Name: opts.Name}, nil
+			},
+		}
+		auditor := &auditmocks.RecorderMock{
+			RecordFunc: func(ctx context.Context, event *audit.Event) error {
+				return nil
+			},
+		}
+		secrets := &secretmocks.ResolverMock{
+			ResolveFunc: func(repoID, name string) (string, error) {
+				return "ghp_" + repoID, nil
+			},
+		}
+
+		mgr := NewManager(store, runtime, nil, mux, nil, ManagerConfig{LogsDir: t.TempDir(), Auditor: auditor, Secrets: secrets})
+
+		_, err := mgr.CreateSession(ctx, "abc12345", &CreateSessionConfig{
+			Env: []string{"GITHUB_TOKEN=secret://github", "DEBUG=1"},
+		})
+
+		require.NoError(t, err)
+		require.Len(t, secrets.ResolveCalls(), 1)
+		assert.Equal(t, "github", secrets.ResolveCalls()[0].Name)
+		cmd := mux.CreateSessionCalls()[0].Opts.Command
+		assert.Contains(t, cmd, "GITHUB_TOKEN=ghp_myproject-abc1234")
+		assert.Contains(t, cmd, "DEBUG=1")
+		assert.Equal(t, []string{"GITHUB_TOKEN=secret://github", "DEBUG=1"}, auditor.RecordCalls()[0].Event.Env,
+			"the audit trail records the reference, not the value")
+	})
+
+	t.Run("fails when a referenced secret is missing", func(t *testing.T) {
+		store := &catalogmocks.StoreMock{
+			GetFunc: func(ctx context.Context, id string) (*catalog.Entry, error) {
+				return &catalog.Entry{ID: "abc12345", ContainerID: "container-123"}, nil
+			},
+		}
+		runtime := &containermocks.RuntimeMock{
+			GetFunc: func(ctx context.Context, id string) (*container.Container, error) {
+				return &container.Container{ID: "container-123", Status: container.StatusRunning}, nil
+			},
+		}
+		mux := &muxmocks.MultiplexerMock{}
+		secrets := &secretmocks.ResolverMock{
+			ResolveFunc: func(repoID, name string) (string, error) {
+				return "", secret.ErrNotFound
+			},
+		}
+
+		mgr := NewManager(store, runtime, nil, mux, nil, ManagerConfig{LogsDir: t.TempDir(), Secrets: secrets})
+
+		_, err := mgr.CreateSession(ctx, "abc12345", &CreateSessionConfig{
+			Env: []string{"GITHUB_TOKEN=secret://github"},
+		})
+
+		require.ErrorIs(t, err, secret.ErrNotFound)
+		assert.Empty(t, mux.CreateSessionCalls())
+	})
+
 	t.Run("returns ErrSessionExists for duplicate name", func(t *testing.T) {
 		store := &catalogmocks.StoreMock{
 			GetFunc: func(ctx context.Context, id string) (*catalog.Entry, error) {
@@ -840,16 +1296,355 @@ func TestManager_CreateSession(t *testing.T) {
 	})
 }
 
-func TestManager_GetSession(t *testing.T) {
+func TestManager_PruneLogs(t *testing.T) {
 	ctx := context.Background()
+	logsDir := t.TempDir()
+	pathMgr := logging.NewPathManager(logsDir)
 
-	t.Run("returns session by name", func(t *testing.T) {
-		now := time.Now()
-		store := &catalogmocks.StoreMock{
-			GetFunc: func(ctx context.Context, id string) (*catalog.Entry, error) {
-				return &catalog.Entry{
-					ID: "abc12345",
-					Sessions: []catalog.Session{
+	old := time.Now().Add(-48 * time.Hour)
+	for _, sess := range []string{"live", "gone"} {
+		path, err := pathMgr.EnsureSessionLog("inst1", sess)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(path, []byte("output\n"), 0o600))
+		require.NoError(t, os.Chtimes(path, old, old))
+	}
+
+	store := &catalogmocks.StoreMock{
+		ListFunc: func(ctx context.Context, filter catalog.ListFilter) ([]catalog.Entry, error) {
+			return []catalog.Entry{{ID: "inst1", Sessions: []catalog.Session{{ID: "live"}}}}, nil
+		},
+	}
+	mgr := NewManager(store, nil, nil, nil, nil, ManagerConfig{LogsDir: logsDir})
+	retention := logging.RetentionPolicy{MaxAge: 24 * time.Hour}
+
+	t.Run("dry run reports inactive sessions", func(t *testing.T) {
+		pruned, err := mgr.PruneLogs(ctx, &PruneLogsConfig{Retention: retention, DryRun: true})
+		require.NoError(t, err)
+
+		require.Len(t, pruned, 1)
+		assert.Equal(t, "gone", pruned[0].SessionID)
+		assert.True(t, pathMgr.LogExists("inst1", "gone"))
+	})
+
+	t.Run("keeps logs of sessions in the catalog", func(t *testing.T) {
+		pruned, err := mgr.PruneLogs(ctx, &PruneLogsConfig{Retention: retention})
+		require.NoError(t, err)
+
+		require.Len(t, pruned, 1)
+		assert.False(t, pathMgr.LogExists("inst1", "gone"))
+		assert.True(t, pathMgr.LogExists("inst1", "live"))
+	})
+
+	t.Run("returns catalog errors", func(t *testing.T) {
+		store.ListFunc = func(ctx context.Context, filter catalog.ListFilter) ([]catalog.Entry, error) {
+			return nil, errors.New("catalog unavailable")
+		}
+
+		_, err := mgr.PruneLogs(ctx, &PruneLogsConfig{Retention: retention})
+		assert.ErrorContains(t, err, "catalog unavailable")
+	})
+}
+
+func TestManager_SearchLogs(t *testing.T) {
+	ctx := context.Background()
+	logsDir := t.TempDir()
+	pathMgr := logging.NewPathManager(logsDir)
+
+	writeLog := func(instanceID, sessionID, content string) {
+		path, err := pathMgr.EnsureSessionLog(instanceID, sessionID)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+	writeLog("inst1", "s-claude", "migrate\nerror: migration failed\n")
+	writeLog("inst1", "s-shell", "$ make\nerror: no rule\n")
+	writeLog("inst1", "s-gone", "error: from a killed session\n")
+	writeLog("inst2", "s-gemini", "error: quota\n")
+
+	store := &catalogmocks.StoreMock{
+		ListFunc: func(ctx context.Context, filter catalog.ListFilter) ([]catalog.Entry, error) {
+			return []catalog.Entry{
+				{ID: "inst1", Branch: "feat/db", Sessions: []catalog.Session{
+					{ID: "s-shell", Name: "shell-1", Type: catalog.SessionTypeShell},
+					{ID: "s-claude", Name: "happy-panda", Type: catalog.SessionTypeClaude},
+				}},
+				{ID: "inst2", Branch: "feat/ui", Sessions: []catalog.Session{
+					{ID: "s-gemini", Name: "calm-otter", Type: catalog.SessionTypeGemini},
+				}},
+			}, nil
+		},
+	}
+	mgr := NewManager(store, nil, nil, nil, nil, ManagerConfig{LogsDir: logsDir})
+
+	search := func(cfg *SearchLogsConfig) []LogMatch {
+		var matches []LogMatch
+		err := mgr.SearchLogs(ctx, cfg, func(m *LogMatch) error {
+			matches = append(matches, *m)
+			return nil
+		})
+		require.NoError(t, err)
+		return matches
+	}
+	where := func(matches []LogMatch) []string {
+		var out []string
+		for _, m := range matches {
+			out = append(out, m.Branch+"/"+m.Session)
+		}
+		return out
+	}
+
+	t.Run("maps matches to branches and sessions", func(t *testing.T) {
+		matches := search(&SearchLogsConfig{Pattern: regexp.MustCompile(`^error`), Context: 1})
+
+		assert.Equal(t, []string{"feat/db/shell-1", "feat/db/happy-panda", "feat/db/s-gone", "feat/ui/calm-otter"}, where(matches))
+		assert.Equal(t, "claude", matches[1].Type)
+		assert.Equal(t, "s-claude", matches[1].SessionID)
+		assert.Equal(t, "inst1", matches[1].InstanceID)
+		assert.Equal(t, 2, matches[1].Line)
+		assert.Equal(t, []string{"migrate"}, matches[1].Before)
+		assert.Empty(t, matches[2].Type, "sessions no longer in the catalog have no type")
+	})
+
+	t.Run("filters by branch", func(t *testing.T) {
+		matches := search(&SearchLogsConfig{Pattern: regexp.MustCompile(`error`), Branch: "feat/ui"})
+		assert.Equal(t, []string{"feat/ui/calm-otter"}, where(matches))
+	})
+
+	t.Run("filters by agent", func(t *testing.T) {
+		matches := search(&SearchLogsConfig{Pattern: regexp.MustCompile(`error`), Agent: "claude"})
+		assert.Equal(t, []string{"feat/db/happy-panda"}, where(matches))
+	})
+}
+
+func TestManager_RefreshUsage(t *testing.T) {
+	ctx := context.Background()
+	created := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+
+	claudeDump := "\x1e/home/dev/.claude/projects/-workspace/3f1c.jsonl\n" +
+		`{"type":"user","timestamp":"2026-01-02T10:00:05Z","message":{"role":"user"}}` + "\n" +
+		`{"type":"assistant","timestamp":"2026-01-02T10:00:09Z","message":{"id":"msg_01","usage":{"input_tokens":10,"output_tokens":20,"cache_read_input_tokens":30,"cache_creation_input_tokens":40}}}` + "\n"
+
+	newRuntime := func(dump string) *containermocks.RuntimeMock {
+		return &containermocks.RuntimeMock{
+			ExecFunc: func(ctx context.Context, containerID string, cfg container.ExecConfig) error {
+				_, err := cfg.Stdout.Write([]byte(dump))
+				return err
+			},
+		}
+	}
+
+	t.Run("stores usage on the session that ran the agent", func(t *testing.T) {
+		store := modifyViaUpdate(&catalogmocks.StoreMock{
+			ListFunc: func(ctx context.Context, filter catalog.ListFilter) ([]catalog.Entry, error) {
+				return []catalog.Entry{{
+					ID:          "abc12345",
+					ContainerID: "container123",
+					Status:      catalog.StatusRunning,
+					Sessions: []catalog.Session{
+						{ID: "sess1", Name: "first", Type: catalog.SessionTypeClaude, CreatedAt: created},
+						{ID: "sess2", Name: "later", Type: catalog.SessionTypeClaude, CreatedAt: created.Add(time.Hour)},
+						{ID: "sess3", Name: "shell", Type: catalog.SessionTypeShell, CreatedAt: created},
+					},
+				}}, nil
+			},
+			UpdateFunc: func(ctx context.Context, entry *catalog.Entry) error {
+				return nil
+			},
+		})
+		runtime := newRuntime(claudeDump)
+
+		mgr := NewManager(store, runtime, nil, nil, nil, ManagerConfig{LogsDir: t.TempDir()})
+
+		err := mgr.RefreshUsage(ctx)
+
+		require.NoError(t, err)
+		require.Len(t, runtime.ExecCalls(), 1)
+		require.Len(t, store.UpdateCalls(), 1)
+		entry := store.UpdateCalls()[0].Entry
+		assert.Equal(t, meter.Usage{InputTokens: 10, OutputTokens: 20, CacheReadTokens: 30, CacheWriteTokens: 40}, entry.Sessions[0].Usage)
+		assert.True(t, entry.Sessions[1].Usage.IsZero())
+		assert.Equal(t, "sess1", entry.UsageFiles["/home/dev/.claude/projects/-workspace/3f1c.jsonl"])
+	})
+
+	t.Run("falls back to the cost reported in the session transcript", func(t *testing.T) {
+		logsDir := t.TempDir()
+		require.NoError(t, os.MkdirAll(logsDir+"/abc12345", 0o750))
+		transcriptPath := logsDir + "/abc12345/sess1.transcript.jsonl"
+		require.NoError(t, os.WriteFile(transcriptPath, []byte(`{"kind":"result","cost_usd":0.25}`+"\n"), 0o600))
+
+		store := modifyViaUpdate(&catalogmocks.StoreMock{
+			ListFunc: func(ctx context.Context, filter catalog.ListFilter) ([]catalog.Entry, error) {
+				return []catalog.Entry{{
+					ID:          "abc12345",
+					ContainerID: "container123",
+					Status:      catalog.StatusRunning,
+					Sessions: []catalog.Session{
+						{ID: "sess1", Name: "first", Type: catalog.SessionTypeClaude, CreatedAt: created},
+					},
+				}}, nil
+			},
+			UpdateFunc: func(ctx context.Context, entry *catalog.Entry) error {
+				return nil
+			},
+		})
+
+		mgr := NewManager(store, newRuntime(claudeDump), nil, nil, nil, ManagerConfig{LogsDir: logsDir})
+
+		err := mgr.RefreshUsage(ctx)
+
+		require.NoError(t, err)
+		require.Len(t, store.UpdateCalls(), 1)
+		assert.InDelta(t, 0.25, store.UpdateCalls()[0].Entry.Sessions[0].Usage.CostUSD, 1e-9)
+
+		// Later refreshes read only the events recorded since
+		f, err := os.OpenFile(transcriptPath, os.O_APPEND|os.O_WRONLY, 0o600)
+		require.NoError(t, err)
+		_, err = f.WriteString(`{"kind":"result","cost_usd":0.5}` + "\n")
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+
+		require.NoError(t, mgr.RefreshUsage(ctx))
+
+		require.Len(t, store.UpdateCalls(), 2)
+		assert.InDelta(t, 0.75, store.UpdateCalls()[1].Entry.Sessions[0].Usage.CostUSD, 1e-9)
+	})
+
+	t.Run("keeps sessions created while usage was read", func(t *testing.T) {
+		entry := catalog.Entry{
+			ID:          "abc12345",
+			ContainerID: "container123",
+			Status:      catalog.StatusRunning,
+			Sessions:    []catalog.Session{{ID: "sess1", Name: "first", Type: catalog.SessionTypeClaude, CreatedAt: created}},
+		}
+		var saved catalog.Entry
+		store := &catalogmocks.StoreMock{
+			ListFunc: func(ctx context.Context, filter catalog.ListFilter) ([]catalog.Entry, error) {
+				return []catalog.Entry{entry}, nil
+			},
+			ModifyFunc: func(ctx context.Context, id string, fn func(*catalog.Entry) error) error {
+				saved = entry
+				saved.Sessions = append(slices.Clone(entry.Sessions), catalog.Session{ID: "sess2", Name: "new", Type: catalog.SessionTypeShell})
+				return fn(&saved)
+			},
+		}
+
+		mgr := NewManager(store, newRuntime(claudeDump), nil, nil, nil, ManagerConfig{LogsDir: t.TempDir()})
+
+		err := mgr.RefreshUsage(ctx)
+
+		require.NoError(t, err)
+		require.Len(t, saved.Sessions, 2)
+		assert.Equal(t, int64(20), saved.Sessions[0].Usage.OutputTokens)
+		assert.Equal(t, "new", saved.Sessions[1].Name)
+		assert.Equal(t, "sess1", saved.UsageFiles["/home/dev/.claude/projects/-workspace/3f1c.jsonl"])
+	})
+
+	t.Run("skips instances without agent sessions", func(t *testing.T) {
+		store := &catalogmocks.StoreMock{
+			ListFunc: func(ctx context.Context, filter catalog.ListFilter) ([]catalog.Entry, error) {
+				return []catalog.Entry{{
+					ID:          "abc12345",
+					ContainerID: "container123",
+					Status:      catalog.StatusRunning,
+					Sessions:    []catalog.Session{{ID: "sess1", Name: "shell", Type: catalog.SessionTypeShell}},
+				}}, nil
+			},
+		}
+		runtime := newRuntime("")
+
+		mgr := NewManager(store, runtime, nil, nil, nil, ManagerConfig{})
+
+		err := mgr.RefreshUsage(ctx)
+
+		require.NoError(t, err)
+		assert.Empty(t, runtime.ExecCalls())
+		assert.Empty(t, store.UpdateCalls())
+	})
+
+	t.Run("reports exec errors", func(t *testing.T) {
+		store := &catalogmocks.StoreMock{
+			ListFunc: func(ctx context.Context, filter catalog.ListFilter) ([]catalog.Entry, error) {
+				return []catalog.Entry{{
+					ID:          "abc12345",
+					Branch:      "feat/auth",
+					ContainerID: "container123",
+					Status:      catalog.StatusRunning,
+					Sessions:    []catalog.Session{{ID: "sess1", Name: "first", Type: catalog.SessionTypeClaude}},
+				}}, nil
+			},
+		}
+		runtime := &containermocks.RuntimeMock{
+			ExecFunc: func(ctx context.Context, containerID string, cfg container.ExecConfig) error {
+				return errors.New("exec failed")
+			},
+		}
+
+		mgr := NewManager(store, runtime, nil, nil, nil, ManagerConfig{})
+
+		err := mgr.RefreshUsage(ctx)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "feat/auth")
+		assert.Empty(t, store.UpdateCalls())
+	})
+}
+
+func TestManager_Usage(t *testing.T) {
+	ctx := context.Background()
+	since := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	store := &catalogmocks.StoreMock{
+		ListFunc: func(ctx context.Context, filter catalog.ListFilter) ([]catalog.Entry, error) {
+			return []catalog.Entry{{
+				ID:     "abc12345",
+				Repo:   testRepoPath,
+				Branch: "feat/auth",
+				Status: catalog.StatusStopped,
+				Sessions: []catalog.Session{
+					{ID: "sess1", Name: "agent", Type: catalog.SessionTypeClaude, Usage: meter.Usage{InputTokens: 5}},
+					{ID: "sess2", Name: "shell", Type: catalog.SessionTypeShell},
+				},
+			}}, nil
+		},
+	}
+	ledger := &usagemocks.RecorderMock{
+		QueryFunc: func(filter usage.Filter) ([]usage.Record, error) {
+			assert.Equal(t, since, filter.Since)
+			return []usage.Record{{
+				Time:      since.Add(time.Hour),
+				Repo:      testRepoPath,
+				Branch:    "main",
+				SessionID: "old1",
+				Session:   "ended",
+				Agent:     "codex",
+				Usage:     meter.Usage{OutputTokens: 7},
+			}}, nil
+		},
+	}
+
+	mgr := NewManager(store, nil, nil, nil, nil, ManagerConfig{UsageLedger: ledger})
+
+	report, err := mgr.Usage(ctx, &UsageConfig{Since: since})
+
+	require.NoError(t, err)
+	require.Len(t, report, 2)
+	assert.Equal(t, "agent", report[0].Session)
+	assert.Equal(t, int64(5), report[0].Usage.InputTokens)
+	assert.True(t, report[0].Ended.IsZero())
+	assert.Equal(t, "ended", report[1].Session)
+	assert.Equal(t, "codex", report[1].Type)
+	assert.Equal(t, since.Add(time.Hour), report[1].Ended)
+}
+
+func TestManager_GetSession(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("returns session by name", func(t *testing.T) {
+		now := time.Now()
+		store := &catalogmocks.StoreMock{
+			GetFunc: func(ctx context.Context, id string) (*catalog.Entry, error) {
+				return &catalog.Entry{
+					ID: "abc12345",
+					Sessions: []catalog.Session{
 						{ID: "sess1", Name: "first-session", Type: catalog.SessionTypeShell, CreatedAt: now, LastAccessed: now},
 						{ID: "sess2", Name: "second-session", Type: catalog.SessionTypeClaude, CreatedAt: now, LastAccessed: now},
 					},
@@ -1002,6 +1797,44 @@ func TestManager_KillSession(t *testing.T) {
 		assert.True(t, os.IsNotExist(statErr))
 	})
 
+	t.Run("records the final usage of agent sessions", func(t *testing.T) {
+		store := &catalogmocks.StoreMock{
+			GetFunc: func(ctx context.Context, id string) (*catalog.Entry, error) {
+				return &catalog.Entry{
+					ID:     "abc12345",
+					Branch: "feat/auth",
+					Sessions: []catalog.Session{
+						{ID: "sess1", Name: "my-session", Type: catalog.SessionTypeClaude, MuxSessionID: "hjk-abc12345-sess1", Usage: meter.Usage{OutputTokens: 42}},
+					},
+				}, nil
+			},
+			UpdateFunc: func(ctx context.Context, entry *catalog.Entry) error {
+				return nil
+			},
+		}
+		mux := &muxmocks.MultiplexerMock{
+			KillSessionFunc: func(ctx context.Context, sessionName string) error {
+				return nil
+			},
+		}
+		ledger := &usagemocks.RecorderMock{
+			RecordFunc: func(ctx context.Context, record *usage.Record) error {
+				return nil
+			},
+		}
+
+		mgr := NewManager(store, nil, nil, mux, nil, ManagerConfig{LogsDir: t.TempDir(), UsageLedger: ledger})
+
+		err := mgr.KillSession(ctx, "abc12345", "my-session")
+
+		require.NoError(t, err)
+		require.Len(t, ledger.RecordCalls(), 1)
+		record := ledger.RecordCalls()[0].Record
+		assert.Equal(t, "my-session", record.Session)
+		assert.Equal(t, "claude", record.Agent)
+		assert.Equal(t, int64(42), record.Usage.OutputTokens)
+	})
+
 	t.Run("succeeds even if multiplexer session already dead", func(t *testing.T) {
 		store := &catalogmocks.StoreMock{
 			GetFunc: func(ctx context.Context, id string) (*catalog.Entry, error) {
@@ -1061,92 +1894,555 @@ func TestManager_KillSession(t *testing.T) {
 	})
 }
 
-func TestManager_AttachSession(t *testing.T) {
+func TestManager_EnforceTimeouts(t *testing.T) {
 	ctx := context.Background()
+	now := time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC)
 
-	t.Run("attaches to session and updates last accessed", func(t *testing.T) {
-		oldTime := time.Now().Add(-1 * time.Hour)
-		store := &catalogmocks.StoreMock{
-			GetFunc: func(ctx context.Context, id string) (*catalog.Entry, error) {
-				return &catalog.Entry{
-					ID: "abc12345",
+	newStore := func() *catalogmocks.StoreMock {
+		return modifyViaUpdate(&catalogmocks.StoreMock{
+			ListFunc: func(ctx context.Context, filter catalog.ListFilter) ([]catalog.Entry, error) {
+				return []catalog.Entry{{
+					ID:     "abc12345",
+					Repo:   testRepoPath,
+					Branch: "feat/auth",
 					Sessions: []catalog.Session{
-						{ID: "sess1", Name: "my-session", MuxSessionID: "hjk-abc12345-sess1", LastAccessed: oldTime},
+						{ID: "sess1", Name: "expired", Type: catalog.SessionTypeClaude, MuxSessionID: "hjk-abc12345-sess1", CreatedAt: now.Add(-3 * time.Hour), MaxRuntime: 2 * time.Hour},
+						{ID: "sess2", Name: "within-limit", MuxSessionID: "hjk-abc12345-sess2", CreatedAt: now.Add(-time.Hour), MaxRuntime: 2 * time.Hour},
+						{ID: "sess3", Name: "unlimited", MuxSessionID: "hjk-abc12345-sess3", CreatedAt: now.Add(-48 * time.Hour)},
 					},
-				}, nil
+				}}, nil
 			},
 			UpdateFunc: func(ctx context.Context, entry *catalog.Entry) error {
-				require.Len(t, entry.Sessions, 1)
-				// LastAccessed should be updated to a recent time
-				assert.True(t, entry.Sessions[0].LastAccessed.After(oldTime))
 				return nil
 			},
-		}
+		})
+	}
+
+	t.Run("kills sessions past their limit and keeps their logs", func(t *testing.T) {
+		logsDir := t.TempDir()
+		sessLogPath := logsDir + "/abc12345/sess1.log"
+		require.NoError(t, os.MkdirAll(logsDir+"/abc12345", 0o750))
+		require.NoError(t, os.WriteFile(sessLogPath, []byte("log content"), 0o600))
+
+		store := newStore()
 		mux := &muxmocks.MultiplexerMock{
-			AttachSessionFunc: func(ctx context.Context, sessionName string) error {
-				assert.Equal(t, "hjk-abc12345-sess1", sessionName)
+			KillSessionFunc: func(ctx context.Context, sessionName string) error {
 				return nil
 			},
-			ListSessionsFunc: func(ctx context.Context) ([]multiplexer.Session, error) {
-				// Session still exists (user detached, not exited)
-				return []multiplexer.Session{{Name: "hjk-abc12345-sess1"}}, nil
+		}
+		auditor := &auditmocks.RecorderMock{
+			RecordFunc: func(ctx context.Context, event *audit.Event) error {
+				return nil
+			},
+		}
+		notifier := &notifymocks.NotifierMock{
+			NotifyFunc: func(ctx context.Context, n *notify.Notification) error {
+				return nil
 			},
 		}
 
-		mgr := NewManager(store, nil, nil, mux, nil, ManagerConfig{})
+		mgr := NewManager(store, nil, nil, mux, nil, ManagerConfig{LogsDir: logsDir, Auditor: auditor, Notifier: notifier})
 
-		err := mgr.AttachSession(ctx, "abc12345", "my-session")
+		timedOut, err := mgr.EnforceTimeouts(ctx, now)
 
 		require.NoError(t, err)
-		require.Len(t, mux.AttachSessionCalls(), 1)
+		require.Len(t, timedOut, 1)
+		assert.Equal(t, "expired", timedOut[0].Session)
+		assert.Equal(t, "feat/auth", timedOut[0].Branch)
+		assert.Equal(t, 3*time.Hour, timedOut[0].Runtime)
+
+		require.Len(t, mux.KillSessionCalls(), 1)
+		assert.Equal(t, "hjk-abc12345-sess1", mux.KillSessionCalls()[0].SessionName)
+
 		require.Len(t, store.UpdateCalls(), 1)
+		remaining := store.UpdateCalls()[0].Entry.Sessions
+		require.Len(t, remaining, 2)
+		assert.Equal(t, "within-limit", remaining[0].Name)
+		assert.Equal(t, "unlimited", remaining[1].Name)
+
+		require.Len(t, auditor.RecordCalls(), 1)
+		event := auditor.RecordCalls()[0].Event
+		assert.Equal(t, audit.ActionSessionTimeout, event.Action)
+		assert.Equal(t, "expired", event.Session)
+		assert.Equal(t, "claude", event.Agent)
+		assert.Equal(t, "exceeded max runtime of 2h0m0s", event.Reason)
+
+		require.Len(t, notifier.NotifyCalls(), 1)
+		n := notifier.NotifyCalls()[0].N
+		assert.Equal(t, notify.KindSessionTimeout, n.Kind)
+		assert.Equal(t, "expired", n.Session)
+		assert.Contains(t, n.Message, "exceeded max runtime of 2h0m0s")
+
+		_, statErr := os.Stat(sessLogPath)
+		assert.NoError(t, statErr)
 	})
 
-	t.Run("returns ErrSessionNotFound for missing session", func(t *testing.T) {
-		store := &catalogmocks.StoreMock{
-			GetFunc: func(ctx context.Context, id string) (*catalog.Entry, error) {
-				return &catalog.Entry{
-					ID:       "abc12345",
-					Sessions: []catalog.Session{},
-				}, nil
+	t.Run("removes sessions whose multiplexer session is already gone", func(t *testing.T) {
+		store := newStore()
+		mux := &muxmocks.MultiplexerMock{
+			KillSessionFunc: func(ctx context.Context, sessionName string) error {
+				return multiplexer.ErrSessionNotFound
 			},
 		}
 
-		mgr := NewManager(store, nil, nil, nil, nil, ManagerConfig{})
+		mgr := NewManager(store, nil, nil, mux, nil, ManagerConfig{})
 
-		err := mgr.AttachSession(ctx, "abc12345", "nonexistent")
+		timedOut, err := mgr.EnforceTimeouts(ctx, now)
 
-		assert.ErrorIs(t, err, ErrSessionNotFound)
+		require.NoError(t, err)
+		assert.Len(t, timedOut, 1)
+		require.Len(t, store.UpdateCalls(), 1)
+		assert.Len(t, store.UpdateCalls()[0].Entry.Sessions, 2)
 	})
 
-	t.Run("cleans up session from catalog when user exits", func(t *testing.T) {
-		oldTime := time.Now().Add(-1 * time.Hour)
-		getCalls := 0
-		updateCalls := 0
-		store := &catalogmocks.StoreMock{
-			GetFunc: func(ctx context.Context, id string) (*catalog.Entry, error) {
-				getCalls++
-				return &catalog.Entry{
-					ID: "abc12345",
-					Sessions: []catalog.Session{
-						{ID: "sess1", Name: "my-session", MuxSessionID: "hjk-abc12345-sess1", LastAccessed: oldTime},
-					},
-				}, nil
-			},
-			UpdateFunc: func(ctx context.Context, entry *catalog.Entry) error {
-				updateCalls++
-				if updateCalls == 2 {
-					// Second update should have removed the session
-					assert.Empty(t, entry.Sessions, "session should be removed from catalog")
-				}
-				return nil
-			},
+	t.Run("keeps sessions created while ending others", func(t *testing.T) {
+		store := newStore()
+		var saved catalog.Entry
+		store.ModifyFunc = func(ctx context.Context, id string, fn func(*catalog.Entry) error) error {
+			entries, _ := store.ListFunc(ctx, catalog.ListFilter{})
+			saved = entries[0]
+			saved.Sessions = append(saved.Sessions, catalog.Session{ID: "sess4", Name: "new"})
+			return fn(&saved)
 		}
 		mux := &muxmocks.MultiplexerMock{
-			AttachSessionFunc: func(ctx context.Context, sessionName string) error {
+			KillSessionFunc: func(ctx context.Context, sessionName string) error {
 				return nil
 			},
-			ListSessionsFunc: func(ctx context.Context) ([]multiplexer.Session, error) {
+		}
+
+		mgr := NewManager(store, nil, nil, mux, nil, ManagerConfig{})
+
+		_, err := mgr.EnforceTimeouts(ctx, now)
+
+		require.NoError(t, err)
+		assert.Empty(t, store.UpdateCalls())
+		names := make([]string, 0, len(saved.Sessions))
+		for _, s := range saved.Sessions {
+			names = append(names, s.Name)
+		}
+		assert.Equal(t, []string{"within-limit", "unlimited", "new"}, names)
+	})
+
+	t.Run("keeps sessions that fail to die", func(t *testing.T) {
+		store := newStore()
+		mux := &muxmocks.MultiplexerMock{
+			KillSessionFunc: func(ctx context.Context, sessionName string) error {
+				return errors.New("tmux exploded")
+			},
+		}
+
+		mgr := NewManager(store, nil, nil, mux, nil, ManagerConfig{})
+
+		timedOut, err := mgr.EnforceTimeouts(ctx, now)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "tmux exploded")
+		assert.Empty(t, timedOut)
+		assert.Empty(t, store.UpdateCalls())
+	})
+
+	t.Run("does nothing when no session has expired", func(t *testing.T) {
+		store := newStore()
+		mux := &muxmocks.MultiplexerMock{}
+
+		mgr := NewManager(store, nil, nil, mux, nil, ManagerConfig{})
+
+		timedOut, err := mgr.EnforceTimeouts(ctx, now.Add(-2*time.Hour))
+
+		require.NoError(t, err)
+		assert.Empty(t, timedOut)
+		assert.Empty(t, store.UpdateCalls())
+	})
+}
+
+func TestManager_EnforceBudgets(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 1, 2, 12, 0, 0, 0, time.Local)
+
+	newStore := func(sessions ...catalog.Session) *catalogmocks.StoreMock {
+		return modifyViaUpdate(&catalogmocks.StoreMock{
+			ListFunc: func(ctx context.Context, filter catalog.ListFilter) ([]catalog.Entry, error) {
+				return []catalog.Entry{{
+					ID:       "abc12345",
+					Repo:     testRepoPath,
+					Branch:   "feat/auth",
+					Status:   catalog.StatusStopped, // No container to refresh usage from
+					Sessions: sessions,
+				}}, nil
+			},
+			UpdateFunc: func(ctx context.Context, entry *catalog.Entry) error {
+				return nil
+			},
+		})
+	}
+	newMux := func() *muxmocks.MultiplexerMock {
+		return &muxmocks.MultiplexerMock{
+			KillSessionFunc: func(ctx context.Context, sessionName string) error {
+				return nil
+			},
+		}
+	}
+
+	t.Run("kills sessions over their session budget", func(t *testing.T) {
+		store := newStore(
+			catalog.Session{ID: "sess1", Name: "spender", Type: catalog.SessionTypeClaude, MuxSessionID: "hjk-abc12345-sess1", Usage: meter.Usage{CostUSD: 6}},
+			catalog.Session{ID: "sess2", Name: "frugal", Type: catalog.SessionTypeClaude, MuxSessionID: "hjk-abc12345-sess2", Usage: meter.Usage{CostUSD: 1}},
+			catalog.Session{ID: "sess3", Name: "shell", Type: catalog.SessionTypeShell, MuxSessionID: "hjk-abc12345-sess3"},
+		)
+		mux := newMux()
+		auditor := &auditmocks.RecorderMock{
+			RecordFunc: func(ctx context.Context, event *audit.Event) error {
+				return nil
+			},
+		}
+
+		mgr := NewManager(store, nil, nil, mux, nil, ManagerConfig{
+			Auditor: auditor,
+			Budgets: map[string]budget.Policy{"claude": {Session: budget.Limit{CostUSD: 5}}},
+		})
+
+		events, pending, err := mgr.enforceBudgets(ctx, now)
+
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		assert.True(t, events[0].Killed)
+		assert.Equal(t, "spender", events[0].Session)
+		assert.Equal(t, budget.ScopeSession, events[0].Scope)
+		assert.Equal(t, 1, pending)
+
+		require.Len(t, mux.KillSessionCalls(), 1)
+		assert.Equal(t, "hjk-abc12345-sess1", mux.KillSessionCalls()[0].SessionName)
+		require.Len(t, store.UpdateCalls(), 1)
+		assert.Len(t, store.UpdateCalls()[0].Entry.Sessions, 2)
+
+		require.Len(t, auditor.RecordCalls(), 1)
+		ev := auditor.RecordCalls()[0].Event
+		assert.Equal(t, audit.ActionSessionBudget, ev.Action)
+		assert.Equal(t, "exceeded session budget of $5.00", ev.Reason)
+	})
+
+	t.Run("warns once when a budget is nearly used up", func(t *testing.T) {
+		store := newStore(
+			catalog.Session{ID: "sess1", Name: "agent", Type: catalog.SessionTypeCodex, Usage: meter.Usage{OutputTokens: 900}},
+		)
+		notifier := &notifymocks.NotifierMock{
+			NotifyFunc: func(ctx context.Context, n *notify.Notification) error {
+				return nil
+			},
+		}
+		state := &budget.State{}
+		stateStore := &budgetmocks.StoreMock{
+			UpdateFunc: func(ctx context.Context, fn func(*budget.State) error) error {
+				return fn(state)
+			},
+		}
+
+		mgr := NewManager(store, nil, nil, newMux(), nil, ManagerConfig{
+			Notifier:    notifier,
+			Budgets:     map[string]budget.Policy{"codex": {Instance: budget.Limit{Tokens: 1000}}},
+			BudgetState: stateStore,
+		})
+
+		events, pending, err := mgr.enforceBudgets(ctx, now)
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		assert.False(t, events[0].Killed)
+		assert.Equal(t, budget.ScopeInstance, events[0].Scope)
+		assert.Equal(t, 1, pending)
+		require.Len(t, notifier.NotifyCalls(), 1)
+		assert.Equal(t, notify.KindBudgetWarning, notifier.NotifyCalls()[0].N.Kind)
+
+		events, _, err = mgr.enforceBudgets(ctx, now.Add(time.Minute))
+		require.NoError(t, err)
+		assert.Empty(t, events)
+		assert.Len(t, notifier.NotifyCalls(), 1)
+	})
+
+	t.Run("blocks the agent when its daily budget is used up", func(t *testing.T) {
+		store := newStore(
+			catalog.Session{ID: "sess1", Name: "agent", Type: catalog.SessionTypeClaude, MuxSessionID: "hjk-abc12345-sess1", CreatedAt: now.Add(-time.Hour), Usage: meter.Usage{CostUSD: 3}},
+		)
+		ledger := &usagemocks.RecorderMock{
+			QueryFunc: func(filter usage.Filter) ([]usage.Record, error) {
+				return []usage.Record{
+					{Time: now.Add(-time.Hour), InstanceID: "other", Agent: "claude", Usage: meter.Usage{CostUSD: 8}},
+					{Time: now.Add(-24 * time.Hour), InstanceID: "other", Agent: "claude", Usage: meter.Usage{CostUSD: 100}},
+				}, nil
+			}, RecordFunc: func(ctx context.Context, record *usage.Record) error {
+				return nil
+			},
+		}
+		state := &budget.State{}
+		stateStore := &budgetmocks.StoreMock{
+			UpdateFunc: func(ctx context.Context, fn func(*budget.State) error) error {
+				return fn(state)
+			},
+		}
+
+		mgr := NewManager(store, nil, nil, newMux(), nil, ManagerConfig{
+			UsageLedger: ledger,
+			Budgets:     map[string]budget.Policy{"claude": {Daily: budget.Limit{CostUSD: 10}}},
+			BudgetState: stateStore,
+		})
+
+		events, _, err := mgr.enforceBudgets(ctx, now)
+
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		assert.True(t, events[0].Killed)
+		assert.Equal(t, budget.ScopeDaily, events[0].Scope)
+		assert.InDelta(t, 11.0, events[0].Usage.CostUSD, 1e-9)
+		until, blocked := state.Blocked("claude", now)
+		assert.True(t, blocked)
+		assert.Equal(t, budget.NextDay(now), until)
+	})
+
+	t.Run("counts only today's usage of sessions started earlier", func(t *testing.T) {
+		yesterday := now.Add(-24 * time.Hour)
+		ledger := &usagemocks.RecorderMock{
+			QueryFunc: func(filter usage.Filter) ([]usage.Record, error) {
+				return []usage.Record{
+					{Time: now.Add(-time.Hour), SessionID: "ended", Started: yesterday, Agent: "claude", Usage: meter.Usage{CostUSD: 20}},
+				}, nil
+			},
+			RecordFunc: func(ctx context.Context, record *usage.Record) error {
+				return nil
+			},
+		}
+		state := &budget.State{Baselines: map[string]budget.Baseline{
+			"ended": {Day: now.Format(time.DateOnly), Usage: meter.Usage{CostUSD: 18}},
+		}}
+		stateStore := &budgetmocks.StoreMock{
+			UpdateFunc: func(ctx context.Context, fn func(*budget.State) error) error {
+				return fn(state)
+			},
+		}
+		session := catalog.Session{ID: "sess1", Name: "agent", Type: catalog.SessionTypeClaude, MuxSessionID: "hjk-abc12345-sess1", CreatedAt: yesterday, Usage: meter.Usage{CostUSD: 50}}
+
+		mgr := NewManager(newStore(session), nil, nil, newMux(), nil, ManagerConfig{
+			UsageLedger: ledger,
+			Budgets:     map[string]budget.Policy{"claude": {Daily: budget.Limit{CostUSD: 10}}},
+			BudgetState: stateStore,
+		})
+
+		events, pending, err := mgr.enforceBudgets(ctx, now)
+		require.NoError(t, err)
+		assert.Empty(t, events)
+		assert.Equal(t, 1, pending)
+		assert.Equal(t, meter.Usage{CostUSD: 50}, state.Baselines["sess1"].Usage)
+
+		session.Usage = meter.Usage{CostUSD: 58}
+		mgr = NewManager(newStore(session), nil, nil, newMux(), nil, ManagerConfig{
+			UsageLedger: ledger,
+			Budgets:     map[string]budget.Policy{"claude": {Daily: budget.Limit{CostUSD: 10}}},
+			BudgetState: stateStore,
+		})
+
+		events, _, err = mgr.enforceBudgets(ctx, now.Add(time.Hour))
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		assert.True(t, events[0].Killed)
+		assert.InDelta(t, 10.0, events[0].Usage.CostUSD, 1e-9)
+	})
+
+	t.Run("ends sessions after releasing the state lock", func(t *testing.T) {
+		store := newStore(
+			catalog.Session{ID: "sess1", Name: "spender", Type: catalog.SessionTypeClaude, MuxSessionID: "hjk-abc12345-sess1", Usage: meter.Usage{CostUSD: 6}},
+		)
+		locked := false
+		stateStore := &budgetmocks.StoreMock{
+			UpdateFunc: func(ctx context.Context, fn func(*budget.State) error) error {
+				locked = true
+				defer func() { locked = false }()
+				return fn(&budget.State{})
+			},
+		}
+		mux := &muxmocks.MultiplexerMock{
+			KillSessionFunc: func(ctx context.Context, sessionName string) error {
+				assert.False(t, locked, "session killed while holding the budget lock")
+				return nil
+			},
+		}
+
+		mgr := NewManager(store, nil, nil, mux, nil, ManagerConfig{
+			Budgets:     map[string]budget.Policy{"claude": {Session: budget.Limit{CostUSD: 5}}},
+			BudgetState: stateStore,
+		})
+
+		events, _, err := mgr.enforceBudgets(ctx, now)
+
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		assert.Len(t, mux.KillSessionCalls(), 1)
+	})
+
+	t.Run("does nothing without budgets", func(t *testing.T) {
+		store := newStore(catalog.Session{ID: "sess1", Type: catalog.SessionTypeClaude, Usage: meter.Usage{CostUSD: 100}})
+
+		mgr := NewManager(store, nil, nil, nil, nil, ManagerConfig{})
+
+		events, pending, err := mgr.enforceBudgets(ctx, now)
+
+		require.NoError(t, err)
+		assert.Empty(t, events)
+		assert.Zero(t, pending)
+		assert.Empty(t, store.ListCalls())
+	})
+}
+
+func TestManager_Watchdog(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("kills expired sessions and exits when idle", func(t *testing.T) {
+		entry := catalog.Entry{
+			ID:     "abc12345",
+			Branch: "feat/auth",
+			Sessions: []catalog.Session{
+				{ID: "sess1", Name: "expired", MuxSessionID: "hjk-abc12345-sess1", CreatedAt: time.Now().Add(-time.Hour), MaxRuntime: time.Minute},
+			},
+		}
+		store := modifyViaUpdate(&catalogmocks.StoreMock{
+			ListFunc: func(ctx context.Context, filter catalog.ListFilter) ([]catalog.Entry, error) {
+				return []catalog.Entry{entry}, nil
+			},
+			UpdateFunc: func(ctx context.Context, e *catalog.Entry) error {
+				entry = *e
+				return nil
+			},
+		})
+		mux := &muxmocks.MultiplexerMock{
+			KillSessionFunc: func(ctx context.Context, sessionName string) error {
+				return nil
+			},
+		}
+
+		mgr := NewManager(store, nil, nil, mux, nil, ManagerConfig{})
+
+		var killed []string
+		err := mgr.Watchdog(ctx, &WatchdogConfig{
+			Interval:     time.Millisecond,
+			ExitWhenIdle: true,
+			OnTimeout: func(t *TimedOutSession) {
+				killed = append(killed, t.Session)
+			},
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"expired"}, killed)
+		assert.Empty(t, entry.Sessions)
+	})
+
+	t.Run("keeps running while limits are pending until cancelled", func(t *testing.T) {
+		store := &catalogmocks.StoreMock{
+			ListFunc: func(ctx context.Context, filter catalog.ListFilter) ([]catalog.Entry, error) {
+				return []catalog.Entry{{
+					ID: "abc12345",
+					Sessions: []catalog.Session{
+						{ID: "sess1", Name: "pending", CreatedAt: time.Now(), MaxRuntime: time.Hour},
+					},
+				}}, nil
+			},
+		}
+
+		mgr := NewManager(store, nil, nil, &muxmocks.MultiplexerMock{}, nil, ManagerConfig{})
+
+		ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+
+		err := mgr.Watchdog(ctx, &WatchdogConfig{Interval: 5 * time.Millisecond, ExitWhenIdle: true})
+
+		require.NoError(t, err)
+		assert.Greater(t, len(store.ListCalls()), 1)
+	})
+}
+
+func TestManager_AttachSession(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("attaches to session and updates last accessed", func(t *testing.T) {
+		oldTime := time.Now().Add(-1 * time.Hour)
+		store := &catalogmocks.StoreMock{
+			GetFunc: func(ctx context.Context, id string) (*catalog.Entry, error) {
+				return &catalog.Entry{
+					ID: "abc12345",
+					Sessions: []catalog.Session{
+						{ID: "sess1", Name: "my-session", MuxSessionID: "hjk-abc12345-sess1", LastAccessed: oldTime},
+					},
+				}, nil
+			},
+			UpdateFunc: func(ctx context.Context, entry *catalog.Entry) error {
+				require.Len(t, entry.Sessions, 1)
+				// LastAccessed should be updated to a recent time
+				assert.True(t, entry.Sessions[0].LastAccessed.After(oldTime))
+				assert.True(t, entry.Sessions[0].LastInteraction.After(oldTime))
+				return nil
+			},
+		}
+		mux := &muxmocks.MultiplexerMock{
+			AttachSessionFunc: func(ctx context.Context, sessionName string) error {
+				assert.Equal(t, "hjk-abc12345-sess1", sessionName)
+				return nil
+			},
+			ListSessionsFunc: func(ctx context.Context) ([]multiplexer.Session, error) {
+				// Session still exists (user detached, not exited)
+				return []multiplexer.Session{{Name: "hjk-abc12345-sess1"}}, nil
+			},
+		}
+
+		mgr := NewManager(store, nil, nil, mux, nil, ManagerConfig{})
+
+		err := mgr.AttachSession(ctx, "abc12345", "my-session")
+
+		require.NoError(t, err)
+		require.Len(t, mux.AttachSessionCalls(), 1)
+		require.Len(t, store.UpdateCalls(), 1)
+	})
+
+	t.Run("returns ErrSessionNotFound for missing session", func(t *testing.T) {
+		store := &catalogmocks.StoreMock{
+			GetFunc: func(ctx context.Context, id string) (*catalog.Entry, error) {
+				return &catalog.Entry{
+					ID:       "abc12345",
+					Sessions: []catalog.Session{},
+				}, nil
+			},
+		}
+
+		mgr := NewManager(store, nil, nil, nil, nil, ManagerConfig{})
+
+		err := mgr.AttachSession(ctx, "abc12345", "nonexistent")
+
+		assert.ErrorIs(t, err, ErrSessionNotFound)
+	})
+
+	t.Run("cleans up session from catalog when user exits", func(t *testing.T) {
+		oldTime := time.Now().Add(-1 * time.Hour)
+		getCalls := 0
+		updateCalls := 0
+		store := &catalogmocks.StoreMock{
+			GetFunc: func(ctx context.Context, id string) (*catalog.Entry, error) {
+				getCalls++
+				return &catalog.Entry{
+					ID: "abc12345",
+					Sessions: []catalog.Session{
+						{ID: "sess1", Name: "my-session", MuxSessionID: "hjk-abc12345-sess1", LastAccessed: oldTime},
+					},
+				}, nil
+			},
+			UpdateFunc: func(ctx context.Context, entry *catalog.Entry) error {
+				updateCalls++
+				if updateCalls == 2 {
+					// Second update should have removed the session
+					assert.Empty(t, entry.Sessions, "session should be removed from catalog")
+				}
+				return nil
+			},
+		}
+		mux := &muxmocks.MultiplexerMock{
+			AttachSessionFunc: func(ctx context.Context, sessionName string) error {
+				return nil
+			},
+			ListSessionsFunc: func(ctx context.Context) ([]multiplexer.Session, error) {
 				// Session no longer exists (user exited)
 				return []multiplexer.Session{}, nil
 			},
@@ -1162,6 +2458,168 @@ func TestManager_AttachSession(t *testing.T) {
 	})
 }
 
+func TestManager_SendInput(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("sends input and records interaction", func(t *testing.T) {
+		oldTime := time.Now().Add(-1 * time.Hour)
+		store := &catalogmocks.StoreMock{
+			GetFunc: func(ctx context.Context, id string) (*catalog.Entry, error) {
+				return &catalog.Entry{
+					ID:     "abc12345",
+					Branch: "feat/auth",
+					Sessions: []catalog.Session{
+						{ID: "sess1", Name: "my-session", Type: catalog.SessionTypeClaude, MuxSessionID: "hjk-abc12345-sess1", LastAccessed: oldTime},
+					},
+				}, nil
+			},
+			UpdateFunc: func(ctx context.Context, entry *catalog.Entry) error {
+				require.Len(t, entry.Sessions, 1)
+				assert.True(t, entry.Sessions[0].LastInteraction.After(oldTime))
+				// Sending input is not an attach, so MRU order is unchanged
+				assert.Equal(t, oldTime, entry.Sessions[0].LastAccessed)
+				return nil
+			},
+		}
+		mux := &muxmocks.MultiplexerMock{
+			SendKeysFunc: func(ctx context.Context, sessionName string, opts *multiplexer.SendKeysOpts) error {
+				assert.Equal(t, "hjk-abc12345-sess1", sessionName)
+				assert.Equal(t, &multiplexer.SendKeysOpts{Text: "continue", Enter: true}, opts)
+				return nil
+			},
+		}
+		auditor := &auditmocks.RecorderMock{
+			RecordFunc: func(ctx context.Context, event *audit.Event) error {
+				assert.Equal(t, audit.ActionSessionSend, event.Action)
+				assert.Equal(t, "feat/auth", event.Branch)
+				assert.Equal(t, "continue", event.Prompt)
+				return nil
+			},
+		}
+
+		mgr := NewManager(store, nil, nil, mux, nil, ManagerConfig{Auditor: auditor})
+
+		err := mgr.SendInput(ctx, "abc12345", "my-session", &SendInputConfig{Text: "continue", Enter: true})
+
+		require.NoError(t, err)
+		require.Len(t, mux.SendKeysCalls(), 1)
+		require.Len(t, store.UpdateCalls(), 1)
+		require.Len(t, auditor.RecordCalls(), 1)
+	})
+
+	t.Run("returns ErrSessionNotFound for missing session", func(t *testing.T) {
+		store := &catalogmocks.StoreMock{
+			GetFunc: func(ctx context.Context, id string) (*catalog.Entry, error) {
+				return &catalog.Entry{ID: "abc12345"}, nil
+			},
+		}
+
+		mgr := NewManager(store, nil, nil, nil, nil, ManagerConfig{})
+
+		err := mgr.SendInput(ctx, "abc12345", "nonexistent", &SendInputConfig{Text: "hi"})
+
+		assert.ErrorIs(t, err, ErrSessionNotFound)
+	})
+
+	t.Run("cleans up session that exited", func(t *testing.T) {
+		store := &catalogmocks.StoreMock{
+			GetFunc: func(ctx context.Context, id string) (*catalog.Entry, error) {
+				return &catalog.Entry{
+					ID: "abc12345",
+					Sessions: []catalog.Session{
+						{ID: "sess1", Name: "my-session", MuxSessionID: "hjk-abc12345-sess1"},
+					},
+				}, nil
+			},
+			UpdateFunc: func(ctx context.Context, entry *catalog.Entry) error {
+				assert.Empty(t, entry.Sessions, "exited session should be removed from catalog")
+				return nil
+			},
+		}
+		mux := &muxmocks.MultiplexerMock{
+			SendKeysFunc: func(ctx context.Context, sessionName string, opts *multiplexer.SendKeysOpts) error {
+				return multiplexer.ErrSessionNotFound
+			},
+			ListSessionsFunc: func(ctx context.Context) ([]multiplexer.Session, error) {
+				return []multiplexer.Session{}, nil
+			},
+		}
+
+		mgr := NewManager(store, nil, nil, mux, nil, ManagerConfig{})
+
+		err := mgr.SendInput(ctx, "abc12345", "my-session", &SendInputConfig{Text: "hi"})
+
+		require.ErrorIs(t, err, ErrSessionNotFound)
+		require.Len(t, store.UpdateCalls(), 1)
+	})
+}
+
+func TestManager_CaptureSession(t *testing.T) {
+	ctx := context.Background()
+
+	newStore := func() *catalogmocks.StoreMock {
+		return &catalogmocks.StoreMock{
+			GetFunc: func(ctx context.Context, id string) (*catalog.Entry, error) {
+				return &catalog.Entry{
+					ID: "abc12345",
+					Sessions: []catalog.Session{
+						{ID: "sess1", Name: "my-session", MuxSessionID: "hjk-abc12345-sess1"},
+					},
+				}, nil
+			},
+			UpdateFunc: func(ctx context.Context, entry *catalog.Entry) error {
+				return nil
+			},
+		}
+	}
+
+	t.Run("returns captured screen", func(t *testing.T) {
+		store := newStore()
+		mux := &muxmocks.MultiplexerMock{
+			CapturePaneFunc: func(ctx context.Context, sessionName string, opts *multiplexer.CaptureOpts) (string, error) {
+				assert.Equal(t, "hjk-abc12345-sess1", sessionName)
+				assert.Equal(t, &multiplexer.CaptureOpts{History: true}, opts)
+				return "Thinking...\n", nil
+			},
+		}
+
+		mgr := NewManager(store, nil, nil, mux, nil, ManagerConfig{})
+
+		screen, err := mgr.CaptureSession(ctx, "abc12345", "my-session", &CaptureConfig{History: true})
+
+		require.NoError(t, err)
+		assert.Equal(t, "Thinking...\n", screen)
+		assert.Empty(t, store.UpdateCalls(), "capturing must not update the catalog")
+	})
+
+	t.Run("returns ErrSessionNotFound for missing session", func(t *testing.T) {
+		mgr := NewManager(newStore(), nil, nil, nil, nil, ManagerConfig{})
+
+		_, err := mgr.CaptureSession(ctx, "abc12345", "nonexistent", &CaptureConfig{})
+
+		assert.ErrorIs(t, err, ErrSessionNotFound)
+	})
+
+	t.Run("cleans up session that exited", func(t *testing.T) {
+		store := newStore()
+		mux := &muxmocks.MultiplexerMock{
+			CapturePaneFunc: func(ctx context.Context, sessionName string, opts *multiplexer.CaptureOpts) (string, error) {
+				return "", multiplexer.ErrSessionNotFound
+			},
+			ListSessionsFunc: func(ctx context.Context) ([]multiplexer.Session, error) {
+				return []multiplexer.Session{}, nil
+			},
+		}
+
+		mgr := NewManager(store, nil, nil, mux, nil, ManagerConfig{})
+
+		_, err := mgr.CaptureSession(ctx, "abc12345", "my-session", &CaptureConfig{})
+
+		require.ErrorIs(t, err, ErrSessionNotFound)
+		require.Len(t, store.UpdateCalls(), 1)
+	})
+}
+
 func TestManager_GetMRUSession(t *testing.T) {
 	ctx := context.Background()
 
@@ -1222,6 +2680,285 @@ func TestManager_GetMRUSession(t *testing.T) {
 	})
 }
 
+func TestManager_runAgentSetup(t *testing.T) {
+	ctx := context.Background()
+	env := []string{"CODEX_AUTH_JSON={}"}
+
+	newRuntime := func() *containermocks.RuntimeMock {
+		return &containermocks.RuntimeMock{
+			ExecFunc: func(ctx context.Context, containerID string, cfg container.ExecConfig) error {
+				return nil
+			},
+		}
+	}
+
+	t.Run("runs the auth setup only when the credential needs it", func(t *testing.T) {
+		runtime := newRuntime()
+		mgr := NewManager(nil, runtime, nil, nil, nil, ManagerConfig{})
+
+		require.NoError(t, mgr.runAgentSetup(ctx, "container123", catalog.SessionTypeCodex, env, false))
+		assert.Empty(t, runtime.ExecCalls())
+
+		require.NoError(t, mgr.runAgentSetup(ctx, "container123", catalog.SessionTypeCodex, env, true))
+		require.Len(t, runtime.ExecCalls(), 1)
+		assert.Contains(t, runtime.ExecCalls()[0].Cfg.Command[2], "~/.codex/auth.json")
+		assert.Equal(t, env, runtime.ExecCalls()[0].Cfg.Env)
+	})
+
+	t.Run("runs a custom agent's setup script", func(t *testing.T) {
+		agents, err := agent.NewRegistry(agent.Definition{
+			Name:    "aider",
+			Command: []string{"aider"},
+			Setup:   "pip install aider-chat",
+		})
+		require.NoError(t, err)
+		runtime := newRuntime()
+		mgr := NewManager(nil, runtime, nil, nil, nil, ManagerConfig{Agents: agents})
+
+		require.NoError(t, mgr.runAgentSetup(ctx, "container123", catalog.SessionType("aider"), nil, false))
+
+		require.Len(t, runtime.ExecCalls(), 1)
+		assert.Equal(t, []string{"sh", "-c", "pip install aider-chat"}, runtime.ExecCalls()[0].Cfg.Command)
+	})
+
+	t.Run("skips shells and unknown agents", func(t *testing.T) {
+		runtime := newRuntime()
+		mgr := NewManager(nil, runtime, nil, nil, nil, ManagerConfig{})
+
+		require.NoError(t, mgr.runAgentSetup(ctx, "container123", catalog.SessionTypeShell, nil, true))
+		require.NoError(t, mgr.runAgentSetup(ctx, "container123", catalog.SessionType("aider"), nil, true))
+		assert.Empty(t, runtime.ExecCalls())
+	})
+}
+
+func TestManager_PropagateCredential(t *testing.T) {
+	ctx := context.Background()
+	env := []string{"CODEX_AUTH_JSON={\"tokens\":{}}"}
+
+	entries := []catalog.Entry{
+		{ID: "inst1", Branch: "main", ContainerID: "c1", Status: catalog.StatusRunning, Sessions: []catalog.Session{
+			{Name: "busy-bee", Type: catalog.SessionTypeCodex},
+			{Name: "calm-cat", Type: catalog.SessionTypeClaude, AuthProfile: "work"},
+		}},
+		{ID: "inst2", Branch: "feat", ContainerID: "c2", Status: catalog.StatusRunning, Sessions: []catalog.Session{
+			{Name: "dark-dog", Type: catalog.SessionTypeCodex, AuthProfile: "work"},
+		}},
+		{ID: "inst3", Branch: "idle", ContainerID: "c3", Status: catalog.StatusRunning},
+		{ID: "inst4", Branch: "gone", ContainerID: "c4", Status: catalog.StatusRunning},
+	}
+	store := &catalogmocks.StoreMock{
+		ListFunc: func(ctx context.Context, filter catalog.ListFilter) ([]catalog.Entry, error) {
+			assert.Equal(t, catalog.StatusRunning, filter.Status)
+			return entries, nil
+		},
+		GetFunc: func(ctx context.Context, id string) (*catalog.Entry, error) {
+			for i := range entries {
+				if entries[i].ID == id {
+					entry := entries[i]
+					return &entry, nil
+				}
+			}
+			return nil, catalog.ErrNotFound
+		},
+	}
+	newRuntime := func() *containermocks.RuntimeMock {
+		return &containermocks.RuntimeMock{
+			GetFunc: func(ctx context.Context, id string) (*container.Container, error) {
+				if id == "c4" {
+					return &container.Container{ID: id, Status: container.StatusStopped}, nil
+				}
+				return &container.Container{ID: id, Status: container.StatusRunning}, nil
+			},
+			ExecFunc: func(ctx context.Context, containerID string, cfg container.ExecConfig) error {
+				return nil
+			},
+		}
+	}
+
+	t.Run("rewrites subscription credentials", func(t *testing.T) {
+		runtime := newRuntime()
+		mgr := NewManager(store, runtime, nil, nil, nil, ManagerConfig{})
+
+		updates, err := mgr.PropagateCredential(ctx, &PropagateCredentialConfig{
+			Auth:               "codex",
+			Profile:            "default",
+			Env:                env,
+			RequiresAgentSetup: true,
+		})
+
+		require.NoError(t, err)
+		require.Len(t, updates, 3, "stopped instances are left out")
+
+		assert.Equal(t, "inst1", updates[0].InstanceID)
+		assert.True(t, updates[0].Updated)
+		assert.Equal(t, []string{"busy-bee"}, updates[0].Restart, "sessions of other providers keep running")
+
+		assert.Equal(t, "inst2", updates[1].InstanceID)
+		assert.False(t, updates[1].Updated)
+		assert.Contains(t, updates[1].Skipped, "dark-dog uses auth profile work")
+		assert.Empty(t, updates[1].Restart)
+
+		assert.True(t, updates[2].Updated)
+		assert.Empty(t, updates[2].Restart)
+
+		require.Len(t, runtime.ExecCalls(), 2)
+		for _, call := range runtime.ExecCalls() {
+			assert.Contains(t, call.Cfg.Command[2], "~/.codex/auth.json")
+			assert.Equal(t, env, call.Cfg.Env)
+		}
+		assert.Equal(t, "c1", runtime.ExecCalls()[0].ID)
+		assert.Equal(t, "c3", runtime.ExecCalls()[1].ID)
+	})
+
+	t.Run("reports sessions of environment credentials", func(t *testing.T) {
+		runtime := newRuntime()
+		mgr := NewManager(store, runtime, nil, nil, nil, ManagerConfig{})
+
+		updates, err := mgr.PropagateCredential(ctx, &PropagateCredentialConfig{
+			Auth:    "claude",
+			Profile: "work",
+			Env:     []string{"ANTHROPIC_API_KEY=sk-ant-api03-new"},
+		})
+
+		require.NoError(t, err)
+		require.Len(t, updates, 3)
+		assert.False(t, updates[0].Updated)
+		assert.Equal(t, []string{"calm-cat"}, updates[0].Restart)
+		assert.Empty(t, runtime.ExecCalls())
+	})
+}
+
+func TestManager_InspectImage(t *testing.T) {
+	ctx := context.Background()
+	fetchedAt := time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC)
+
+	t.Run("returns headjack labels from the registry", func(t *testing.T) {
+		reg := &registrymocks.ClientMock{
+			GetMetadataFunc: func(ctx context.Context, ref string) (*registry.ImageMetadata, error) {
+				return &registry.ImageMetadata{
+					Digest: "sha256:abc123",
+					Labels: map[string]string{
+						"io.headjack.init":               "/lib/systemd/systemd",
+						"org.opencontainers.image.title": "headjack",
+					},
+				}, nil
+			},
+		}
+		mgr := NewManager(nil, nil, nil, nil, reg, ManagerConfig{})
+
+		info, err := mgr.InspectImage(ctx, "myimage:systemd")
+
+		require.NoError(t, err)
+		assert.Equal(t, ImageSourceRegistry, info.Source)
+		assert.Equal(t, "sha256:abc123", info.Digest)
+		assert.Equal(t, map[string]string{"io.headjack.init": "/lib/systemd/systemd"}, info.Labels)
+	})
+
+	t.Run("reports cached metadata", func(t *testing.T) {
+		reg := &registrymocks.ClientMock{
+			GetMetadataFunc: func(ctx context.Context, ref string) (*registry.ImageMetadata, error) {
+				return &registry.ImageMetadata{Digest: "sha256:abc123", Cached: true, FetchedAt: fetchedAt}, nil
+			},
+		}
+		mgr := NewManager(nil, nil, nil, nil, reg, ManagerConfig{})
+
+		info, err := mgr.InspectImage(ctx, "myimage:systemd")
+
+		require.NoError(t, err)
+		assert.Equal(t, ImageSourceCache, info.Source)
+		assert.Equal(t, fetchedAt, info.FetchedAt)
+	})
+
+	t.Run("prefers the local image store", func(t *testing.T) {
+		reg := &registrymocks.ClientMock{
+			GetMetadataFunc: func(ctx context.Context, ref string) (*registry.ImageMetadata, error) {
+				return &registry.ImageMetadata{Digest: "sha256:abc123"}, nil
+			},
+		}
+		runtime := &containermocks.RuntimeMock{
+			InspectImageFunc: func(ctx context.Context, ref string) (*container.Image, error) {
+				return &container.Image{
+					ID:           "sha256:def456",
+					Labels:       map[string]string{"io.headjack.podman.flags": "systemd=always"},
+					User:         "dev",
+					WorkingDir:   "/workspace",
+					Env:          []string{"PATH=/usr/bin"},
+					Entrypoint:   []string{"/init"},
+					Cmd:          []string{"bash"},
+					Architecture: "arm64",
+					OS:           "linux",
+				}, nil
+			},
+		}
+		mgr := NewManager(nil, runtime, nil, nil, reg, ManagerConfig{})
+
+		info, err := mgr.InspectImage(ctx, "myimage:dev")
+
+		require.NoError(t, err)
+		assert.Equal(t, ImageSourceLocal, info.Source)
+		assert.Empty(t, info.Digest)
+		assert.Equal(t, "systemd=always", info.Labels["io.headjack.podman.flags"])
+		assert.Equal(t, "dev", info.User)
+		assert.Equal(t, "/workspace", info.WorkingDir)
+		assert.Equal(t, []string{"PATH=/usr/bin"}, info.Env)
+		assert.Equal(t, []string{"/init"}, info.Entrypoint)
+		assert.Equal(t, []string{"bash"}, info.Cmd)
+		assert.Equal(t, "linux", info.OS)
+		assert.Equal(t, "arm64", info.Architecture)
+		require.Len(t, runtime.InspectImageCalls(), 1)
+		assert.Equal(t, "myimage:dev", runtime.InspectImageCalls()[0].Ref)
+		assert.Empty(t, reg.GetMetadataCalls())
+	})
+
+	t.Run("falls back to the registry when the image is not local", func(t *testing.T) {
+		reg := &registrymocks.ClientMock{
+			GetMetadataFunc: func(ctx context.Context, ref string) (*registry.ImageMetadata, error) {
+				return &registry.ImageMetadata{
+					Digest:       "sha256:abc123",
+					Labels:       map[string]string{"io.headjack.init": "/lib/systemd/systemd"},
+					Architecture: "amd64",
+					OS:           "linux",
+				}, nil
+			},
+		}
+		runtime := &containermocks.RuntimeMock{
+			InspectImageFunc: func(ctx context.Context, ref string) (*container.Image, error) {
+				return nil, container.ErrImageNotFound
+			},
+		}
+		mgr := NewManager(nil, runtime, nil, nil, reg, ManagerConfig{})
+
+		info, err := mgr.InspectImage(ctx, "myimage:systemd")
+
+		require.NoError(t, err)
+		assert.Equal(t, ImageSourceRegistry, info.Source)
+		assert.Equal(t, "amd64", info.Architecture)
+		assert.Empty(t, info.User, "runtime defaults are only known for local images")
+		assert.Equal(t, "/lib/systemd/systemd", info.Labels["io.headjack.init"])
+		require.Len(t, reg.GetMetadataCalls(), 1)
+	})
+
+	t.Run("returns ErrNoImageMetadata when no source has the image", func(t *testing.T) {
+		reg := &registrymocks.ClientMock{
+			GetMetadataFunc: func(ctx context.Context, ref string) (*registry.ImageMetadata, error) {
+				return nil, registry.ErrImageNotFound
+			},
+		}
+		runtime := &containermocks.RuntimeMock{
+			InspectImageFunc: func(ctx context.Context, ref string) (*container.Image, error) {
+				return nil, container.ErrImageNotFound
+			},
+		}
+		mgr := NewManager(nil, runtime, nil, nil, reg, ManagerConfig{})
+
+		_, err := mgr.InspectImage(ctx, "myimage:systemd")
+
+		assert.ErrorIs(t, err, ErrNoImageMetadata)
+		assert.ErrorIs(t, err, registry.ErrImageNotFound)
+		assert.ErrorIs(t, err, container.ErrImageNotFound)
+	})
+}
+
 func TestGetImageRuntimeConfig(t *testing.T) {
 	ctx := context.Background()
 
@@ -1254,6 +2991,33 @@ func TestGetImageRuntimeConfig(t *testing.T) {
 		require.Len(t, reg.GetMetadataCalls(), 1)
 	})
 
+	t.Run("uses local image labels", func(t *testing.T) {
+		reg := &registrymocks.ClientMock{
+			GetMetadataFunc: func(ctx context.Context, ref string) (*registry.ImageMetadata, error) {
+				return nil, errors.New("registry unavailable")
+			},
+		}
+		runtime := &containermocks.RuntimeMock{
+			InspectImageFunc: func(ctx context.Context, ref string) (*container.Image, error) {
+				return &container.Image{
+					Labels: map[string]string{
+						"io.headjack.init":         "/lib/systemd/systemd",
+						"io.headjack.podman.flags": "systemd=always",
+					},
+				}, nil
+			},
+		}
+
+		mgr := NewManager(nil, runtime, nil, nil, reg, ManagerConfig{
+			RuntimeType: RuntimePodman,
+		})
+
+		cfg := mgr.getImageRuntimeConfig(ctx, "myimage:systemd")
+
+		assert.Equal(t, "/lib/systemd/systemd", cfg.Init)
+		assert.Equal(t, "always", cfg.Flags["systemd"])
+	})
+
 	t.Run("extracts init label", func(t *testing.T) {
 		reg := &registrymocks.ClientMock{
 			GetMetadataFunc: func(ctx context.Context, ref string) (*registry.ImageMetadata, error) {
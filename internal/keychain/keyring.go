@@ -31,15 +31,7 @@ func New() (Keychain, error) {
 
 // NewWithConfig creates a new Keychain with the specified configuration.
 func NewWithConfig(cfg Config) (Keychain, error) {
-	backend := cfg.Backend
-	if backend == BackendAuto {
-		backend = detectBackend()
-	}
-
-	// Check for environment variable override
-	if envBackend := os.Getenv(EnvKeyringBackend); envBackend != "" {
-		backend = Backend(envBackend)
-	}
+	backend := ActiveBackend(cfg)
 
 	ring, err := openKeyring(backend, cfg)
 	if err != nil {
@@ -49,6 +41,19 @@ func NewWithConfig(cfg Config) (Keychain, error) {
 	return &keyringStore{ring: ring}, nil
 }
 
+// ActiveBackend returns the backend NewWithConfig opens for cfg: the
+// HEADJACK_KEYRING_BACKEND override if set, else the configured backend, else
+// the best available backend for the platform.
+func ActiveBackend(cfg Config) Backend {
+	if envBackend := os.Getenv(EnvKeyringBackend); envBackend != "" {
+		return Backend(envBackend)
+	}
+	if cfg.Backend == BackendAuto {
+		return detectBackend()
+	}
+	return cfg.Backend
+}
+
 // detectBackend returns the best available backend for the current platform.
 func detectBackend() Backend {
 	switch runtime.GOOS {
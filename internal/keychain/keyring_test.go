@@ -212,6 +212,21 @@ func TestEnvBackendOverride(t *testing.T) {
 	}
 }
 
+func TestActiveBackend(t *testing.T) {
+	t.Setenv(EnvKeyringBackend, "")
+	if got := ActiveBackend(Config{Backend: BackendFile}); got != BackendFile {
+		t.Errorf("ActiveBackend() = %v, want configured %v", got, BackendFile)
+	}
+	if got := ActiveBackend(Config{}); got != detectBackend() {
+		t.Errorf("ActiveBackend() = %v, want detected %v", got, detectBackend())
+	}
+
+	t.Setenv(EnvKeyringBackend, string(BackendKeyctl))
+	if got := ActiveBackend(Config{Backend: BackendFile}); got != BackendKeyctl {
+		t.Errorf("ActiveBackend() with env override = %v, want %v", got, BackendKeyctl)
+	}
+}
+
 func TestDefaultPasswordFunc_EnvVar(t *testing.T) {
 	t.Setenv(EnvKeyringPassword, "env-password")
 
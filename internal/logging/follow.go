@@ -0,0 +1,420 @@
+package logging
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// maxPartialLine is how much of an unterminated line FollowMany holds back
+// before writing it anyway, so output redrawn without newlines still shows.
+const maxPartialLine = 4096
+
+// FollowTarget is a session log followed by FollowMany.
+type FollowTarget struct {
+	InstanceID string
+	SessionID  string
+	Prefix     string // Written before every line from this session
+}
+
+// Follow streams new log output to the provided writer as it is appended.
+// This is similar to `tail -F`: when the log is rotated, the rest of the old
+// file is drained and the new live log is followed from its start, and when
+// it is truncated, it is followed from its new end. It blocks until the
+// context is canceled.
+//
+// Changes are detected with filesystem notifications where available; if
+// they are not, the log is polled every pollInterval.
+func (r *Reader) Follow(ctx context.Context, instanceID, sessionID string, out io.Writer, pollInterval time.Duration) error {
+	f := &follower{path: r.pathMgr.SessionLogPath(instanceID, sessionID), out: out}
+	if err := f.open(true); err != nil {
+		return err
+	}
+	defer f.close()
+
+	return followAll(ctx, []*follower{f}, pollInterval)
+}
+
+// FollowWithHistory reads the last n lines and then follows new output.
+// This is similar to `tail -n N -f`.
+func (r *Reader) FollowWithHistory(ctx context.Context, instanceID, sessionID string, out io.Writer, n int, pollInterval time.Duration) error {
+	// First, output the last N lines
+	lines, err := r.ReadLastN(instanceID, sessionID, n)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(out, line); err != nil {
+			return fmt.Errorf("write history: %w", err)
+		}
+	}
+
+	// Then start following
+	return r.Follow(ctx, instanceID, sessionID, out, pollInterval)
+}
+
+// TailMany writes the last n lines of each target's log, one target after
+// another, prefixing every line with the target's prefix. If n <= 0, each
+// log is written in full. Targets without a log are skipped.
+func (r *Reader) TailMany(targets []FollowTarget, out io.Writer, n int) error {
+	for _, t := range targets {
+		if err := r.tail(t, &prefixWriter{out: out, prefix: t.Prefix}, n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tail writes the last n lines (or, if n <= 0, all) of a target's log.
+func (r *Reader) tail(t FollowTarget, w io.Writer, n int) error {
+	var lines []string
+	var err error
+	if n <= 0 {
+		lines, err = r.ReadAll(t.InstanceID, t.SessionID)
+	} else {
+		lines, err = r.ReadLastN(t.InstanceID, t.SessionID, n)
+	}
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return fmt.Errorf("write history: %w", err)
+		}
+	}
+	return nil
+}
+
+// FollowMany writes the last n lines of each target's log as TailMany does,
+// then follows all of them at once in a single loop, merging their output
+// line by line with each target's prefix. Targets whose log does not exist
+// yet are followed from the moment it appears. It blocks until the context
+// is canceled.
+func (r *Reader) FollowMany(ctx context.Context, targets []FollowTarget, out io.Writer, n int, pollInterval time.Duration) error {
+	followers := make([]*follower, 0, len(targets))
+	writers := make([]*prefixWriter, 0, len(targets))
+	defer func() {
+		for _, f := range followers {
+			f.close()
+		}
+	}()
+
+	for _, t := range targets {
+		pw := &prefixWriter{out: out, prefix: t.Prefix}
+		writers = append(writers, pw)
+
+		if err := r.tail(t, pw, n); err != nil {
+			return err
+		}
+
+		f := &follower{path: r.pathMgr.SessionLogPath(t.InstanceID, t.SessionID), out: pw}
+		if err := f.open(true); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		followers = append(followers, f)
+	}
+
+	err := followAll(ctx, followers, pollInterval)
+	for _, pw := range writers {
+		_ = pw.Flush()
+	}
+	return err
+}
+
+// followAll copies new output from every follower until the context is
+// canceled, waking on filesystem events for the logs' directories, or on
+// every pollInterval if notifications are unavailable.
+func followAll(ctx context.Context, followers []*follower, pollInterval time.Duration) error {
+	pollAll := func() error {
+		for _, f := range followers {
+			if err := f.poll(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	watcher, err := newDirWatcher(followers)
+	if err != nil {
+		// Notifications unavailable (e.g., inotify limits); poll instead
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-ticker.C:
+				if err := pollAll(); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	defer watcher.Close()
+
+	// Catch anything written between opening the logs and watching them
+	if err := pollAll(); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			for _, f := range followers {
+				if f.affectedBy(event.Name) {
+					if err := f.poll(); err != nil {
+						return err
+					}
+				}
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			// Events may have been dropped; catch up on every log
+			if errors.Is(err, fsnotify.ErrEventOverflow) {
+				if err := pollAll(); err != nil {
+					return err
+				}
+				continue
+			}
+			return fmt.Errorf("watch log files: %w", err)
+		}
+	}
+}
+
+// newDirWatcher watches the directories containing the followed logs.
+// Watching directories rather than files also reports rotation and creation.
+func newDirWatcher(followers []*follower) (*fsnotify.Watcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("create watcher: %w", err)
+	}
+
+	watched := make(map[string]bool)
+	for _, f := range followers {
+		dir := filepath.Dir(f.path)
+		if watched[dir] {
+			continue
+		}
+		if err := watcher.Add(dir); err != nil {
+			_ = watcher.Close()
+			return nil, fmt.Errorf("watch %s: %w", dir, err)
+		}
+		watched[dir] = true
+	}
+	return watcher, nil
+}
+
+// follower tracks the read position in one followed log.
+type follower struct {
+	path   string
+	out    io.Writer
+	file   *os.File // nil until the log exists
+	reader *bufio.Reader
+	offset int64 // Bytes read from file
+}
+
+// open opens the live log, positioned at its end if seekEnd is set.
+func (f *follower) open(seekEnd bool) error {
+	//nolint:gosec // G304: path is constructed from trusted PathManager, not arbitrary user input
+	file, err := os.Open(f.path)
+	if err != nil {
+		return fmt.Errorf("open log file: %w", err)
+	}
+
+	var offset int64
+	if seekEnd {
+		if offset, err = file.Seek(0, io.SeekEnd); err != nil {
+			_ = file.Close()
+			return fmt.Errorf("seek to end: %w", err)
+		}
+	}
+
+	f.file = file
+	f.offset = offset
+	if f.reader == nil {
+		f.reader = bufio.NewReader(file)
+	} else {
+		f.reader.Reset(file)
+	}
+	return nil
+}
+
+func (f *follower) close() {
+	if f.file != nil {
+		_ = f.file.Close()
+		f.file = nil
+	}
+}
+
+// affectedBy reports whether a change to path may affect this log: the live
+// log itself or one of its rotated segments.
+func (f *follower) affectedBy(path string) bool {
+	return path == f.path || filepath.Dir(path) == filepath.Dir(f.path) &&
+		len(path) > len(f.path) && path[:len(f.path)+1] == f.path+"."
+}
+
+// poll copies any new output, then handles truncation and rotation.
+func (f *follower) poll() error {
+	if f.file == nil {
+		// Log created after following started: read it from the start
+		if err := f.open(false); err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+	}
+
+	if err := f.copyAvailable(); err != nil {
+		return err
+	}
+
+	// Truncated in place: continue from the new end of the file
+	info, err := f.file.Stat()
+	if err != nil {
+		return fmt.Errorf("stat log file: %w", err)
+	}
+	if info.Size() < f.offset {
+		offset, err := f.file.Seek(0, io.SeekEnd)
+		if err != nil {
+			return fmt.Errorf("seek to end: %w", err)
+		}
+		f.offset = offset
+		f.reader.Reset(f.file)
+	}
+
+	next, err := reopenIfRotated(f.path, f.file)
+	if err != nil || next == nil {
+		return err
+	}
+
+	// Drain anything written to the old file before it was rotated
+	if err := f.copyAvailable(); err != nil {
+		_ = next.Close()
+		return err
+	}
+	_ = f.file.Close()
+	f.file = next
+	f.offset = 0
+	f.reader.Reset(next)
+	return f.copyAvailable()
+}
+
+// copyAvailable copies everything currently readable to the output.
+// Partial lines are written as they arrive.
+func (f *follower) copyAvailable() error {
+	for {
+		line, err := f.reader.ReadBytes('\n')
+		// Always write any data we received, even with EOF
+		if len(line) > 0 {
+			f.offset += int64(len(line))
+			if _, werr := f.out.Write(line); werr != nil {
+				return fmt.Errorf("write output: %w", werr)
+			}
+		}
+		if err != nil {
+			if err == io.EOF {
+				// No more data, wait for the next change
+				return nil
+			}
+			return fmt.Errorf("read line: %w", err)
+		}
+	}
+}
+
+// reopenIfRotated opens path if it no longer refers to the open file, which
+// happens when the log is rotated. It returns nil while the path is unchanged
+// or briefly missing mid-rotation.
+func reopenIfRotated(path string, file *os.File) (*os.File, error) {
+	current, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("stat log file: %w", err)
+	}
+	open, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("stat log file: %w", err)
+	}
+	if os.SameFile(current, open) {
+		return nil, nil
+	}
+
+	//nolint:gosec // G304: path is constructed from trusted PathManager, not arbitrary user input
+	next, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reopen log file: %w", err)
+	}
+	return next, nil
+}
+
+// prefixWriter writes whole lines to out, each preceded by prefix. Partial
+// lines are held until they are completed, or until they grow past
+// maxPartialLine, so lines from different sessions never interleave.
+type prefixWriter struct {
+	out     io.Writer
+	prefix  string
+	partial []byte
+}
+
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	w.partial = append(w.partial, p...)
+	for {
+		i := bytes.IndexByte(w.partial, '\n')
+		if i < 0 {
+			break
+		}
+		if err := w.writeLine(w.partial[:i+1]); err != nil {
+			return 0, err
+		}
+		w.partial = w.partial[i+1:]
+	}
+	if len(w.partial) > maxPartialLine {
+		if err := w.Flush(); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Flush writes any held partial line, terminated with a newline.
+func (w *prefixWriter) Flush() error {
+	if len(w.partial) == 0 {
+		return nil
+	}
+	line := append(w.partial, '\n')
+	w.partial = nil
+	return w.writeLine(line)
+}
+
+func (w *prefixWriter) writeLine(line []byte) error {
+	buf := make([]byte, 0, len(w.prefix)+len(line))
+	buf = append(buf, w.prefix...)
+	buf = append(buf, line...)
+	_, err := w.out.Write(buf)
+	return err
+}
@@ -0,0 +1,210 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lockedBuffer is a bytes.Buffer safe for a follower writing while the test reads.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// startFollow runs follow in the background and returns a function that stops
+// it and returns its error.
+func startFollow(t *testing.T, follow func(ctx context.Context) error) func() error {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- follow(ctx) }()
+	t.Cleanup(cancel)
+
+	// Let the follower open its files and start watching
+	time.Sleep(100 * time.Millisecond)
+
+	return func() error {
+		cancel()
+		return <-done
+	}
+}
+
+func appendLog(t *testing.T, path, data string) {
+	t.Helper()
+	//nolint:gosec // G304: path is from test temp dir
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	require.NoError(t, err)
+	_, err = f.WriteString(data)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+}
+
+func TestReader_Follow_Truncation(t *testing.T) {
+	dir := t.TempDir()
+	path := createTestLog(t, dir, "inst1", "sess1", []string{"old content that is long"})
+	reader := NewReader(NewPathManager(dir))
+	out := &lockedBuffer{}
+
+	stop := startFollow(t, func(ctx context.Context) error {
+		// A long poll interval shows changes are picked up by notifications
+		return reader.Follow(ctx, "inst1", "sess1", out, time.Hour)
+	})
+
+	appendLog(t, path, "before\n")
+	require.Eventually(t, func() bool { return out.String() == "before\n" }, 2*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, os.Truncate(path, 0))
+	time.Sleep(50 * time.Millisecond)
+	appendLog(t, path, "after truncate\n")
+
+	require.Eventually(t, func() bool {
+		return out.String() == "before\nafter truncate\n"
+	}, 2*time.Second, 10*time.Millisecond)
+	require.ErrorIs(t, stop(), context.Canceled)
+}
+
+func TestReader_Follow_RenameRotation(t *testing.T) {
+	dir := t.TempDir()
+	path := createTestLog(t, dir, "inst1", "sess1", nil)
+	reader := NewReader(NewPathManager(dir))
+	out := &lockedBuffer{}
+
+	stop := startFollow(t, func(ctx context.Context) error {
+		return reader.Follow(ctx, "inst1", "sess1", out, time.Hour)
+	})
+
+	appendLog(t, path, "first\n")
+	require.NoError(t, os.Rename(path, path+".1"))
+	appendLog(t, path, "second\n")
+
+	require.Eventually(t, func() bool {
+		return out.String() == "first\nsecond\n"
+	}, 2*time.Second, 10*time.Millisecond)
+	require.ErrorIs(t, stop(), context.Canceled)
+}
+
+func TestReader_FollowMany(t *testing.T) {
+	dir := t.TempDir()
+	pm := NewPathManager(dir)
+	pathA := createTestLog(t, dir, "inst1", "a", []string{"a history 1", "a history 2"})
+	pathB := createTestLog(t, dir, "inst1", "b", []string{"b history"})
+	reader := NewReader(pm)
+	out := &lockedBuffer{}
+
+	targets := []FollowTarget{
+		{InstanceID: "inst1", SessionID: "a", Prefix: "feat/x/a | "},
+		{InstanceID: "inst1", SessionID: "b", Prefix: "feat/x/b | "},
+		{InstanceID: "inst1", SessionID: "later", Prefix: "feat/x/later | "},
+	}
+	stop := startFollow(t, func(ctx context.Context) error {
+		return reader.FollowMany(ctx, targets, out, 1, time.Hour)
+	})
+
+	assert.Equal(t, "feat/x/a | a history 2\nfeat/x/b | b history\n", out.String(), "last n lines of each log")
+
+	// Partial lines are held until complete so sessions never interleave mid-line
+	appendLog(t, pathA, "a par")
+	appendLog(t, pathB, "b line\n")
+	time.Sleep(100 * time.Millisecond)
+	appendLog(t, pathA, "tial\n")
+
+	// Logs created after following started are read from the start
+	appendLog(t, pm.SessionLogPath("inst1", "later"), "late line\n")
+
+	require.Eventually(t, func() bool {
+		return strings.Contains(out.String(), "feat/x/later | late line\n")
+	}, 2*time.Second, 10*time.Millisecond)
+	require.ErrorIs(t, stop(), context.Canceled)
+
+	assert.Equal(t, "feat/x/a | a history 2\nfeat/x/b | b history\n"+
+		"feat/x/b | b line\nfeat/x/a | a partial\nfeat/x/later | late line\n", out.String())
+}
+
+func TestReader_FollowMany_PollingFallback(t *testing.T) {
+	dir := t.TempDir()
+	pm := NewPathManager(dir)
+	reader := NewReader(pm)
+	out := &lockedBuffer{}
+
+	// The instance directory does not exist yet, so it cannot be watched
+	targets := []FollowTarget{{InstanceID: "inst1", SessionID: "sess1", Prefix: "> "}}
+	stop := startFollow(t, func(ctx context.Context) error {
+		return reader.FollowMany(ctx, targets, out, 10, 20*time.Millisecond)
+	})
+
+	createTestLog(t, dir, "inst1", "sess1", []string{"appeared"})
+
+	require.Eventually(t, func() bool {
+		return out.String() == "> appeared\n"
+	}, 2*time.Second, 10*time.Millisecond)
+	require.ErrorIs(t, stop(), context.Canceled)
+}
+
+func TestPrefixWriter(t *testing.T) {
+	t.Run("prefixes complete lines", func(t *testing.T) {
+		var out bytes.Buffer
+		w := &prefixWriter{out: &out, prefix: "p | "}
+
+		_, err := w.Write([]byte("one\ntwo\nthr"))
+		require.NoError(t, err)
+		assert.Equal(t, "p | one\np | two\n", out.String())
+
+		require.NoError(t, w.Flush())
+		assert.Equal(t, "p | one\np | two\np | thr\n", out.String())
+	})
+
+	t.Run("writes long partial lines", func(t *testing.T) {
+		var out bytes.Buffer
+		w := &prefixWriter{out: &out, prefix: "p | "}
+
+		_, err := w.Write(bytes.Repeat([]byte("x"), maxPartialLine+1))
+		require.NoError(t, err)
+		assert.Equal(t, "p | "+strings.Repeat("x", maxPartialLine+1)+"\n", out.String())
+	})
+}
+
+func TestReader_TailMany(t *testing.T) {
+	dir := t.TempDir()
+	createTestLog(t, dir, "inst1", "a", []string{"a1", "a2", "a3"})
+	createTestLog(t, dir, "inst1", "b", []string{"b1"})
+	reader := NewReader(NewPathManager(dir))
+
+	targets := []FollowTarget{
+		{InstanceID: "inst1", SessionID: "a", Prefix: "a | "},
+		{InstanceID: "inst1", SessionID: "missing", Prefix: "m | "},
+		{InstanceID: "inst1", SessionID: "b", Prefix: "b | "},
+	}
+
+	t.Run("last n lines of each log", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, reader.TailMany(targets, &out, 2))
+		assert.Equal(t, "a | a2\na | a3\nb | b1\n", out.String())
+	})
+
+	t.Run("whole logs", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, reader.TailMany(targets, &out, 0))
+		assert.Equal(t, "a | a1\na | a2\na | a3\nb | b1\n", out.String())
+	})
+}
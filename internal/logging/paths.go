@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 // PathManager handles log file path construction and directory management.
@@ -35,6 +36,12 @@ func (p *PathManager) SessionLogPath(instanceID, sessionID string) string {
 	return filepath.Join(p.baseDir, instanceID, sessionID+".log")
 }
 
+// TranscriptPath returns the full path for a session's structured transcript.
+// Path format: <baseDir>/<instanceID>/<sessionID>.transcript.jsonl
+func (p *PathManager) TranscriptPath(instanceID, sessionID string) string {
+	return filepath.Join(p.baseDir, instanceID, sessionID+".transcript.jsonl")
+}
+
 // EnsureInstanceDir creates the instance log directory if it doesn't exist.
 // Returns the instance directory path.
 func (p *PathManager) EnsureInstanceDir(instanceID string) (string, error) {
@@ -54,19 +61,33 @@ func (p *PathManager) EnsureSessionLog(instanceID, sessionID string) (string, er
 	return p.SessionLogPath(instanceID, sessionID), nil
 }
 
-// LogExists checks if a log file exists for the given session.
+// LogExists checks if a log file, or a rotated segment of one, exists for
+// the given session.
 func (p *PathManager) LogExists(instanceID, sessionID string) bool {
-	path := p.SessionLogPath(instanceID, sessionID)
-	_, err := os.Stat(path)
-	return err == nil
+	paths, err := segmentPaths(p.SessionLogPath(instanceID, sessionID))
+	return err == nil && len(paths) > 0
 }
 
-// RemoveSessionLog removes a session's log file if it exists.
+// RemoveSessionLog removes a session's log file, its rotated segments, and
+// its transcript if they exist.
 func (p *PathManager) RemoveSessionLog(instanceID, sessionID string) error {
 	path := p.SessionLogPath(instanceID, sessionID)
 	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf("remove session log: %w", err)
 	}
+	segments, err := rotatedSegments(path)
+	if err != nil {
+		return err
+	}
+	for _, seg := range segments {
+		if err := os.Remove(seg.path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("remove session log segment: %w", err)
+		}
+	}
+	path = p.TranscriptPath(instanceID, sessionID)
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("remove session transcript: %w", err)
+	}
 	return nil
 }
 
@@ -80,6 +101,7 @@ func (p *PathManager) RemoveInstanceLogs(instanceID string) error {
 }
 
 // ListSessionLogs returns a list of session IDs that have log files for the given instance.
+// Sessions whose only remaining logs are rotated segments are included.
 func (p *PathManager) ListSessionLogs(instanceID string) ([]string, error) {
 	dir := p.InstanceDir(instanceID)
 	entries, err := os.ReadDir(dir)
@@ -91,13 +113,18 @@ func (p *PathManager) ListSessionLogs(instanceID string) ([]string, error) {
 	}
 
 	var sessions []string
+	seen := make(map[string]bool)
 	for _, entry := range entries {
 		if entry.IsDir() {
 			continue
 		}
-		name := entry.Name()
-		if ext := filepath.Ext(name); ext == ".log" {
-			sessions = append(sessions, name[:len(name)-len(ext)])
+		id, ok := strings.CutSuffix(entry.Name(), ".log")
+		if !ok {
+			id, _, ok = strings.Cut(entry.Name(), ".log.")
+		}
+		if ok && !seen[id] {
+			seen[id] = true
+			sessions = append(sessions, id)
 		}
 	}
 	return sessions, nil
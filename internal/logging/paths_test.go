@@ -25,6 +25,12 @@ func TestPathManager_SessionLogPath(t *testing.T) {
 	assert.Equal(t, "/var/log/headjack/abc123/session456.log", path)
 }
 
+func TestPathManager_TranscriptPath(t *testing.T) {
+	pm := NewPathManager("/var/log/headjack")
+	path := pm.TranscriptPath("abc123", "session456")
+	assert.Equal(t, "/var/log/headjack/abc123/session456.transcript.jsonl", path)
+}
+
 func TestPathManager_EnsureInstanceDir(t *testing.T) {
 	baseDir := t.TempDir()
 	pm := NewPathManager(baseDir)
@@ -82,6 +88,8 @@ func TestPathManager_RemoveSessionLog(t *testing.T) {
 
 	err = os.WriteFile(path, []byte("test"), 0o600)
 	require.NoError(t, err)
+	err = os.WriteFile(pm.TranscriptPath("inst1", "sess1"), []byte("{}"), 0o600)
+	require.NoError(t, err)
 
 	assert.True(t, pm.LogExists("inst1", "sess1"))
 
@@ -90,6 +98,7 @@ func TestPathManager_RemoveSessionLog(t *testing.T) {
 	require.NoError(t, err)
 
 	assert.False(t, pm.LogExists("inst1", "sess1"))
+	assert.NoFileExists(t, pm.TranscriptPath("inst1", "sess1"))
 
 	// Removing non-existent should not error
 	err = pm.RemoveSessionLog("inst1", "nonexistent")
@@ -147,8 +156,14 @@ func TestPathManager_ListSessionLogs(t *testing.T) {
 	err = os.WriteFile(filepath.Join(pm.InstanceDir("inst1"), "other.txt"), []byte("not a log"), 0o600)
 	require.NoError(t, err)
 
+	// Rotated segments count once, even without a live log
+	for _, name := range []string{"alpha.log.1.gz", "delta.log.1.gz", "delta.log.2.gz"} {
+		err = os.WriteFile(filepath.Join(pm.InstanceDir("inst1"), name), []byte("rotated"), 0o600)
+		require.NoError(t, err)
+	}
+
 	// List sessions
 	sessions, err = pm.ListSessionLogs("inst1")
 	require.NoError(t, err)
-	assert.ElementsMatch(t, []string{"alpha", "beta", "gamma"}, sessions)
+	assert.ElementsMatch(t, []string{"alpha", "beta", "gamma", "delta"}, sessions)
 }
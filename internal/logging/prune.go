@@ -0,0 +1,209 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// transcriptExt is the suffix of a session's structured transcript file.
+const transcriptExt = ".transcript.jsonl"
+
+// RetentionPolicy limits how much session log history is kept across all
+// instances. Zero values disable the corresponding limit.
+type RetentionPolicy struct {
+	MaxAge       time.Duration // Delete log files not written to within this duration
+	MaxTotalSize int64         // Delete the oldest log files until the total is at most this many bytes
+}
+
+// PruneReason explains why a file was pruned.
+type PruneReason string
+
+// Prune reasons.
+const (
+	PruneReasonAge  PruneReason = "age"
+	PruneReasonSize PruneReason = "size"
+)
+
+// PruneOpts configures a prune pass.
+type PruneOpts struct {
+	Policy RetentionPolicy
+
+	// Active reports whether a session is still running. The live log and
+	// transcript of an active session are never pruned, though its rotated
+	// segments are. A nil Active treats every session as inactive.
+	Active func(instanceID, sessionID string) bool
+
+	DryRun bool      // Report what would be pruned without deleting anything
+	Now    time.Time // Reference time for MaxAge (defaults to time.Now)
+}
+
+// PrunedFile describes a log file removed (or, in a dry run, selected for
+// removal) by Prune.
+type PrunedFile struct {
+	Path       string
+	InstanceID string
+	SessionID  string
+	Size       int64
+	ModTime    time.Time
+	Reason     PruneReason
+}
+
+// logFile is a file in the log directory considered for pruning.
+type logFile struct {
+	PrunedFile
+	protected bool
+}
+
+// Prune applies a retention policy to every session log under the base
+// directory. Files older than MaxAge are removed first; then, if the total
+// size still exceeds MaxTotalSize, the least recently written files are
+// removed until it fits. Instance directories left empty are removed.
+func (p *PathManager) Prune(opts *PruneOpts) ([]PrunedFile, error) {
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	files, err := p.logFiles(opts.Active)
+	if err != nil {
+		return nil, err
+	}
+
+	// Oldest first, so size pruning removes the stalest history
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].ModTime.Before(files[j].ModTime)
+	})
+
+	var total int64
+	for i := range files {
+		total += files[i].Size
+	}
+
+	var pruned []PrunedFile
+	remove := func(f *logFile, reason PruneReason) error {
+		if !opts.DryRun {
+			if err := os.Remove(f.Path); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("remove log file: %w", err)
+			}
+		}
+		f.Reason = reason
+		pruned = append(pruned, f.PrunedFile)
+		total -= f.Size
+		return nil
+	}
+
+	kept := files[:0]
+	for i := range files {
+		f := &files[i]
+		if !f.protected && opts.Policy.MaxAge > 0 && now.Sub(f.ModTime) > opts.Policy.MaxAge {
+			if err := remove(f, PruneReasonAge); err != nil {
+				return pruned, err
+			}
+			continue
+		}
+		kept = append(kept, *f)
+	}
+
+	if opts.Policy.MaxTotalSize > 0 {
+		for i := range kept {
+			if total <= opts.Policy.MaxTotalSize {
+				break
+			}
+			if kept[i].protected {
+				continue
+			}
+			if err := remove(&kept[i], PruneReasonSize); err != nil {
+				return pruned, err
+			}
+		}
+	}
+
+	if !opts.DryRun {
+		p.removeEmptyInstanceDirs()
+	}
+
+	return pruned, nil
+}
+
+// logFiles lists every session log, rotated segment, and transcript under the
+// base directory.
+func (p *PathManager) logFiles(active func(instanceID, sessionID string) bool) ([]logFile, error) {
+	instances, err := os.ReadDir(p.baseDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read log directory: %w", err)
+	}
+
+	var files []logFile
+	for _, inst := range instances {
+		if !inst.IsDir() {
+			continue
+		}
+		entries, err := os.ReadDir(p.InstanceDir(inst.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("read instance log directory: %w", err)
+		}
+
+		for _, entry := range entries {
+			sessionID, live, ok := parseLogFileName(entry.Name())
+			if !ok || entry.IsDir() {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil {
+				if os.IsNotExist(err) {
+					continue
+				}
+				return nil, fmt.Errorf("stat log file: %w", err)
+			}
+
+			files = append(files, logFile{
+				PrunedFile: PrunedFile{
+					Path:       filepath.Join(p.InstanceDir(inst.Name()), entry.Name()),
+					InstanceID: inst.Name(),
+					SessionID:  sessionID,
+					Size:       info.Size(),
+					ModTime:    info.ModTime(),
+				},
+				protected: live && active != nil && active(inst.Name(), sessionID),
+			})
+		}
+	}
+	return files, nil
+}
+
+// parseLogFileName extracts the session ID from a log directory entry.
+// live is true for the live log and transcript, false for rotated segments.
+func parseLogFileName(name string) (sessionID string, live, ok bool) {
+	if id, found := strings.CutSuffix(name, transcriptExt); found {
+		return id, true, true
+	}
+	if id, found := strings.CutSuffix(name, ".log"); found {
+		return id, true, true
+	}
+	if id, rest, found := strings.Cut(name, ".log."); found && rest != "" {
+		return id, false, true
+	}
+	return "", false, false
+}
+
+// removeEmptyInstanceDirs removes instance log directories with no files left.
+func (p *PathManager) removeEmptyInstanceDirs() {
+	instances, err := os.ReadDir(p.baseDir)
+	if err != nil {
+		return
+	}
+	for _, inst := range instances {
+		if !inst.IsDir() {
+			continue
+		}
+		// os.Remove only succeeds on empty directories
+		_ = os.Remove(p.InstanceDir(inst.Name()))
+	}
+}
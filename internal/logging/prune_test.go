@@ -0,0 +1,136 @@
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeAged writes a log file of the given size with a modification time age
+// before now.
+func writeAged(t *testing.T, path string, size int, age time.Duration, now time.Time) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+	require.NoError(t, os.WriteFile(path, make([]byte, size), 0o600))
+	mtime := now.Add(-age)
+	require.NoError(t, os.Chtimes(path, mtime, mtime))
+}
+
+func prunedNames(files []PrunedFile) []string {
+	names := make([]string, len(files))
+	for i, f := range files {
+		names[i] = filepath.Base(f.Path)
+	}
+	return names
+}
+
+func TestPathManager_Prune(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	setup := func(t *testing.T) *PathManager {
+		t.Helper()
+		dir := t.TempDir()
+		pm := NewPathManager(dir)
+
+		// Active session with rotated history
+		writeAged(t, pm.SessionLogPath("inst1", "live"), 100, 40*24*time.Hour, now)
+		writeAged(t, pm.SessionLogPath("inst1", "live")+".1.gz", 100, 20*24*time.Hour, now)
+		writeAged(t, pm.SessionLogPath("inst1", "live")+".2.gz", 100, 50*24*time.Hour, now)
+
+		// Finished sessions
+		writeAged(t, pm.SessionLogPath("inst1", "old"), 100, 60*24*time.Hour, now)
+		writeAged(t, pm.TranscriptPath("inst1", "old"), 10, 60*24*time.Hour, now)
+		writeAged(t, pm.SessionLogPath("inst2", "recent"), 100, time.Hour, now)
+
+		return pm
+	}
+	active := func(instanceID, sessionID string) bool {
+		return instanceID == "inst1" && sessionID == "live"
+	}
+
+	t.Run("removes files older than max age", func(t *testing.T) {
+		pm := setup(t)
+
+		pruned, err := pm.Prune(&PruneOpts{
+			Policy: RetentionPolicy{MaxAge: 30 * 24 * time.Hour},
+			Active: active,
+			Now:    now,
+		})
+		require.NoError(t, err)
+
+		assert.ElementsMatch(t, []string{"live.log.2.gz", "old.log", "old.transcript.jsonl"}, prunedNames(pruned))
+		for _, f := range pruned {
+			assert.Equal(t, PruneReasonAge, f.Reason)
+			assert.NoFileExists(t, f.Path)
+		}
+		assert.FileExists(t, pm.SessionLogPath("inst1", "live"), "active live log is kept regardless of age")
+	})
+
+	t.Run("removes oldest files over the total size", func(t *testing.T) {
+		pm := setup(t)
+
+		pruned, err := pm.Prune(&PruneOpts{
+			Policy: RetentionPolicy{MaxTotalSize: 250},
+			Active: active,
+			Now:    now,
+		})
+		require.NoError(t, err)
+
+		// 510 bytes total: drop oldest unprotected files until <= 250
+		assert.Equal(t, []string{"old.log", "old.transcript.jsonl", "live.log.2.gz", "live.log.1.gz"}, prunedNames(pruned))
+		assert.Equal(t, PruneReasonSize, pruned[0].Reason)
+		assert.Equal(t, "inst1", pruned[0].InstanceID)
+		assert.Equal(t, "old", pruned[0].SessionID)
+		assert.FileExists(t, pm.SessionLogPath("inst2", "recent"))
+	})
+
+	t.Run("dry run deletes nothing", func(t *testing.T) {
+		pm := setup(t)
+
+		pruned, err := pm.Prune(&PruneOpts{
+			Policy: RetentionPolicy{MaxAge: time.Minute},
+			DryRun: true,
+			Now:    now,
+		})
+		require.NoError(t, err)
+
+		assert.Len(t, pruned, 6, "without Active every file is eligible")
+		for _, f := range pruned {
+			assert.FileExists(t, f.Path)
+		}
+	})
+
+	t.Run("removes emptied instance directories", func(t *testing.T) {
+		pm := setup(t)
+
+		_, err := pm.Prune(&PruneOpts{
+			Policy: RetentionPolicy{MaxAge: time.Minute},
+			Active: active,
+			Now:    now,
+		})
+		require.NoError(t, err)
+
+		assert.NoDirExists(t, pm.InstanceDir("inst2"))
+		assert.DirExists(t, pm.InstanceDir("inst1"))
+	})
+
+	t.Run("no policy prunes nothing", func(t *testing.T) {
+		pm := setup(t)
+
+		pruned, err := pm.Prune(&PruneOpts{Now: now})
+		require.NoError(t, err)
+		assert.Empty(t, pruned)
+	})
+
+	t.Run("missing base directory", func(t *testing.T) {
+		pm := NewPathManager(filepath.Join(t.TempDir(), "missing"))
+
+		pruned, err := pm.Prune(&PruneOpts{Policy: RetentionPolicy{MaxAge: time.Minute}})
+		require.NoError(t, err)
+		assert.Empty(t, pruned)
+	})
+}
@@ -2,11 +2,9 @@ package logging
 
 import (
 	"bufio"
-	"context"
 	"fmt"
 	"io"
 	"os"
-	"time"
 )
 
 // DefaultTailLines is the default number of lines to read when tailing.
@@ -22,13 +20,14 @@ func NewReader(pathMgr *PathManager) *Reader {
 	return &Reader{pathMgr: pathMgr}
 }
 
-// ReadAll reads the entire log file for a session.
+// ReadAll reads the entire log for a session, including rotated segments.
 func (r *Reader) ReadAll(instanceID, sessionID string) ([]string, error) {
 	path := r.pathMgr.SessionLogPath(instanceID, sessionID)
 	return readAllLines(path)
 }
 
-// ReadLastN reads the last n lines from a session's log file.
+// ReadLastN reads the last n lines from a session's log. Rotated segments are
+// read only when the live log holds fewer than n lines.
 // If n <= 0, uses DefaultTailLines.
 func (r *Reader) ReadLastN(instanceID, sessionID string, n int) ([]string, error) {
 	if n <= 0 {
@@ -39,85 +38,48 @@ func (r *Reader) ReadLastN(instanceID, sessionID string, n int) ([]string, error
 	return readLastNLines(path, n)
 }
 
-// Follow streams new log lines to the provided writer as they are appended.
-// This is similar to `tail -f`. It blocks until the context is canceled.
-// The pollInterval determines how frequently to check for new content.
-//
-//nolint:gocognit // Follow requires nested loops for polling and reading; complexity is inherent to tail -f semantics
-func (r *Reader) Follow(ctx context.Context, instanceID, sessionID string, out io.Writer, pollInterval time.Duration) error {
+// Render writes a session's log as rendered text, resolving the terminal
+// escape sequences and redraws in the raw stream. See Render.
+func (r *Reader) Render(instanceID, sessionID string, w io.Writer, opts *RenderOpts) error {
 	path := r.pathMgr.SessionLogPath(instanceID, sessionID)
 
-	//nolint:gosec // G304: path is constructed from trusted PathManager, not arbitrary user input
-	file, err := os.Open(path)
+	reader, closer, err := openSegments(path, 0)
 	if err != nil {
-		return fmt.Errorf("open log file: %w", err)
-	}
-	defer file.Close()
-
-	// Seek to end of file
-	if _, err := file.Seek(0, io.SeekEnd); err != nil {
-		return fmt.Errorf("seek to end: %w", err)
-	}
-
-	reader := bufio.NewReader(file)
-	ticker := time.NewTicker(pollInterval)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-ticker.C:
-			for {
-				line, err := reader.ReadBytes('\n')
-				// Always write any data we received, even with EOF
-				if len(line) > 0 {
-					if _, werr := out.Write(line); werr != nil {
-						return fmt.Errorf("write output: %w", werr)
-					}
-				}
-				if err != nil {
-					if err == io.EOF {
-						// No more data, wait for next poll
-						break
-					}
-					return fmt.Errorf("read line: %w", err)
-				}
-			}
-		}
+		return err
 	}
+	defer closer.Close()
+
+	return Render(reader, w, opts)
 }
 
-// FollowWithHistory reads the last n lines and then follows new output.
-// This is similar to `tail -n N -f`.
-func (r *Reader) FollowWithHistory(ctx context.Context, instanceID, sessionID string, out io.Writer, n int, pollInterval time.Duration) error {
-	// First, output the last N lines
-	lines, err := r.ReadLastN(instanceID, sessionID, n)
-	if err != nil && !os.IsNotExist(err) {
-		return err
-	}
+// Open opens the raw log for a session, including rotated segments, as a
+// single stream, oldest first. The caller must close it.
+func (r *Reader) Open(instanceID, sessionID string) (io.ReadCloser, error) {
+	path := r.pathMgr.SessionLogPath(instanceID, sessionID)
 
-	for _, line := range lines {
-		if _, err := fmt.Fprintln(out, line); err != nil {
-			return fmt.Errorf("write history: %w", err)
-		}
+	reader, closer, err := openSegments(path, 0)
+	if err != nil {
+		return nil, err
 	}
+	return readCloser{Reader: reader, Closer: closer}, nil
+}
 
-	// Then start following
-	return r.Follow(ctx, instanceID, sessionID, out, pollInterval)
+// readCloser pairs a reader with the closer releasing its files.
+type readCloser struct {
+	io.Reader
+	io.Closer
 }
 
-// readAllLines reads all lines from a file.
+// readAllLines reads all lines from a log and its rotated segments.
 func readAllLines(path string) ([]string, error) {
-	//nolint:gosec // G304: path is constructed from trusted PathManager, not arbitrary user input
-	file, err := os.Open(path)
+	reader, closer, err := openSegments(path, 0)
 	if err != nil {
-		return nil, fmt.Errorf("open log file: %w", err)
+		return nil, err
 	}
-	defer file.Close()
+	defer closer.Close()
 
 	var lines []string
-	scanner := bufio.NewScanner(file)
+	scanner := bufio.NewScanner(reader)
 	for scanner.Scan() {
 		lines = append(lines, scanner.Text())
 	}
@@ -129,22 +91,56 @@ func readAllLines(path string) ([]string, error) {
 	return lines, nil
 }
 
-// readLastNLines reads the last n lines from a file.
-// Uses a ring buffer approach for efficiency with large files.
+// readLastNLines reads the last n lines from a log. It reads the live log
+// and then older rotated segments, newest first, until it has n lines or runs
+// out, so each segment is read at most once.
 func readLastNLines(path string, n int) ([]string, error) {
-	//nolint:gosec // G304: path is constructed from trusted PathManager, not arbitrary user input
-	file, err := os.Open(path)
+	paths, err := segmentPaths(path)
 	if err != nil {
-		return nil, fmt.Errorf("open log file: %w", err)
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("open log file: %w", os.ErrNotExist)
+	}
+
+	// Collect one line more than needed: the oldest line collected may
+	// continue a line begun in an older segment
+	var lines []string
+	for i := len(paths) - 1; i >= 0 && len(lines) <= n; i-- {
+		segment, complete, err := scanLastNLines(paths[i], n+2-len(lines))
+		if err != nil {
+			return nil, err
+		}
+		if !complete && len(segment) > 0 && len(lines) > 0 {
+			// The segment ends mid-line; the rest is in the newer one
+			lines[0] = segment[len(segment)-1] + lines[0]
+			segment = segment[:len(segment)-1]
+		}
+		lines = append(segment, lines...)
 	}
-	defer file.Close()
 
-	// Use a ring buffer to keep track of last n lines
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines, nil
+}
+
+// scanLastNLines reads the last n lines of a single log segment, reporting
+// whether its last line is complete. Uses a ring buffer so only n lines are
+// held at a time.
+func scanLastNLines(path string, n int) ([]string, bool, error) {
+	reader, closers, err := openSegment(path)
+	if err != nil {
+		return nil, false, err
+	}
+	defer multiCloser(closers).Close()
+
 	ring := make([]string, n)
 	idx := 0
 	count := 0
 
-	scanner := bufio.NewScanner(file)
+	tracked := &lastByteReader{r: reader}
+	scanner := bufio.NewScanner(tracked)
 	for scanner.Scan() {
 		ring[idx] = scanner.Text()
 		idx = (idx + 1) % n
@@ -152,17 +148,15 @@ func readLastNLines(path string, n int) ([]string, error) {
 	}
 
 	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("scan log file: %w", err)
+		return nil, false, fmt.Errorf("scan log file: %w", err)
 	}
 
-	// Build result in correct order
+	complete := tracked.last == '\n'
 	if count == 0 {
-		return nil, nil
+		return nil, complete, nil
 	}
-
 	if count < n {
-		// Haven't filled the buffer yet
-		return ring[:count], nil
+		return ring[:count], complete, nil
 	}
 
 	// Buffer is full, need to reorder
@@ -170,5 +164,19 @@ func readLastNLines(path string, n int) ([]string, error) {
 	for i := range n {
 		result[i] = ring[(idx+i)%n]
 	}
-	return result, nil
+	return result, complete, nil
+}
+
+// lastByteReader remembers the last byte read through it.
+type lastByteReader struct {
+	r    io.Reader
+	last byte
+}
+
+func (l *lastByteReader) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	if n > 0 {
+		l.last = p[n-1]
+	}
+	return n, err
 }
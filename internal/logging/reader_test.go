@@ -318,3 +318,59 @@ func TestReadLastNLines_LargeFile(t *testing.T) {
 		assert.Equal(t, strings.Repeat("x", 100), line)
 	}
 }
+
+func TestReadLastNLines_SplitAcrossSegments(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "sess1.log")
+	require.NoError(t, os.WriteFile(logPath+".2", []byte("one\ntw"), 0o600))
+	require.NoError(t, os.WriteFile(logPath+".1", []byte("o\nthr"), 0o600))
+	require.NoError(t, os.WriteFile(logPath, []byte("ee\nfour\n"), 0o600))
+
+	tests := []struct {
+		n    int
+		want []string
+	}{
+		{1, []string{"four"}},
+		{2, []string{"three", "four"}},
+		{3, []string{"two", "three", "four"}},
+		{10, []string{"one", "two", "three", "four"}},
+	}
+	for _, tt := range tests {
+		result, err := readLastNLines(logPath, tt.n)
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, result, "n=%d", tt.n)
+	}
+}
+
+func TestReader_Follow_Rotation(t *testing.T) {
+	dir := t.TempDir()
+	pm := NewPathManager(dir)
+	logPath, err := pm.EnsureSessionLog("inst1", "sess1")
+	require.NoError(t, err)
+
+	w, err := NewRotatingWriter(logPath, RotationPolicy{MaxSize: 20, MaxFiles: 2, Compress: true})
+	require.NoError(t, err)
+	defer w.Close()
+
+	reader := NewReader(pm)
+	output := &bytes.Buffer{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Millisecond)
+	defer cancel()
+
+	done := make(chan error)
+	go func() {
+		done <- reader.Follow(ctx, "inst1", "sess1", output, 20*time.Millisecond)
+	}()
+	time.Sleep(100 * time.Millisecond)
+
+	// Each write after the first rotates the log
+	for _, line := range []string{"before rotate 1\n", "after rotate 1\n", "after rotate 2\n"} {
+		_, err := w.Write([]byte(line))
+		require.NoError(t, err)
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	err = <-done
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, "before rotate 1\nafter rotate 1\nafter rotate 2\n", output.String())
+}
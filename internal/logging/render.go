@@ -0,0 +1,232 @@
+package logging
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+
+	"github.com/jmgilman/headjack/internal/vt"
+)
+
+// Default virtual screen size for rendering. Session logs do not record the
+// terminal size, so rendering uses a screen wide enough that lines are not
+// wrapped artificially.
+const (
+	DefaultRenderRows = 50
+	DefaultRenderCols = 200
+)
+
+// RenderFormat selects the output format for rendered logs.
+type RenderFormat string
+
+// Supported render formats.
+const (
+	RenderPlain RenderFormat = "plain"
+	RenderHTML  RenderFormat = "html"
+)
+
+// RenderOpts configures log rendering.
+type RenderOpts struct {
+	Format RenderFormat // Output format (default RenderPlain)
+	Lines  int          // Only render the last n lines (0 renders everything)
+	Rows   int          // Virtual screen height (default DefaultRenderRows)
+	Cols   int          // Virtual screen width (default DefaultRenderCols)
+}
+
+// Render replays a raw terminal output stream through a virtual screen and
+// writes the text a user would have seen, including lines that scrolled off
+// the top. Cursor movement, redraws, and escape sequences are resolved
+// rather than copied, so spinners and TUI repaints collapse to their final
+// state.
+func Render(r io.Reader, w io.Writer, opts *RenderOpts) error {
+	rows, cols := opts.Rows, opts.Cols
+	if rows <= 0 {
+		rows = DefaultRenderRows
+	}
+	if cols <= 0 {
+		cols = DefaultRenderCols
+	}
+
+	// Lines beyond the last n are never rendered, so only n need be kept
+	history := -1
+	if opts.Lines > 0 {
+		history = opts.Lines
+	}
+	screen := vt.New(rows, cols, history)
+	if _, err := io.Copy(screen, r); err != nil {
+		return fmt.Errorf("replay log: %w", err)
+	}
+
+	lines := screen.Lines(true)
+	if opts.Lines > 0 && len(lines) > opts.Lines {
+		lines = lines[len(lines)-opts.Lines:]
+	}
+
+	bw := bufio.NewWriter(w)
+	switch opts.Format {
+	case RenderHTML:
+		writeHTML(bw, lines)
+	case RenderPlain, "":
+		writePlain(bw, lines)
+	default:
+		return fmt.Errorf("unknown render format %q", opts.Format)
+	}
+
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("write output: %w", err)
+	}
+	return nil
+}
+
+func writePlain(w *bufio.Writer, lines [][]vt.Cell) {
+	for _, line := range lines {
+		for _, c := range line {
+			_, _ = w.WriteRune(cellRune(c))
+		}
+		_ = w.WriteByte('\n')
+	}
+}
+
+// htmlHeader starts a standalone document styled like a dark terminal.
+const htmlHeader = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>headjack session log</title>
+<style>
+body { margin: 0; background: #1e1e1e; }
+pre { margin: 0; padding: 1em; color: #d4d4d4; font-family: ui-monospace, Menlo, Consolas, monospace; font-size: 13px; line-height: 1.3; }
+</style>
+</head>
+<body>
+<pre>`
+
+const htmlFooter = `</pre>
+</body>
+</html>
+`
+
+func writeHTML(w *bufio.Writer, lines [][]vt.Cell) {
+	_, _ = w.WriteString(htmlHeader)
+
+	for _, line := range lines {
+		// Group runs of cells with the same attributes into one span
+		for start := 0; start < len(line); {
+			end := start + 1
+			for end < len(line) && line[end].Attr == line[start].Attr {
+				end++
+			}
+
+			var text strings.Builder
+			for _, c := range line[start:end] {
+				text.WriteRune(cellRune(c))
+			}
+			escaped := html.EscapeString(text.String())
+
+			if style := cssStyle(line[start].Attr); style != "" {
+				_, _ = fmt.Fprintf(w, `<span style="%s">%s</span>`, style, escaped)
+			} else {
+				_, _ = w.WriteString(escaped)
+			}
+			start = end
+		}
+		_ = w.WriteByte('\n')
+	}
+
+	_, _ = w.WriteString(htmlFooter)
+}
+
+// cellRune returns the rune to display, rendering unwritten cells as spaces.
+func cellRune(c vt.Cell) rune {
+	if c.Rune == 0 {
+		return ' '
+	}
+	return c.Rune
+}
+
+// Default colors used when reverse video swaps an unset color.
+const (
+	defaultFgCSS = "#d4d4d4"
+	defaultBgCSS = "#1e1e1e"
+)
+
+// cssStyle returns inline CSS for a cell attribute, or "" for the default.
+func cssStyle(a vt.Attr) string {
+	fg, bg := cssColor(a.Fg), cssColor(a.Bg)
+	if a.Flags&vt.AttrReverse != 0 {
+		fg, bg = bg, fg
+		if fg == "" {
+			fg = defaultBgCSS
+		}
+		if bg == "" {
+			bg = defaultFgCSS
+		}
+	}
+
+	var decls []string
+	if fg != "" {
+		decls = append(decls, "color:"+fg)
+	}
+	if bg != "" {
+		decls = append(decls, "background:"+bg)
+	}
+	if a.Flags&vt.AttrBold != 0 {
+		decls = append(decls, "font-weight:bold")
+	}
+	if a.Flags&vt.AttrDim != 0 {
+		decls = append(decls, "opacity:0.6")
+	}
+	if a.Flags&vt.AttrItalic != 0 {
+		decls = append(decls, "font-style:italic")
+	}
+
+	var decorations []string
+	if a.Flags&vt.AttrUnderline != 0 {
+		decorations = append(decorations, "underline")
+	}
+	if a.Flags&vt.AttrStrike != 0 {
+		decorations = append(decorations, "line-through")
+	}
+	if len(decorations) > 0 {
+		decls = append(decls, "text-decoration:"+strings.Join(decorations, " "))
+	}
+	if a.Flags&vt.AttrHidden != 0 {
+		decls = append(decls, "visibility:hidden")
+	}
+
+	return strings.Join(decls, ";")
+}
+
+// ansiColors are the 16 standard and bright colors (VS Code dark theme).
+var ansiColors = [16]string{
+	"#000000", "#cd3131", "#0dbc79", "#e5e510", "#2472c8", "#bc3fbc", "#11a8cd", "#e5e5e5",
+	"#666666", "#f14c4c", "#23d18b", "#f5f543", "#3b8eea", "#d670d6", "#29b8db", "#ffffff",
+}
+
+// cssColor converts a cell color to a CSS color, or "" for the default.
+func cssColor(c vt.Color) string {
+	if r, g, b, ok := c.RGB(); ok {
+		return fmt.Sprintf("#%02x%02x%02x", r, g, b)
+	}
+
+	i, ok := c.Index()
+	if !ok {
+		return ""
+	}
+
+	switch {
+	case i < 16:
+		return ansiColors[i]
+	case i < 232:
+		// 6x6x6 color cube
+		levels := [6]int{0, 95, 135, 175, 215, 255}
+		n := int(i) - 16
+		return fmt.Sprintf("#%02x%02x%02x", levels[n/36], levels[(n/6)%6], levels[n%6])
+	default:
+		// Grayscale ramp
+		v := 8 + (int(i)-232)*10
+		return fmt.Sprintf("#%02x%02x%02x", v, v, v)
+	}
+}
@@ -0,0 +1,127 @@
+package logging
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmgilman/headjack/internal/vt"
+)
+
+// The fixtures in testdata were recorded from programs running on a real
+// PTY, and each .txt/.html file holds the expected rendering.
+func TestRender_Fixtures(t *testing.T) {
+	tests := []struct {
+		fixture string
+		format  RenderFormat
+		golden  string
+	}{
+		{"progress.log", RenderPlain, "progress.txt"},
+		{"redraw.log", RenderPlain, "redraw.txt"},
+		{"altscreen.log", RenderPlain, "altscreen.txt"},
+		{"progress.log", RenderHTML, "progress.html"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			input, err := os.Open(filepath.Join("testdata", tt.fixture))
+			require.NoError(t, err)
+			defer input.Close()
+
+			expected, err := os.ReadFile(filepath.Join("testdata", tt.golden))
+			require.NoError(t, err)
+
+			var out bytes.Buffer
+			require.NoError(t, Render(input, &out, &RenderOpts{Format: tt.format}))
+			assert.Equal(t, string(expected), out.String())
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	t.Run("resolves redraws in the raw stream", func(t *testing.T) {
+		raw, err := os.ReadFile(filepath.Join("testdata", "redraw.log"))
+		require.NoError(t, err)
+		require.Contains(t, string(raw), "Thinking", "fixture should contain intermediate frames")
+
+		var out bytes.Buffer
+		require.NoError(t, Render(bytes.NewReader(raw), &out, &RenderOpts{}))
+
+		assert.NotContains(t, out.String(), "Thinking")
+		assert.NotContains(t, out.String(), "\x1b")
+	})
+
+	t.Run("limits to the last n lines", func(t *testing.T) {
+		var out bytes.Buffer
+		err := Render(strings.NewReader("1\r\n2\r\n3\r\n4\r\n"), &out, &RenderOpts{Lines: 2})
+
+		require.NoError(t, err)
+		assert.Equal(t, "3\n4\n", out.String())
+	})
+
+	t.Run("limits to the last n lines beyond the screen", func(t *testing.T) {
+		var log strings.Builder
+		for i := range 1000 {
+			fmt.Fprintf(&log, "%d\r\n", i)
+		}
+
+		var out bytes.Buffer
+		err := Render(strings.NewReader(log.String()), &out, &RenderOpts{Lines: 3, Rows: 10})
+
+		require.NoError(t, err)
+		assert.Equal(t, "997\n998\n999\n", out.String())
+	})
+
+	t.Run("escapes HTML", func(t *testing.T) {
+		var out bytes.Buffer
+		err := Render(strings.NewReader("<b>&</b>"), &out, &RenderOpts{Format: RenderHTML})
+
+		require.NoError(t, err)
+		assert.Contains(t, out.String(), "&lt;b&gt;&amp;&lt;/b&gt;")
+	})
+
+	t.Run("rejects unknown format", func(t *testing.T) {
+		err := Render(strings.NewReader(""), &bytes.Buffer{}, &RenderOpts{Format: "pdf"})
+		assert.Error(t, err)
+	})
+}
+
+func TestReader_Render(t *testing.T) {
+	dir := t.TempDir()
+	pm := NewPathManager(dir)
+	path, err := pm.EnsureSessionLog("inst1", "sess1")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, []byte("loading\r\x1b[Kdone\r\n"), 0o600))
+
+	var out bytes.Buffer
+	require.NoError(t, NewReader(pm).Render("inst1", "sess1", &out, &RenderOpts{}))
+	assert.Equal(t, "done\n", out.String())
+
+	err = NewReader(pm).Render("inst1", "missing", &out, &RenderOpts{})
+	assert.Error(t, err)
+}
+
+func TestCSSColor(t *testing.T) {
+	tests := []struct {
+		name   string
+		index  uint8
+		expect string
+	}{
+		{"standard", 1, "#cd3131"},
+		{"bright", 9, "#f14c4c"},
+		{"cube", 196, "#ff0000"},
+		{"grayscale", 232, "#080808"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expect, cssColor(vt.Palette(tt.index)))
+		})
+	}
+}
@@ -0,0 +1,406 @@
+package logging
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// gzipExt is the extension appended to compressed rotated segments.
+const gzipExt = ".gz"
+
+// RotationPolicy controls when a session log is rotated and how many rotated
+// segments are kept.
+//
+// Rotated segments are named <session>.log.1 (newest) through
+// <session>.log.N (oldest), with a .gz suffix when compressed. Segments are
+// compressed in the background after rotation, so writes are not held up.
+type RotationPolicy struct {
+	MaxSize  int64 // Rotate once the live log would exceed this many bytes (0 disables rotation)
+	MaxFiles int   // Number of rotated segments to keep
+	Compress bool  // Gzip rotated segments
+}
+
+// RotatingWriter appends to a log file, rotating it according to a
+// RotationPolicy. It implements io.WriteCloser.
+type RotatingWriter struct {
+	path   string
+	policy RotationPolicy
+	file   *os.File
+	size   int64
+	mu     sync.Mutex
+
+	compressing chan error // Result of the running compression, if any
+	compressErr error      // First compression failure, reported by Close
+}
+
+// NewRotatingWriter opens the log file at path for appending.
+func NewRotatingWriter(path string, policy RotationPolicy) (*RotatingWriter, error) {
+	w := &RotatingWriter{path: path, policy: policy}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Write appends p to the log, first rotating if p would push the live log
+// past the maximum size. A single write is never split across segments.
+//
+// If rotation fails, the live log is reopened and p appended to it, so output
+// is never dropped; rotation is retried on the next write.
+func (w *RotatingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return 0, os.ErrClosed
+	}
+
+	if w.policy.MaxSize > 0 && w.size > 0 && w.size+int64(len(p)) > w.policy.MaxSize {
+		if err := w.rotate(); err != nil && w.file == nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	if err != nil {
+		return n, fmt.Errorf("write to log file: %w", err)
+	}
+	return n, nil
+}
+
+// Close waits for any running compression and closes the log file. It
+// reports a failure to compress a rotated segment since the writer opened.
+func (w *RotatingWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return nil
+	}
+	w.waitCompress()
+	err := w.file.Close()
+	w.file = nil
+	if err != nil {
+		return errors.Join(fmt.Errorf("close log file: %w", err), w.compressErr)
+	}
+	return w.compressErr
+}
+
+// waitCompress waits for the running compression, if any, and records its
+// failure.
+func (w *RotatingWriter) waitCompress() {
+	if w.compressing == nil {
+		return
+	}
+	if err := <-w.compressing; err != nil && w.compressErr == nil {
+		w.compressErr = err
+	}
+	w.compressing = nil
+}
+
+// open opens the live log file and records its current size.
+func (w *RotatingWriter) open() error {
+	//nolint:gosec // G302/G304: path is from trusted PathManager; 0644 matches the other log writers
+	file, err := os.OpenFile(w.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("open log file for append: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("stat log file: %w", err)
+	}
+
+	w.file = file
+	w.size = info.Size()
+	return nil
+}
+
+// rotate shifts the existing segments up by one, moves the live log to
+// segment 1, and reopens an empty live log. The live log is reopened even if
+// rotation fails; w.file is nil afterwards only if that also failed.
+func (w *RotatingWriter) rotate() error {
+	err := w.file.Close()
+	w.file = nil
+	if err != nil {
+		return errors.Join(fmt.Errorf("close log file: %w", err), w.open())
+	}
+
+	if err := w.moveLiveLog(); err != nil {
+		return errors.Join(err, w.open())
+	}
+	return w.open()
+}
+
+// moveLiveLog shifts the existing segments up by one and moves the live log
+// to segment 1, or removes it if no segments are kept.
+//
+// Segment 1 is then compressed in the background if the policy asks for it.
+// A compression still running from the previous rotation is waited for
+// first, so segments are never renamed while being compressed.
+func (w *RotatingWriter) moveLiveLog() error {
+	w.waitCompress()
+	if err := shiftSegments(w.path, w.policy.MaxFiles); err != nil {
+		return err
+	}
+
+	if w.policy.MaxFiles > 0 {
+		first := segmentPath(w.path, 1, false)
+		if err := os.Rename(w.path, first); err != nil {
+			return fmt.Errorf("rotate log file: %w", err)
+		}
+		if w.policy.Compress {
+			done := make(chan error, 1)
+			w.compressing = done
+			go func() { done <- compressFile(first) }()
+		}
+	} else if err := os.Remove(w.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("remove log file: %w", err)
+	}
+	return nil
+}
+
+// shiftSegments renames segment i to i+1 for every existing segment,
+// deleting any that would exceed maxFiles.
+func shiftSegments(path string, maxFiles int) error {
+	segments, err := rotatedSegments(path)
+	if err != nil {
+		return err
+	}
+
+	// Highest index first so renames never overwrite a newer segment
+	for i := len(segments) - 1; i >= 0; i-- {
+		seg := segments[i]
+		if seg.index >= maxFiles {
+			if err := os.Remove(seg.path); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("remove log segment: %w", err)
+			}
+			continue
+		}
+		if err := os.Rename(seg.path, segmentPath(path, seg.index+1, seg.compressed)); err != nil {
+			return fmt.Errorf("shift log segment: %w", err)
+		}
+	}
+	return nil
+}
+
+// segment is a rotated piece of a session log.
+type segment struct {
+	path       string
+	index      int
+	compressed bool
+}
+
+// segmentPath returns the path of rotated segment index for a live log path.
+func segmentPath(path string, index int, compressed bool) string {
+	p := path + "." + strconv.Itoa(index)
+	if compressed {
+		p += gzipExt
+	}
+	return p
+}
+
+// rotatedSegments returns the rotated segments of a live log path, ordered
+// newest (index 1) first.
+func rotatedSegments(path string) ([]segment, error) {
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read log directory: %w", err)
+	}
+
+	prefix := filepath.Base(path) + "."
+	var segments []segment
+	for _, entry := range entries {
+		name, ok := strings.CutPrefix(entry.Name(), prefix)
+		if !ok || entry.IsDir() {
+			continue
+		}
+		name, compressed := strings.CutSuffix(name, gzipExt)
+		index, err := strconv.Atoi(name)
+		if err != nil || index < 1 {
+			continue
+		}
+		segments = append(segments, segment{
+			path:       filepath.Join(filepath.Dir(path), entry.Name()),
+			index:      index,
+			compressed: compressed,
+		})
+	}
+
+	sort.Slice(segments, func(i, j int) bool {
+		if segments[i].index != segments[j].index {
+			return segments[i].index < segments[j].index
+		}
+		return !segments[i].compressed && segments[j].compressed
+	})
+	return segments, nil
+}
+
+// compressFile gzips path to path.gz and removes the original. The
+// compressed file is written under a temporary name that readers ignore and
+// renamed into place once complete.
+func compressFile(path string) error {
+	//nolint:gosec // G304: path is a rotated segment of a trusted log path
+	src, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open log segment: %w", err)
+	}
+	defer src.Close()
+
+	tmp := path + gzipExt + ".tmp"
+	//nolint:gosec // G302/G304: path is from trusted PathManager; 0644 matches the live log
+	dst, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("create compressed segment: %w", err)
+	}
+
+	zw := gzip.NewWriter(dst)
+	_, copyErr := io.Copy(zw, src)
+	closeErr := errors.Join(zw.Close(), dst.Close())
+	if err := errors.Join(copyErr, closeErr); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("compress log segment: %w", err)
+	}
+	if err := os.Rename(tmp, path+gzipExt); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("rename compressed segment: %w", err)
+	}
+
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("remove uncompressed segment: %w", err)
+	}
+	return nil
+}
+
+// openSegments opens a session's rotated segments and live log as a single
+// stream, oldest first. Segments from skip onward (counting from the oldest)
+// are included; compressed segments are decompressed transparently. The
+// returned closer releases every opened file.
+func openSegments(path string, skip int) (io.Reader, io.Closer, error) {
+	paths, err := segmentPaths(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(paths) == 0 {
+		return nil, nil, fmt.Errorf("open log file: %w", os.ErrNotExist)
+	}
+	if skip > len(paths)-1 {
+		skip = len(paths) - 1
+	}
+
+	var closers multiCloser
+	readers := make([]io.Reader, 0, len(paths)-skip)
+	for _, p := range paths[skip:] {
+		r, c, err := openSegment(p)
+		if err != nil {
+			_ = closers.Close()
+			return nil, nil, err
+		}
+		readers = append(readers, r)
+		closers = append(closers, c...)
+	}
+	return io.MultiReader(readers...), closers, nil
+}
+
+// segmentPaths returns the existing files making up a session log, oldest
+// rotated segment first and the live log last.
+func segmentPaths(path string) ([]string, error) {
+	segments, err := rotatedSegments(path)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(segments)+1)
+	for i := len(segments) - 1; i >= 0; i-- {
+		// A segment being compressed briefly exists in both forms; read the
+		// uncompressed one
+		if i > 0 && segments[i-1].index == segments[i].index {
+			continue
+		}
+		paths = append(paths, segments[i].path)
+	}
+	if _, err := os.Stat(path); err == nil {
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// openSegment opens a single log file, decompressing it if gzipped.
+func openSegment(path string) (io.Reader, []io.Closer, error) {
+	//nolint:gosec // G304: path is constructed from trusted PathManager, not arbitrary user input
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("open log file: %w", err)
+	}
+	if !strings.HasSuffix(path, gzipExt) {
+		return file, []io.Closer{file}, nil
+	}
+
+	zr, err := gzip.NewReader(file)
+	if err != nil {
+		_ = file.Close()
+		return nil, nil, fmt.Errorf("open compressed log segment: %w", err)
+	}
+	return zr, []io.Closer{zr, file}, nil
+}
+
+// multiCloser closes a list of closers, joining their errors.
+type multiCloser []io.Closer
+
+func (m multiCloser) Close() error {
+	var errs []error
+	for _, c := range m {
+		errs = append(errs, c.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// ParseSize parses a byte size such as "512", "100KB", "50MB", or "2GB".
+// Units are binary (1KB = 1024 bytes) and case-insensitive; an empty value
+// or "0" yields 0.
+func ParseSize(value string) (int64, error) {
+	orig := value
+	value = strings.ToUpper(strings.TrimSpace(value))
+	if value == "" {
+		return 0, nil
+	}
+
+	units := []struct {
+		suffix string
+		mult   int64
+	}{
+		{"GB", 1 << 30},
+		{"MB", 1 << 20},
+		{"KB", 1 << 10},
+		{"G", 1 << 30},
+		{"M", 1 << 20},
+		{"K", 1 << 10},
+		{"B", 1},
+	}
+
+	mult := int64(1)
+	for _, u := range units {
+		if num, ok := strings.CutSuffix(value, u.suffix); ok {
+			value, mult = strings.TrimSpace(num), u.mult
+			break
+		}
+	}
+
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q (use a byte count like 50MB or 2GB)", orig)
+	}
+	return n * mult, nil
+}
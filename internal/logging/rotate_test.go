@@ -0,0 +1,204 @@
+package logging
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readGzip(t *testing.T, path string) string {
+	t.Helper()
+
+	//nolint:gosec // G304: path is from test temp dir
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	zr, err := gzip.NewReader(file)
+	require.NoError(t, err)
+	data, err := io.ReadAll(zr)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestRotatingWriter(t *testing.T) {
+	t.Run("rotates and compresses segments", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "sess1.log")
+		w, err := NewRotatingWriter(path, RotationPolicy{MaxSize: 10, MaxFiles: 2, Compress: true})
+		require.NoError(t, err)
+
+		for _, chunk := range []string{"aaaaaaaa\n", "bbbbbbbb\n", "cccccccc\n", "dddddddd\n"} {
+			_, err := w.Write([]byte(chunk))
+			require.NoError(t, err)
+		}
+		require.NoError(t, w.Close())
+
+		live, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "dddddddd\n", string(live))
+		assert.Equal(t, "cccccccc\n", readGzip(t, path+".1.gz"))
+		assert.Equal(t, "bbbbbbbb\n", readGzip(t, path+".2.gz"))
+		assert.NoFileExists(t, path+".3.gz", "segments beyond MaxFiles are deleted")
+		assert.NoFileExists(t, path+".1", "uncompressed segment is removed after compression")
+	})
+
+	t.Run("keeps segments uncompressed", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "sess1.log")
+		w, err := NewRotatingWriter(path, RotationPolicy{MaxSize: 10, MaxFiles: 1})
+		require.NoError(t, err)
+
+		_, err = w.Write([]byte("aaaaaaaa\n"))
+		require.NoError(t, err)
+		_, err = w.Write([]byte("bbbbbbbb\n"))
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+
+		seg, err := os.ReadFile(path + ".1")
+		require.NoError(t, err)
+		assert.Equal(t, "aaaaaaaa\n", string(seg))
+	})
+
+	t.Run("counts existing content toward the size", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "sess1.log")
+		require.NoError(t, os.WriteFile(path, []byte("existing\n"), 0o600))
+
+		w, err := NewRotatingWriter(path, RotationPolicy{MaxSize: 10, MaxFiles: 1})
+		require.NoError(t, err)
+		_, err = w.Write([]byte("new\n"))
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+
+		assert.FileExists(t, path+".1")
+	})
+
+	t.Run("never rotates without a max size", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "sess1.log")
+		w, err := NewRotatingWriter(path, RotationPolicy{MaxFiles: 3})
+		require.NoError(t, err)
+		for range 100 {
+			_, err := w.Write([]byte("line\n"))
+			require.NoError(t, err)
+		}
+		require.NoError(t, w.Close())
+
+		assert.NoFileExists(t, path+".1")
+	})
+
+	t.Run("keeps appending when rotation fails", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "sess1.log")
+		w, err := NewRotatingWriter(path, RotationPolicy{MaxSize: 10, MaxFiles: 1})
+		require.NoError(t, err)
+		// A directory in place of the first segment makes the rename fail
+		require.NoError(t, os.Mkdir(path+".1", 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(path+".1", "blocker"), nil, 0o600))
+
+		_, err = w.Write([]byte("aaaaaaaa\n"))
+		require.NoError(t, err)
+		_, err = w.Write([]byte("bbbbbbbb\n"))
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+
+		live, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "aaaaaaaa\nbbbbbbbb\n", string(live))
+	})
+
+	t.Run("rejects writes after close", func(t *testing.T) {
+		w, err := NewRotatingWriter(filepath.Join(t.TempDir(), "sess1.log"), RotationPolicy{})
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+
+		_, err = w.Write([]byte("x"))
+		assert.ErrorIs(t, err, os.ErrClosed)
+	})
+}
+
+func TestReader_RotatedSegments(t *testing.T) {
+	dir := t.TempDir()
+	pm := NewPathManager(dir)
+	path, err := pm.EnsureSessionLog("inst1", "sess1")
+	require.NoError(t, err)
+
+	w, err := NewRotatingWriter(path, RotationPolicy{MaxSize: 12, MaxFiles: 5, Compress: true})
+	require.NoError(t, err)
+	for _, line := range []string{"line1", "line2", "line3", "line4", "line5"} {
+		_, err := w.Write([]byte(line + "\n"))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+
+	reader := NewReader(pm)
+
+	all, err := reader.ReadAll("inst1", "sess1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"line1", "line2", "line3", "line4", "line5"}, all)
+
+	last, err := reader.ReadLastN("inst1", "sess1", 3)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"line3", "line4", "line5"}, last)
+
+	last, err = reader.ReadLastN("inst1", "sess1", 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"line5"}, last)
+
+	raw, err := reader.Open("inst1", "sess1")
+	require.NoError(t, err)
+	content, err := io.ReadAll(raw)
+	require.NoError(t, err)
+	require.NoError(t, raw.Close())
+	assert.Equal(t, "line1\nline2\nline3\nline4\nline5\n", string(content))
+
+	assert.True(t, pm.LogExists("inst1", "sess1"))
+	require.NoError(t, pm.RemoveSessionLog("inst1", "sess1"))
+	assert.False(t, pm.LogExists("inst1", "sess1"), "rotated segments are removed with the log")
+}
+
+func TestSegmentPaths_SegmentBeingCompressed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sess1.log")
+	require.NoError(t, os.WriteFile(path+".1", []byte("line1\n"), 0o600))
+	require.NoError(t, compressFile(path+".1"))
+	require.NoError(t, os.WriteFile(path+".1", []byte("line1\n"), 0o600))
+	require.NoError(t, os.WriteFile(path, []byte("line2\n"), 0o600))
+
+	paths, err := segmentPaths(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{path + ".1", path}, paths)
+
+	lines, err := readAllLines(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"line1", "line2"}, lines)
+}
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		value  string
+		expect int64
+	}{
+		{"", 0},
+		{"0", 0},
+		{"512", 512},
+		{"512B", 512},
+		{"100KB", 100 << 10},
+		{"50MB", 50 << 20},
+		{"50mb", 50 << 20},
+		{"2G", 2 << 30},
+		{" 1 GB ", 1 << 30},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			n, err := ParseSize(tt.value)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expect, n)
+		})
+	}
+
+	for _, bad := range []string{"lots", "-5MB", "1.5GB", "10TB"} {
+		_, err := ParseSize(bad)
+		assert.Error(t, err, bad)
+	}
+}
@@ -0,0 +1,240 @@
+package logging
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// maxSearchLineSize is the longest log line Search reads. Agents that redraw
+// without newlines can produce very long lines; longer lines are truncated.
+const maxSearchLineSize = 1 << 20
+
+// SearchOpts configures a log search.
+type SearchOpts struct {
+	Pattern *regexp.Regexp // Matched against each line with escape sequences removed (required)
+	Context int            // Lines of context to include before and after each match
+	Since   time.Time      // Skip log segments last written before this time (zero = all)
+}
+
+// Match is a log line matching a search, with surrounding context.
+// Lines are stripped of terminal escape sequences.
+type Match struct {
+	Line   int      // 1-based line number across all of the session's segments
+	Text   string   // The matching line
+	Before []string // Up to Context lines preceding the match
+	After  []string // Up to Context lines following the match
+}
+
+// Search streams a session's log, oldest segment first, and calls fn for each
+// matching line. Lines within the context of a match are attached to it
+// rather than reported separately, so a line appears in at most one match's
+// Before or After. Returning an error from fn stops the search.
+func (r *Reader) Search(instanceID, sessionID string, opts *SearchOpts, fn func(*Match) error) error {
+	path := r.pathMgr.SessionLogPath(instanceID, sessionID)
+
+	paths, err := segmentPaths(path)
+	if err != nil {
+		return err
+	}
+
+	s := &searcher{opts: opts, fn: fn}
+	for _, p := range paths {
+		// Line numbers stay consistent even for segments skipped by Since
+		if !opts.Since.IsZero() {
+			info, err := os.Stat(p)
+			if err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("stat log file: %w", err)
+			}
+			if err != nil || info.ModTime().Before(opts.Since) {
+				if err := s.skip(p); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if err := s.scan(p); err != nil {
+			return err
+		}
+	}
+	return s.flush()
+}
+
+// searcher carries match and context state across segments.
+type searcher struct {
+	opts    *SearchOpts
+	fn      func(*Match) error
+	line    int
+	before  []string // Ring of the most recent non-matching lines
+	pending *Match   // Match still collecting After lines
+}
+
+// scan searches one segment.
+func (s *searcher) scan(path string) error {
+	return eachLine(path, func(raw []byte) error {
+		s.line++
+		text := StripEscapes(string(raw))
+
+		if s.opts.Pattern.MatchString(text) {
+			if err := s.flush(); err != nil {
+				return err
+			}
+			s.pending = &Match{Line: s.line, Text: text, Before: s.before}
+			s.before = nil
+			return nil
+		}
+
+		if s.pending != nil && len(s.pending.After) < s.opts.Context {
+			s.pending.After = append(s.pending.After, text)
+			return nil
+		}
+		if err := s.flush(); err != nil {
+			return err
+		}
+		if s.opts.Context > 0 {
+			if len(s.before) == s.opts.Context {
+				s.before = s.before[1:]
+			}
+			s.before = append(s.before, text)
+		}
+		return nil
+	})
+}
+
+// skip counts the lines of a segment excluded from the search.
+func (s *searcher) skip(path string) error {
+	if err := s.flush(); err != nil {
+		return err
+	}
+	s.before = nil
+	return eachLine(path, func([]byte) error {
+		s.line++
+		return nil
+	})
+}
+
+// flush reports the pending match, if any.
+func (s *searcher) flush() error {
+	if s.pending == nil {
+		return nil
+	}
+	m := s.pending
+	s.pending = nil
+	return s.fn(m)
+}
+
+// eachLine calls fn for each line of a log segment, without the trailing
+// newline. Lines longer than maxSearchLineSize are truncated.
+func eachLine(path string, fn func([]byte) error) error {
+	reader, closers, err := openSegment(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			// Rotated away since it was listed
+			return nil
+		}
+		return err
+	}
+	defer multiCloser(closers).Close()
+
+	br := bufio.NewReaderSize(reader, 64*1024)
+	var long []byte
+	for {
+		chunk, err := br.ReadSlice('\n')
+		if errors.Is(err, bufio.ErrBufferFull) {
+			if len(long) < maxSearchLineSize {
+				long = append(long, chunk...)
+			}
+			continue
+		}
+		if len(chunk) > 0 || long != nil {
+			line := chunk
+			if long != nil {
+				line = append(long, chunk...)
+				long = nil
+			}
+			if ferr := fn(trimNewline(line)); ferr != nil {
+				return ferr
+			}
+		}
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return fmt.Errorf("read log file: %w", err)
+		}
+	}
+}
+
+// trimNewline removes a trailing "\n" or "\r\n".
+func trimNewline(b []byte) []byte {
+	if n := len(b); n > 0 && b[n-1] == '\n' {
+		b = b[:n-1]
+		if n := len(b); n > 0 && b[n-1] == '\r' {
+			b = b[:n-1]
+		}
+	}
+	return b
+}
+
+// StripEscapes removes terminal escape sequences (CSI, OSC, and other ESC
+// sequences) and control characters other than tab from s. Carriage returns
+// are dropped, so text redrawn in place reads as one run.
+func StripEscapes(s string) string {
+	if !strings.ContainsFunc(s, isControl) {
+		return s
+	}
+
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\x1b':
+			i = skipEscape(s, i)
+		case c == '\t' || !isControl(rune(c)):
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// skipEscape returns the index of the last byte of the escape sequence
+// starting at s[i].
+func skipEscape(s string, i int) int {
+	if i+1 >= len(s) {
+		return i
+	}
+	switch s[i+1] {
+	case '[': // CSI: parameters and intermediates, ended by a byte in @-~
+		for j := i + 2; j < len(s); j++ {
+			if s[j] >= 0x40 && s[j] <= 0x7e {
+				return j
+			}
+		}
+		return len(s) - 1
+	case ']', 'P', '_', '^': // OSC, DCS, APC, PM: ended by BEL or ST (ESC \)
+		for j := i + 2; j < len(s); j++ {
+			if s[j] == '\a' {
+				return j
+			}
+			if s[j] == '\x1b' && j+1 < len(s) && s[j+1] == '\\' {
+				return j + 1
+			}
+		}
+		return len(s) - 1
+	case '(', ')', '*', '+': // Character set designation takes one more byte
+		return min(i+2, len(s)-1)
+	default: // Two-byte sequence such as ESC 7 or ESC =
+		return i + 1
+	}
+}
+
+// isControl reports whether r is an ASCII control character or DEL.
+func isControl(r rune) bool {
+	return r < 0x20 || r == 0x7f
+}
@@ -0,0 +1,160 @@
+package logging
+
+import (
+	"errors"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func searchLog(t *testing.T, reader *Reader, opts *SearchOpts) []Match {
+	t.Helper()
+
+	var matches []Match
+	err := reader.Search("inst1", "sess1", opts, func(m *Match) error {
+		matches = append(matches, *m)
+		return nil
+	})
+	require.NoError(t, err)
+	return matches
+}
+
+func TestReader_Search(t *testing.T) {
+	dir := t.TempDir()
+	lines := []string{
+		"starting",
+		"\x1b[32mrunning migration 001\x1b[0m",
+		"ok",
+		"running migration 002",
+		"\x1b[31merror: migration failed\x1b[0m",
+		"rolling back",
+		"done",
+		"idle",
+		"idle",
+		"error: again",
+	}
+	createTestLog(t, dir, "inst1", "sess1", lines)
+	reader := NewReader(NewPathManager(dir))
+
+	t.Run("matches text without escape sequences", func(t *testing.T) {
+		matches := searchLog(t, reader, &SearchOpts{Pattern: regexp.MustCompile(`^error: migration`)})
+
+		require.Len(t, matches, 1)
+		assert.Equal(t, Match{Line: 5, Text: "error: migration failed"}, matches[0])
+	})
+
+	t.Run("attaches context lines", func(t *testing.T) {
+		matches := searchLog(t, reader, &SearchOpts{Pattern: regexp.MustCompile(`error`), Context: 1})
+
+		require.Len(t, matches, 2)
+		assert.Equal(t, Match{
+			Line:   5,
+			Text:   "error: migration failed",
+			Before: []string{"running migration 002"},
+			After:  []string{"rolling back"},
+		}, matches[0])
+		assert.Equal(t, Match{Line: 10, Text: "error: again", Before: []string{"idle"}}, matches[1])
+	})
+
+	t.Run("adjacent matches do not repeat context", func(t *testing.T) {
+		matches := searchLog(t, reader, &SearchOpts{Pattern: regexp.MustCompile(`migration`), Context: 2})
+
+		require.Len(t, matches, 3)
+		assert.Equal(t, []string{"starting"}, matches[0].Before)
+		assert.Equal(t, []string{"ok"}, matches[0].After)
+		assert.Empty(t, matches[1].Before)
+		assert.Empty(t, matches[1].After)
+		assert.Equal(t, []string{"rolling back", "done"}, matches[2].After)
+	})
+
+	t.Run("stops when the callback fails", func(t *testing.T) {
+		stop := errors.New("stop")
+		calls := 0
+		err := reader.Search("inst1", "sess1", &SearchOpts{Pattern: regexp.MustCompile(`migration`)}, func(*Match) error {
+			calls++
+			return stop
+		})
+
+		require.ErrorIs(t, err, stop)
+		assert.Equal(t, 1, calls)
+	})
+}
+
+func TestReader_Search_RotatedSegments(t *testing.T) {
+	dir := t.TempDir()
+	pm := NewPathManager(dir)
+	path, err := pm.EnsureSessionLog("inst1", "sess1")
+	require.NoError(t, err)
+
+	w, err := NewRotatingWriter(path, RotationPolicy{MaxSize: 12, MaxFiles: 5, Compress: true})
+	require.NoError(t, err)
+	for _, line := range []string{"alpha 1", "beta 2", "alpha 3"} {
+		_, err := w.Write([]byte(line + "\n"))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+
+	reader := NewReader(pm)
+
+	t.Run("searches every segment with global line numbers", func(t *testing.T) {
+		matches := searchLog(t, reader, &SearchOpts{Pattern: regexp.MustCompile(`alpha`)})
+
+		require.Len(t, matches, 2)
+		assert.Equal(t, 1, matches[0].Line)
+		assert.Equal(t, 3, matches[1].Line)
+	})
+
+	t.Run("skips segments last written before since", func(t *testing.T) {
+		old := time.Now().Add(-48 * time.Hour)
+		require.NoError(t, os.Chtimes(path+".2.gz", old, old))
+
+		matches := searchLog(t, reader, &SearchOpts{
+			Pattern: regexp.MustCompile(`alpha`),
+			Since:   time.Now().Add(-24 * time.Hour),
+		})
+
+		require.Len(t, matches, 1)
+		assert.Equal(t, 3, matches[0].Line, "line numbers count skipped segments")
+	})
+}
+
+func TestReader_Search_LongLines(t *testing.T) {
+	dir := t.TempDir()
+	long := strings.Repeat("x", 200*1024) + " needle"
+	createTestLog(t, dir, "inst1", "sess1", []string{long, "short needle"})
+
+	matches := searchLog(t, NewReader(NewPathManager(dir)), &SearchOpts{Pattern: regexp.MustCompile(`needle`)})
+
+	require.Len(t, matches, 2)
+	assert.Equal(t, long, matches[0].Text)
+	assert.Equal(t, 2, matches[1].Line)
+}
+
+func TestStripEscapes(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		expect string
+	}{
+		{"plain text", "hello world", "hello world"},
+		{"SGR colors", "\x1b[1;31merror\x1b[0m", "error"},
+		{"cursor movement", "\x1b[2K\x1b[1Gprogress", "progress"},
+		{"private mode", "\x1b[?25lhidden\x1b[?25h", "hidden"},
+		{"OSC title with BEL", "\x1b]0;title\aafter", "after"},
+		{"OSC with ST", "\x1b]8;;http://x\x1b\\link\x1b]8;;\x1b\\", "link"},
+		{"charset designation", "\x1b(Bline", "line"},
+		{"carriage returns and controls", "10%\r20%\x08\tdone", "10%20%\tdone"},
+		{"trailing ESC", "text\x1b", "text"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expect, StripEscapes(tt.input))
+		})
+	}
+}
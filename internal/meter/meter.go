@@ -0,0 +1,44 @@
+// Package meter defines amounts of model usage: the tokens an agent used and
+// the cost it reported. It has no dependencies, so the catalog, budgets, and
+// usage accounting can all share the type.
+package meter
+
+// Usage is an amount of model usage.
+type Usage struct {
+	InputTokens      int64   `json:"input_tokens,omitempty"`       // Uncached input tokens
+	OutputTokens     int64   `json:"output_tokens,omitempty"`      // Output tokens, including reasoning
+	CacheReadTokens  int64   `json:"cache_read_tokens,omitempty"`  // Input tokens served from the prompt cache
+	CacheWriteTokens int64   `json:"cache_write_tokens,omitempty"` // Input tokens written to the prompt cache
+	CostUSD          float64 `json:"cost_usd,omitempty"`           // Cost reported by the agent (0 if not reported)
+}
+
+// Add adds o to u.
+func (u *Usage) Add(o Usage) {
+	u.InputTokens += o.InputTokens
+	u.OutputTokens += o.OutputTokens
+	u.CacheReadTokens += o.CacheReadTokens
+	u.CacheWriteTokens += o.CacheWriteTokens
+	u.CostUSD += o.CostUSD
+}
+
+// Since returns the usage added to u after it was o, assuming usage only
+// grows. Amounts that shrank, such as after a file was removed, count as zero.
+func (u Usage) Since(o Usage) Usage {
+	return Usage{
+		InputTokens:      max(u.InputTokens-o.InputTokens, 0),
+		OutputTokens:     max(u.OutputTokens-o.OutputTokens, 0),
+		CacheReadTokens:  max(u.CacheReadTokens-o.CacheReadTokens, 0),
+		CacheWriteTokens: max(u.CacheWriteTokens-o.CacheWriteTokens, 0),
+		CostUSD:          max(u.CostUSD-o.CostUSD, 0),
+	}
+}
+
+// TotalTokens returns all input, cached, and output tokens.
+func (u Usage) TotalTokens() int64 {
+	return u.InputTokens + u.OutputTokens + u.CacheReadTokens + u.CacheWriteTokens
+}
+
+// IsZero reports whether no usage has been recorded.
+func (u Usage) IsZero() bool {
+	return u == Usage{}
+}
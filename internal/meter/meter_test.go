@@ -0,0 +1,25 @@
+package meter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUsage_Add(t *testing.T) {
+	u := Usage{InputTokens: 1, OutputTokens: 2, CacheReadTokens: 3, CacheWriteTokens: 4, CostUSD: 0.5}
+	u.Add(Usage{InputTokens: 10, OutputTokens: 20, CacheReadTokens: 30, CacheWriteTokens: 40, CostUSD: 0.25})
+
+	assert.Equal(t, Usage{InputTokens: 11, OutputTokens: 22, CacheReadTokens: 33, CacheWriteTokens: 44, CostUSD: 0.75}, u)
+	assert.Equal(t, int64(110), u.TotalTokens())
+	assert.False(t, u.IsZero())
+	assert.True(t, Usage{}.IsZero())
+}
+
+func TestUsage_Since(t *testing.T) {
+	u := Usage{InputTokens: 11, OutputTokens: 22, CacheReadTokens: 3, CostUSD: 0.75}
+
+	assert.Equal(t, Usage{InputTokens: 10, OutputTokens: 20, CostUSD: 0.25},
+		u.Since(Usage{InputTokens: 1, OutputTokens: 2, CacheReadTokens: 4, CostUSD: 0.5}))
+	assert.Equal(t, u, u.Since(Usage{}))
+}
@@ -0,0 +1,252 @@
+package multiplexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/muesli/cancelreader"
+	"golang.org/x/term"
+
+	"github.com/jmgilman/headjack/internal/ptyd"
+)
+
+// daemonStartTimeout bounds how long to wait for a started daemon to accept connections.
+const daemonStartTimeout = 5 * time.Second
+
+// DaemonStarter launches the session daemon in the background.
+// It should return once the daemon process has been started; readiness is
+// checked by the caller.
+type DaemonStarter func(ctx context.Context) error
+
+// builtin implements Multiplexer using headjack's own PTY session daemon,
+// removing the dependency on an external multiplexer binary.
+type builtin struct {
+	client *ptyd.Client
+	start  DaemonStarter
+	stdin  io.Reader
+	stdout io.Writer
+}
+
+// NewBuiltin creates a Multiplexer backed by the session daemon listening on
+// socketPath. The daemon is started with start when a session is created and
+// no daemon is running.
+func NewBuiltin(socketPath string, start DaemonStarter) Multiplexer {
+	return &builtin{
+		client: ptyd.NewClient(socketPath),
+		start:  start,
+		stdin:  os.Stdin,
+		stdout: os.Stdout,
+	}
+}
+
+func (b *builtin) CreateSession(ctx context.Context, opts *CreateSessionOpts) (*Session, error) {
+	if opts == nil || opts.Name == "" {
+		return nil, fmt.Errorf("%w: session name is required", ErrCreateFailed)
+	}
+
+	if err := b.ensureDaemon(ctx); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCreateFailed, err)
+	}
+
+	info, err := b.client.Create(ctx, &ptyd.CreateOpts{
+		Name:       opts.Name,
+		Command:    opts.Command,
+		Cwd:        opts.Cwd,
+		Env:        opts.Env,
+		LogPath:    opts.LogPath,
+		LogCommand: opts.LogCommand,
+	})
+	if err != nil {
+		if errors.Is(err, ptyd.ErrSessionExists) {
+			return nil, ErrSessionExists
+		}
+		return nil, fmt.Errorf("%w: %v", ErrCreateFailed, err)
+	}
+
+	return &Session{
+		ID:        info.Name,
+		Name:      info.Name,
+		CreatedAt: info.CreatedAt,
+	}, nil
+}
+
+func (b *builtin) AttachSession(ctx context.Context, sessionName string) error {
+	opts := &ptyd.AttachOpts{
+		Stdin:  b.stdin,
+		Stdout: b.stdout,
+	}
+
+	if file, ok := b.stdin.(*os.File); ok && term.IsTerminal(int(file.Fd())) {
+		fd := int(file.Fd())
+
+		// Put terminal in raw mode for proper TTY handling
+		oldState, err := term.MakeRaw(fd)
+		if err != nil {
+			return fmt.Errorf("set terminal raw mode: %w", err)
+		}
+		defer func() { _ = term.Restore(fd, oldState) }()
+
+		// A cancelable reader stops the input goroutine on detach so it does
+		// not swallow keystrokes meant for the host shell.
+		reader, err := cancelreader.NewReader(file)
+		if err == nil {
+			defer reader.Close()
+			defer reader.Cancel()
+			opts.Stdin = reader
+		}
+
+		if cols, rows, err := term.GetSize(fd); err == nil {
+			opts.Size = &ptyd.Size{Rows: uint16(rows), Cols: uint16(cols)} //nolint:gosec // G115: terminal sizes fit in uint16
+		}
+
+		// Forward window resizes to the session
+		sigCh := make(chan os.Signal, 1)
+		signal.Notify(sigCh, syscall.SIGWINCH)
+		defer signal.Stop(sigCh)
+
+		resize := make(chan ptyd.Size, 1)
+		stop := make(chan struct{})
+		defer close(stop)
+		go func() {
+			for {
+				select {
+				case <-sigCh:
+					cols, rows, err := term.GetSize(fd)
+					if err != nil {
+						continue
+					}
+					select {
+					case resize <- ptyd.Size{Rows: uint16(rows), Cols: uint16(cols)}: //nolint:gosec // G115: terminal sizes fit in uint16
+					case <-stop:
+						return
+					}
+				case <-stop:
+					return
+				}
+			}
+		}()
+		opts.Resize = resize
+	}
+
+	if err := b.client.Attach(ctx, sessionName, opts); err != nil {
+		if errors.Is(err, ptyd.ErrSessionNotFound) || errors.Is(err, ptyd.ErrNotRunning) {
+			return ErrSessionNotFound
+		}
+		return fmt.Errorf("%w: %v", ErrAttachFailed, err)
+	}
+
+	return nil
+}
+
+func (b *builtin) ListSessions(ctx context.Context) ([]Session, error) {
+	infos, err := b.client.List(ctx)
+	if err != nil {
+		// No daemon means no sessions
+		if errors.Is(err, ptyd.ErrNotRunning) {
+			return []Session{}, nil
+		}
+		return nil, fmt.Errorf("list sessions: %w", err)
+	}
+
+	sessions := make([]Session, 0, len(infos))
+	for _, info := range infos {
+		sessions = append(sessions, Session{
+			ID:        info.Name,
+			Name:      info.Name,
+			CreatedAt: info.CreatedAt,
+		})
+	}
+
+	return sessions, nil
+}
+
+func (b *builtin) KillSession(ctx context.Context, sessionName string) error {
+	if err := b.client.Kill(ctx, sessionName); err != nil {
+		if errors.Is(err, ptyd.ErrSessionNotFound) || errors.Is(err, ptyd.ErrNotRunning) {
+			return ErrSessionNotFound
+		}
+		return fmt.Errorf("kill session: %w", err)
+	}
+
+	return nil
+}
+
+func (b *builtin) SendKeys(ctx context.Context, sessionName string, opts *SendKeysOpts) error {
+	if opts == nil {
+		opts = &SendKeysOpts{}
+	}
+	input := opts.Text
+	if opts.Enter {
+		// Enter is a carriage return on the terminal's input
+		input += "\r"
+	}
+	if input == "" {
+		return nil
+	}
+
+	if err := b.client.Send(ctx, sessionName, []byte(input)); err != nil {
+		if errors.Is(err, ptyd.ErrSessionNotFound) || errors.Is(err, ptyd.ErrNotRunning) {
+			return ErrSessionNotFound
+		}
+		return fmt.Errorf("send keys: %w", err)
+	}
+
+	return nil
+}
+
+func (b *builtin) CapturePane(ctx context.Context, sessionName string, opts *CaptureOpts) (string, error) {
+	if opts == nil {
+		opts = &CaptureOpts{}
+	}
+
+	// The daemon renders output through a virtual terminal as it arrives
+	screen, err := b.client.Capture(ctx, sessionName, &ptyd.CaptureOpts{
+		History: opts.History,
+		Escapes: opts.Escapes,
+	})
+	if err != nil {
+		if errors.Is(err, ptyd.ErrSessionNotFound) || errors.Is(err, ptyd.ErrNotRunning) {
+			return "", ErrSessionNotFound
+		}
+		return "", fmt.Errorf("capture pane: %w", err)
+	}
+
+	return screen, nil
+}
+
+// ensureDaemon starts the daemon if it is not running and waits until it
+// accepts connections.
+func (b *builtin) ensureDaemon(ctx context.Context) error {
+	if err := b.client.Ping(ctx); err == nil {
+		return nil
+	}
+
+	if b.start == nil {
+		return ptyd.ErrNotRunning
+	}
+	if err := b.start(ctx); err != nil {
+		return fmt.Errorf("start session daemon: %w", err)
+	}
+
+	deadline := time.Now().Add(daemonStartTimeout)
+	for {
+		err := b.client.Ping(ctx)
+		if err == nil {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package multiplexer
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmgilman/headjack/internal/ptyd"
+)
+
+// newTestBuiltin returns a builtin multiplexer whose starter runs an
+// in-process daemon, along with a counter of daemon starts.
+func newTestBuiltin(t *testing.T) (*builtin, *int) {
+	t.Helper()
+
+	// Unix socket paths are limited to ~100 bytes, so avoid t.TempDir's long names
+	dir, err := os.MkdirTemp("", "hjkmux")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	socketPath := filepath.Join(dir, "d.sock")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	t.Cleanup(func() {
+		cancel()
+		wg.Wait()
+	})
+
+	starts := 0
+	start := func(context.Context) error {
+		starts++
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = ptyd.NewServer(socketPath).Serve(ctx)
+		}()
+		return nil
+	}
+
+	b, ok := NewBuiltin(socketPath, start).(*builtin)
+	require.True(t, ok)
+	return b, &starts
+}
+
+func TestBuiltin_NoDaemon(t *testing.T) {
+	ctx := context.Background()
+	b, starts := newTestBuiltin(t)
+
+	sessions, err := b.ListSessions(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, sessions)
+
+	require.ErrorIs(t, b.KillSession(ctx, "missing"), ErrSessionNotFound)
+	require.ErrorIs(t, b.AttachSession(ctx, "missing"), ErrSessionNotFound)
+	require.ErrorIs(t, b.SendKeys(ctx, "missing", &SendKeysOpts{Text: "hi"}), ErrSessionNotFound)
+	_, err = b.CapturePane(ctx, "missing", &CaptureOpts{})
+	require.ErrorIs(t, err, ErrSessionNotFound)
+	assert.Equal(t, 0, *starts, "read-only operations must not start the daemon")
+}
+
+func TestBuiltin_CreateSession(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("starts daemon once and manages sessions", func(t *testing.T) {
+		b, starts := newTestBuiltin(t)
+		logPath := filepath.Join(t.TempDir(), "session.log")
+
+		session, err := b.CreateSession(ctx, &CreateSessionOpts{
+			Name:    "hjk-abc-one",
+			Command: []string{"sh", "-c", "echo started; sleep 60"},
+			LogPath: logPath,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "hjk-abc-one", session.Name)
+		assert.False(t, session.CreatedAt.IsZero())
+
+		_, err = b.CreateSession(ctx, &CreateSessionOpts{Name: "hjk-abc-two", Command: []string{"sleep", "60"}})
+		require.NoError(t, err)
+		assert.Equal(t, 1, *starts)
+
+		_, err = b.CreateSession(ctx, &CreateSessionOpts{Name: "hjk-abc-one", Command: []string{"sleep", "60"}})
+		require.ErrorIs(t, err, ErrSessionExists)
+
+		sessions, err := b.ListSessions(ctx)
+		require.NoError(t, err)
+		require.Len(t, sessions, 2)
+		assert.Equal(t, "hjk-abc-one", sessions[0].Name)
+
+		require.Eventually(t, func() bool {
+			data, err := os.ReadFile(logPath)
+			return err == nil && strings.Contains(string(data), "started")
+		}, 5*time.Second, 10*time.Millisecond)
+
+		require.NoError(t, b.KillSession(ctx, "hjk-abc-one"))
+		require.NoError(t, b.KillSession(ctx, "hjk-abc-two"))
+		require.ErrorIs(t, b.KillSession(ctx, "hjk-abc-one"), ErrSessionNotFound)
+	})
+
+	t.Run("returns ErrCreateFailed when name is empty", func(t *testing.T) {
+		b, _ := newTestBuiltin(t)
+
+		_, err := b.CreateSession(ctx, &CreateSessionOpts{})
+		require.ErrorIs(t, err, ErrCreateFailed)
+	})
+
+	t.Run("returns ErrCreateFailed when daemon cannot start", func(t *testing.T) {
+		b := &builtin{client: ptyd.NewClient(filepath.Join(t.TempDir(), "d.sock"))}
+
+		_, err := b.CreateSession(ctx, &CreateSessionOpts{Name: "test"})
+		require.ErrorIs(t, err, ErrCreateFailed)
+	})
+}
+
+func TestBuiltin_AttachSession(t *testing.T) {
+	ctx := context.Background()
+	b, _ := newTestBuiltin(t)
+
+	_, err := b.CreateSession(ctx, &CreateSessionOpts{
+		Name:    "hjk-abc-attach",
+		Command: []string{"sh", "-c", "echo hello; read -r line"},
+	})
+	require.NoError(t, err)
+
+	// Closing input without detaching ends the attach; the session keeps running
+	out := &strings.Builder{}
+	b.stdin = strings.NewReader("")
+	b.stdout = &lockedWriter{w: out}
+	require.NoError(t, b.AttachSession(ctx, "hjk-abc-attach"))
+
+	sessions, err := b.ListSessions(ctx)
+	require.NoError(t, err)
+	assert.Len(t, sessions, 1)
+	require.NoError(t, b.KillSession(ctx, "hjk-abc-attach"))
+}
+
+func TestBuiltin_SendKeys(t *testing.T) {
+	ctx := context.Background()
+	b, _ := newTestBuiltin(t)
+	logPath := filepath.Join(t.TempDir(), "session.log")
+
+	_, err := b.CreateSession(ctx, &CreateSessionOpts{
+		Name:    "hjk-abc-send",
+		Command: []string{"sh", "-c", "stty -echo; while read -r line; do echo \"got:$line\"; done"},
+		LogPath: logPath,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = b.KillSession(ctx, "hjk-abc-send") })
+
+	require.NoError(t, b.SendKeys(ctx, "hjk-abc-send", nil))
+	require.NoError(t, b.SendKeys(ctx, "hjk-abc-send", &SendKeysOpts{Text: "continue", Enter: true}))
+
+	require.Eventually(t, func() bool {
+		data, err := os.ReadFile(logPath)
+		return err == nil && strings.Contains(string(data), "got:continue")
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestBuiltin_CapturePane(t *testing.T) {
+	ctx := context.Background()
+	b, _ := newTestBuiltin(t)
+
+	_, err := b.CreateSession(ctx, &CreateSessionOpts{
+		Name:    "hjk-abc-capture",
+		Command: []string{"sh", "-c", "printf 'step 1\\rstep 2\\n'; sleep 60"},
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = b.KillSession(ctx, "hjk-abc-capture") })
+
+	require.Eventually(t, func() bool {
+		screen, err := b.CapturePane(ctx, "hjk-abc-capture", nil)
+		return err == nil && screen == "step 2\n"
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+// lockedWriter serializes writes to an underlying writer.
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (l *lockedWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Write(p)
+}
@@ -40,6 +40,23 @@ type CreateSessionOpts struct {
 	Cwd     string   // Working directory (optional)
 	Env     []string // Environment variables (KEY=VALUE format)
 	LogPath string   // Path to log file for capturing session output (optional)
+
+	// LogCommand receives the session output on stdin and writes it to
+	// LogPath, e.g. to rotate the log (optional, defaults to appending to
+	// LogPath directly). Ignored when LogPath is empty.
+	LogCommand []string
+}
+
+// SendKeysOpts configures input sent to a session.
+type SendKeysOpts struct {
+	Text  string // Literal text to type into the session (optional)
+	Enter bool   // Press Enter after the text
+}
+
+// CaptureOpts configures a pane capture.
+type CaptureOpts struct {
+	History bool // Include scrollback above the visible screen
+	Escapes bool // Preserve colors and text attributes as ANSI escape sequences
 }
 
 // Multiplexer provides terminal multiplexer operations.
@@ -64,6 +81,17 @@ type Multiplexer interface {
 	// KillSession terminates a session.
 	// Returns ErrSessionNotFound if session doesn't exist.
 	KillSession(ctx context.Context, sessionName string) error
+
+	// SendKeys types input into a session as if entered at its terminal,
+	// without attaching to it. A nil opts sends nothing.
+	// Returns ErrSessionNotFound if session doesn't exist.
+	SendKeys(ctx context.Context, sessionName string, opts *SendKeysOpts) error
+
+	// CapturePane returns the text currently displayed in a session's pane,
+	// one line per row, with trailing blank lines removed. A nil opts
+	// captures the visible screen as plain text.
+	// Returns ErrSessionNotFound if session doesn't exist.
+	CapturePane(ctx context.Context, sessionName string, opts *CaptureOpts) (string, error)
 }
 
 // FormatSessionName creates a namespaced session name using the format:
@@ -0,0 +1,62 @@
+package multiplexer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"golang.org/x/term"
+
+	"github.com/jmgilman/headjack/internal/exec"
+)
+
+// runAttached runs a blocking attach command with the current terminal's stdio.
+// If stdin is a terminal it is put into raw mode for the duration of the command.
+// When the command fails, mapErr receives the captured stderr so backends can
+// translate their messages into sentinel errors.
+func runAttached(
+	ctx context.Context,
+	e exec.Executor,
+	name string,
+	args []string,
+	mapErr func(stderr string, err error) error,
+) error {
+	stdinFd := int(os.Stdin.Fd())
+
+	// Capture stderr while also streaming to os.Stderr for user visibility
+	var stderrBuf bytes.Buffer
+	stderrWriter := io.MultiWriter(os.Stderr, &stderrBuf)
+
+	// Check if stdin is a terminal
+	if term.IsTerminal(stdinFd) {
+		// Put terminal in raw mode for proper TTY handling
+		oldState, err := term.MakeRaw(stdinFd)
+		if err != nil {
+			return fmt.Errorf("set terminal raw mode: %w", err)
+		}
+		defer func() { _ = term.Restore(stdinFd, oldState) }()
+
+		// Handle window resize signals
+		sigCh := make(chan os.Signal, 1)
+		signal.Notify(sigCh, syscall.SIGWINCH)
+		defer signal.Stop(sigCh)
+	}
+
+	// Run the multiplexer client with stdio attached
+	_, err := e.Run(ctx, &exec.RunOptions{
+		Name:   name,
+		Args:   args,
+		Stdin:  os.Stdin,
+		Stdout: os.Stdout,
+		Stderr: stderrWriter,
+	})
+	if err != nil {
+		return mapErr(stderrBuf.String(), err)
+	}
+
+	return nil
+}
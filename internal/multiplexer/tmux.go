@@ -1,16 +1,9 @@
 package multiplexer
 
 import (
-	"bytes"
 	"context"
 	"fmt"
-	"io"
-	"os"
-	"os/signal"
 	"strings"
-	"syscall"
-
-	"golang.org/x/term"
 
 	"github.com/jmgilman/headjack/internal/exec"
 )
@@ -77,8 +70,11 @@ func (t *tmux) CreateSession(ctx context.Context, opts *CreateSessionOpts) (*Ses
 	// Set up log capture via pipe-pane if LogPath is specified
 	if opts.LogPath != "" {
 		// Shell-escape the path to handle spaces and special characters safely
-		escapedPath := shellEscape(opts.LogPath)
-		pipeArgs := []string{"pipe-pane", "-t", opts.Name, "cat >> " + escapedPath}
+		pipeCmd := "cat >> " + shellEscape(opts.LogPath)
+		if len(opts.LogCommand) > 0 {
+			pipeCmd = shellJoin(opts.LogCommand)
+		}
+		pipeArgs := []string{"pipe-pane", "-t", opts.Name, pipeCmd}
 		// Log capture failure is non-fatal, session was still created
 		//nolint:errcheck // best-effort log capture
 		_, _ = t.exec.Run(ctx, &exec.RunOptions{
@@ -97,61 +93,12 @@ func (t *tmux) AttachSession(ctx context.Context, sessionName string) error {
 	// tmux attach-session -t <session-name>
 	args := []string{"attach-session", "-t", sessionName}
 
-	stdinFd := int(os.Stdin.Fd())
-
-	// Capture stderr while also streaming to os.Stderr for user visibility
-	var stderrBuf bytes.Buffer
-	stderrWriter := io.MultiWriter(os.Stderr, &stderrBuf)
-
-	// Check if stdin is a terminal
-	if !term.IsTerminal(stdinFd) {
-		// Fall back to non-interactive mode
-		_, err := t.exec.Run(ctx, &exec.RunOptions{
-			Name:   "tmux",
-			Args:   args,
-			Stdin:  os.Stdin,
-			Stdout: os.Stdout,
-			Stderr: stderrWriter,
-		})
-		if err != nil {
-			stderr := stderrBuf.String()
-			if strings.Contains(stderr, "no session") || strings.Contains(stderr, "can't find session") {
-				return ErrSessionNotFound
-			}
-			return fmt.Errorf("%w: %v", ErrAttachFailed, err)
-		}
-		return nil
-	}
-
-	// Put terminal in raw mode for proper TTY handling
-	oldState, err := term.MakeRaw(stdinFd)
-	if err != nil {
-		return fmt.Errorf("set terminal raw mode: %w", err)
-	}
-	defer func() { _ = term.Restore(stdinFd, oldState) }()
-
-	// Handle window resize signals
-	sigCh := make(chan os.Signal, 1)
-	signal.Notify(sigCh, syscall.SIGWINCH)
-	defer signal.Stop(sigCh)
-
-	// Run tmux with stdio attached
-	_, err = t.exec.Run(ctx, &exec.RunOptions{
-		Name:   "tmux",
-		Args:   args,
-		Stdin:  os.Stdin,
-		Stdout: os.Stdout,
-		Stderr: stderrWriter,
-	})
-	if err != nil {
-		stderr := stderrBuf.String()
+	return runAttached(ctx, t.exec, "tmux", args, func(stderr string, err error) error {
 		if strings.Contains(stderr, "no session") || strings.Contains(stderr, "can't find session") {
 			return ErrSessionNotFound
 		}
 		return fmt.Errorf("%w: %v", ErrAttachFailed, err)
-	}
-
-	return nil
+	})
 }
 
 func (t *tmux) ListSessions(ctx context.Context) ([]Session, error) {
@@ -213,6 +160,84 @@ func (t *tmux) KillSession(ctx context.Context, sessionName string) error {
 	return nil
 }
 
+func (t *tmux) SendKeys(ctx context.Context, sessionName string, opts *SendKeysOpts) error {
+	if opts == nil {
+		opts = &SendKeysOpts{}
+	}
+	var calls [][]string
+	if opts.Text != "" {
+		// -l sends the text literally instead of interpreting key names
+		calls = append(calls, []string{"send-keys", "-t", sessionName, "-l", "--", opts.Text})
+	}
+	if opts.Enter {
+		calls = append(calls, []string{"send-keys", "-t", sessionName, "Enter"})
+	}
+
+	for _, args := range calls {
+		result, err := t.exec.Run(ctx, &exec.RunOptions{
+			Name: "tmux",
+			Args: args,
+		})
+		if err != nil {
+			if isTmuxNotFound(string(result.Stderr)) {
+				return ErrSessionNotFound
+			}
+			return fmt.Errorf("send keys: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (t *tmux) CapturePane(ctx context.Context, sessionName string, opts *CaptureOpts) (string, error) {
+	if opts == nil {
+		opts = &CaptureOpts{}
+	}
+
+	// tmux capture-pane -p -t <session-name>
+	// -p: print to stdout instead of a paste buffer
+	// -e: include escape sequences for colors and attributes
+	// -S -: start at the beginning of the scrollback
+	args := []string{"capture-pane", "-p", "-t", sessionName}
+	if opts.Escapes {
+		args = append(args, "-e")
+	}
+	if opts.History {
+		args = append(args, "-S", "-")
+	}
+
+	result, err := t.exec.Run(ctx, &exec.RunOptions{
+		Name: "tmux",
+		Args: args,
+	})
+	if err != nil {
+		if isTmuxNotFound(string(result.Stderr)) {
+			return "", ErrSessionNotFound
+		}
+		return "", fmt.Errorf("capture pane: %w", err)
+	}
+
+	return trimTrailingBlankLines(string(result.Stdout)), nil
+}
+
+// isTmuxNotFound reports whether tmux output indicates a missing session.
+func isTmuxNotFound(stderr string) bool {
+	return strings.Contains(stderr, "no session") ||
+		strings.Contains(stderr, "can't find session") ||
+		strings.Contains(stderr, "can't find pane") ||
+		strings.Contains(stderr, "no server running")
+}
+
+// trimTrailingBlankLines removes the empty rows below the last line of
+// output in a captured screen, leaving a single trailing newline.
+func trimTrailingBlankLines(screen string) string {
+	trimmed := strings.TrimRight(screen, " \n")
+	if trimmed == "" {
+		return ""
+	}
+	return trimmed + "\n"
+}
+
 // shellEscape escapes a string for safe use in a shell command.
 // It wraps the string in single quotes and escapes any embedded single quotes.
 func shellEscape(s string) string {
@@ -128,6 +128,31 @@ func TestTmux_CreateSession(t *testing.T) {
 		require.NoError(t, err)
 	})
 
+	t.Run("pipes output through the log command", func(t *testing.T) {
+		var pipeCmd string
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				switch opts.Args[0] {
+				case tmuxCmdListSessions:
+					return &exec.Result{Stderr: []byte("no server running"), ExitCode: 1}, errors.New("exit code 1")
+				case "pipe-pane":
+					pipeCmd = opts.Args[3]
+				}
+				return &exec.Result{ExitCode: 0}, nil
+			},
+		}
+
+		tm := NewTmux(mockExec)
+		_, err := tm.CreateSession(ctx, &CreateSessionOpts{
+			Name:       "test-session",
+			LogPath:    "/var/log/session.log",
+			LogCommand: []string{"/usr/bin/hjk", "log-writer", "/var/log/my session.log"},
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "'/usr/bin/hjk' 'log-writer' '/var/log/my session.log'", pipeCmd)
+	})
+
 	t.Run("returns ErrSessionExists when session exists", func(t *testing.T) {
 		mockExec := &mocks.ExecutorMock{
 			RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
@@ -477,3 +502,126 @@ func TestTmux_AttachSession(t *testing.T) {
 		assert.ErrorIs(t, err, ErrAttachFailed)
 	})
 }
+
+func TestTmux_SendKeys(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("sends nothing for nil options", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{}
+
+		tm := NewTmux(mockExec)
+		err := tm.SendKeys(ctx, "my-session", nil)
+
+		require.NoError(t, err)
+		assert.Empty(t, mockExec.RunCalls())
+	})
+
+	t.Run("sends literal text then Enter", func(t *testing.T) {
+		var calls [][]string
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				assert.Equal(t, "tmux", opts.Name)
+				calls = append(calls, opts.Args)
+				return &exec.Result{ExitCode: 0}, nil
+			},
+		}
+
+		tm := NewTmux(mockExec)
+		err := tm.SendKeys(ctx, "my-session", &SendKeysOpts{Text: "-continue", Enter: true})
+
+		require.NoError(t, err)
+		assert.Equal(t, [][]string{
+			{"send-keys", "-t", "my-session", "-l", "--", "-continue"},
+			{"send-keys", "-t", "my-session", "Enter"},
+		}, calls)
+	})
+
+	t.Run("sends text without Enter", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				return &exec.Result{ExitCode: 0}, nil
+			},
+		}
+
+		tm := NewTmux(mockExec)
+		require.NoError(t, tm.SendKeys(ctx, "my-session", &SendKeysOpts{Text: "hello"}))
+		assert.Len(t, mockExec.RunCalls(), 1)
+	})
+
+	t.Run("returns ErrSessionNotFound when session missing", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				return &exec.Result{
+					Stderr:   []byte("can't find session: missing"),
+					ExitCode: 1,
+				}, errors.New("exit code 1")
+			},
+		}
+
+		tm := NewTmux(mockExec)
+		err := tm.SendKeys(ctx, "missing", &SendKeysOpts{Text: "hello", Enter: true})
+
+		require.ErrorIs(t, err, ErrSessionNotFound)
+		assert.Len(t, mockExec.RunCalls(), 1)
+	})
+}
+
+func TestTmux_CapturePane(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name         string
+		opts         *CaptureOpts
+		expectedArgs []string
+	}{
+		{
+			name:         "visible screen",
+			opts:         &CaptureOpts{},
+			expectedArgs: []string{"capture-pane", "-p", "-t", "my-session"},
+		},
+		{
+			name:         "nil options",
+			opts:         nil,
+			expectedArgs: []string{"capture-pane", "-p", "-t", "my-session"},
+		},
+		{
+			name:         "with escapes and history",
+			opts:         &CaptureOpts{Escapes: true, History: true},
+			expectedArgs: []string{"capture-pane", "-p", "-t", "my-session", "-e", "-S", "-"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockExec := &mocks.ExecutorMock{
+				RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+					assert.Equal(t, "tmux", opts.Name)
+					assert.Equal(t, tt.expectedArgs, opts.Args)
+					return &exec.Result{Stdout: []byte("$ claude\nThinking...\n\n\n\n"), ExitCode: 0}, nil
+				},
+			}
+
+			tm := NewTmux(mockExec)
+			screen, err := tm.CapturePane(ctx, "my-session", tt.opts)
+
+			require.NoError(t, err)
+			assert.Equal(t, "$ claude\nThinking...\n", screen)
+		})
+	}
+
+	t.Run("returns ErrSessionNotFound when session missing", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				return &exec.Result{
+					Stderr:   []byte("can't find pane: missing"),
+					ExitCode: 1,
+				}, errors.New("exit code 1")
+			},
+		}
+
+		tm := NewTmux(mockExec)
+		_, err := tm.CapturePane(ctx, "missing", &CaptureOpts{})
+
+		require.ErrorIs(t, err, ErrSessionNotFound)
+	})
+}
@@ -0,0 +1,390 @@
+package multiplexer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jmgilman/headjack/internal/exec"
+)
+
+// zellijNoSessions is printed by zellij when no sessions exist.
+const zellijNoSessions = "No active zellij sessions found"
+
+// Zellij reads a new session's layout after the creating client returns, so
+// CreateSession waits up to zellijStartTimeout for the session to be listed,
+// polling every zellijStartPoll, before removing the layout file.
+const (
+	zellijStartTimeout = 5 * time.Second
+	zellijStartPoll    = 50 * time.Millisecond
+)
+
+// zellij implements Multiplexer using the Zellij terminal workspace.
+//
+// Sessions are created in the background from a generated single-pane layout
+// whose pane runs the requested command and closes when it exits, mirroring a
+// tmux session that ends with its command. Zellij has no equivalent of tmux
+// pipe-pane, so log capture wraps the command with script(1), which records
+// everything written to the pane's terminal.
+type zellij struct {
+	exec exec.Executor
+	goos string
+}
+
+// NewZellij creates a Multiplexer using the zellij CLI.
+func NewZellij(e exec.Executor) Multiplexer {
+	return &zellij{exec: e, goos: runtime.GOOS}
+}
+
+func (z *zellij) CreateSession(ctx context.Context, opts *CreateSessionOpts) (*Session, error) {
+	if opts == nil || opts.Name == "" {
+		return nil, fmt.Errorf("%w: session name is required", ErrCreateFailed)
+	}
+
+	// Check if session already exists
+	sessions, err := z.ListSessions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("check existing sessions: %w", err)
+	}
+	for _, s := range sessions {
+		if s.Name == opts.Name {
+			return nil, ErrSessionExists
+		}
+	}
+
+	layout, err := z.layout(opts)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCreateFailed, err)
+	}
+	layoutPath, err := writeLayout(layout)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCreateFailed, err)
+	}
+	defer os.Remove(layoutPath)
+
+	// Create the session in the background
+	// The zellij server inherits the client's environment, which is how
+	// environment variables reach the session's pane.
+	result, err := z.exec.Run(ctx, &exec.RunOptions{
+		Name: "zellij",
+		Args: []string{"attach", "--create-background", opts.Name, "options", "--default-layout", layoutPath},
+		Dir:  opts.Cwd,
+		Env:  opts.Env,
+	})
+	if err != nil {
+		if strings.Contains(string(result.Stderr), "already exists") {
+			return nil, ErrSessionExists
+		}
+		return nil, fmt.Errorf("%w: %v", ErrCreateFailed, err)
+	}
+	if err := z.waitForSession(ctx, opts.Name); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCreateFailed, err)
+	}
+
+	return &Session{
+		ID:   opts.Name,
+		Name: opts.Name,
+	}, nil
+}
+
+// waitForSession waits until a session is listed, running or already exited.
+func (z *zellij) waitForSession(ctx context.Context, name string) error {
+	deadline := time.Now().Add(zellijStartTimeout)
+	for {
+		sessions, err := z.listSessions(ctx, true)
+		if err != nil {
+			return err
+		}
+		if slices.ContainsFunc(sessions, func(s Session) bool { return s.Name == name }) {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("session %s did not start within %s", name, zellijStartTimeout)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(zellijStartPoll):
+		}
+	}
+}
+
+func (z *zellij) AttachSession(ctx context.Context, sessionName string) error {
+	// zellij attach <session-name>
+	args := []string{"attach", sessionName}
+
+	return runAttached(ctx, z.exec, "zellij", args, func(stderr string, err error) error {
+		if isZellijNotFound(stderr) {
+			return ErrSessionNotFound
+		}
+		return fmt.Errorf("%w: %v", ErrAttachFailed, err)
+	})
+}
+
+func (z *zellij) ListSessions(ctx context.Context) ([]Session, error) {
+	return z.listSessions(ctx, false)
+}
+
+// listSessions lists sessions, including exited ones kept for resurrection
+// if includeExited is set.
+func (z *zellij) listSessions(ctx context.Context, includeExited bool) ([]Session, error) {
+	// zellij list-sessions --no-formatting
+	result, err := z.exec.Run(ctx, &exec.RunOptions{
+		Name: "zellij",
+		Args: []string{"list-sessions", "--no-formatting"},
+	})
+	if err != nil {
+		// Zellij exits non-zero when there are no sessions
+		if strings.Contains(string(result.Stderr), zellijNoSessions) ||
+			strings.Contains(string(result.Stdout), zellijNoSessions) {
+			return []Session{}, nil
+		}
+		return nil, fmt.Errorf("list sessions: %w", err)
+	}
+
+	output := strings.TrimSpace(string(result.Stdout))
+	if output == "" || strings.Contains(output, zellijNoSessions) {
+		return []Session{}, nil
+	}
+
+	// Each line looks like: <name> [Created 5m ago] (EXITED - attach to resurrect)
+	lines := strings.Split(output, "\n")
+	sessions := make([]Session, 0, len(lines))
+
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		// Exited sessions are kept for resurrection but are not running
+		if !includeExited && strings.Contains(line, "(EXITED") {
+			continue
+		}
+
+		sessions = append(sessions, Session{
+			ID:   fields[0],
+			Name: fields[0],
+		})
+	}
+
+	return sessions, nil
+}
+
+func (z *zellij) KillSession(ctx context.Context, sessionName string) error {
+	// zellij delete-session --force <session-name>
+	// delete-session (rather than kill-session) also discards the resurrection
+	// state so the name can be reused by a new session.
+	result, err := z.exec.Run(ctx, &exec.RunOptions{
+		Name: "zellij",
+		Args: []string{"delete-session", "--force", sessionName},
+	})
+	if err != nil {
+		if isZellijNotFound(string(result.Stderr)) {
+			return ErrSessionNotFound
+		}
+		return fmt.Errorf("kill session: %w", err)
+	}
+
+	return nil
+}
+
+func (z *zellij) SendKeys(ctx context.Context, sessionName string, opts *SendKeysOpts) error {
+	if opts == nil {
+		opts = &SendKeysOpts{}
+	}
+	var calls [][]string
+	if opts.Text != "" {
+		calls = append(calls, []string{"--session", sessionName, "action", "write-chars", "--", opts.Text})
+	}
+	if opts.Enter {
+		// Enter is a carriage return (byte 13) on the terminal's input
+		calls = append(calls, []string{"--session", sessionName, "action", "write", "13"})
+	}
+
+	for _, args := range calls {
+		result, err := z.exec.Run(ctx, &exec.RunOptions{
+			Name: "zellij",
+			Args: args,
+		})
+		if err != nil {
+			if isZellijNotFound(string(result.Stderr)) {
+				return ErrSessionNotFound
+			}
+			return fmt.Errorf("send keys: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (z *zellij) CapturePane(ctx context.Context, sessionName string, opts *CaptureOpts) (string, error) {
+	if opts == nil {
+		opts = &CaptureOpts{}
+	}
+
+	// zellij --session <session-name> action dump-screen [--full] <path>
+	// Zellij writes the screen to a file and always renders plain text, so
+	// Escapes is not supported.
+	file, err := os.CreateTemp("", "hjk-zellij-*.txt")
+	if err != nil {
+		return "", fmt.Errorf("create capture file: %w", err)
+	}
+	_ = file.Close()
+	defer os.Remove(file.Name())
+
+	args := []string{"--session", sessionName, "action", "dump-screen"}
+	if opts.History {
+		args = append(args, "--full")
+	}
+	args = append(args, file.Name())
+
+	result, err := z.exec.Run(ctx, &exec.RunOptions{
+		Name: "zellij",
+		Args: args,
+	})
+	if err != nil {
+		if isZellijNotFound(string(result.Stderr)) {
+			return "", ErrSessionNotFound
+		}
+		return "", fmt.Errorf("capture pane: %w", err)
+	}
+
+	data, err := os.ReadFile(file.Name())
+	if err != nil {
+		return "", fmt.Errorf("read capture file: %w", err)
+	}
+
+	return trimTrailingBlankLines(string(data)), nil
+}
+
+// layout renders a KDL layout with a single pane running the session command.
+func (z *zellij) layout(opts *CreateSessionOpts) (string, error) {
+	command, err := z.paneCommand(opts)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	b.WriteString("layout {\n    pane")
+	if opts.Cwd != "" {
+		b.WriteString(" cwd=" + kdlQuote(opts.Cwd))
+	}
+	if len(command) > 0 {
+		b.WriteString(" command=" + kdlQuote(command[0]) + " close_on_exit=true")
+		if len(command) > 1 {
+			b.WriteString(" {\n        args")
+			for _, arg := range command[1:] {
+				b.WriteString(" " + kdlQuote(arg))
+			}
+			b.WriteString("\n    }")
+		}
+	}
+	b.WriteString("\n}\n")
+
+	return b.String(), nil
+}
+
+// paneCommand returns the command the pane runs, wrapped with script(1) when
+// a log path is set. An empty result runs the user's default shell.
+func (z *zellij) paneCommand(opts *CreateSessionOpts) ([]string, error) {
+	if opts.LogPath == "" {
+		return opts.Command, nil
+	}
+	if _, err := z.exec.LookPath("script"); err != nil {
+		return nil, fmt.Errorf("session logging with zellij requires script(1) to be installed: %w", err)
+	}
+	if len(opts.LogCommand) == 0 {
+		return z.scriptCommand(opts.Command, opts.LogPath), nil
+	}
+
+	// Have script write its typescript to fd 3, piped into the log command,
+	// while the session itself keeps the pane's terminal (saved on fd 4).
+	script := z.scriptCommand(opts.Command, "/dev/fd/3")
+	return []string{"sh", "-c", "{ " + shellJoin(script) + " 3>&1 >&4 | " + shellJoin(opts.LogCommand) + "; } 4>&1"}, nil
+}
+
+// scriptCommand wraps command with script(1), recording output to logPath.
+func (z *zellij) scriptCommand(command []string, logPath string) []string {
+	// BSD script (macOS) takes the command as trailing arguments, while
+	// util-linux script takes it as a single shell string via -c.
+	if z.goos == "darwin" {
+		return append([]string{"script", "-q", "-a", "-F", logPath}, command...)
+	}
+
+	args := []string{"script", "-q", "-f", "-a"}
+	if len(command) > 0 {
+		args = append(args, "-c", shellJoin(command))
+	}
+	return append(args, logPath)
+}
+
+// writeLayout writes a layout to a temporary file and returns its path.
+func writeLayout(layout string) (string, error) {
+	file, err := os.CreateTemp("", "hjk-zellij-*.kdl")
+	if err != nil {
+		return "", fmt.Errorf("create layout file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString(layout); err != nil {
+		_ = os.Remove(file.Name())
+		return "", fmt.Errorf("write layout file: %w", err)
+	}
+
+	return file.Name(), nil
+}
+
+// zellijNotFoundPattern matches the messages zellij prints when a session
+// does not exist, such as "Session 'name' not found" and
+// "No session named "name" found".
+var zellijNotFoundPattern = regexp.MustCompile(`Session ["'][^"']*["'] not found|No session named |There is no active session|` + zellijNoSessions)
+
+// isZellijNotFound reports whether zellij output indicates a missing session.
+// Other "not found" errors, such as a missing command, do not match.
+func isZellijNotFound(output string) bool {
+	return zellijNotFoundPattern.MatchString(output)
+}
+
+// kdlQuote returns s as a KDL string literal.
+func kdlQuote(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(&b, `\u{%x}`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// shellJoin shell-escapes each argument and joins them with spaces.
+func shellJoin(args []string) string {
+	escaped := make([]string, len(args))
+	for i, arg := range args {
+		escaped[i] = shellEscape(arg)
+	}
+	return strings.Join(escaped, " ")
+}
@@ -0,0 +1,569 @@
+package multiplexer
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmgilman/headjack/internal/exec"
+	"github.com/jmgilman/headjack/internal/exec/mocks"
+)
+
+const zellijCmdListSessions = "list-sessions"
+
+// noZellijSessions simulates zellij's response when no sessions exist.
+func noZellijSessions() (*exec.Result, error) {
+	return &exec.Result{
+		Stderr:   []byte("No active zellij sessions found."),
+		ExitCode: 1,
+	}, errors.New("exit code 1")
+}
+
+// zellijCreate simulates zellij creating a session with create, listing it
+// only once create has run.
+func zellijCreate(name string, create func(opts *exec.RunOptions) (*exec.Result, error)) func(context.Context, *exec.RunOptions) (*exec.Result, error) {
+	created := false
+	return func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+		if opts.Args[0] != zellijCmdListSessions {
+			created = true
+			return create(opts)
+		}
+		if !created {
+			return noZellijSessions()
+		}
+		return &exec.Result{Stdout: []byte(name + " [Created 0s ago]\n")}, nil
+	}
+}
+
+func TestNewZellij(t *testing.T) {
+	mockExec := &mocks.ExecutorMock{}
+	z := NewZellij(mockExec)
+
+	require.NotNil(t, z)
+}
+
+func TestZellij_CreateSession(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("creates background session with shell layout", func(t *testing.T) {
+		var layout string
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: zellijCreate("test-session", func(opts *exec.RunOptions) (*exec.Result, error) {
+				assert.Equal(t, "zellij", opts.Name)
+				require.Len(t, opts.Args, 6)
+				assert.Equal(t, []string{"attach", "--create-background", "test-session", "options", "--default-layout"}, opts.Args[:5])
+
+				data, err := os.ReadFile(opts.Args[5])
+				require.NoError(t, err)
+				layout = string(data)
+				return &exec.Result{ExitCode: 0}, nil
+			}),
+			LookPathFunc: func(name string) (string, error) { return "/usr/bin/" + name, nil },
+		}
+
+		z := NewZellij(mockExec)
+		session, err := z.CreateSession(ctx, &CreateSessionOpts{
+			Name: "test-session",
+		})
+
+		require.NoError(t, err)
+		require.NotNil(t, session)
+		assert.Equal(t, "test-session", session.Name)
+		assert.Equal(t, "test-session", session.ID)
+		assert.Equal(t, "layout {\n    pane\n}\n", layout)
+	})
+
+	t.Run("creates session with all options", func(t *testing.T) {
+		var layoutPath, layout string
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: zellijCreate("my-session", func(opts *exec.RunOptions) (*exec.Result, error) {
+				assert.Equal(t, "/workspace", opts.Dir)
+				assert.Equal(t, []string{"FOO=bar"}, opts.Env)
+
+				layoutPath = opts.Args[5]
+				data, err := os.ReadFile(layoutPath)
+				require.NoError(t, err)
+				layout = string(data)
+				return &exec.Result{ExitCode: 0}, nil
+			}),
+			LookPathFunc: func(name string) (string, error) { return "/usr/bin/" + name, nil },
+		}
+
+		z := &zellij{exec: mockExec, goos: "linux"}
+		_, err := z.CreateSession(ctx, &CreateSessionOpts{
+			Name:    "my-session",
+			Command: []string{"docker", "exec", "-it", "abc", "claude", "fix the \"bug\""},
+			Cwd:     "/workspace",
+			Env:     []string{"FOO=bar"},
+			LogPath: "/var/log/my session.log",
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, `layout {
+    pane cwd="/workspace" command="script" close_on_exit=true {
+        args "-q" "-f" "-a" "-c" "'docker' 'exec' '-it' 'abc' 'claude' 'fix the \"bug\"'" "/var/log/my session.log"
+    }
+}
+`, layout)
+
+		// Layout file is cleaned up after creation
+		_, err = os.Stat(layoutPath)
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("keeps the layout file until the session is listed", func(t *testing.T) {
+		var layoutPath string
+		listed := 0
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				if opts.Args[0] != zellijCmdListSessions {
+					layoutPath = opts.Args[5]
+					return &exec.Result{ExitCode: 0}, nil
+				}
+				if layoutPath == "" {
+					return noZellijSessions()
+				}
+
+				// The layout must still be readable while the server starts
+				_, err := os.Stat(layoutPath)
+				require.NoError(t, err)
+				listed++
+				if listed < 3 {
+					return noZellijSessions()
+				}
+				return &exec.Result{Stdout: []byte("test-session [Created 0s ago] (EXITED - attach to resurrect)\n")}, nil
+			},
+		}
+
+		z := NewZellij(mockExec)
+		_, err := z.CreateSession(ctx, &CreateSessionOpts{Name: "test-session"})
+
+		require.NoError(t, err)
+		assert.Equal(t, 3, listed)
+		_, err = os.Stat(layoutPath)
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("uses BSD script flags on darwin", func(t *testing.T) {
+		var layout string
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: zellijCreate("my-session", func(opts *exec.RunOptions) (*exec.Result, error) {
+				data, err := os.ReadFile(opts.Args[5])
+				require.NoError(t, err)
+				layout = string(data)
+				return &exec.Result{ExitCode: 0}, nil
+			}),
+			LookPathFunc: func(name string) (string, error) { return "/usr/bin/" + name, nil },
+		}
+
+		z := &zellij{exec: mockExec, goos: "darwin"}
+		_, err := z.CreateSession(ctx, &CreateSessionOpts{
+			Name:    "my-session",
+			Command: []string{"bash"},
+			LogPath: "/tmp/session.log",
+		})
+
+		require.NoError(t, err)
+		assert.Contains(t, layout, `args "-q" "-a" "-F" "/tmp/session.log" "bash"`)
+	})
+
+	t.Run("pipes script output through the log command", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			LookPathFunc: func(name string) (string, error) { return "/usr/bin/" + name, nil },
+		}
+
+		z := &zellij{exec: mockExec, goos: "linux"}
+		command, err := z.paneCommand(&CreateSessionOpts{
+			Command:    []string{"bash"},
+			LogPath:    "/tmp/session.log",
+			LogCommand: []string{"hjk", "log-writer", "/tmp/session.log"},
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"sh", "-c",
+			`{ 'script' '-q' '-f' '-a' '-c' ''\''bash'\''' '/dev/fd/3' 3>&1 >&4 | 'hjk' 'log-writer' '/tmp/session.log'; } 4>&1`,
+		}, command)
+	})
+
+	t.Run("fails when script is missing and a log path is set", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				return &exec.Result{ExitCode: 0}, nil
+			},
+			LookPathFunc: func(name string) (string, error) {
+				return "", errors.New("executable file not found in $PATH")
+			},
+		}
+
+		z := &zellij{exec: mockExec, goos: "linux"}
+		_, err := z.CreateSession(ctx, &CreateSessionOpts{
+			Name:    "my-session",
+			Command: []string{"bash"},
+			LogPath: "/tmp/session.log",
+		})
+
+		require.ErrorIs(t, err, ErrCreateFailed)
+		assert.Contains(t, err.Error(), "requires script(1)")
+		for _, call := range mockExec.RunCalls() {
+			assert.NotEqual(t, "attach", call.Opts.Args[0])
+		}
+	})
+
+	t.Run("returns ErrSessionExists when session exists", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				if opts.Args[0] == zellijCmdListSessions {
+					return &exec.Result{
+						Stdout:   []byte("existing-session [Created 5m ago]\n"),
+						ExitCode: 0,
+					}, nil
+				}
+				return &exec.Result{ExitCode: 0}, nil
+			},
+		}
+
+		z := NewZellij(mockExec)
+		_, err := z.CreateSession(ctx, &CreateSessionOpts{
+			Name: "existing-session",
+		})
+
+		require.ErrorIs(t, err, ErrSessionExists)
+	})
+
+	t.Run("returns ErrCreateFailed when name is empty", func(t *testing.T) {
+		z := NewZellij(&mocks.ExecutorMock{})
+		_, err := z.CreateSession(ctx, &CreateSessionOpts{})
+
+		require.ErrorIs(t, err, ErrCreateFailed)
+	})
+
+	t.Run("returns ErrCreateFailed when opts is nil", func(t *testing.T) {
+		z := NewZellij(&mocks.ExecutorMock{})
+		_, err := z.CreateSession(ctx, nil)
+
+		require.ErrorIs(t, err, ErrCreateFailed)
+	})
+
+	t.Run("returns ErrCreateFailed on command error", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				if opts.Args[0] == zellijCmdListSessions {
+					return noZellijSessions()
+				}
+				return &exec.Result{
+					Stderr:   []byte("boom"),
+					ExitCode: 1,
+				}, errors.New("exit code 1")
+			},
+		}
+
+		z := NewZellij(mockExec)
+		_, err := z.CreateSession(ctx, &CreateSessionOpts{
+			Name: "test-session",
+		})
+
+		require.ErrorIs(t, err, ErrCreateFailed)
+	})
+}
+
+func TestZellij_ListSessions(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("returns empty list when no sessions", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				assert.Equal(t, "zellij", opts.Name)
+				assert.Equal(t, []string{"list-sessions", "--no-formatting"}, opts.Args)
+				return noZellijSessions()
+			},
+		}
+
+		z := NewZellij(mockExec)
+		sessions, err := z.ListSessions(ctx)
+
+		require.NoError(t, err)
+		assert.Empty(t, sessions)
+	})
+
+	t.Run("parses sessions and skips exited ones", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				return &exec.Result{
+					Stdout: []byte("hjk-abc-one [Created 2m ago]\n" +
+						"hjk-abc-two [Created 1h ago] (EXITED - attach to resurrect)\n" +
+						"hjk-def-three [Created 3s ago] (current)\n"),
+					ExitCode: 0,
+				}, nil
+			},
+		}
+
+		z := NewZellij(mockExec)
+		sessions, err := z.ListSessions(ctx)
+
+		require.NoError(t, err)
+		require.Len(t, sessions, 2)
+		assert.Equal(t, "hjk-abc-one", sessions[0].Name)
+		assert.Equal(t, "hjk-def-three", sessions[1].Name)
+	})
+
+	t.Run("returns error on unexpected command failure", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				return &exec.Result{
+					Stderr:   []byte("permission denied"),
+					ExitCode: 1,
+				}, errors.New("exit code 1")
+			},
+		}
+
+		z := NewZellij(mockExec)
+		_, err := z.ListSessions(ctx)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "list sessions")
+	})
+}
+
+func TestZellij_KillSession(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("kills session successfully", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				assert.Equal(t, "zellij", opts.Name)
+				assert.Equal(t, []string{"delete-session", "--force", "my-session"}, opts.Args)
+				return &exec.Result{ExitCode: 0}, nil
+			},
+		}
+
+		z := NewZellij(mockExec)
+		err := z.KillSession(ctx, "my-session")
+
+		require.NoError(t, err)
+	})
+
+	t.Run("returns ErrSessionNotFound when session missing", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				return &exec.Result{
+					Stderr:   []byte("No session named \"missing\" found."),
+					ExitCode: 1,
+				}, errors.New("exit code 1")
+			},
+		}
+
+		z := NewZellij(mockExec)
+		err := z.KillSession(ctx, "missing")
+
+		require.ErrorIs(t, err, ErrSessionNotFound)
+	})
+
+	t.Run("returns generic error for other failures", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				return &exec.Result{
+					Stderr:   []byte("permission denied"),
+					ExitCode: 1,
+				}, errors.New("exit code 1")
+			},
+		}
+
+		z := NewZellij(mockExec)
+		err := z.KillSession(ctx, "my-session")
+
+		require.Error(t, err)
+		assert.NotErrorIs(t, err, ErrSessionNotFound)
+	})
+}
+
+func TestZellij_AttachSession(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("attaches to session with correct args", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				assert.Equal(t, "zellij", opts.Name)
+				assert.Equal(t, []string{"attach", "my-session"}, opts.Args)
+				return &exec.Result{ExitCode: 0}, nil
+			},
+		}
+
+		z := NewZellij(mockExec)
+		// Note: This test won't fully exercise TTY handling since we're not in a terminal
+		err := z.AttachSession(ctx, "my-session")
+
+		require.NoError(t, err)
+	})
+
+	t.Run("returns ErrSessionNotFound when session missing", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				if opts.Stderr != nil {
+					_, _ = opts.Stderr.Write([]byte("Session \"missing\" not found"))
+				}
+				return &exec.Result{ExitCode: 1}, errors.New("exit code 1")
+			},
+		}
+
+		z := NewZellij(mockExec)
+		err := z.AttachSession(ctx, "missing")
+
+		assert.ErrorIs(t, err, ErrSessionNotFound)
+	})
+
+	t.Run("returns ErrAttachFailed on command error", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				if opts.Stderr != nil {
+					_, _ = opts.Stderr.Write([]byte("attach failed"))
+				}
+				return &exec.Result{ExitCode: 1}, errors.New("exit code 1")
+			},
+		}
+
+		z := NewZellij(mockExec)
+		err := z.AttachSession(ctx, "my-session")
+
+		assert.ErrorIs(t, err, ErrAttachFailed)
+	})
+}
+
+func TestIsZellijNotFound(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   bool
+	}{
+		{"session not found", "Session 'missing' not found.", true},
+		{"double quoted session not found", `Session "missing" not found`, true},
+		{"no session named", `No session named "missing" found.`, true},
+		{"no sessions", "No active zellij sessions found.", true},
+		{"no active session", "There is no active session!", true},
+		{"command not found", "sh: 1: claude: command not found", false},
+		{"file not found", "Error: file not found: /tmp/layout.kdl", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isZellijNotFound(tt.output))
+		})
+	}
+}
+
+func TestKdlQuote(t *testing.T) {
+	assert.Equal(t, `"plain"`, kdlQuote("plain"))
+	assert.Equal(t, `"a \"b\" \\c\n"`, kdlQuote("a \"b\" \\c\n"))
+	assert.Equal(t, `"\u{1b}"`, kdlQuote("\x1b"))
+}
+
+func TestZellij_SendKeys(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("sends nothing for nil options", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{}
+
+		z := NewZellij(mockExec)
+		err := z.SendKeys(ctx, "my-session", nil)
+
+		require.NoError(t, err)
+		assert.Empty(t, mockExec.RunCalls())
+	})
+
+	t.Run("writes chars then carriage return", func(t *testing.T) {
+		var calls [][]string
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				assert.Equal(t, "zellij", opts.Name)
+				calls = append(calls, opts.Args)
+				return &exec.Result{ExitCode: 0}, nil
+			},
+		}
+
+		z := NewZellij(mockExec)
+		err := z.SendKeys(ctx, "my-session", &SendKeysOpts{Text: "run the tests", Enter: true})
+
+		require.NoError(t, err)
+		assert.Equal(t, [][]string{
+			{"--session", "my-session", "action", "write-chars", "--", "run the tests"},
+			{"--session", "my-session", "action", "write", "13"},
+		}, calls)
+	})
+
+	t.Run("returns ErrSessionNotFound when session missing", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				return &exec.Result{
+					Stderr:   []byte("Session 'missing' not found."),
+					ExitCode: 1,
+				}, errors.New("exit code 1")
+			},
+		}
+
+		z := NewZellij(mockExec)
+		err := z.SendKeys(ctx, "missing", &SendKeysOpts{Enter: true})
+
+		require.ErrorIs(t, err, ErrSessionNotFound)
+	})
+}
+
+func TestZellij_CapturePane(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("dumps screen to a file and reads it", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				require.Len(t, opts.Args, 6)
+				assert.Equal(t, []string{"--session", "my-session", "action", "dump-screen", "--full"}, opts.Args[:5])
+				require.NoError(t, os.WriteFile(opts.Args[5], []byte("$ claude\nThinking...\n\n\n"), 0o600))
+				return &exec.Result{ExitCode: 0}, nil
+			},
+		}
+
+		z := NewZellij(mockExec)
+		screen, err := z.CapturePane(ctx, "my-session", &CaptureOpts{History: true})
+
+		require.NoError(t, err)
+		assert.Equal(t, "$ claude\nThinking...\n", screen)
+
+		_, statErr := os.Stat(mockExec.RunCalls()[0].Opts.Args[5])
+		assert.True(t, os.IsNotExist(statErr), "capture file should be removed")
+	})
+
+	t.Run("captures the visible screen for nil options", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				require.Len(t, opts.Args, 5)
+				assert.Equal(t, []string{"--session", "my-session", "action", "dump-screen"}, opts.Args[:4])
+				require.NoError(t, os.WriteFile(opts.Args[4], []byte("$ claude\n"), 0o600))
+				return &exec.Result{ExitCode: 0}, nil
+			},
+		}
+
+		z := NewZellij(mockExec)
+		screen, err := z.CapturePane(ctx, "my-session", nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, "$ claude\n", screen)
+	})
+
+	t.Run("returns ErrSessionNotFound when session missing", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				return &exec.Result{
+					Stderr:   []byte("Session 'missing' not found."),
+					ExitCode: 1,
+				}, errors.New("exit code 1")
+			},
+		}
+
+		z := NewZellij(mockExec)
+		_, err := z.CapturePane(ctx, "missing", &CaptureOpts{})
+
+		require.ErrorIs(t, err, ErrSessionNotFound)
+	})
+}
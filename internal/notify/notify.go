@@ -0,0 +1,75 @@
+// Package notify delivers notifications about session events to the user.
+package notify
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmgilman/headjack/internal/exec"
+)
+
+// Kind identifies the event a notification describes.
+type Kind string
+
+// Notification kinds.
+const (
+	KindSessionTimeout Kind = "session.timeout"
+	KindBudgetWarning  Kind = "budget.warning"
+	KindBudgetExceeded Kind = "budget.exceeded"
+)
+
+// Notification describes a session event worth telling the user about.
+type Notification struct {
+	Kind       Kind
+	Message    string // Human-readable summary
+	InstanceID string
+	Repo       string
+	Branch     string
+	Session    string // Session name
+	Agent      string // Session type
+}
+
+// Notifier delivers notifications.
+//
+//go:generate go run github.com/matryer/moq@latest -pkg mocks -out mocks/notifier.go . Notifier
+type Notifier interface {
+	// Notify delivers a notification.
+	Notify(ctx context.Context, n *Notification) error
+}
+
+// command implements Notifier by running a user-configured shell command.
+type command struct {
+	exec    exec.Executor
+	command string
+}
+
+// NewCommand creates a Notifier that runs a shell command for each
+// notification. The command receives the notification's fields in
+// HEADJACK_EVENT, HEADJACK_MESSAGE, HEADJACK_INSTANCE, HEADJACK_REPO,
+// HEADJACK_BRANCH, HEADJACK_SESSION, and HEADJACK_AGENT.
+func NewCommand(e exec.Executor, cmd string) Notifier {
+	return &command{exec: e, command: cmd}
+}
+
+func (c *command) Notify(ctx context.Context, n *Notification) error {
+	result, err := c.exec.Run(ctx, &exec.RunOptions{
+		Name: "sh",
+		Args: []string{"-c", c.command},
+		Env: []string{
+			"HEADJACK_EVENT=" + string(n.Kind),
+			"HEADJACK_MESSAGE=" + n.Message,
+			"HEADJACK_INSTANCE=" + n.InstanceID,
+			"HEADJACK_REPO=" + n.Repo,
+			"HEADJACK_BRANCH=" + n.Branch,
+			"HEADJACK_SESSION=" + n.Session,
+			"HEADJACK_AGENT=" + n.Agent,
+		},
+	})
+	if err != nil {
+		if result != nil && len(result.Stderr) > 0 {
+			return fmt.Errorf("run notify command: %w: %s", err, result.Stderr)
+		}
+		return fmt.Errorf("run notify command: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmgilman/headjack/internal/exec"
+	"github.com/jmgilman/headjack/internal/exec/mocks"
+)
+
+func TestCommand_Notify(t *testing.T) {
+	ctx := context.Background()
+	n := &Notification{
+		Kind:       KindSessionTimeout,
+		Message:    "session happy-panda on feat/auth exceeded its 2h0m0s runtime limit",
+		InstanceID: "inst-1",
+		Repo:       "/repo",
+		Branch:     "feat/auth",
+		Session:    "happy-panda",
+		Agent:      "claude",
+	}
+
+	t.Run("runs the command with notification fields in the environment", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				assert.Equal(t, "sh", opts.Name)
+				assert.Equal(t, []string{"-c", `notify-send "$HEADJACK_MESSAGE"`}, opts.Args)
+				assert.Contains(t, opts.Env, "HEADJACK_EVENT=session.timeout")
+				assert.Contains(t, opts.Env, "HEADJACK_MESSAGE="+n.Message)
+				assert.Contains(t, opts.Env, "HEADJACK_BRANCH=feat/auth")
+				assert.Contains(t, opts.Env, "HEADJACK_SESSION=happy-panda")
+				assert.Contains(t, opts.Env, "HEADJACK_AGENT=claude")
+				return &exec.Result{}, nil
+			},
+		}
+
+		err := NewCommand(mockExec, `notify-send "$HEADJACK_MESSAGE"`).Notify(ctx, n)
+
+		require.NoError(t, err)
+		assert.Len(t, mockExec.RunCalls(), 1)
+	})
+
+	t.Run("includes stderr when the command fails", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(context.Context, *exec.RunOptions) (*exec.Result, error) {
+				return &exec.Result{Stderr: []byte("no such command"), ExitCode: 127}, errors.New("exit status 127")
+			},
+		}
+
+		err := NewCommand(mockExec, "missing").Notify(ctx, n)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no such command")
+	})
+}
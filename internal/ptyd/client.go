@@ -0,0 +1,256 @@
+package ptyd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+)
+
+// DetachKey is the prefix key (Ctrl+B) that, followed by 'd', detaches an
+// attached client. Pressing the prefix twice sends it through to the session.
+const DetachKey byte = 0x02
+
+// Client talks to a session daemon over its Unix socket.
+type Client struct {
+	socketPath string
+}
+
+// NewClient creates a Client for the daemon listening on socketPath.
+func NewClient(socketPath string) *Client {
+	return &Client{socketPath: socketPath}
+}
+
+// SocketPath returns the daemon socket path.
+func (c *Client) SocketPath() string {
+	return c.socketPath
+}
+
+// Ping checks whether the daemon is accepting connections.
+// Returns ErrNotRunning if it is not.
+func (c *Client) Ping(ctx context.Context) error {
+	conn, err := c.dial(ctx)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// Create starts a new session.
+// Returns ErrSessionExists if a session with the same name is running.
+func (c *Client) Create(ctx context.Context, opts *CreateOpts) (*SessionInfo, error) {
+	resp, err := c.call(ctx, &request{Op: opCreate, Create: opts})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Session, nil
+}
+
+// List returns all running sessions ordered by creation time.
+func (c *Client) List(ctx context.Context) ([]SessionInfo, error) {
+	resp, err := c.call(ctx, &request{Op: opList})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Sessions, nil
+}
+
+// Kill terminates a session and waits for its process to exit.
+// Returns ErrSessionNotFound if the session is not running.
+func (c *Client) Kill(ctx context.Context, name string) error {
+	_, err := c.call(ctx, &request{Op: opKill, Name: name})
+	return err
+}
+
+// Send writes input to a session's terminal without attaching.
+// Returns ErrSessionNotFound if the session is not running.
+func (c *Client) Send(ctx context.Context, name string, input []byte) error {
+	_, err := c.call(ctx, &request{Op: opSend, Name: name, Input: input})
+	return err
+}
+
+// Capture returns the session's current screen as the daemon's virtual
+// terminal renders it.
+// Returns ErrSessionNotFound if the session is not running.
+func (c *Client) Capture(ctx context.Context, name string, opts *CaptureOpts) (string, error) {
+	resp, err := c.call(ctx, &request{Op: opCapture, Name: name, Capture: opts})
+	if err != nil {
+		return "", err
+	}
+	return resp.Screen, nil
+}
+
+// AttachOpts configures an attach.
+type AttachOpts struct {
+	Stdin  io.Reader   // Input forwarded to the session
+	Stdout io.Writer   // Receives scrollback, then live output
+	Size   *Size       // Initial terminal size (optional)
+	Resize <-chan Size // Terminal size changes (optional)
+}
+
+// Attach connects to a session, replaying its scrollback before streaming
+// live output. It blocks until the session exits, the input is exhausted, or
+// the user detaches with DetachKey followed by 'd'.
+// Returns ErrSessionNotFound if the session is not running.
+func (c *Client) Attach(ctx context.Context, name string, opts *AttachOpts) error {
+	conn, err := c.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, reader, err := roundTrip(conn, &request{Op: opAttach, Name: name, Size: opts.Size})
+	if err != nil {
+		return err
+	}
+
+	// Serialize frame writes from the input and resize goroutines
+	var writeMu sync.Mutex
+	send := func(typ byte, payload []byte) error {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		return writeFrame(conn, typ, payload)
+	}
+
+	done := make(chan struct{})
+	var once sync.Once
+	finish := func() { once.Do(func() { close(done) }) }
+
+	// Output: copy until the daemon closes the connection
+	go func() {
+		_, _ = io.Copy(opts.Stdout, reader)
+		finish()
+	}()
+
+	// Input: forward keystrokes, watching for the detach sequence
+	go func() {
+		forwardInput(opts.Stdin, func(p []byte) error { return send(frameData, p) })
+		finish()
+	}()
+
+	if opts.Resize != nil {
+		go func() {
+			for {
+				select {
+				case size, ok := <-opts.Resize:
+					if !ok {
+						return
+					}
+					if err := send(frameResize, encodeSize(size)); err != nil {
+						return
+					}
+				case <-done:
+					return
+				}
+			}
+		}()
+	}
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+	return nil
+}
+
+// forwardInput sends input to the session until the reader ends, a send
+// fails, or the detach sequence is typed.
+func forwardInput(r io.Reader, send func([]byte) error) {
+	buf := make([]byte, 4096)
+	prefixed := false
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			out := make([]byte, 0, n+1)
+			for _, b := range buf[:n] {
+				switch {
+				case prefixed && b == 'd':
+					if len(out) > 0 {
+						_ = send(out)
+					}
+					return
+				case prefixed:
+					// Prefix followed by anything else is passed through;
+					// a doubled prefix sends a single literal prefix.
+					prefixed = false
+					out = append(out, DetachKey)
+					if b != DetachKey {
+						out = append(out, b)
+					}
+				case b == DetachKey:
+					prefixed = true
+				default:
+					out = append(out, b)
+				}
+			}
+			if len(out) > 0 {
+				if sendErr := send(out); sendErr != nil {
+					return
+				}
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// call performs a single request/response exchange.
+func (c *Client) call(ctx context.Context, req *request) (*response, error) {
+	conn, err := c.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	resp, _, err := roundTrip(conn, req)
+	return resp, err
+}
+
+// roundTrip sends a request and reads its response. The returned reader is
+// positioned at any data that follows the response line.
+func roundTrip(conn net.Conn, req *request) (*response, *bufio.Reader, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("marshal request: %w", err)
+	}
+	if _, err := conn.Write(append(data, '\n')); err != nil {
+		return nil, nil, fmt.Errorf("send request: %w", err)
+	}
+
+	reader := bufio.NewReader(conn)
+	line, err := reader.ReadBytes('\n')
+	if err != nil {
+		return nil, nil, fmt.Errorf("read response: %w", err)
+	}
+
+	var resp response
+	if err := json.Unmarshal(line, &resp); err != nil {
+		return nil, nil, fmt.Errorf("parse response: %w", err)
+	}
+
+	switch resp.Code {
+	case codeNotFound:
+		return nil, nil, ErrSessionNotFound
+	case codeExists:
+		return nil, nil, ErrSessionExists
+	}
+	if resp.Error != "" {
+		return nil, nil, errors.New(resp.Error)
+	}
+
+	return &resp, reader, nil
+}
+
+func (c *Client) dial(ctx context.Context) (net.Conn, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "unix", c.socketPath)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotRunning, err)
+	}
+	return conn, nil
+}
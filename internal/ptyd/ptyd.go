@@ -0,0 +1,148 @@
+// Package ptyd implements a headless PTY session daemon.
+//
+// The daemon owns a pseudo-terminal for each session, writes session output
+// directly to the session log, and keeps a scrollback buffer that is replayed
+// to clients when they attach. Clients talk to the daemon over a Unix socket:
+// each connection carries one JSON request line and one JSON response line.
+// Attach connections then switch to streaming, with raw PTY output flowing
+// from the daemon and framed input and resize messages flowing from the client.
+package ptyd
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Sentinel errors for daemon operations.
+var (
+	ErrSessionNotFound = errors.New("session not found")
+	ErrSessionExists   = errors.New("session already exists")
+	ErrNotRunning      = errors.New("session daemon not running")
+	ErrAlreadyRunning  = errors.New("session daemon already running")
+)
+
+// Request operations.
+const (
+	opCreate  = "create"
+	opList    = "list"
+	opKill    = "kill"
+	opAttach  = "attach"
+	opSend    = "send"
+	opCapture = "capture"
+)
+
+// Response error codes mapped to sentinel errors by the client.
+const (
+	codeNotFound = "not_found"
+	codeExists   = "exists"
+)
+
+// Frame types sent from client to daemon on attach connections.
+const (
+	frameData   byte = 0
+	frameResize byte = 1
+)
+
+// maxFrameSize bounds a single input frame.
+const maxFrameSize = 64 * 1024
+
+// SessionInfo describes a running session.
+type SessionInfo struct {
+	Name      string    `json:"name"`
+	PID       int       `json:"pid"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CreateOpts configures session creation.
+type CreateOpts struct {
+	Name    string   `json:"name"`
+	Command []string `json:"command,omitempty"`  // Defaults to $SHELL, then /bin/sh
+	Cwd     string   `json:"cwd,omitempty"`      // Working directory (optional)
+	Env     []string `json:"env,omitempty"`      // Added to the daemon's environment
+	LogPath string   `json:"log_path,omitempty"` // Session output is appended here (optional)
+
+	// LogCommand receives session output on stdin in place of appending to
+	// LogPath directly (optional, ignored when LogPath is empty).
+	LogCommand []string `json:"log_command,omitempty"`
+}
+
+// CaptureOpts configures a screen capture.
+type CaptureOpts struct {
+	History bool `json:"history,omitempty"` // Include scrollback above the visible screen
+	Escapes bool `json:"escapes,omitempty"` // Preserve colors and attributes as ANSI escape sequences
+}
+
+// Size is a terminal size in character cells.
+type Size struct {
+	Rows uint16 `json:"rows"`
+	Cols uint16 `json:"cols"`
+}
+
+// request is the first line sent on every connection.
+type request struct {
+	Op      string       `json:"op"`
+	Name    string       `json:"name,omitempty"`
+	Create  *CreateOpts  `json:"create,omitempty"`
+	Size    *Size        `json:"size,omitempty"`
+	Input   []byte       `json:"input,omitempty"`
+	Capture *CaptureOpts `json:"capture,omitempty"`
+}
+
+// response is the first line returned on every connection.
+type response struct {
+	Error    string        `json:"error,omitempty"`
+	Code     string        `json:"code,omitempty"`
+	Session  *SessionInfo  `json:"session,omitempty"`
+	Sessions []SessionInfo `json:"sessions,omitempty"`
+	Screen   string        `json:"screen,omitempty"`
+}
+
+// writeFrame writes a typed, length-prefixed frame.
+func writeFrame(w io.Writer, typ byte, payload []byte) error {
+	header := make([]byte, 5)
+	header[0] = typ
+	binary.BigEndian.PutUint32(header[1:], uint32(len(payload))) //nolint:gosec // G115: payload is bounded by maxFrameSize
+	if _, err := w.Write(append(header, payload...)); err != nil {
+		return fmt.Errorf("write frame: %w", err)
+	}
+	return nil
+}
+
+// readFrame reads a frame written by writeFrame.
+func readFrame(r io.Reader) (byte, []byte, error) {
+	header := make([]byte, 5)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return 0, nil, err
+	}
+	n := binary.BigEndian.Uint32(header[1:])
+	if n > maxFrameSize {
+		return 0, nil, fmt.Errorf("frame too large: %d bytes", n)
+	}
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, err
+	}
+	return header[0], payload, nil
+}
+
+// encodeSize encodes a Size as a resize frame payload.
+func encodeSize(s Size) []byte {
+	payload := make([]byte, 4)
+	binary.BigEndian.PutUint16(payload[0:], s.Rows)
+	binary.BigEndian.PutUint16(payload[2:], s.Cols)
+	return payload
+}
+
+// decodeSize decodes a resize frame payload.
+func decodeSize(payload []byte) (Size, error) {
+	if len(payload) != 4 {
+		return Size{}, fmt.Errorf("invalid resize payload: %d bytes", len(payload))
+	}
+	return Size{
+		Rows: binary.BigEndian.Uint16(payload[0:]),
+		Cols: binary.BigEndian.Uint16(payload[2:]),
+	}, nil
+}
@@ -0,0 +1,39 @@
+package ptyd
+
+// scrollback retains the most recent output of a session for replay on attach.
+// It is not safe for concurrent use; the owning session serializes access.
+type scrollback struct {
+	buf []byte
+	max int
+}
+
+// newScrollback creates a scrollback holding at most maxBytes.
+func newScrollback(maxBytes int) *scrollback {
+	return &scrollback{max: maxBytes}
+}
+
+// Write appends p, discarding the oldest bytes beyond the limit.
+func (s *scrollback) Write(p []byte) {
+	if len(p) >= s.max {
+		s.buf = append(s.buf[:0], p[len(p)-s.max:]...)
+		return
+	}
+
+	// Compact before growing past twice the limit to bound memory use
+	if len(s.buf)+len(p) > 2*s.max {
+		keep := s.max - len(p)
+		s.buf = append(s.buf[:0], s.buf[len(s.buf)-keep:]...)
+	}
+	s.buf = append(s.buf, p...)
+}
+
+// Bytes returns a copy of the retained output.
+func (s *scrollback) Bytes() []byte {
+	start := 0
+	if len(s.buf) > s.max {
+		start = len(s.buf) - s.max
+	}
+	out := make([]byte, len(s.buf)-start)
+	copy(out, s.buf[start:])
+	return out
+}
@@ -0,0 +1,559 @@
+package ptyd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/creack/pty"
+
+	"github.com/jmgilman/headjack/internal/logging"
+	"github.com/jmgilman/headjack/internal/vt"
+)
+
+const (
+	// scrollbackSize is the amount of output replayed to attaching clients.
+	scrollbackSize = 256 * 1024
+
+	// clientQueueSize is the number of output chunks buffered per client.
+	// Clients that fall further behind are disconnected rather than stalling the session.
+	clientQueueSize = 1024
+
+	// killGracePeriod is how long a killed session has to exit after SIGHUP.
+	killGracePeriod = 5 * time.Second
+
+	// screenHistory is the number of scrolled-off lines kept for captures.
+	screenHistory = 5000
+
+	// defaultRows and defaultCols size new PTYs until a client attaches.
+	defaultRows = 24
+	defaultCols = 80
+)
+
+// Server is the session daemon. It listens on a Unix socket and owns a PTY per session.
+type Server struct {
+	socketPath string
+
+	mu       sync.Mutex
+	sessions map[string]*session
+}
+
+// NewServer creates a Server listening on the given socket path.
+func NewServer(socketPath string) *Server {
+	return &Server{
+		socketPath: socketPath,
+		sessions:   make(map[string]*session),
+	}
+}
+
+// Serve accepts connections until ctx is cancelled, then kills all sessions.
+// Returns ErrAlreadyRunning if another daemon owns the socket.
+func (s *Server) Serve(ctx context.Context) error {
+	if err := os.MkdirAll(filepath.Dir(s.socketPath), 0o700); err != nil {
+		return fmt.Errorf("create socket directory: %w", err)
+	}
+
+	// Hold an exclusive lock for the daemon's lifetime so only one daemon
+	// serves a socket, even when several clients race to start it.
+	lockFile, err := os.OpenFile(s.socketPath+".lock", os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return fmt.Errorf("open lock file: %w", err)
+	}
+	defer lockFile.Close()
+	if err := syscall.Flock(int(lockFile.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		return ErrAlreadyRunning
+	}
+
+	// A socket left behind by a crashed daemon is stale once we hold the lock
+	if err := os.Remove(s.socketPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("remove stale socket: %w", err)
+	}
+
+	listener, err := net.Listen("unix", s.socketPath)
+	if err != nil {
+		return fmt.Errorf("listen: %w", err)
+	}
+	defer os.Remove(s.socketPath)
+
+	if err := os.Chmod(s.socketPath, 0o600); err != nil {
+		_ = listener.Close()
+		return fmt.Errorf("set socket permissions: %w", err)
+	}
+
+	go func() {
+		<-ctx.Done()
+		_ = listener.Close()
+	}()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				s.shutdown()
+				return nil
+			}
+			return fmt.Errorf("accept: %w", err)
+		}
+		go s.handle(conn)
+	}
+}
+
+// handle serves a single client connection.
+func (s *Server) handle(conn net.Conn) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	line, err := reader.ReadBytes('\n')
+	if err != nil {
+		return
+	}
+
+	var req request
+	if err := json.Unmarshal(line, &req); err != nil {
+		writeResponse(conn, &response{Error: fmt.Sprintf("invalid request: %v", err)})
+		return
+	}
+
+	switch req.Op {
+	case opCreate:
+		writeResponse(conn, s.create(req.Create))
+	case opList:
+		writeResponse(conn, &response{Sessions: s.list()})
+	case opKill:
+		writeResponse(conn, s.kill(req.Name))
+	case opSend:
+		writeResponse(conn, s.send(req.Name, req.Input))
+	case opCapture:
+		writeResponse(conn, s.capture(req.Name, req.Capture))
+	case opAttach:
+		s.attach(conn, reader, &req)
+	default:
+		writeResponse(conn, &response{Error: fmt.Sprintf("unknown operation %q", req.Op)})
+	}
+}
+
+func (s *Server) create(opts *CreateOpts) *response {
+	if opts == nil || opts.Name == "" {
+		return &response{Error: "session name is required"}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.sessions[opts.Name]; ok {
+		return errorResponse(ErrSessionExists)
+	}
+
+	sess, err := startSession(opts)
+	if err != nil {
+		return &response{Error: err.Error()}
+	}
+	s.sessions[opts.Name] = sess
+
+	go func() {
+		sess.run()
+		s.mu.Lock()
+		delete(s.sessions, opts.Name)
+		s.mu.Unlock()
+	}()
+
+	info := sess.info()
+	return &response{Session: &info}
+}
+
+func (s *Server) list() []SessionInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	infos := make([]SessionInfo, 0, len(s.sessions))
+	for _, sess := range s.sessions {
+		infos = append(infos, sess.info())
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].CreatedAt.Before(infos[j].CreatedAt)
+	})
+	return infos
+}
+
+func (s *Server) kill(name string) *response {
+	sess := s.lookup(name)
+	if sess == nil {
+		return errorResponse(ErrSessionNotFound)
+	}
+
+	sess.kill()
+	return &response{}
+}
+
+func (s *Server) send(name string, input []byte) *response {
+	sess := s.lookup(name)
+	if sess == nil {
+		return errorResponse(ErrSessionNotFound)
+	}
+
+	if _, err := sess.ptmx.Write(input); err != nil {
+		return &response{Error: fmt.Sprintf("write input: %v", err)}
+	}
+	return &response{}
+}
+
+func (s *Server) capture(name string, opts *CaptureOpts) *response {
+	sess := s.lookup(name)
+	if sess == nil {
+		return errorResponse(ErrSessionNotFound)
+	}
+	if opts == nil {
+		opts = &CaptureOpts{}
+	}
+
+	return &response{Screen: sess.capture(opts)}
+}
+
+func (s *Server) attach(conn net.Conn, reader *bufio.Reader, req *request) {
+	sess := s.lookup(req.Name)
+	if sess == nil {
+		writeResponse(conn, errorResponse(ErrSessionNotFound))
+		return
+	}
+
+	if req.Size != nil {
+		sess.resize(*req.Size)
+	}
+
+	c := sess.addClient(conn)
+	if c == nil {
+		// Session exited between lookup and attach
+		writeResponse(conn, errorResponse(ErrSessionNotFound))
+		return
+	}
+	defer sess.removeClient(c)
+
+	// Input loop: forward client frames to the PTY until the client goes away
+	for {
+		typ, payload, err := readFrame(reader)
+		if err != nil {
+			return
+		}
+		switch typ {
+		case frameData:
+			if _, err := sess.ptmx.Write(payload); err != nil {
+				return
+			}
+		case frameResize:
+			if size, err := decodeSize(payload); err == nil {
+				sess.resize(size)
+			}
+		}
+	}
+}
+
+func (s *Server) lookup(name string) *session {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sessions[name]
+}
+
+// shutdown kills all sessions and waits for them to exit.
+func (s *Server) shutdown() {
+	s.mu.Lock()
+	sessions := make([]*session, 0, len(s.sessions))
+	for _, sess := range s.sessions {
+		sessions = append(sessions, sess)
+	}
+	s.mu.Unlock()
+
+	for _, sess := range sessions {
+		sess.kill()
+	}
+}
+
+// session is a process running on a PTY owned by the daemon.
+type session struct {
+	name      string
+	cmd       *exec.Cmd
+	ptmx      *os.File
+	log       io.WriteCloser
+	createdAt time.Time
+	done      chan struct{}
+
+	mu      sync.Mutex
+	history *scrollback
+	screen  *vt.Screen
+	clients map[*client]struct{}
+	exited  bool
+}
+
+// client is an attached connection receiving session output.
+type client struct {
+	conn  net.Conn
+	queue chan []byte
+}
+
+// startSession starts the session command on a new PTY.
+func startSession(opts *CreateOpts) (*session, error) {
+	command := opts.Command
+	if len(command) == 0 {
+		shell := os.Getenv("SHELL")
+		if shell == "" {
+			shell = "/bin/sh"
+		}
+		command = []string{shell}
+	}
+
+	//nolint:gosec // G204: running the caller's command is the purpose of the daemon
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Dir = opts.Cwd
+	cmd.Env = append(os.Environ(), opts.Env...)
+
+	var logWriter io.WriteCloser
+	switch {
+	case opts.LogPath != "" && len(opts.LogCommand) > 0:
+		w, err := startLogCommand(opts.LogCommand)
+		if err != nil {
+			return nil, err
+		}
+		logWriter = w
+	case opts.LogPath != "":
+		w, err := logging.LogOnlyWriterAppend(opts.LogPath)
+		if err != nil {
+			return nil, err
+		}
+		logWriter = w
+	}
+
+	ptmx, err := pty.StartWithSize(cmd, &pty.Winsize{Rows: defaultRows, Cols: defaultCols})
+	if err != nil {
+		if logWriter != nil {
+			_ = logWriter.Close()
+		}
+		return nil, fmt.Errorf("start session command: %w", err)
+	}
+
+	return &session{
+		name:      opts.Name,
+		cmd:       cmd,
+		ptmx:      ptmx,
+		log:       logWriter,
+		createdAt: time.Now(),
+		done:      make(chan struct{}),
+		history:   newScrollback(scrollbackSize),
+		screen:    vt.New(defaultRows, defaultCols, screenHistory),
+		clients:   make(map[*client]struct{}),
+	}, nil
+}
+
+// logCommand is a running log command fed session output on stdin.
+type logCommand struct {
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+}
+
+// startLogCommand starts command with a pipe to its stdin.
+func startLogCommand(command []string) (*logCommand, error) {
+	//nolint:gosec // G204: the log command comes from the daemon's caller
+	cmd := exec.Command(command[0], command[1:]...)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("create log command pipe: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("start log command: %w", err)
+	}
+	return &logCommand{cmd: cmd, stdin: stdin}, nil
+}
+
+func (l *logCommand) Write(p []byte) (int, error) {
+	return l.stdin.Write(p)
+}
+
+// Close closes the command's stdin and waits for it to finish writing.
+func (l *logCommand) Close() error {
+	_ = l.stdin.Close()
+	if err := l.cmd.Wait(); err != nil {
+		return fmt.Errorf("log command: %w", err)
+	}
+	return nil
+}
+
+// run copies PTY output to the log, scrollback, and attached clients until
+// the session's process exits.
+func (s *session) run() {
+	defer close(s.done)
+
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := s.ptmx.Read(buf)
+		if n > 0 {
+			s.broadcast(buf[:n])
+		}
+		if err != nil {
+			// EIO signals that the process closed the terminal
+			break
+		}
+	}
+
+	_ = s.cmd.Wait()
+	_ = s.ptmx.Close()
+	if s.log != nil {
+		_ = s.log.Close()
+	}
+
+	s.mu.Lock()
+	s.exited = true
+	for c := range s.clients {
+		close(c.queue)
+		delete(s.clients, c)
+	}
+	s.mu.Unlock()
+}
+
+func (s *session) broadcast(p []byte) {
+	chunk := make([]byte, len(p))
+	copy(chunk, p)
+
+	if s.log != nil {
+		// Log write failures must not interrupt the session
+		_, _ = s.log.Write(chunk)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.history.Write(chunk)
+	_, _ = s.screen.Write(chunk)
+	for c := range s.clients {
+		select {
+		case c.queue <- chunk:
+		default:
+			// Client is too slow; drop it so the session keeps running
+			close(c.queue)
+			delete(s.clients, c)
+		}
+	}
+}
+
+// addClient registers a connection, replays scrollback, and starts its writer.
+// Returns nil if the session has already exited.
+func (s *session) addClient(conn net.Conn) *client {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.exited {
+		return nil
+	}
+
+	c := &client{conn: conn, queue: make(chan []byte, clientQueueSize)}
+
+	// Queue the response and replay under the lock so no output is lost or
+	// duplicated; the writer sends them, so a stalled client cannot block
+	// the session
+	c.queue <- responseLine(&response{})
+	if history := s.history.Bytes(); len(history) > 0 {
+		c.queue <- history
+	}
+	s.clients[c] = struct{}{}
+
+	go func() {
+		for chunk := range c.queue {
+			if _, err := conn.Write(chunk); err != nil {
+				break
+			}
+		}
+		// Closing the connection tells the client the session ended or it was dropped
+		_ = conn.Close()
+	}()
+
+	return c
+}
+
+func (s *session) removeClient(c *client) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.clients[c]; ok {
+		close(c.queue)
+		delete(s.clients, c)
+	}
+}
+
+func (s *session) resize(size Size) {
+	if size.Rows == 0 || size.Cols == 0 {
+		return
+	}
+	_ = pty.Setsize(s.ptmx, &pty.Winsize{Rows: size.Rows, Cols: size.Cols})
+
+	s.mu.Lock()
+	s.screen.Resize(int(size.Rows), int(size.Cols))
+	s.mu.Unlock()
+}
+
+// capture renders the session's virtual screen.
+func (s *session) capture(opts *CaptureOpts) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if opts.Escapes {
+		return s.screen.ANSI(opts.History)
+	}
+	return s.screen.Text(opts.History)
+}
+
+// kill hangs up the session's process group, escalating to SIGKILL if it
+// does not exit within the grace period, and waits for it to exit.
+func (s *session) kill() {
+	pgid := s.cmd.Process.Pid
+	_ = syscall.Kill(-pgid, syscall.SIGHUP)
+
+	select {
+	case <-s.done:
+	case <-time.After(killGracePeriod):
+		_ = syscall.Kill(-pgid, syscall.SIGKILL)
+		<-s.done
+	}
+}
+
+func (s *session) info() SessionInfo {
+	return SessionInfo{
+		Name:      s.name,
+		PID:       s.cmd.Process.Pid,
+		CreatedAt: s.createdAt,
+	}
+}
+
+// writeResponse writes a response line, ignoring errors from departed clients.
+func writeResponse(w io.Writer, resp *response) {
+	_, _ = w.Write(responseLine(resp))
+}
+
+// responseLine encodes a response as a line, or returns nil if it cannot be
+// encoded.
+func responseLine(resp *response) []byte {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return nil
+	}
+	return append(data, '\n')
+}
+
+// errorResponse converts a sentinel error to a response with its code.
+func errorResponse(err error) *response {
+	resp := &response{Error: err.Error()}
+	switch {
+	case errors.Is(err, ErrSessionNotFound):
+		resp.Code = codeNotFound
+	case errors.Is(err, ErrSessionExists):
+		resp.Code = codeExists
+	}
+	return resp
+}
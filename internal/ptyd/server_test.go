@@ -0,0 +1,370 @@
+package ptyd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmgilman/headjack/internal/vt"
+)
+
+// startServer runs a daemon on a short temporary socket path and returns a client for it.
+func startServer(t *testing.T) *Client {
+	t.Helper()
+
+	// Unix socket paths are limited to ~100 bytes, so avoid t.TempDir's long names
+	dir, err := os.MkdirTemp("", "ptyd")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	socketPath := filepath.Join(dir, "d.sock")
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() { errCh <- NewServer(socketPath).Serve(ctx) }()
+	t.Cleanup(func() {
+		cancel()
+		assert.NoError(t, <-errCh)
+	})
+
+	client := NewClient(socketPath)
+	require.Eventually(t, func() bool {
+		return client.Ping(context.Background()) == nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	return client
+}
+
+// syncBuffer is a bytes.Buffer safe for concurrent writes and reads.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestServer_Lifecycle(t *testing.T) {
+	ctx := context.Background()
+	client := startServer(t)
+
+	t.Run("creates lists and kills sessions", func(t *testing.T) {
+		info, err := client.Create(ctx, &CreateOpts{Name: "sleeper", Command: []string{"sleep", "60"}})
+		require.NoError(t, err)
+		assert.Equal(t, "sleeper", info.Name)
+		assert.Positive(t, info.PID)
+
+		sessions, err := client.List(ctx)
+		require.NoError(t, err)
+		require.Len(t, sessions, 1)
+		assert.Equal(t, "sleeper", sessions[0].Name)
+
+		_, err = client.Create(ctx, &CreateOpts{Name: "sleeper", Command: []string{"sleep", "60"}})
+		require.ErrorIs(t, err, ErrSessionExists)
+
+		require.NoError(t, client.Kill(ctx, "sleeper"))
+		require.Eventually(t, func() bool {
+			sessions, err := client.List(ctx)
+			return err == nil && len(sessions) == 0
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("returns ErrSessionNotFound for unknown sessions", func(t *testing.T) {
+		require.ErrorIs(t, client.Kill(ctx, "missing"), ErrSessionNotFound)
+
+		err := client.Attach(ctx, "missing", &AttachOpts{Stdin: strings.NewReader(""), Stdout: io.Discard})
+		require.ErrorIs(t, err, ErrSessionNotFound)
+	})
+
+	t.Run("removes sessions whose process exits", func(t *testing.T) {
+		_, err := client.Create(ctx, &CreateOpts{Name: "short", Command: []string{"true"}})
+		require.NoError(t, err)
+
+		require.Eventually(t, func() bool {
+			sessions, err := client.List(ctx)
+			return err == nil && len(sessions) == 0
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("writes output to the session log with env and cwd", func(t *testing.T) {
+		dir := t.TempDir()
+		logPath := filepath.Join(dir, "session.log")
+
+		_, err := client.Create(ctx, &CreateOpts{
+			Name:    "logger",
+			Command: []string{"sh", "-c", "echo \"$GREETING from $(pwd)\""},
+			Cwd:     dir,
+			Env:     []string{"GREETING=hello"},
+			LogPath: logPath,
+		})
+		require.NoError(t, err)
+
+		require.Eventually(t, func() bool {
+			data, err := os.ReadFile(logPath)
+			return err == nil && strings.Contains(string(data), "hello from "+dir)
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("pipes output through the log command", func(t *testing.T) {
+		dir := t.TempDir()
+		logPath := filepath.Join(dir, "session.log")
+
+		_, err := client.Create(ctx, &CreateOpts{
+			Name:       "piped",
+			Command:    []string{"echo", "through the pipe"},
+			LogPath:    logPath,
+			LogCommand: []string{"sh", "-c", "tr a-z A-Z > \"$0\"", logPath},
+		})
+		require.NoError(t, err)
+
+		require.Eventually(t, func() bool {
+			data, err := os.ReadFile(logPath)
+			return err == nil && strings.Contains(string(data), "THROUGH THE PIPE")
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+}
+
+func TestServer_Attach(t *testing.T) {
+	ctx := context.Background()
+	client := startServer(t)
+
+	t.Run("replays scrollback and forwards input until detach", func(t *testing.T) {
+		_, err := client.Create(ctx, &CreateOpts{
+			Name:    "echo",
+			Command: []string{"sh", "-c", "echo ready; while read -r line; do echo \"got:$line\"; done"},
+		})
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = client.Kill(ctx, "echo") })
+
+		// Wait for output produced before attaching
+		time.Sleep(200 * time.Millisecond)
+
+		inR, inW := io.Pipe()
+		out := &syncBuffer{}
+		attachErr := make(chan error, 1)
+		go func() {
+			attachErr <- client.Attach(ctx, "echo", &AttachOpts{
+				Stdin:  inR,
+				Stdout: out,
+				Size:   &Size{Rows: 40, Cols: 120},
+			})
+		}()
+
+		require.Eventually(t, func() bool {
+			return strings.Contains(out.String(), "ready")
+		}, 5*time.Second, 10*time.Millisecond, "scrollback replayed")
+
+		_, err = inW.Write([]byte("ping\n"))
+		require.NoError(t, err)
+		require.Eventually(t, func() bool {
+			return strings.Contains(out.String(), "got:ping")
+		}, 5*time.Second, 10*time.Millisecond, "input forwarded")
+
+		// Ctrl+B d detaches without ending the session
+		_, err = inW.Write([]byte{DetachKey, 'd'})
+		require.NoError(t, err)
+		select {
+		case err := <-attachErr:
+			require.NoError(t, err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("attach did not return after detach")
+		}
+
+		sessions, err := client.List(ctx)
+		require.NoError(t, err)
+		require.Len(t, sessions, 1)
+	})
+
+	t.Run("returns when the session exits", func(t *testing.T) {
+		_, err := client.Create(ctx, &CreateOpts{
+			Name:    "exiter",
+			Command: []string{"sh", "-c", "read -r line; echo bye"},
+		})
+		require.NoError(t, err)
+
+		inR, inW := io.Pipe()
+		defer inW.Close()
+		out := &syncBuffer{}
+		attachErr := make(chan error, 1)
+		go func() {
+			attachErr <- client.Attach(ctx, "exiter", &AttachOpts{Stdin: inR, Stdout: out})
+		}()
+
+		_, err = inW.Write([]byte("x\n"))
+		require.NoError(t, err)
+
+		select {
+		case err := <-attachErr:
+			require.NoError(t, err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("attach did not return after session exit")
+		}
+		assert.Contains(t, out.String(), "bye")
+	})
+}
+
+func TestServer_Send(t *testing.T) {
+	ctx := context.Background()
+	client := startServer(t)
+	logPath := filepath.Join(t.TempDir(), "session.log")
+
+	_, err := client.Create(ctx, &CreateOpts{
+		Name:    "reader",
+		Command: []string{"sh", "-c", "read -r line; echo \"got:$line\"; sleep 1"},
+		LogPath: logPath,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Kill(ctx, "reader") })
+
+	require.NoError(t, client.Send(ctx, "reader", []byte("hello\r")))
+	require.Eventually(t, func() bool {
+		data, err := os.ReadFile(logPath)
+		return err == nil && strings.Contains(string(data), "got:hello")
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.ErrorIs(t, client.Send(ctx, "missing", []byte("x")), ErrSessionNotFound)
+}
+
+func TestServer_Capture(t *testing.T) {
+	ctx := context.Background()
+	client := startServer(t)
+
+	// Print enough lines to scroll, then redraw the last line in color
+	_, err := client.Create(ctx, &CreateOpts{
+		Name:    "screen",
+		Command: []string{"sh", "-c", "seq 1 30; printf 'working\\r\\033[32mdone\\033[0m\\033[K'; sleep 60"},
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Kill(ctx, "screen") })
+
+	var screen string
+	require.Eventually(t, func() bool {
+		screen, err = client.Capture(ctx, "screen", &CaptureOpts{})
+		return err == nil && strings.Contains(screen, "done")
+	}, 5*time.Second, 10*time.Millisecond)
+
+	assert.NotContains(t, screen, "working")
+	assert.NotContains(t, screen, "\n1\n", "early lines scrolled off the visible screen")
+	assert.True(t, strings.HasSuffix(screen, "30\ndone\n"))
+
+	history, err := client.Capture(ctx, "screen", &CaptureOpts{History: true})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(history, "1\n2\n"))
+
+	colored, err := client.Capture(ctx, "screen", &CaptureOpts{Escapes: true})
+	require.NoError(t, err)
+	assert.Contains(t, colored, "\x1b[0;32mdone\x1b[0m")
+
+	_, err = client.Capture(ctx, "missing", &CaptureOpts{})
+	require.ErrorIs(t, err, ErrSessionNotFound)
+}
+
+func TestServer_AlreadyRunning(t *testing.T) {
+	client := startServer(t)
+
+	err := NewServer(client.SocketPath()).Serve(context.Background())
+	require.ErrorIs(t, err, ErrAlreadyRunning)
+}
+
+func TestClient_NotRunning(t *testing.T) {
+	client := NewClient(filepath.Join(t.TempDir(), "missing.sock"))
+
+	_, err := client.List(context.Background())
+	require.ErrorIs(t, err, ErrNotRunning)
+}
+
+func TestForwardInput(t *testing.T) {
+	tests := []struct {
+		name  string
+		input []byte
+		want  []byte
+	}{
+		{"passes plain input", []byte("hello"), []byte("hello")},
+		{"stops at detach sequence", []byte{'a', DetachKey, 'd', 'b'}, []byte("a")},
+		{"doubled prefix sends literal prefix", []byte{DetachKey, DetachKey, 'x'}, []byte{DetachKey, 'x'}},
+		{"prefix with other key passes both", []byte{DetachKey, 'c'}, []byte{DetachKey, 'c'}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []byte
+			forwardInput(bytes.NewReader(tt.input), func(p []byte) error {
+				got = append(got, p...)
+				return nil
+			})
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSession_AddClient(t *testing.T) {
+	sess := &session{
+		history: newScrollback(64),
+		screen:  vt.New(defaultRows, defaultCols, screenHistory),
+		clients: make(map[*client]struct{}),
+	}
+	sess.history.Write([]byte("earlier output"))
+
+	// A client that never reads must not block the session
+	server, conn := net.Pipe()
+	defer conn.Close()
+	added := make(chan *client, 1)
+	go func() { added <- sess.addClient(server) }()
+
+	var c *client
+	select {
+	case c = <-added:
+		require.NotNil(t, c)
+	case <-time.After(5 * time.Second):
+		t.Fatal("addClient blocked on a client that does not read")
+	}
+	sess.broadcast([]byte(" and more"))
+
+	reader := bufio.NewReader(conn)
+	line, err := reader.ReadString('\n')
+	require.NoError(t, err)
+	assert.JSONEq(t, `{}`, line)
+	buf := make([]byte, len("earlier output and more"))
+	_, err = io.ReadFull(reader, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "earlier output and more", string(buf))
+
+	sess.removeClient(c)
+}
+
+func TestScrollback(t *testing.T) {
+	sb := newScrollback(8)
+
+	sb.Write([]byte("abc"))
+	assert.Equal(t, []byte("abc"), sb.Bytes())
+
+	sb.Write([]byte("defghij"))
+	assert.Equal(t, []byte("cdefghij"), sb.Bytes())
+
+	for range 10 {
+		sb.Write([]byte("xyz"))
+	}
+	assert.Equal(t, []byte("yzxyzxyz"), sb.Bytes())
+
+	sb.Write([]byte("0123456789"))
+	assert.Equal(t, []byte("23456789"), sb.Bytes())
+}
@@ -0,0 +1,263 @@
+package registry
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+)
+
+// Cache file permissions.
+const (
+	cacheDirMode  = 0o755
+	cacheFileMode = 0o644
+)
+
+// CacheConfig configures the on-disk metadata cache.
+type CacheConfig struct {
+	// Dir is the directory holding the cache (required).
+	Dir string
+
+	// TTL is how long the digest a tag resolved to is trusted before the
+	// registry is asked again. Zero means the registry is always asked and
+	// the cache is only used when it cannot be reached. References by digest
+	// never expire, since their metadata cannot change.
+	TTL time.Duration
+
+	// MaxAge is how long metadata is kept after it was last fetched. Older
+	// entries are ignored and pruned. Zero means entries are kept forever.
+	MaxAge time.Duration
+
+	// Now returns the current time (optional, nil = time.Now).
+	Now func() time.Time
+}
+
+// cachingClient wraps a Client with an on-disk cache.
+type cachingClient struct {
+	client Client
+	config CacheConfig
+}
+
+// NewCachingClient wraps a client with an on-disk metadata cache. Metadata is
+// stored by image digest, and each reference records the digest it resolved
+// to. Cached metadata is returned while it is fresh, and while it is younger
+// than MaxAge when the registry cannot be reached, so known images work
+// offline. Errors the registry answers with, such as a missing image or
+// rejected credentials, are always returned.
+func NewCachingClient(client Client, cfg CacheConfig) Client {
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	return &cachingClient{client: client, config: cfg}
+}
+
+// refEntry records the digest a reference resolved to.
+type refEntry struct {
+	Ref       string    `json:"ref"`
+	Digest    string    `json:"digest"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// digestEntry holds the metadata of an image digest.
+type digestEntry struct {
+	Digest       string            `json:"digest"`
+	Labels       map[string]string `json:"labels,omitempty"`
+	Created      time.Time         `json:"created,omitzero"`
+	Architecture string            `json:"architecture,omitempty"`
+	OS           string            `json:"os,omitempty"`
+}
+
+// GetMetadata returns cached metadata for an image reference if it is fresh,
+// else fetches it from the registry and caches it.
+func (c *cachingClient) GetMetadata(ctx context.Context, ref string) (*ImageMetadata, error) {
+	parsedRef, err := name.ParseReference(ref)
+	if err != nil {
+		// Let the client report the invalid reference
+		return c.client.GetMetadata(ctx, ref)
+	}
+	key := parsedRef.Name()
+	now := c.config.Now()
+
+	cached := c.load(key, now)
+	if cached != nil {
+		_, byDigest := parsedRef.(name.Digest)
+		if byDigest || (c.config.TTL > 0 && now.Sub(cached.FetchedAt) < c.config.TTL) {
+			return cached, nil
+		}
+	}
+
+	metadata, err := c.client.GetMetadata(ctx, ref)
+	if err != nil {
+		if cached != nil && isUnreachable(err) {
+			return cached, nil
+		}
+		return nil, err
+	}
+
+	// Caching is best-effort; a read-only or full disk must not fail lookups
+	_ = c.store(key, metadata, now) //nolint:errcheck // see above
+	c.prune(now)
+	return metadata, nil
+}
+
+// isUnreachable reports whether err means the registry could not be reached
+// or was unavailable, as opposed to answering that the image is missing or the
+// credentials are wrong.
+func isUnreachable(err error) bool {
+	if errors.Is(err, ErrUnauthorized) || errors.Is(err, ErrImageNotFound) || errors.Is(err, ErrInvalidRef) {
+		return false
+	}
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var transportErr *transport.Error
+	if errors.As(err, &transportErr) {
+		return transportErr.StatusCode == http.StatusTooManyRequests || transportErr.StatusCode >= http.StatusInternalServerError
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// load returns the cached metadata of a reference, or nil if there is none
+// within MaxAge.
+func (c *cachingClient) load(key string, now time.Time) *ImageMetadata {
+	var ref refEntry
+	if err := readCacheFile(c.refPath(key), &ref); err != nil {
+		return nil
+	}
+	if ref.Ref != key || c.expired(ref.FetchedAt, now) {
+		return nil
+	}
+	digestPath, err := c.digestPath(ref.Digest)
+	if err != nil {
+		return nil
+	}
+	var entry digestEntry
+	if err := readCacheFile(digestPath, &entry); err != nil || entry.Digest != ref.Digest {
+		return nil
+	}
+
+	return &ImageMetadata{
+		Digest:       entry.Digest,
+		Labels:       entry.Labels,
+		Created:      entry.Created,
+		Architecture: entry.Architecture,
+		OS:           entry.OS,
+		Cached:       true,
+		FetchedAt:    ref.FetchedAt,
+	}
+}
+
+// store caches the metadata of a reference.
+func (c *cachingClient) store(key string, metadata *ImageMetadata, now time.Time) error {
+	digestPath, err := c.digestPath(metadata.Digest)
+	if err != nil {
+		return err
+	}
+	err = writeCacheFile(digestPath, &digestEntry{
+		Digest:       metadata.Digest,
+		Labels:       metadata.Labels,
+		Created:      metadata.Created,
+		Architecture: metadata.Architecture,
+		OS:           metadata.OS,
+	})
+	if err != nil {
+		return err
+	}
+	return writeCacheFile(c.refPath(key), &refEntry{Ref: key, Digest: metadata.Digest, FetchedAt: now})
+}
+
+// prune removes cache files not written to within MaxAge.
+func (c *cachingClient) prune(now time.Time) {
+	if c.config.MaxAge <= 0 {
+		return
+	}
+	for _, dir := range []string{"refs", "digests"} {
+		entries, err := os.ReadDir(filepath.Join(c.config.Dir, dir))
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			info, err := e.Info()
+			if err != nil || !c.expired(info.ModTime(), now) {
+				continue
+			}
+			_ = os.Remove(filepath.Join(c.config.Dir, dir, e.Name())) //nolint:errcheck // best-effort cleanup
+		}
+	}
+}
+
+// expired reports whether an entry fetched at t is older than MaxAge.
+func (c *cachingClient) expired(t, now time.Time) bool {
+	return c.config.MaxAge > 0 && now.Sub(t) > c.config.MaxAge
+}
+
+// refPath returns the cache file of a reference.
+func (c *cachingClient) refPath(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.config.Dir, "refs", hex.EncodeToString(sum[:])+".json")
+}
+
+// digestPath returns the cache file of a digest.
+func (c *cachingClient) digestPath(digest string) (string, error) {
+	hash, err := v1.NewHash(digest)
+	if err != nil {
+		return "", fmt.Errorf("cache metadata: %w", err)
+	}
+	return filepath.Join(c.config.Dir, "digests", hash.Algorithm+"-"+hash.Hex+".json"), nil
+}
+
+// readCacheFile decodes a cache file.
+func readCacheFile(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// writeCacheFile atomically replaces a cache file, so concurrent readers
+// never see a partial entry.
+func writeCacheFile(path string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("marshal cache entry: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), cacheDirMode); err != nil {
+		return fmt.Errorf("create cache directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+strings.TrimSuffix(filepath.Base(path), ".json")+"-*.tmp")
+	if err != nil {
+		return fmt.Errorf("write cache entry: %w", err)
+	}
+	tmpPath := tmp.Name()
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmpPath, cacheFileMode)
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, path)
+	}
+	if err != nil {
+		_ = os.Remove(tmpPath) //nolint:errcheck // best-effort cleanup
+		return fmt.Errorf("write cache entry: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingClient counts the lookups passed to a client, and fails them with
+// err if it is set, or with a connection error while offline is set.
+type countingClient struct {
+	client  Client
+	calls   int
+	offline bool
+	err     error
+}
+
+func (c *countingClient) GetMetadata(ctx context.Context, ref string) (*ImageMetadata, error) {
+	c.calls++
+	if c.err != nil {
+		return nil, c.err
+	}
+	if c.offline {
+		return nil, fmt.Errorf("registry error: %w", &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("network is unreachable")})
+	}
+	return c.client.GetMetadata(ctx, ref)
+}
+
+func TestCachingClient_GetMetadata(t *testing.T) {
+	ctx := context.Background()
+
+	server := httptest.NewServer(registry.New())
+	defer server.Close()
+	regHost := strings.TrimPrefix(server.URL, "http://")
+	ref := regHost + "/test/image:latest"
+	img, err := random.Image(1024, 1)
+	require.NoError(t, err)
+	img, err = mutate.Config(img, v1.Config{Labels: map[string]string{"io.headjack.init": "/sbin/init"}})
+	require.NoError(t, err)
+	parsed, err := name.ParseReference(ref)
+	require.NoError(t, err)
+	require.NoError(t, remote.Write(parsed, img))
+
+	now := time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC)
+	setup := func(t *testing.T, ttl, maxAge time.Duration) (*countingClient, Client, string) {
+		t.Helper()
+		dir := t.TempDir()
+		inner := &countingClient{client: NewClient(ClientConfig{Insecure: true})}
+		return inner, NewCachingClient(inner, CacheConfig{Dir: dir, TTL: ttl, MaxAge: maxAge, Now: func() time.Time { return now }}), dir
+	}
+
+	t.Run("serves fresh entries from the cache", func(t *testing.T) {
+		inner, client, _ := setup(t, time.Hour, 0)
+
+		first, err := client.GetMetadata(ctx, ref)
+		require.NoError(t, err)
+		assert.False(t, first.Cached)
+
+		second, err := client.GetMetadata(ctx, ref)
+		require.NoError(t, err)
+		assert.True(t, second.Cached)
+		assert.Equal(t, now, second.FetchedAt)
+		assert.Equal(t, first.Digest, second.Digest)
+		assert.Equal(t, "/sbin/init", second.Labels["io.headjack.init"])
+		assert.Equal(t, 1, inner.calls)
+	})
+
+	t.Run("revalidates expired tags", func(t *testing.T) {
+		inner, client, _ := setup(t, time.Hour, 0)
+
+		_, err := client.GetMetadata(ctx, ref)
+		require.NoError(t, err)
+		now = now.Add(2 * time.Hour)
+		metadata, err := client.GetMetadata(ctx, ref)
+
+		require.NoError(t, err)
+		assert.False(t, metadata.Cached)
+		assert.Equal(t, 2, inner.calls)
+	})
+
+	t.Run("references by digest never expire", func(t *testing.T) {
+		inner, client, _ := setup(t, 0, 0)
+
+		metadata, err := client.GetMetadata(ctx, ref)
+		require.NoError(t, err)
+		digestRef := regHost + "/test/image@" + metadata.Digest
+		_, err = client.GetMetadata(ctx, digestRef)
+		require.NoError(t, err)
+		now = now.Add(24 * time.Hour)
+		cached, err := client.GetMetadata(ctx, digestRef)
+
+		require.NoError(t, err)
+		assert.True(t, cached.Cached)
+		assert.Equal(t, 2, inner.calls)
+	})
+
+	t.Run("falls back to stale entries when the registry is unreachable", func(t *testing.T) {
+		inner, client, _ := setup(t, 0, 30*24*time.Hour)
+
+		_, err := client.GetMetadata(ctx, ref)
+		require.NoError(t, err)
+		inner.offline = true
+		now = now.Add(48 * time.Hour)
+		metadata, err := client.GetMetadata(ctx, ref)
+
+		require.NoError(t, err)
+		assert.True(t, metadata.Cached)
+		assert.Equal(t, now.Add(-48*time.Hour), metadata.FetchedAt)
+		assert.Equal(t, "/sbin/init", metadata.Labels["io.headjack.init"])
+	})
+
+	t.Run("falls back to stale entries when the registry is unavailable", func(t *testing.T) {
+		inner, client, _ := setup(t, 0, 30*24*time.Hour)
+
+		_, err := client.GetMetadata(ctx, ref)
+		require.NoError(t, err)
+		inner.err = fmt.Errorf("registry error: %w", &transport.Error{StatusCode: http.StatusServiceUnavailable})
+		metadata, err := client.GetMetadata(ctx, ref)
+
+		require.NoError(t, err)
+		assert.True(t, metadata.Cached)
+	})
+
+	t.Run("returns registry answers instead of stale entries", func(t *testing.T) {
+		for _, sentinel := range []error{ErrUnauthorized, ErrImageNotFound} {
+			inner, client, _ := setup(t, 0, 30*24*time.Hour)
+
+			_, err := client.GetMetadata(ctx, ref)
+			require.NoError(t, err)
+			inner.err = fmt.Errorf("%w: denied", sentinel)
+			_, err = client.GetMetadata(ctx, ref)
+
+			assert.ErrorIs(t, err, sentinel)
+		}
+	})
+
+	t.Run("ignores entries older than max age", func(t *testing.T) {
+		inner, client, _ := setup(t, 0, time.Hour)
+
+		_, err := client.GetMetadata(ctx, ref)
+		require.NoError(t, err)
+		inner.offline = true
+		now = now.Add(2 * time.Hour)
+		_, err = client.GetMetadata(ctx, ref)
+
+		assert.ErrorContains(t, err, "network is unreachable")
+	})
+
+	t.Run("prunes entries older than max age", func(t *testing.T) {
+		inner, client, dir := setup(t, 0, time.Hour)
+
+		_, err := client.GetMetadata(ctx, ref)
+		require.NoError(t, err)
+		old := now.Add(-2 * time.Hour)
+		stale := filepath.Join(dir, "refs", "stale.json")
+		require.NoError(t, os.WriteFile(stale, []byte("{}"), 0o644))
+		require.NoError(t, os.Chtimes(stale, old, old))
+		_, err = client.GetMetadata(ctx, ref)
+
+		require.NoError(t, err)
+		assert.NoFileExists(t, stale)
+		assert.Equal(t, 2, inner.calls)
+	})
+
+	t.Run("returns errors without a cached entry", func(t *testing.T) {
+		_, client, _ := setup(t, time.Hour, 0)
+
+		_, err := client.GetMetadata(ctx, regHost+"/test/missing:latest")
+
+		assert.ErrorIs(t, err, ErrImageNotFound)
+	})
+
+	t.Run("ignores a corrupt cache", func(t *testing.T) {
+		inner, client, dir := setup(t, time.Hour, 0)
+
+		_, err := client.GetMetadata(ctx, ref)
+		require.NoError(t, err)
+		entries, err := os.ReadDir(filepath.Join(dir, "refs"))
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "refs", entries[0].Name()), []byte("not json"), 0o644))
+		metadata, err := client.GetMetadata(ctx, ref)
+
+		require.NoError(t, err)
+		assert.False(t, metadata.Cached)
+		assert.Equal(t, 2, inner.calls)
+	})
+}
@@ -3,16 +3,23 @@ package registry
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"os"
 	"runtime"
+	"strings"
 
 	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/google/go-containerregistry/pkg/name"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+
+	"github.com/jmgilman/headjack/internal/exec"
+	"github.com/jmgilman/headjack/internal/secret"
 )
 
 // client implements the Client interface using go-containerregistry.
@@ -22,26 +29,57 @@ type client struct {
 
 // NewClient creates a new registry client with the given configuration.
 func NewClient(cfg ClientConfig) Client {
+	if cfg.Exec == nil {
+		cfg.Exec = exec.New()
+	}
 	return &client{config: cfg}
 }
 
-// GetMetadata fetches metadata for an image reference.
+// GetMetadata fetches metadata for an image reference. Mirrors configured for
+// the image's registry are tried first, then the registry itself.
 func (c *client) GetMetadata(ctx context.Context, ref string) (*ImageMetadata, error) {
-	// Build name options for reference parsing
-	var nameOpts []name.Option
-	if c.config.Insecure {
-		nameOpts = append(nameOpts, name.Insecure)
+	parsedRef, err := c.reference(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	var mirrorErrs []error
+	if host := c.host(parsedRef.Context().RegistryStr()); host != nil {
+		for _, mirror := range host.Mirrors {
+			mirrorRef, mirrorErr := c.mirrorReference(parsedRef, mirror)
+			if mirrorErr == nil {
+				var metadata *ImageMetadata
+				if metadata, mirrorErr = c.fetch(ctx, mirrorRef); mirrorErr == nil {
+					return metadata, nil
+				}
+			}
+			mirrorErrs = append(mirrorErrs, fmt.Errorf("mirror %s: %w", mirror, mirrorErr))
+		}
 	}
 
-	// Parse the image reference
-	parsedRef, err := name.ParseReference(ref, nameOpts...)
+	metadata, err := c.fetch(ctx, parsedRef)
 	if err != nil {
-		return nil, fmt.Errorf("%w: %s", ErrInvalidRef, err)
+		return nil, errors.Join(append([]error{err}, mirrorErrs...)...)
+	}
+	return metadata, nil
+}
+
+// fetch fetches metadata for an image reference from its registry.
+func (c *client) fetch(ctx context.Context, ref name.Reference) (*ImageMetadata, error) {
+	host := c.host(ref.Context().RegistryStr())
+
+	transport, err := c.transport(host)
+	if err != nil {
+		return nil, err
+	}
+	keychain, err := c.keychain(host)
+	if err != nil {
+		return nil, err
 	}
 
 	// Build remote options
 	opts := []remote.Option{
-		remote.WithAuthFromKeychain(authn.DefaultKeychain),
+		remote.WithAuthFromKeychain(keychain),
 		remote.WithContext(ctx),
 		// Use current platform to handle multi-arch images
 		remote.WithPlatform(v1.Platform{
@@ -49,23 +87,12 @@ func (c *client) GetMetadata(ctx context.Context, ref string) (*ImageMetadata, e
 			OS:           "linux",
 		}),
 	}
-
-	// Allow insecure (HTTP and skip TLS verify) connections if configured
-	if c.config.Insecure {
-		// Clone http.DefaultTransport to preserve proxy, keep-alive, and timeout settings.
-		// Fall back to a basic transport if the type assertion fails (shouldn't happen in practice).
-		var insecureTransport *http.Transport
-		if defaultTransport, ok := http.DefaultTransport.(*http.Transport); ok {
-			insecureTransport = defaultTransport.Clone()
-		} else {
-			insecureTransport = &http.Transport{}
-		}
-		insecureTransport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true} //nolint:gosec // intentional for insecure mode
-		opts = append(opts, remote.WithTransport(insecureTransport))
+	if transport != nil {
+		opts = append(opts, remote.WithTransport(transport))
 	}
 
 	// Fetch the image
-	img, err := remote.Image(parsedRef, opts...)
+	img, err := remote.Image(ref, opts...)
 	if err != nil {
 		return nil, c.mapError(err)
 	}
@@ -98,6 +125,182 @@ func (c *client) GetMetadata(ctx context.Context, ref string) (*ImageMetadata, e
 	return metadata, nil
 }
 
+// host returns the configuration of a registry, or nil if it has none.
+func (c *client) host(registry string) *HostConfig {
+	for i := range c.config.Hosts {
+		configured, err := name.NewRegistry(c.config.Hosts[i].Host)
+		if err == nil && configured.RegistryStr() == registry {
+			return &c.config.Hosts[i]
+		}
+	}
+	return nil
+}
+
+// reference parses an image reference, using plain HTTP if its registry is
+// insecure.
+func (c *client) reference(ref string) (name.Reference, error) {
+	parsedRef, err := name.ParseReference(ref)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidRef, err)
+	}
+	nameOpts := c.nameOptions(parsedRef.Context().RegistryStr())
+	if len(nameOpts) == 0 {
+		return parsedRef, nil
+	}
+	parsedRef, err = name.ParseReference(ref, nameOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidRef, err)
+	}
+	return parsedRef, nil
+}
+
+// nameOptions returns the options for parsing references on a registry.
+func (c *client) nameOptions(registry string) []name.Option {
+	if host := c.host(registry); c.config.Insecure || (host != nil && host.Insecure) {
+		return []name.Option{name.Insecure}
+	}
+	return nil
+}
+
+// mirrorReference returns the reference of an image on a mirror: the
+// mirror's host and path prefix followed by the image's repository.
+func (c *client) mirrorReference(ref name.Reference, mirror string) (name.Reference, error) {
+	mirrorHost, _, _ := strings.Cut(mirror, "/")
+	nameOpts := c.nameOptions(mirrorHost)
+
+	repo := strings.TrimSuffix(mirror, "/") + "/" + ref.Context().RepositoryStr()
+	sep := ":"
+	if _, ok := ref.(name.Digest); ok {
+		sep = "@"
+	}
+	mirrorRef, err := name.ParseReference(repo+sep+ref.Identifier(), nameOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidRef, err)
+	}
+	return mirrorRef, nil
+}
+
+// transport returns the HTTP transport for a registry, or nil to use the
+// default transport.
+func (c *client) transport(host *HostConfig) (http.RoundTripper, error) {
+	insecure := c.config.Insecure
+	caFiles := []string{c.config.CAFile}
+	if host != nil {
+		insecure = insecure || host.Insecure
+		caFiles = append(caFiles, host.CAFile)
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	customized := false
+	if insecure {
+		tlsConfig.InsecureSkipVerify = true //nolint:gosec // intentional for insecure mode
+		customized = true
+	}
+	for _, caFile := range caFiles {
+		if caFile == "" {
+			continue
+		}
+		if tlsConfig.RootCAs == nil {
+			pool, err := x509.SystemCertPool()
+			if err != nil {
+				pool = x509.NewCertPool()
+			}
+			tlsConfig.RootCAs = pool
+		}
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("read CA bundle: %w", err)
+		}
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("read CA bundle: no certificates in %s", caFile)
+		}
+		customized = true
+	}
+	if !customized {
+		return nil, nil
+	}
+
+	// Clone http.DefaultTransport to preserve proxy, keep-alive, and timeout settings.
+	// Fall back to a basic transport if the type assertion fails (shouldn't happen in practice).
+	var t *http.Transport
+	if defaultTransport, ok := http.DefaultTransport.(*http.Transport); ok {
+		t = defaultTransport.Clone()
+	} else {
+		t = &http.Transport{}
+	}
+	t.TLSClientConfig = tlsConfig
+	return t, nil
+}
+
+// keychain returns the credentials for a registry: its static credentials,
+// secret, or credential helper if configured, else Docker's config.json and
+// the default credential helpers.
+func (c *client) keychain(host *HostConfig) (authn.Keychain, error) {
+	switch {
+	case host == nil:
+		return authn.DefaultKeychain, nil
+	case host.Username != "" || host.Password != "":
+		return staticKeychain{auth: authn.FromConfig(authn.AuthConfig{Username: host.Username, Password: host.Password})}, nil
+	case host.Secret != "":
+		if c.config.Secrets == nil {
+			return nil, fmt.Errorf("registry %s: %w", host.Host, secret.ErrNoStore)
+		}
+		value, err := c.config.Secrets.Resolve("", host.Secret)
+		if err != nil {
+			return nil, fmt.Errorf("registry %s: %w", host.Host, err)
+		}
+		if username, password, ok := strings.Cut(value, ":"); ok {
+			return staticKeychain{auth: authn.FromConfig(authn.AuthConfig{Username: username, Password: password})}, nil
+		}
+		return staticKeychain{auth: authn.FromConfig(authn.AuthConfig{RegistryToken: value})}, nil
+	case host.CredentialHelper != "":
+		return authn.NewKeychainFromHelper(&credentialHelper{exec: c.config.Exec, name: host.CredentialHelper}), nil
+	default:
+		return authn.DefaultKeychain, nil
+	}
+}
+
+// staticKeychain returns the same credentials for every registry.
+type staticKeychain struct {
+	auth authn.Authenticator
+}
+
+// Resolve implements authn.Keychain.
+func (k staticKeychain) Resolve(authn.Resource) (authn.Authenticator, error) {
+	return k.auth, nil
+}
+
+// credentialHelper runs a Docker credential helper.
+type credentialHelper struct {
+	exec exec.Executor
+	name string
+}
+
+// Get implements authn.Helper by running docker-credential-<name> get with
+// the server URL on standard input.
+func (h *credentialHelper) Get(serverURL string) (string, string, error) {
+	result, err := h.exec.Run(context.Background(), &exec.RunOptions{
+		Name:  "docker-credential-" + h.name,
+		Args:  []string{"get"},
+		Stdin: strings.NewReader(serverURL),
+	})
+	if err != nil {
+		if result != nil && len(result.Stdout) > 0 {
+			return "", "", fmt.Errorf("credential helper %s: %w: %s", h.name, err, strings.TrimSpace(string(result.Stdout)))
+		}
+		return "", "", fmt.Errorf("credential helper %s: %w", h.name, err)
+	}
+
+	var creds struct {
+		Username string `json:"Username"`
+		Secret   string `json:"Secret"`
+	}
+	if err := json.Unmarshal(result.Stdout, &creds); err != nil {
+		return "", "", fmt.Errorf("credential helper %s: parse output: %w", h.name, err)
+	}
+	return creds.Username, creds.Secret, nil
+}
+
 // mapError converts go-containerregistry errors to sentinel errors.
 func (c *client) mapError(err error) error {
 	// Check for transport errors (HTTP status codes)
@@ -2,12 +2,17 @@ package registry
 
 import (
 	"context"
+	"encoding/pem"
 	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/google/go-containerregistry/pkg/registry"
 	"github.com/google/go-containerregistry/pkg/v1/random"
@@ -15,6 +20,10 @@ import (
 	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/jmgilman/headjack/internal/exec"
+	execmocks "github.com/jmgilman/headjack/internal/exec/mocks"
+	secretmocks "github.com/jmgilman/headjack/internal/secret/mocks"
 )
 
 func TestNewClient(t *testing.T) {
@@ -75,6 +84,235 @@ func TestClient_GetMetadata(t *testing.T) {
 	})
 }
 
+// pushTestImage pushes a random image to a registry and returns its digest.
+func pushTestImage(t *testing.T, ref string, opts ...remote.Option) string {
+	t.Helper()
+	img, err := random.Image(1024, 1)
+	require.NoError(t, err)
+	parsed, err := name.ParseReference(ref)
+	require.NoError(t, err)
+	require.NoError(t, remote.Write(parsed, img, opts...))
+	digest, err := img.Digest()
+	require.NoError(t, err)
+	return digest.String()
+}
+
+// basicAuth requires the given credentials for every request.
+func basicAuth(next http.Handler, username, password string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if u, p, ok := r.BasicAuth(); !ok || u != username || p != password {
+			w.Header().Set("WWW-Authenticate", `Basic realm="test"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func TestClient_Mirrors(t *testing.T) {
+	ctx := context.Background()
+
+	mirror := httptest.NewServer(registry.New())
+	defer mirror.Close()
+	mirrorHost := strings.TrimPrefix(mirror.URL, "http://")
+	digest := pushTestImage(t, mirrorHost+"/ghcr/test/image:latest")
+
+	t.Run("fetches from a mirror with a path prefix", func(t *testing.T) {
+		client := NewClient(ClientConfig{
+			Insecure: true,
+			Hosts:    []HostConfig{{Host: "ghcr.io", Mirrors: []string{mirrorHost + "/ghcr"}}},
+		})
+
+		metadata, err := client.GetMetadata(ctx, "ghcr.io/test/image:latest")
+
+		require.NoError(t, err)
+		assert.Equal(t, digest, metadata.Digest)
+	})
+
+	t.Run("fetches digests from a mirror", func(t *testing.T) {
+		client := NewClient(ClientConfig{
+			Insecure: true,
+			Hosts:    []HostConfig{{Host: "ghcr.io", Mirrors: []string{mirrorHost + "/ghcr"}}},
+		})
+
+		metadata, err := client.GetMetadata(ctx, "ghcr.io/test/image@"+digest)
+
+		require.NoError(t, err)
+		assert.Equal(t, digest, metadata.Digest)
+	})
+
+	t.Run("falls back to the registry", func(t *testing.T) {
+		origin := httptest.NewServer(registry.New())
+		defer origin.Close()
+		originHost := strings.TrimPrefix(origin.URL, "http://")
+		originDigest := pushTestImage(t, originHost+"/other/image:latest")
+
+		client := NewClient(ClientConfig{
+			Insecure: true,
+			Hosts:    []HostConfig{{Host: originHost, Mirrors: []string{mirrorHost + "/ghcr"}}},
+		})
+
+		metadata, err := client.GetMetadata(ctx, originHost+"/other/image:latest")
+
+		require.NoError(t, err)
+		assert.Equal(t, originDigest, metadata.Digest)
+	})
+}
+
+func TestClient_Credentials(t *testing.T) {
+	ctx := context.Background()
+
+	server := httptest.NewServer(basicAuth(registry.New(), "hjk", "s3cret"))
+	defer server.Close()
+	regHost := strings.TrimPrefix(server.URL, "http://")
+	pushTestImage(t, regHost+"/test/image:latest", remote.WithAuth(&authn.Basic{Username: "hjk", Password: "s3cret"}))
+	ref := regHost + "/test/image:latest"
+
+	t.Run("rejects missing credentials", func(t *testing.T) {
+		client := NewClient(ClientConfig{Insecure: true, Hosts: []HostConfig{{Host: regHost}}})
+
+		_, err := client.GetMetadata(ctx, ref)
+
+		assert.ErrorIs(t, err, ErrUnauthorized)
+	})
+
+	t.Run("uses static credentials", func(t *testing.T) {
+		client := NewClient(ClientConfig{
+			Hosts: []HostConfig{{Host: regHost, Insecure: true, Username: "hjk", Password: "s3cret"}},
+		})
+
+		_, err := client.GetMetadata(ctx, ref)
+
+		require.NoError(t, err)
+	})
+
+	t.Run("uses credentials stored as a secret", func(t *testing.T) {
+		secrets := &secretmocks.ResolverMock{
+			ResolveFunc: func(repoID, name string) (string, error) {
+				assert.Empty(t, repoID)
+				assert.Equal(t, "registry", name)
+				return "hjk:s3cret", nil
+			},
+		}
+		client := NewClient(ClientConfig{
+			Insecure: true,
+			Secrets:  secrets,
+			Hosts:    []HostConfig{{Host: regHost, Secret: "registry"}},
+		})
+
+		_, err := client.GetMetadata(ctx, ref)
+
+		require.NoError(t, err)
+	})
+
+	t.Run("runs the credential helper", func(t *testing.T) {
+		helper := &execmocks.ExecutorMock{
+			RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				assert.Equal(t, "docker-credential-corp", opts.Name)
+				assert.Equal(t, []string{"get"}, opts.Args)
+				serverURL, err := io.ReadAll(opts.Stdin)
+				require.NoError(t, err)
+				assert.Contains(t, string(serverURL), regHost)
+				return &exec.Result{Stdout: []byte(`{"ServerURL":"","Username":"hjk","Secret":"s3cret"}`)}, nil
+			},
+		}
+		client := NewClient(ClientConfig{
+			Insecure: true,
+			Exec:     helper,
+			Hosts:    []HostConfig{{Host: regHost, CredentialHelper: "corp"}},
+		})
+
+		_, err := client.GetMetadata(ctx, ref)
+
+		require.NoError(t, err)
+		assert.NotEmpty(t, helper.RunCalls())
+	})
+}
+
+func TestClient_CAFile(t *testing.T) {
+	ctx := context.Background()
+
+	server := httptest.NewTLSServer(registry.New())
+	defer server.Close()
+	regHost := strings.TrimPrefix(server.URL, "https://")
+	pushTestImage(t, regHost+"/test/image:latest", remote.WithTransport(server.Client().Transport))
+	ref := regHost + "/test/image:latest"
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	require.NoError(t, os.WriteFile(caFile, certPEM, 0o600))
+
+	t.Run("trusts a registry's CA bundle", func(t *testing.T) {
+		client := NewClient(ClientConfig{Hosts: []HostConfig{{Host: regHost, CAFile: caFile}}})
+
+		_, err := client.GetMetadata(ctx, ref)
+
+		require.NoError(t, err)
+	})
+
+	t.Run("trusts a global CA bundle", func(t *testing.T) {
+		client := NewClient(ClientConfig{CAFile: caFile})
+
+		_, err := client.GetMetadata(ctx, ref)
+
+		require.NoError(t, err)
+	})
+
+	t.Run("rejects a bundle without certificates", func(t *testing.T) {
+		empty := filepath.Join(t.TempDir(), "empty.pem")
+		require.NoError(t, os.WriteFile(empty, []byte("not a certificate"), 0o600))
+		client := NewClient(ClientConfig{CAFile: empty})
+
+		_, err := client.GetMetadata(ctx, ref)
+
+		assert.ErrorContains(t, err, "no certificates")
+	})
+}
+
+func TestClient_reference(t *testing.T) {
+	tests := []struct {
+		name   string
+		config ClientConfig
+		ref    string
+		scheme string
+	}{
+		{
+			name:   "secure by default",
+			ref:    "192.0.2.2:5000/test/image:latest",
+			scheme: "https",
+		},
+		{
+			name:   "globally insecure",
+			config: ClientConfig{Insecure: true},
+			ref:    "192.0.2.2:5000/test/image:latest",
+			scheme: "http",
+		},
+		{
+			name:   "insecure host",
+			config: ClientConfig{Hosts: []HostConfig{{Host: "192.0.2.2:5000", Insecure: true}}},
+			ref:    "192.0.2.2:5000/test/image:latest",
+			scheme: "http",
+		},
+		{
+			name:   "other hosts stay secure",
+			config: ClientConfig{Hosts: []HostConfig{{Host: "192.0.2.2:5000", Insecure: true}}},
+			ref:    "192.0.2.3:5000/test/image:latest",
+			scheme: "https",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &client{config: tt.config}
+
+			ref, err := c.reference(tt.ref)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.scheme, ref.Context().Scheme())
+		})
+	}
+}
+
 func TestClient_mapError(t *testing.T) {
 	c := &client{}
 
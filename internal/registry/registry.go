@@ -5,6 +5,9 @@ import (
 	"context"
 	"errors"
 	"time"
+
+	"github.com/jmgilman/headjack/internal/exec"
+	"github.com/jmgilman/headjack/internal/secret"
 )
 
 // Sentinel errors for registry operations.
@@ -35,12 +38,65 @@ type ImageMetadata struct {
 
 	// OS is the operating system (e.g., "linux").
 	OS string
+
+	// Cached is set when the metadata was read from a metadata cache rather
+	// than fetched from the registry.
+	Cached bool
+
+	// FetchedAt is when the metadata was last fetched from the registry.
+	// Only set by a metadata cache.
+	FetchedAt time.Time
 }
 
 // ClientConfig configures the registry client.
 type ClientConfig struct {
 	// Insecure allows HTTP (non-TLS) connections to registries.
 	Insecure bool
+
+	// CAFile is a PEM bundle of certificate authorities trusted for every
+	// registry in addition to the system roots.
+	CAFile string
+
+	// Hosts configures individual registries.
+	Hosts []HostConfig
+
+	// Exec runs credential helpers. Nil means the system executor.
+	Exec exec.Executor
+
+	// Secrets resolves credentials stored as global secrets. Required only
+	// if a host sets Secret.
+	Secrets secret.Resolver
+}
+
+// HostConfig configures access to one registry.
+type HostConfig struct {
+	// Host is the registry host, with the port if any, e.g. "ghcr.io" or
+	// "registry.example.com:5000". "docker.io" is Docker Hub.
+	Host string
+
+	// Mirrors are tried in order before the registry itself, each a host
+	// with an optional path prefix, e.g. "mirror.example.com/ghcr". An image
+	// is fetched from a mirror at the prefix followed by its repository.
+	Mirrors []string
+
+	// Username and Password are static credentials.
+	Username string
+	Password string
+
+	// CredentialHelper names a Docker credential helper, run as
+	// docker-credential-<name>, e.g. "ecr-login".
+	CredentialHelper string
+
+	// Secret names a global secret holding "username:password", or a
+	// bearer token if it contains no colon.
+	Secret string
+
+	// CAFile is a PEM bundle of certificate authorities trusted for this
+	// registry in addition to ClientConfig.CAFile.
+	CAFile string
+
+	// Insecure allows HTTP and unverified TLS connections to this registry.
+	Insecure bool
 }
 
 // Client fetches image metadata from OCI registries.
@@ -0,0 +1,217 @@
+// Package secret stores named secrets for agent sessions, such as API tokens
+// and database URLs, and resolves references to them in session environments.
+//
+// Secrets are scoped globally or to a single repository. Configuration refers
+// to them by name with a secret:// reference, e.g. GITHUB_TOKEN: secret://github,
+// so configuration files never hold plaintext values.
+package secret
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/jmgilman/headjack/internal/credstore"
+	"github.com/jmgilman/headjack/internal/keychain"
+)
+
+// Sentinel errors for secret operations.
+var (
+	ErrNotFound    = errors.New("secret not found")
+	ErrInvalidName = errors.New("invalid secret name")
+	ErrNoStore     = errors.New("no secret store configured")
+)
+
+// RefPrefix marks an environment value as a reference to a secret.
+const RefPrefix = "secret://"
+
+// indexAccount is the backend account listing the stored secrets.
+const indexAccount = "secret-index"
+
+// namePattern matches valid secret names.
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// ValidateName checks that name is usable as a secret name.
+func ValidateName(name string) error {
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("%w: %q (use letters, digits, '.', '-' and '_')", ErrInvalidName, name)
+	}
+	return nil
+}
+
+// ParseRef returns the secret name referenced by an environment value, and
+// whether the value is a reference at all.
+func ParseRef(value string) (string, bool) {
+	return strings.CutPrefix(value, RefPrefix)
+}
+
+// Scope selects which sessions a secret applies to.
+type Scope struct {
+	// RepoID is the identifier of the repository the secret is limited to.
+	// Empty means the secret is global.
+	RepoID string `json:"repo_id,omitempty"`
+}
+
+// Global returns the scope of secrets available to every repository.
+func Global() Scope {
+	return Scope{}
+}
+
+// Repo returns the scope of secrets limited to one repository.
+func Repo(repoID string) Scope {
+	return Scope{RepoID: repoID}
+}
+
+// IsGlobal reports whether the scope covers every repository.
+func (s Scope) IsGlobal() bool {
+	return s.RepoID == ""
+}
+
+// String returns "global" or the repository identifier.
+func (s Scope) String() string {
+	if s.IsGlobal() {
+		return "global"
+	}
+	return s.RepoID
+}
+
+// account returns the backend account holding a secret.
+func (s Scope) account(name string) string {
+	if s.IsGlobal() {
+		return "secret:" + name
+	}
+	return "secret:" + s.RepoID + ":" + name
+}
+
+// Entry identifies a stored secret.
+type Entry struct {
+	Scope Scope  `json:"scope"`
+	Name  string `json:"name"`
+}
+
+// Backend is the credential storage secrets are kept in. keychain.Keychain
+// satisfies it.
+type Backend interface {
+	Set(account, secret string) error
+	Get(account string) (string, error)
+	Delete(account string) error
+}
+
+// Resolver looks up secret values for sessions.
+//
+//go:generate go run github.com/matryer/moq@latest -pkg mocks -out mocks/resolver.go . Resolver
+type Resolver interface {
+	// Resolve returns the value of the named secret for a repository,
+	// preferring a secret scoped to the repository over a global one.
+	// Returns ErrNotFound if neither exists.
+	Resolve(repoID, name string) (string, error)
+}
+
+// Store keeps secrets in a Backend.
+type Store struct {
+	backend Backend
+	index   *credstore.Index[Entry]
+}
+
+// NewStore creates a Store backed by the given backend.
+func NewStore(backend Backend) *Store {
+	return &Store{backend: backend, index: credstore.NewIndex[Entry](backend, indexAccount)}
+}
+
+// Set stores a secret, replacing any existing value in the same scope.
+func (s *Store) Set(scope Scope, name, value string) error {
+	if err := ValidateName(name); err != nil {
+		return err
+	}
+	if err := s.backend.Set(scope.account(name), value); err != nil {
+		return fmt.Errorf("store secret: %w", err)
+	}
+
+	return s.index.Add(Entry{Scope: scope, Name: name})
+}
+
+// Get returns the value of a secret in exactly the given scope.
+// Returns ErrNotFound if it does not exist.
+func (s *Store) Get(scope Scope, name string) (string, error) {
+	value, err := s.backend.Get(scope.account(name))
+	if errors.Is(err, keychain.ErrNotFound) {
+		return "", fmt.Errorf("%w: %s (%s)", ErrNotFound, name, scope)
+	}
+	if err != nil {
+		return "", fmt.Errorf("load secret: %w", err)
+	}
+	return value, nil
+}
+
+// Delete removes a secret from the given scope.
+// Returns ErrNotFound if it does not exist.
+func (s *Store) Delete(scope Scope, name string) error {
+	entries, err := s.index.Load()
+	if err != nil {
+		return err
+	}
+	entry := Entry{Scope: scope, Name: name}
+	if !slices.Contains(entries, entry) {
+		return fmt.Errorf("%w: %s (%s)", ErrNotFound, name, scope)
+	}
+
+	if err := s.backend.Delete(scope.account(name)); err != nil {
+		return fmt.Errorf("delete secret: %w", err)
+	}
+	_, err = s.index.Remove(entry)
+	return err
+}
+
+// List returns the stored secrets, global secrets first, then by repository
+// and name.
+func (s *Store) List() ([]Entry, error) {
+	entries, err := s.index.Load()
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(entries, func(a, b Entry) int {
+		if c := strings.Compare(a.Scope.RepoID, b.Scope.RepoID); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	return entries, nil
+}
+
+// Resolve returns the value of the named secret for a repository, preferring
+// a secret scoped to the repository over a global one.
+func (s *Store) Resolve(repoID, name string) (string, error) {
+	if repoID != "" {
+		value, err := s.Get(Repo(repoID), name)
+		if !errors.Is(err, ErrNotFound) {
+			return value, err
+		}
+	}
+	return s.Get(Global(), name)
+}
+
+// ResolveEnv returns env with every secret:// reference replaced by the
+// secret's value for the repository. Entries without a reference are kept
+// as they are. A nil resolver is only an error if env holds references.
+func ResolveEnv(resolver Resolver, repoID string, env []string) ([]string, error) {
+	resolved := make([]string, len(env))
+	for i, e := range env {
+		key, value, _ := strings.Cut(e, "=")
+		name, ok := ParseRef(value)
+		if !ok {
+			resolved[i] = e
+			continue
+		}
+		if resolver == nil {
+			return nil, fmt.Errorf("resolve %s: %w", key, ErrNoStore)
+		}
+		secretValue, err := resolver.Resolve(repoID, name)
+		if err != nil {
+			return nil, fmt.Errorf("resolve %s: %w", key, err)
+		}
+		resolved[i] = key + "=" + secretValue
+	}
+	return resolved, nil
+}
@@ -0,0 +1,168 @@
+package secret
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmgilman/headjack/internal/keychain"
+	"github.com/jmgilman/headjack/internal/keychain/mocks"
+)
+
+// newTestKeychain returns an in-memory keychain.
+func newTestKeychain() (*mocks.KeychainMock, map[string]string) {
+	stored := make(map[string]string)
+	return &mocks.KeychainMock{
+		SetFunc: func(account, secret string) error {
+			stored[account] = secret
+			return nil
+		},
+		GetFunc: func(account string) (string, error) {
+			value, ok := stored[account]
+			if !ok {
+				return "", keychain.ErrNotFound
+			}
+			return value, nil
+		},
+		DeleteFunc: func(account string) error {
+			delete(stored, account)
+			return nil
+		},
+	}, stored
+}
+
+func TestValidateName(t *testing.T) {
+	for _, name := range []string{"github", "GITHUB_TOKEN", "npm.token", "db-url"} {
+		assert.NoError(t, ValidateName(name), name)
+	}
+	for _, name := range []string{"", "-github", "my secret", "a:b", "a/b"} {
+		assert.ErrorIs(t, ValidateName(name), ErrInvalidName, name)
+	}
+}
+
+func TestParseRef(t *testing.T) {
+	name, ok := ParseRef("secret://github")
+	assert.True(t, ok)
+	assert.Equal(t, "github", name)
+
+	_, ok = ParseRef("ghp_plaintext")
+	assert.False(t, ok)
+}
+
+func TestStore(t *testing.T) {
+	kc, stored := newTestKeychain()
+	store := NewStore(kc)
+
+	require.NoError(t, store.Set(Global(), "github", "ghp_global"))
+	require.NoError(t, store.Set(Repo("myproject-abc1234"), "github", "ghp_repo"))
+	require.NoError(t, store.Set(Global(), "npm", "npm_token"))
+	require.NoError(t, store.Set(Global(), "github", "ghp_global2"), "overwrite keeps one index entry")
+
+	t.Run("stores under scoped accounts", func(t *testing.T) {
+		assert.Equal(t, "ghp_global2", stored["secret:github"])
+		assert.Equal(t, "ghp_repo", stored["secret:myproject-abc1234:github"])
+	})
+
+	t.Run("lists global secrets first", func(t *testing.T) {
+		entries, err := store.List()
+
+		require.NoError(t, err)
+		assert.Equal(t, []Entry{
+			{Scope: Global(), Name: "github"},
+			{Scope: Global(), Name: "npm"},
+			{Scope: Repo("myproject-abc1234"), Name: "github"},
+		}, entries)
+	})
+
+	t.Run("gets exact scope", func(t *testing.T) {
+		value, err := store.Get(Repo("myproject-abc1234"), "github")
+		require.NoError(t, err)
+		assert.Equal(t, "ghp_repo", value)
+
+		_, err = store.Get(Repo("myproject-abc1234"), "npm")
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("resolves repository secrets before global ones", func(t *testing.T) {
+		value, err := store.Resolve("myproject-abc1234", "github")
+		require.NoError(t, err)
+		assert.Equal(t, "ghp_repo", value)
+
+		value, err = store.Resolve("myproject-abc1234", "npm")
+		require.NoError(t, err)
+		assert.Equal(t, "npm_token", value)
+
+		value, err = store.Resolve("other-def5678", "github")
+		require.NoError(t, err)
+		assert.Equal(t, "ghp_global2", value)
+
+		_, err = store.Resolve("", "missing")
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("deletes secrets", func(t *testing.T) {
+		require.NoError(t, store.Delete(Repo("myproject-abc1234"), "github"))
+		assert.NotContains(t, stored, "secret:myproject-abc1234:github")
+
+		err := store.Delete(Repo("myproject-abc1234"), "github")
+		assert.ErrorIs(t, err, ErrNotFound)
+
+		require.NoError(t, store.Delete(Global(), "github"))
+		require.NoError(t, store.Delete(Global(), "npm"))
+		assert.Empty(t, stored, "index is removed with the last secret")
+	})
+
+	t.Run("rejects invalid names", func(t *testing.T) {
+		assert.ErrorIs(t, store.Set(Global(), "my secret", "value"), ErrInvalidName)
+	})
+}
+
+func TestResolveEnv(t *testing.T) {
+	resolver := resolverFunc(func(repoID, name string) (string, error) {
+		if name == "github" {
+			return "ghp_" + repoID, nil
+		}
+		return "", ErrNotFound
+	})
+
+	t.Run("replaces references", func(t *testing.T) {
+		env, err := ResolveEnv(resolver, "repo1", []string{"GITHUB_TOKEN=secret://github", "MAX_TURNS=100"})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"GITHUB_TOKEN=ghp_repo1", "MAX_TURNS=100"}, env)
+	})
+
+	t.Run("missing secret", func(t *testing.T) {
+		_, err := ResolveEnv(resolver, "repo1", []string{"NPM_TOKEN=secret://npm"})
+
+		assert.ErrorIs(t, err, ErrNotFound)
+		assert.ErrorContains(t, err, "NPM_TOKEN")
+	})
+
+	t.Run("nil resolver without references", func(t *testing.T) {
+		env, err := ResolveEnv(nil, "repo1", []string{"MAX_TURNS=100"})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"MAX_TURNS=100"}, env)
+	})
+
+	t.Run("nil resolver with references", func(t *testing.T) {
+		_, err := ResolveEnv(nil, "repo1", []string{"GITHUB_TOKEN=secret://github"})
+		assert.ErrorIs(t, err, ErrNoStore)
+	})
+
+	t.Run("backend errors are wrapped", func(t *testing.T) {
+		failing := resolverFunc(func(string, string) (string, error) { return "", errors.New("locked") })
+		_, err := ResolveEnv(failing, "repo1", []string{"GITHUB_TOKEN=secret://github"})
+		assert.ErrorContains(t, err, "locked")
+	})
+}
+
+// resolverFunc adapts a function to the Resolver interface.
+type resolverFunc func(repoID, name string) (string, error)
+
+func (f resolverFunc) Resolve(repoID, name string) (string, error) {
+	return f(repoID, name)
+}
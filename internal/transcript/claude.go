@@ -0,0 +1,113 @@
+package transcript
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// claudeEditTools are Claude Code tools that modify files.
+var claudeEditTools = map[string]bool{
+	"Edit":         true,
+	"MultiEdit":    true,
+	"Write":        true,
+	"NotebookEdit": true,
+}
+
+// claudeEvent is a line of Claude Code's stream-json output.
+type claudeEvent struct {
+	Type    string `json:"type"`
+	Subtype string `json:"subtype"`
+	Model   string `json:"model"`
+	Message struct {
+		Content []claudeContent `json:"content"`
+	} `json:"message"`
+	Result       string  `json:"result"`
+	IsError      bool    `json:"is_error"`
+	TotalCostUSD float64 `json:"total_cost_usd"`
+}
+
+type claudeContent struct {
+	Type      string          `json:"type"`
+	Text      string          `json:"text"`
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Input     json.RawMessage `json:"input"`
+	ToolUseID string          `json:"tool_use_id"`
+	Content   json.RawMessage `json:"content"` // String or list of text blocks
+	IsError   bool            `json:"is_error"`
+}
+
+type claudeParser struct{}
+
+func (p *claudeParser) parse(raw []byte) []Event {
+	var ev claudeEvent
+	if err := json.Unmarshal(raw, &ev); err != nil {
+		return nil
+	}
+
+	switch ev.Type {
+	case "system":
+		if ev.Subtype == "init" {
+			return []Event{{Kind: KindSession, Model: ev.Model}}
+		}
+	case "assistant":
+		var events []Event
+		for _, c := range ev.Message.Content {
+			switch c.Type {
+			case "text":
+				events = append(events, Event{Kind: KindMessage, Text: c.Text})
+			case "tool_use":
+				event := Event{Kind: KindToolCall, Tool: c.Name, ToolID: c.ID, Input: c.Input}
+				if claudeEditTools[c.Name] {
+					if path := stringField(c.Input, "file_path", "notebook_path"); path != "" {
+						event.Files = []string{path}
+					}
+				}
+				events = append(events, event)
+			}
+		}
+		return events
+	case "user":
+		var events []Event
+		for _, c := range ev.Message.Content {
+			if c.Type == "tool_result" {
+				events = append(events, Event{
+					Kind:    KindToolResult,
+					ToolID:  c.ToolUseID,
+					Text:    claudeResultText(c.Content),
+					IsError: c.IsError,
+				})
+			}
+		}
+		return events
+	case "result":
+		return []Event{{Kind: KindResult, Text: ev.Result, IsError: ev.IsError, CostUSD: ev.TotalCostUSD}}
+	}
+
+	return nil
+}
+
+func (p *claudeParser) finish() []Event {
+	return nil
+}
+
+// claudeResultText flattens tool result content, which is either a string or
+// a list of content blocks.
+func claudeResultText(content json.RawMessage) string {
+	var text string
+	if err := json.Unmarshal(content, &text); err == nil {
+		return text
+	}
+
+	var blocks []claudeContent
+	if err := json.Unmarshal(content, &blocks); err != nil {
+		return ""
+	}
+	parts := make([]string, 0, len(blocks))
+	for _, b := range blocks {
+		if b.Type == "text" {
+			parts = append(parts, b.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
@@ -0,0 +1,100 @@
+package transcript
+
+import (
+	"encoding/json"
+)
+
+// codexEvent is a line of `codex exec --json` output.
+type codexEvent struct {
+	Type  string    `json:"type"`
+	Item  codexItem `json:"item"`
+	Error struct {
+		Message string `json:"message"`
+	} `json:"error"`
+	Message string `json:"message"`
+}
+
+type codexItem struct {
+	ID               string `json:"id"`
+	Type             string `json:"type"`
+	Text             string `json:"text"`
+	Command          string `json:"command"`
+	AggregatedOutput string `json:"aggregated_output"`
+	ExitCode         *int   `json:"exit_code"`
+	Status           string `json:"status"`
+	Server           string `json:"server"`
+	Tool             string `json:"tool"`
+	Query            string `json:"query"`
+	Changes          []struct {
+		Path string `json:"path"`
+		Kind string `json:"kind"`
+	} `json:"changes"`
+}
+
+// codexParser tracks the last agent message, which Codex treats as its
+// final answer when the turn completes.
+type codexParser struct {
+	lastMessage string
+}
+
+func (p *codexParser) parse(raw []byte) []Event {
+	var ev codexEvent
+	if err := json.Unmarshal(raw, &ev); err != nil {
+		return nil
+	}
+
+	switch ev.Type {
+	case "thread.started":
+		return []Event{{Kind: KindSession}}
+	case "item.completed":
+		return p.item(&ev.Item)
+	case "turn.completed":
+		return []Event{{Kind: KindResult, Text: p.lastMessage}}
+	case "turn.failed":
+		return []Event{{Kind: KindResult, Text: ev.Error.Message, IsError: true}}
+	case "error":
+		return []Event{{Kind: KindResult, Text: ev.Message, IsError: true}}
+	}
+
+	return nil
+}
+
+func (p *codexParser) finish() []Event {
+	return nil
+}
+
+// item converts a completed Codex item. Codex reports each tool call once,
+// with its output, so a call yields both a tool call and a tool result.
+func (p *codexParser) item(item *codexItem) []Event {
+	switch item.Type {
+	case "agent_message":
+		p.lastMessage = item.Text
+		return []Event{{Kind: KindMessage, Text: item.Text}}
+	case "command_execution":
+		input, _ := json.Marshal(map[string]string{"command": item.Command})
+		failed := item.Status == "failed" || (item.ExitCode != nil && *item.ExitCode != 0)
+		return []Event{
+			{Kind: KindToolCall, Tool: "shell", ToolID: item.ID, Input: input},
+			{Kind: KindToolResult, ToolID: item.ID, Text: item.AggregatedOutput, IsError: failed},
+		}
+	case "file_change":
+		call := Event{Kind: KindToolCall, Tool: "apply_patch", ToolID: item.ID}
+		for _, change := range item.Changes {
+			call.Files = append(call.Files, change.Path)
+		}
+		return []Event{
+			call,
+			{Kind: KindToolResult, ToolID: item.ID, IsError: item.Status == "failed"},
+		}
+	case "mcp_tool_call":
+		return []Event{
+			{Kind: KindToolCall, Tool: item.Server + "." + item.Tool, ToolID: item.ID},
+			{Kind: KindToolResult, ToolID: item.ID, IsError: item.Status == "failed"},
+		}
+	case "web_search":
+		input, _ := json.Marshal(map[string]string{"query": item.Query})
+		return []Event{{Kind: KindToolCall, Tool: "web_search", ToolID: item.ID, Input: input}}
+	}
+
+	return nil
+}
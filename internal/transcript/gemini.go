@@ -0,0 +1,98 @@
+package transcript
+
+import (
+	"encoding/json"
+)
+
+// geminiEditTools are Gemini CLI tools that modify files.
+var geminiEditTools = map[string]bool{
+	"replace":    true,
+	"write_file": true,
+}
+
+// geminiEvent is a line of Gemini CLI's stream-json output.
+type geminiEvent struct {
+	Type       string          `json:"type"`
+	Model      string          `json:"model"`
+	Role       string          `json:"role"`
+	Content    string          `json:"content"`
+	Delta      bool            `json:"delta"`
+	ToolName   string          `json:"tool_name"`
+	ToolID     string          `json:"tool_id"`
+	Parameters json.RawMessage `json:"parameters"`
+	Status     string          `json:"status"`
+	Output     string          `json:"output"`
+	Error      struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// geminiParser joins streamed assistant deltas into whole messages. A
+// message is emitted when the agent does something other than continue it.
+type geminiParser struct {
+	pending     string
+	lastMessage string
+}
+
+func (p *geminiParser) parse(raw []byte) []Event {
+	var ev geminiEvent
+	if err := json.Unmarshal(raw, &ev); err != nil {
+		return nil
+	}
+
+	if ev.Type == "message" && ev.Role == "assistant" {
+		if ev.Delta {
+			p.pending += ev.Content
+			return nil
+		}
+		events := p.flush()
+		p.pending = ev.Content
+		return append(events, p.flush()...)
+	}
+
+	events := p.flush()
+
+	switch ev.Type {
+	case "init":
+		events = append(events, Event{Kind: KindSession, Model: ev.Model})
+	case "tool_use":
+		event := Event{Kind: KindToolCall, Tool: ev.ToolName, ToolID: ev.ToolID, Input: ev.Parameters}
+		if geminiEditTools[ev.ToolName] {
+			if path := stringField(ev.Parameters, "file_path"); path != "" {
+				event.Files = []string{path}
+			}
+		}
+		events = append(events, event)
+	case "tool_result":
+		text := ev.Output
+		if ev.Status == "error" && ev.Error.Message != "" {
+			text = ev.Error.Message
+		}
+		events = append(events, Event{Kind: KindToolResult, ToolID: ev.ToolID, Text: text, IsError: ev.Status == "error"})
+	case "result":
+		text := p.lastMessage
+		if ev.Status == "error" {
+			text = ev.Error.Message
+		}
+		events = append(events, Event{Kind: KindResult, Text: text, IsError: ev.Status == "error"})
+	case "error":
+		events = append(events, Event{Kind: KindResult, Text: ev.Error.Message, IsError: true})
+	}
+
+	return events
+}
+
+func (p *geminiParser) finish() []Event {
+	return p.flush()
+}
+
+// flush emits the pending assistant message, if any.
+func (p *geminiParser) flush() []Event {
+	if p.pending == "" {
+		return nil
+	}
+	text := p.pending
+	p.pending = ""
+	p.lastMessage = text
+	return []Event{{Kind: KindMessage, Text: text}}
+}
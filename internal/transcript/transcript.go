@@ -0,0 +1,316 @@
+// Package transcript captures structured agent transcripts.
+//
+// Claude Code, Codex, and Gemini CLI can each run headless and stream their
+// progress as JSON lines. In transcript mode, headjack starts detached agent
+// sessions this way, so the session log contains the agent's event stream.
+// This package builds the headless agent commands from their definitions,
+// records the JSON events in the session output as they arrive, normalizes
+// each event format into a common Event, and summarizes the result. An agent
+// supports transcript mode by naming one of the formats in its definition.
+package transcript
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/jmgilman/headjack/internal/agent"
+	"github.com/jmgilman/headjack/internal/logging"
+)
+
+// Sentinel errors for transcript operations.
+var (
+	ErrUnsupportedAgent  = errors.New("agent does not support transcript mode")
+	ErrUnsupportedFormat = errors.New("unknown transcript format")
+	ErrPromptRequired    = errors.New("transcript mode requires a prompt")
+)
+
+// maxLineSize bounds a single event line. Tool results can be large.
+const maxLineSize = 16 * 1024 * 1024
+
+// Kind identifies the type of a transcript event.
+type Kind string
+
+// Event kinds.
+const (
+	KindSession    Kind = "session"     // Agent session started
+	KindMessage    Kind = "message"     // Text from the agent
+	KindToolCall   Kind = "tool_call"   // Agent invoked a tool
+	KindToolResult Kind = "tool_result" // Output of a tool call
+	KindResult     Kind = "result"      // Agent finished
+)
+
+// Event is a single normalized transcript event.
+type Event struct {
+	Kind    Kind            `json:"kind"`
+	Tool    string          `json:"tool,omitempty"`    // Tool name for tool calls
+	ToolID  string          `json:"tool_id,omitempty"` // Links a tool result to its call
+	Input   json.RawMessage `json:"input,omitempty"`   // Tool call arguments
+	Text    string          `json:"text,omitempty"`    // Message text, tool output, or final message
+	Files   []string        `json:"files,omitempty"`   // Files edited by a tool call
+	Model   string          `json:"model,omitempty"`   // Model, for session events
+	IsError bool            `json:"is_error,omitempty"`
+	CostUSD float64         `json:"cost_usd,omitempty"` // Cost the agent reported, for result events
+}
+
+// parser converts agent JSON events into normalized events.
+type parser interface {
+	// parse converts one JSON event line.
+	parse(raw []byte) []Event
+	// finish returns any events still buffered when the stream ends.
+	finish() []Event
+}
+
+// parsers creates a parser for each event format.
+var parsers = map[string]func() parser{
+	"claude": func() parser { return &claudeParser{} },
+	"codex":  func() parser { return &codexParser{} },
+	"gemini": func() parser { return &geminiParser{} },
+}
+
+// Supported reports whether an agent supports transcript mode.
+func Supported(def *agent.Definition) bool {
+	_, ok := parsers[def.TranscriptFormat]
+	return ok
+}
+
+// Command returns the command that runs an agent headless on a prompt,
+// streaming JSON events to stdout.
+// Returns ErrUnsupportedAgent if the agent has no known transcript format
+// and ErrPromptRequired if prompt is empty, since headless agents cannot be
+// prompted interactively.
+func Command(def *agent.Definition, prompt string) ([]string, error) {
+	if !Supported(def) {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedAgent, def.Name)
+	}
+	if prompt == "" {
+		return nil, ErrPromptRequired
+	}
+	return def.BuildHeadlessCommand(prompt), nil
+}
+
+// Recorder parses a session's raw output as it is written and appends the
+// agent's normalized events to a transcript file. Lines that are not JSON
+// objects, such as container runtime messages, are skipped. It implements
+// io.WriteCloser.
+type Recorder struct {
+	file   *os.File
+	enc    *json.Encoder
+	parser parser
+	line   []byte
+	skip   bool // Discarding the rest of a line longer than maxLineSize
+}
+
+// NewRecorder opens the transcript file at path for appending events parsed
+// from an agent's output in the given format.
+func NewRecorder(path, format string) (*Recorder, error) {
+	newParser, ok := parsers[format]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+	}
+
+	//nolint:gosec // G304: path is constructed from trusted PathManager, not arbitrary user input
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("open transcript: %w", err)
+	}
+	return &Recorder{file: file, enc: json.NewEncoder(file), parser: newParser()}, nil
+}
+
+// Write parses the complete lines in p, buffering any trailing partial line
+// until the rest of it is written.
+func (r *Recorder) Write(p []byte) (int, error) {
+	n := len(p)
+	for {
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			r.buffer(p)
+			return n, nil
+		}
+		r.buffer(p[:i])
+		if err := r.flushLine(); err != nil {
+			return 0, err
+		}
+		p = p[i+1:]
+	}
+}
+
+// Close parses any trailing partial line, records the events the agent's
+// parser still buffers, and closes the transcript file.
+func (r *Recorder) Close() error {
+	if r.file == nil {
+		return nil
+	}
+	err := r.flushLine()
+	if err == nil {
+		err = r.record(r.parser.finish())
+	}
+	if closeErr := r.file.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("close transcript: %w", closeErr)
+	}
+	r.file = nil
+	return err
+}
+
+// buffer adds part of a line to the pending line, discarding lines too long
+// to be an event.
+func (r *Recorder) buffer(p []byte) {
+	if r.skip {
+		return
+	}
+	if len(r.line)+len(p) > maxLineSize {
+		r.line, r.skip = r.line[:0], true
+		return
+	}
+	r.line = append(r.line, p...)
+}
+
+// flushLine parses the pending line and records its events.
+func (r *Recorder) flushLine() error {
+	line, skip := r.line, r.skip
+	r.line, r.skip = r.line[:0], false
+	if skip || len(line) == 0 {
+		return nil
+	}
+
+	event := bytes.TrimSpace([]byte(logging.StripEscapes(string(line))))
+	if len(event) == 0 || event[0] != '{' || !json.Valid(event) {
+		return nil
+	}
+	return r.record(r.parser.parse(event))
+}
+
+// record appends events to the transcript file.
+func (r *Recorder) record(events []Event) error {
+	for i := range events {
+		if err := r.enc.Encode(&events[i]); err != nil {
+			return fmt.Errorf("record event: %w", err)
+		}
+	}
+	return nil
+}
+
+// Read reads the events recorded in a transcript file.
+func Read(path string) ([]Event, error) {
+	events, _, err := ReadFrom(path, 0)
+	return events, err
+}
+
+// ReadFrom reads the events recorded in a transcript file after byte offset,
+// returning them with the offset to read the next events from. Transcripts
+// are only appended to, so a caller can keep the offset to read only new
+// events. A partially written last event is left for the next read.
+func ReadFrom(path string, offset int64) ([]Event, int64, error) {
+	//nolint:gosec // G304: path is constructed from trusted PathManager, not arbitrary user input
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, offset, fmt.Errorf("open transcript: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return nil, offset, fmt.Errorf("seek transcript: %w", err)
+	}
+
+	var events []Event
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			return events, offset, nil
+		}
+		if err != nil {
+			return nil, offset, fmt.Errorf("read transcript: %w", err)
+		}
+		var event Event
+		if err := json.Unmarshal(line, &event); err != nil {
+			return nil, offset, fmt.Errorf("parse transcript event: %w", err)
+		}
+		events = append(events, event)
+		offset += int64(len(line))
+	}
+}
+
+// Summary condenses a transcript.
+type Summary struct {
+	Model        string
+	ToolCalls    map[string]int // Calls per tool name
+	ToolErrors   int            // Tool results reporting an error
+	FilesEdited  []string       // Sorted, deduplicated
+	FinalMessage string
+	Finished     bool // The agent reported a final result
+	Failed       bool // The final result was an error
+}
+
+// TotalToolCalls returns the number of tool calls across all tools.
+func (s *Summary) TotalToolCalls() int {
+	total := 0
+	for _, n := range s.ToolCalls {
+		total += n
+	}
+	return total
+}
+
+// Summarize condenses events into a Summary. The final message is the
+// agent's reported result, or its last message if it did not report one.
+func Summarize(events []Event) *Summary {
+	s := &Summary{ToolCalls: make(map[string]int)}
+	files := make(map[string]bool)
+	lastMessage := ""
+
+	for _, e := range events {
+		switch e.Kind {
+		case KindSession:
+			if e.Model != "" {
+				s.Model = e.Model
+			}
+		case KindMessage:
+			lastMessage = e.Text
+		case KindToolCall:
+			s.ToolCalls[e.Tool]++
+			for _, f := range e.Files {
+				files[f] = true
+			}
+		case KindToolResult:
+			if e.IsError {
+				s.ToolErrors++
+			}
+		case KindResult:
+			s.Finished = true
+			s.Failed = e.IsError
+			if e.Text != "" {
+				s.FinalMessage = e.Text
+			}
+		}
+	}
+
+	if s.FinalMessage == "" {
+		s.FinalMessage = lastMessage
+	}
+
+	for f := range files {
+		s.FilesEdited = append(s.FilesEdited, f)
+	}
+	sort.Strings(s.FilesEdited)
+
+	return s
+}
+
+// stringField extracts a string field from a JSON object, or "".
+func stringField(input json.RawMessage, keys ...string) string {
+	var obj map[string]any
+	if err := json.Unmarshal(input, &obj); err != nil {
+		return ""
+	}
+	for _, key := range keys {
+		if v, ok := obj[key].(string); ok && v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,247 @@
+package transcript
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmgilman/headjack/internal/agent"
+)
+
+// record writes output through a Recorder and returns the recorded events.
+func record(t *testing.T, format, output string) []Event {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "transcript.jsonl")
+	r, err := NewRecorder(path, format)
+	require.NoError(t, err)
+	_, err = r.Write([]byte(output))
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+
+	events, err := Read(path)
+	require.NoError(t, err)
+	return events
+}
+
+func recordFixture(t *testing.T, agentName string) []Event {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join("testdata", agentName+".log"))
+	require.NoError(t, err)
+	return record(t, agentName, string(data))
+}
+
+func TestReadFrom(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "transcript.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte(`{"kind":"message","text":"hi"}`+"\n"+`{"kind":"result","cost_usd":0.5}`+"\n"+`{"kind":"mess`), 0o600))
+
+	events, offset, err := ReadFrom(path, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []Event{{Kind: KindMessage, Text: "hi"}, {Kind: KindResult, CostUSD: 0.5}}, events)
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	require.NoError(t, err)
+	_, err = f.WriteString(`age","text":"bye"}` + "\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	events, next, err := ReadFrom(path, offset)
+	require.NoError(t, err)
+	assert.Equal(t, []Event{{Kind: KindMessage, Text: "bye"}}, events)
+
+	events, _, err = ReadFrom(path, next)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+}
+
+func TestCommand(t *testing.T) {
+	tests := []struct {
+		agent  string
+		expect []string
+	}{
+		{"claude", []string{"claude", "--output-format", "stream-json", "--verbose", "-p", "fix it"}},
+		{"codex", []string{"codex", "exec", "--json", "fix it"}},
+		{"gemini", []string{"gemini", "--output-format", "stream-json", "-p", "fix it"}},
+	}
+
+	agents := agent.DefaultRegistry()
+	for _, tt := range tests {
+		t.Run(tt.agent, func(t *testing.T) {
+			def, err := agents.Get(tt.agent)
+			require.NoError(t, err)
+
+			cmd, err := Command(def, "fix it")
+			require.NoError(t, err)
+			assert.Equal(t, tt.expect, cmd)
+		})
+	}
+
+	t.Run("uses the configured command", func(t *testing.T) {
+		def := &agent.Definition{
+			Name:             "claude-opus",
+			Command:          []string{"claude", "--model", "opus"},
+			HeadlessArgs:     []string{"-p"},
+			TranscriptFormat: "claude",
+		}
+
+		cmd, err := Command(def, "fix it")
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"claude", "--model", "opus", "-p", "fix it"}, cmd)
+	})
+
+	t.Run("requires prompt", func(t *testing.T) {
+		_, err := Command(&agent.Definition{Name: "claude", TranscriptFormat: "claude"}, "")
+		assert.ErrorIs(t, err, ErrPromptRequired)
+	})
+
+	t.Run("rejects unsupported agent", func(t *testing.T) {
+		def := &agent.Definition{Name: "aider", Command: []string{"aider"}}
+		_, err := Command(def, "fix it")
+		assert.ErrorIs(t, err, ErrUnsupportedAgent)
+		assert.False(t, Supported(def))
+	})
+}
+
+func TestRecorder_Claude(t *testing.T) {
+	events := recordFixture(t, "claude")
+
+	require.NotEmpty(t, events)
+	assert.Equal(t, Event{Kind: KindSession, Model: "claude-sonnet-4-5"}, events[0])
+	assert.Equal(t, Event{Kind: KindMessage, Text: "I'll look at the login handler first."}, events[1])
+	assert.Equal(t, KindToolCall, events[2].Kind)
+	assert.Equal(t, "Read", events[2].Tool)
+	assert.Empty(t, events[2].Files, "reads are not edits")
+	assert.Equal(t, "package auth\n...", events[3].Text)
+
+	edit := events[4]
+	assert.Equal(t, "Edit", edit.Tool)
+	assert.Equal(t, []string{"/workspace/src/auth/login.go"}, edit.Files)
+	assert.JSONEq(t, `{"file_path":"/workspace/src/auth/login.go","old_string":"return nil","new_string":"return err"}`, string(edit.Input))
+	assert.Equal(t, Event{Kind: KindToolResult, ToolID: "toolu_2", Text: "The file has been updated."}, events[5])
+
+	last := events[len(events)-1]
+	assert.Equal(t, Event{Kind: KindResult, Text: "Fixed the login bug and added a test.", CostUSD: 0.1234}, last)
+
+	summary := Summarize(events)
+	assert.Equal(t, "claude-sonnet-4-5", summary.Model)
+	assert.Equal(t, map[string]int{"Read": 1, "Edit": 1, "Bash": 2, "Write": 1}, summary.ToolCalls)
+	assert.Equal(t, 5, summary.TotalToolCalls())
+	assert.Equal(t, 1, summary.ToolErrors)
+	assert.Equal(t, []string{"/workspace/src/auth/login.go", "/workspace/src/auth/login_test.go"}, summary.FilesEdited)
+	assert.Equal(t, "Fixed the login bug and added a test.", summary.FinalMessage)
+	assert.True(t, summary.Finished)
+	assert.False(t, summary.Failed)
+}
+
+func TestRecorder_Codex(t *testing.T) {
+	events := recordFixture(t, "codex")
+
+	summary := Summarize(events)
+	assert.Equal(t, map[string]int{"shell": 2, "apply_patch": 1}, summary.ToolCalls)
+	assert.Equal(t, 1, summary.ToolErrors, "non-zero exit code is a tool error")
+	assert.Equal(t, []string{"/workspace/src/auth/login.go", "/workspace/src/auth/login_test.go"}, summary.FilesEdited)
+	assert.Equal(t, "Fixed the login bug and added a test.", summary.FinalMessage)
+	assert.True(t, summary.Finished)
+
+	assert.Equal(t, "go.mod\nsrc\n", events[2].Text)
+	assert.JSONEq(t, `{"command":"bash -lc ls"}`, string(events[1].Input))
+}
+
+func TestRecorder_Gemini(t *testing.T) {
+	events := recordFixture(t, "gemini")
+
+	var messages []string
+	for _, e := range events {
+		if e.Kind == KindMessage {
+			messages = append(messages, e.Text)
+		}
+	}
+	assert.Equal(t, []string{"Let me read the handler.", "Fixed the login bug."}, messages, "deltas are joined and user messages dropped")
+
+	summary := Summarize(events)
+	assert.Equal(t, "gemini-2.5-pro", summary.Model)
+	assert.Equal(t, map[string]int{"read_file": 1, "replace": 1, "write_file": 1}, summary.ToolCalls)
+	assert.Equal(t, 1, summary.ToolErrors)
+	assert.Equal(t, []string{"/workspace/src/auth/login.go"}, summary.FilesEdited)
+	assert.Equal(t, "Fixed the login bug.", summary.FinalMessage)
+	assert.True(t, summary.Finished)
+}
+
+func TestRecorder(t *testing.T) {
+	t.Run("flushes messages at end of stream", func(t *testing.T) {
+		events := record(t, "gemini", `{"type":"message","role":"assistant","content":"partial","delta":true}`+"\r\n")
+
+		assert.Equal(t, []Event{{Kind: KindMessage, Text: "partial"}}, events)
+
+		summary := Summarize(events)
+		assert.False(t, summary.Finished)
+		assert.Equal(t, "partial", summary.FinalMessage)
+	})
+
+	t.Run("skips non-JSON and malformed lines", func(t *testing.T) {
+		events := record(t, "claude", "Error: container not running\r\n{\"type\":\r\n{not json}\r\n")
+
+		assert.Empty(t, events)
+	})
+
+	t.Run("strips escape sequences around events", func(t *testing.T) {
+		events := record(t, "claude", "\x1b[?25l\x1b]0;title\x07"+`{"type":"result","is_error":false,"result":"done"}`+"\x1b[K\r\n")
+
+		assert.Equal(t, []Event{{Kind: KindResult, Text: "done"}}, events)
+	})
+
+	t.Run("reports failed result", func(t *testing.T) {
+		events := record(t, "claude", `{"type":"result","subtype":"error_max_turns","is_error":true,"result":""}`)
+
+		summary := Summarize(events)
+		assert.True(t, summary.Failed)
+	})
+
+	t.Run("records events as lines complete", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "transcript.jsonl")
+		r, err := NewRecorder(path, "claude")
+		require.NoError(t, err)
+		defer r.Close()
+
+		line := `{"type":"result","is_error":false,"result":"done"}` + "\n"
+		_, err = r.Write([]byte(line[:10]))
+		require.NoError(t, err)
+		events, err := Read(path)
+		require.NoError(t, err)
+		assert.Empty(t, events, "partial lines are buffered")
+
+		_, err = r.Write([]byte(line[10:]))
+		require.NoError(t, err)
+		events, err = Read(path)
+		require.NoError(t, err)
+		assert.Equal(t, []Event{{Kind: KindResult, Text: "done"}}, events)
+	})
+
+	t.Run("appends to an existing transcript", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "transcript.jsonl")
+		for range 2 {
+			r, err := NewRecorder(path, "claude")
+			require.NoError(t, err)
+			_, err = r.Write([]byte(`{"type":"result","result":"done"}` + "\n"))
+			require.NoError(t, err)
+			require.NoError(t, r.Close())
+		}
+
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+		events, err := Read(path)
+		require.NoError(t, err)
+		assert.Len(t, events, 2)
+	})
+
+	t.Run("rejects unknown format", func(t *testing.T) {
+		_, err := NewRecorder(filepath.Join(t.TempDir(), "transcript.jsonl"), "shell")
+		assert.ErrorIs(t, err, ErrUnsupportedFormat)
+	})
+}
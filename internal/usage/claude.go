@@ -0,0 +1,59 @@
+package usage
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/jmgilman/headjack/internal/meter"
+)
+
+// claudeLine is a line of a Claude Code project file.
+type claudeLine struct {
+	Type      string    `json:"type"`
+	Timestamp time.Time `json:"timestamp"`
+	CostUSD   float64   `json:"costUSD"` // Written by older Claude Code versions
+	Message   struct {
+		ID    string `json:"id"`
+		Usage *struct {
+			InputTokens              int64 `json:"input_tokens"`
+			OutputTokens             int64 `json:"output_tokens"`
+			CacheReadInputTokens     int64 `json:"cache_read_input_tokens"`
+			CacheCreationInputTokens int64 `json:"cache_creation_input_tokens"`
+		} `json:"usage"`
+	} `json:"message"`
+}
+
+// parseClaude reads a Claude Code project file. Claude Code writes one line
+// per content block of a response, each repeating the response's usage, so
+// usage is counted once per message ID.
+func parseClaude(data []byte) (time.Time, meter.Usage) {
+	var start time.Time
+	var total meter.Usage
+	seen := make(map[string]bool)
+	eachLine(data, func(line []byte) {
+		var l claudeLine
+		if err := json.Unmarshal(line, &l); err != nil {
+			return
+		}
+		if start.IsZero() && !l.Timestamp.IsZero() {
+			start = l.Timestamp
+		}
+		if l.Type != "assistant" || l.Message.Usage == nil {
+			return
+		}
+		if l.Message.ID != "" {
+			if seen[l.Message.ID] {
+				return
+			}
+			seen[l.Message.ID] = true
+		}
+		total.Add(meter.Usage{
+			InputTokens:      l.Message.Usage.InputTokens,
+			OutputTokens:     l.Message.Usage.OutputTokens,
+			CacheReadTokens:  l.Message.Usage.CacheReadInputTokens,
+			CacheWriteTokens: l.Message.Usage.CacheCreationInputTokens,
+			CostUSD:          l.CostUSD,
+		})
+	})
+	return start, total
+}
@@ -0,0 +1,51 @@
+package usage
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/jmgilman/headjack/internal/meter"
+)
+
+// codexLine is a line of a Codex rollout file.
+type codexLine struct {
+	Timestamp time.Time `json:"timestamp"`
+	Type      string    `json:"type"`
+	Payload   struct {
+		Type string `json:"type"`
+		Info *struct {
+			TotalTokenUsage struct {
+				InputTokens       int64 `json:"input_tokens"`
+				CachedInputTokens int64 `json:"cached_input_tokens"`
+				OutputTokens      int64 `json:"output_tokens"`
+			} `json:"total_token_usage"`
+		} `json:"info"`
+	} `json:"payload"`
+}
+
+// parseCodex reads a Codex rollout file. Codex periodically records the
+// session's running token totals, so the last total wins. Its input count
+// includes cached tokens.
+func parseCodex(data []byte) (time.Time, meter.Usage) {
+	var start time.Time
+	var total meter.Usage
+	eachLine(data, func(line []byte) {
+		var l codexLine
+		if err := json.Unmarshal(line, &l); err != nil {
+			return
+		}
+		if start.IsZero() && !l.Timestamp.IsZero() {
+			start = l.Timestamp
+		}
+		if l.Type != "event_msg" || l.Payload.Type != "token_count" || l.Payload.Info == nil {
+			return
+		}
+		t := l.Payload.Info.TotalTokenUsage
+		total = meter.Usage{
+			InputTokens:     t.InputTokens - t.CachedInputTokens,
+			OutputTokens:    t.OutputTokens,
+			CacheReadTokens: t.CachedInputTokens,
+		}
+	})
+	return start, total
+}
@@ -0,0 +1,45 @@
+package usage
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/jmgilman/headjack/internal/meter"
+)
+
+// geminiChat is a Gemini CLI chat recording.
+type geminiChat struct {
+	StartTime time.Time `json:"startTime"`
+	Messages  []struct {
+		Type   string `json:"type"`
+		Tokens *struct {
+			Input    int64 `json:"input"`
+			Output   int64 `json:"output"`
+			Cached   int64 `json:"cached"`
+			Thoughts int64 `json:"thoughts"`
+		} `json:"tokens"`
+	} `json:"messages"`
+}
+
+// parseGemini reads a Gemini CLI chat recording, a single JSON document
+// rewritten after each message. Its input count includes cached tokens, and
+// thinking tokens are counted as output.
+func parseGemini(data []byte) (time.Time, meter.Usage) {
+	var chat geminiChat
+	if err := json.Unmarshal(data, &chat); err != nil {
+		return time.Time{}, meter.Usage{}
+	}
+
+	var total meter.Usage
+	for _, m := range chat.Messages {
+		if m.Type != "gemini" || m.Tokens == nil {
+			continue
+		}
+		total.Add(meter.Usage{
+			InputTokens:     m.Tokens.Input - m.Tokens.Cached,
+			OutputTokens:    m.Tokens.Output + m.Tokens.Thoughts,
+			CacheReadTokens: m.Tokens.Cached,
+		})
+	}
+	return chat.StartTime, total
+}
@@ -0,0 +1,142 @@
+package usage
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/jmgilman/headjack/internal/meter"
+)
+
+const (
+	fileMode = 0o600
+	dirMode  = 0o750
+)
+
+// Record is the final usage of a session that has ended.
+type Record struct {
+	Time       time.Time   `json:"time"` // When the session ended
+	InstanceID string      `json:"instance_id"`
+	Repo       string      `json:"repo"`
+	Branch     string      `json:"branch"`
+	SessionID  string      `json:"session_id"`
+	Session    string      `json:"session"` // Session name
+	Agent      string      `json:"agent"`   // Session type
+	Started    time.Time   `json:"started"` // When the session was created
+	Usage      meter.Usage `json:"usage"`
+}
+
+// Filter narrows ledger queries.
+type Filter struct {
+	Since time.Time // Only sessions that ended at or after this time (zero = all)
+}
+
+// Recorder records the usage of ended sessions.
+//
+//go:generate go run github.com/matryer/moq@latest -pkg mocks -out mocks/recorder.go . Recorder
+type Recorder interface {
+	// Record appends a session's final usage to the ledger.
+	Record(ctx context.Context, record *Record) error
+
+	// Query returns the records matching the filter in the order they were recorded.
+	Query(filter Filter) ([]Record, error)
+}
+
+// Ledger is a file-backed Recorder that appends JSON lines.
+type Ledger struct {
+	path string
+	now  func() time.Time
+}
+
+// NewLedger creates a Ledger writing to the given path.
+// The file and its parent directory are created on first write.
+func NewLedger(path string) *Ledger {
+	return &Ledger{path: path, now: time.Now}
+}
+
+// Record appends a record to the ledger, filling in Time when empty.
+func (l *Ledger) Record(ctx context.Context, record *Record) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r := *record
+	if r.Time.IsZero() {
+		r.Time = l.now()
+	}
+	r.Time = r.Time.UTC()
+
+	data, err := json.Marshal(&r)
+	if err != nil {
+		return fmt.Errorf("marshal usage record: %w", err)
+	}
+	data = append(data, '\n')
+
+	if err := os.MkdirAll(filepath.Dir(l.path), dirMode); err != nil {
+		return fmt.Errorf("create usage directory: %w", err)
+	}
+
+	file, err := os.OpenFile(l.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, fileMode)
+	if err != nil {
+		return fmt.Errorf("open usage ledger: %w", err)
+	}
+	defer file.Close()
+
+	// Serialize concurrent writers from separate hjk processes
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX); err != nil {
+		return fmt.Errorf("lock usage ledger: %w", err)
+	}
+	//nolint:errcheck // Unlock errors are not actionable; closing releases the lock anyway
+	defer syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+
+	if _, err := file.Write(data); err != nil {
+		return fmt.Errorf("write usage record: %w", err)
+	}
+
+	return nil
+}
+
+// Query returns all records matching the filter in the order they were recorded.
+// A missing ledger yields no records.
+func (l *Ledger) Query(filter Filter) ([]Record, error) {
+	file, err := os.Open(l.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("open usage ledger: %w", err)
+	}
+	defer file.Close()
+
+	var records []Record
+	scanner := bufio.NewScanner(file)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var r Record
+		if err := json.Unmarshal(line, &r); err != nil {
+			return nil, fmt.Errorf("parse usage ledger line %d: %w", lineNum, err)
+		}
+
+		if !filter.Since.IsZero() && r.Time.Before(filter.Since) {
+			continue
+		}
+		records = append(records, r)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("scan usage ledger: %w", err)
+	}
+
+	return records, nil
+}
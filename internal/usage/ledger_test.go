@@ -0,0 +1,79 @@
+package usage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmgilman/headjack/internal/meter"
+)
+
+func TestLedger_RecordAndQuery(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("round-trips records", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "nested", "usage.jsonl")
+		ledger := NewLedger(path)
+
+		record := &Record{
+			InstanceID: "abc12345",
+			Repo:       "/src/app",
+			Branch:     "feat/auth",
+			SessionID:  "sess1",
+			Session:    "happy-panda",
+			Agent:      "claude",
+			Started:    time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC),
+			Usage:      meter.Usage{InputTokens: 10, OutputTokens: 20, CostUSD: 0.01},
+		}
+		require.NoError(t, ledger.Record(ctx, record))
+
+		records, err := ledger.Query(Filter{})
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		assert.False(t, records[0].Time.IsZero(), "Time should be filled in")
+		assert.Equal(t, "happy-panda", records[0].Session)
+		assert.Equal(t, record.Usage, records[0].Usage)
+		assert.True(t, record.Time.IsZero(), "caller's record should not be modified")
+
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+	})
+
+	t.Run("filters by end time", func(t *testing.T) {
+		ledger := NewLedger(filepath.Join(t.TempDir(), "usage.jsonl"))
+		base := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+
+		require.NoError(t, ledger.Record(ctx, &Record{Time: base, Session: "old"}))
+		require.NoError(t, ledger.Record(ctx, &Record{Time: base.Add(2 * time.Hour), Session: "new"}))
+
+		records, err := ledger.Query(Filter{Since: base.Add(time.Hour)})
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		assert.Equal(t, "new", records[0].Session)
+	})
+
+	t.Run("missing ledger yields no records", func(t *testing.T) {
+		ledger := NewLedger(filepath.Join(t.TempDir(), "missing.jsonl"))
+
+		records, err := ledger.Query(Filter{})
+
+		require.NoError(t, err)
+		assert.Empty(t, records)
+	})
+
+	t.Run("reports corrupt lines", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "usage.jsonl")
+		require.NoError(t, os.WriteFile(path, []byte("{not json}\n"), 0o600))
+
+		_, err := NewLedger(path).Query(Filter{})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "line 1")
+	})
+}
@@ -0,0 +1,112 @@
+// Package usage extracts token and cost usage from the session files agents
+// write inside a container, and keeps a ledger of usage from ended sessions.
+//
+// Agents record their conversations as files under their home directory:
+// Claude Code under ~/.claude/projects, Codex under ~/.codex/sessions, and
+// Gemini CLI under ~/.gemini/tmp. An agent's definition names where its files
+// are and which of these formats they use.
+package usage
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/jmgilman/headjack/internal/agent"
+	"github.com/jmgilman/headjack/internal/meter"
+)
+
+// Sentinel errors for usage accounting.
+var (
+	ErrUnsupportedAgent  = errors.New("usage accounting not supported for agent")
+	ErrUnsupportedFormat = errors.New("unknown usage file format")
+)
+
+// fileMarker starts the header line naming each file in a dump. It is a
+// control character that never appears unescaped in JSON.
+const fileMarker = '\x1e'
+
+// File is the usage recorded in one agent session file.
+type File struct {
+	Path  string    // Path of the file inside the container
+	Start time.Time // When the agent session began (zero if unknown)
+	Usage meter.Usage
+}
+
+// parsers read the start and usage of a session file in each format.
+var parsers = map[string]func(data []byte) (time.Time, meter.Usage){
+	"claude": parseClaude,
+	"codex":  parseCodex,
+	"gemini": parseGemini,
+}
+
+// Supported reports whether an agent's usage can be read.
+func Supported(def *agent.Definition) bool {
+	_, ok := parsers[def.UsageFormat]
+	return ok && def.UsageFiles != ""
+}
+
+// DumpCommand returns a command that, run inside a container, writes every
+// session file of the agent to stdout in the format ParseDump reads.
+func DumpCommand(def *agent.Definition) ([]string, error) {
+	if !Supported(def) {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedAgent, def.Name)
+	}
+	script := fmt.Sprintf(`for f in "$HOME"/%s; do [ -f "$f" ] || continue; printf '\036%%s\n' "$f"; cat "$f"; echo; done`, def.UsageFiles)
+	return []string{"sh", "-c", script}, nil
+}
+
+// ParseDump reads the output of DumpCommand and returns the usage recorded in
+// each file, which are in the given format. Lines that cannot be parsed, such
+// as a line the agent is still writing, are skipped.
+func ParseDump(r io.Reader, format string) ([]File, error) {
+	parse, ok := parsers[format]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+	}
+
+	var files []File
+	var path string
+	var content bytes.Buffer
+	flush := func() {
+		if path != "" {
+			start, u := parse(content.Bytes())
+			files = append(files, File{Path: path, Start: start, Usage: u})
+		}
+		content.Reset()
+	}
+
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadBytes('\n')
+		if len(line) > 0 && line[0] == fileMarker {
+			flush()
+			path = string(bytes.TrimRight(line[1:], "\r\n"))
+		} else {
+			content.Write(line)
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read usage dump: %w", err)
+		}
+	}
+	flush()
+
+	return files, nil
+}
+
+// eachLine calls fn for each non-empty line of JSON lines data.
+func eachLine(data []byte, fn func(line []byte)) {
+	for len(data) > 0 {
+		var line []byte
+		line, data, _ = bytes.Cut(data, []byte{'\n'})
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			fn(line)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package usage
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmgilman/headjack/internal/agent"
+	"github.com/jmgilman/headjack/internal/meter"
+)
+
+// dumpFixtures runs an agent's dump command against a home directory holding
+// the given fixture files and parses the result.
+func dumpFixtures(t *testing.T, agentName string, files map[string]string) []File {
+	t.Helper()
+
+	home := t.TempDir()
+	for rel, fixture := range files {
+		data, err := os.ReadFile(filepath.Join("testdata", fixture))
+		require.NoError(t, err)
+		path := filepath.Join(home, rel)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+		require.NoError(t, os.WriteFile(path, data, 0o600))
+	}
+
+	def, err := agent.DefaultRegistry().Get(agentName)
+	require.NoError(t, err)
+	command, err := DumpCommand(def)
+	require.NoError(t, err)
+
+	//nolint:gosec // G204: runs the dump script under test
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Env = append(os.Environ(), "HOME="+home)
+	out, err := cmd.Output()
+	require.NoError(t, err)
+
+	parsed, err := ParseDump(bytes.NewReader(out), def.UsageFormat)
+	require.NoError(t, err)
+	return parsed
+}
+
+func TestParseDump_Claude(t *testing.T) {
+	files := dumpFixtures(t, "claude", map[string]string{
+		".claude/projects/-workspace/3f1c.jsonl": "claude.jsonl",
+	})
+
+	require.Len(t, files, 1)
+	assert.True(t, strings.HasSuffix(files[0].Path, ".claude/projects/-workspace/3f1c.jsonl"))
+	assert.Equal(t, time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC), files[0].Start)
+	// msg_01 is written twice but counted once
+	assert.Equal(t, meter.Usage{InputTokens: 20, OutputTokens: 100, CacheReadTokens: 1500, CacheWriteTokens: 1700}, files[0].Usage)
+}
+
+func TestParseDump_Codex(t *testing.T) {
+	files := dumpFixtures(t, "codex", map[string]string{
+		".codex/sessions/2026/01/02/rollout-2026-01-02T11-00-00-0199.jsonl": "codex.jsonl",
+	})
+
+	require.Len(t, files, 1)
+	assert.Equal(t, time.Date(2026, 1, 2, 11, 0, 0, 0, time.UTC), files[0].Start)
+	// The last running total wins
+	assert.Equal(t, meter.Usage{InputTokens: 3000, OutputTokens: 500, CacheReadTokens: 4000}, files[0].Usage)
+}
+
+func TestParseDump_Gemini(t *testing.T) {
+	files := dumpFixtures(t, "gemini", map[string]string{
+		".gemini/tmp/9a3b/chats/session-2026-01-02T12-00-c0ffee.json": "gemini.json",
+	})
+
+	require.Len(t, files, 1)
+	assert.Equal(t, time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC), files[0].Start)
+	assert.Equal(t, meter.Usage{InputTokens: 7000, OutputTokens: 350, CacheReadTokens: 4000}, files[0].Usage)
+}
+
+func TestParseDump(t *testing.T) {
+	t.Run("separates multiple files", func(t *testing.T) {
+		files := dumpFixtures(t, "claude", map[string]string{
+			".claude/projects/-workspace/a.jsonl": "claude.jsonl",
+			".claude/projects/-other/b.jsonl":     "claude.jsonl",
+		})
+
+		require.Len(t, files, 2)
+		assert.Equal(t, files[0].Usage, files[1].Usage)
+		assert.NotEqual(t, files[0].Path, files[1].Path)
+	})
+
+	t.Run("yields nothing when the agent has no files", func(t *testing.T) {
+		files := dumpFixtures(t, "codex", nil)
+
+		assert.Empty(t, files)
+	})
+
+	t.Run("skips a partially written line", func(t *testing.T) {
+		dump := "\x1e/home/dev/.claude/projects/-workspace/x.jsonl\n" +
+			`{"type":"assistant","timestamp":"2026-01-02T10:00:00Z","message":{"id":"m1","usage":{"input_tokens":5,"output_tokens":7}}}` + "\n" +
+			`{"type":"assistant","timestamp":"2026-01-02T10:00:01Z","message":{"id":"m2","usa` + "\n"
+
+		files, err := ParseDump(strings.NewReader(dump), "claude")
+
+		require.NoError(t, err)
+		require.Len(t, files, 1)
+		assert.Equal(t, "/home/dev/.claude/projects/-workspace/x.jsonl", files[0].Path)
+		assert.Equal(t, meter.Usage{InputTokens: 5, OutputTokens: 7}, files[0].Usage)
+	})
+
+	t.Run("rejects unsupported agents", func(t *testing.T) {
+		_, err := ParseDump(strings.NewReader(""), "aider")
+		assert.ErrorIs(t, err, ErrUnsupportedFormat)
+
+		def := &agent.Definition{Name: "aider", Command: []string{"aider"}}
+		assert.False(t, Supported(def))
+		_, err = DumpCommand(def)
+		assert.ErrorIs(t, err, ErrUnsupportedAgent)
+	})
+}
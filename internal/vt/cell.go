@@ -0,0 +1,214 @@
+package vt
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Color is a cell color: ColorDefault, a 256-color palette index (0-255), or
+// a 24-bit RGB value created with RGB.
+type Color int32
+
+// ColorDefault is the terminal's default foreground or background color.
+const ColorDefault Color = 0
+
+// Colors are stored offset so that the zero value is the default color.
+const (
+	paletteBase Color = 1
+	rgbFlag     Color = 1 << 24
+)
+
+// Palette returns the color at index i of the 256-color palette.
+func Palette(i uint8) Color {
+	return paletteBase + Color(i)
+}
+
+// RGB returns a 24-bit color.
+func RGB(r, g, b uint8) Color {
+	return rgbFlag | Color(r)<<16 | Color(g)<<8 | Color(b)
+}
+
+// Index returns the palette index and true for palette colors.
+func (c Color) Index() (uint8, bool) {
+	if c == ColorDefault || c&rgbFlag != 0 {
+		return 0, false
+	}
+	return uint8(c - paletteBase), true //nolint:gosec // G115: palette colors are offset indexes 0-255
+}
+
+// RGB returns the red, green, and blue components and true for 24-bit colors.
+func (c Color) RGB() (r, g, b uint8, ok bool) {
+	if c&rgbFlag == 0 {
+		return 0, 0, 0, false
+	}
+	return uint8(c >> 16), uint8(c >> 8), uint8(c), true //nolint:gosec // G115: truncation extracts components
+}
+
+// Text attribute flags.
+const (
+	AttrBold uint8 = 1 << iota
+	AttrDim
+	AttrItalic
+	AttrUnderline
+	AttrBlink
+	AttrReverse
+	AttrHidden
+	AttrStrike
+)
+
+// Attr holds a cell's colors and text attributes.
+type Attr struct {
+	Fg    Color
+	Bg    Color
+	Flags uint8
+}
+
+// Cell is a single character cell on the screen.
+type Cell struct {
+	Rune rune // Zero for a cell that was never written
+	Attr Attr
+}
+
+// char returns the rune to display, rendering unwritten cells as spaces.
+func (c Cell) char() rune {
+	if c.Rune == 0 {
+		return ' '
+	}
+	return c.Rune
+}
+
+// blank reports whether the cell shows nothing, ignoring foreground attributes.
+func (c Cell) blank() bool {
+	return (c.Rune == 0 || c.Rune == ' ') && c.Attr.Bg == ColorDefault && c.Attr.Flags&AttrReverse == 0
+}
+
+// sgr returns the escape sequence that sets exactly this attribute.
+func (a Attr) sgr() string {
+	codes := []string{"0"}
+
+	flagCodes := []struct {
+		flag uint8
+		code string
+	}{
+		{AttrBold, "1"}, {AttrDim, "2"}, {AttrItalic, "3"}, {AttrUnderline, "4"},
+		{AttrBlink, "5"}, {AttrReverse, "7"}, {AttrHidden, "8"}, {AttrStrike, "9"},
+	}
+	for _, fc := range flagCodes {
+		if a.Flags&fc.flag != 0 {
+			codes = append(codes, fc.code)
+		}
+	}
+
+	codes = append(codes, colorCodes(a.Fg, 30, 90, "38")...)
+	codes = append(codes, colorCodes(a.Bg, 40, 100, "48")...)
+
+	return "\x1b[" + strings.Join(codes, ";") + "m"
+}
+
+// colorCodes returns the SGR parameters selecting a color.
+func colorCodes(c Color, base, brightBase int, extended string) []string {
+	if i, ok := c.Index(); ok {
+		switch {
+		case i < 8:
+			return []string{strconv.Itoa(base + int(i))}
+		case i < 16:
+			return []string{strconv.Itoa(brightBase + int(i) - 8)}
+		default:
+			return []string{extended, "5", strconv.Itoa(int(i))}
+		}
+	}
+	if r, g, b, ok := c.RGB(); ok {
+		return []string{extended, "2", strconv.Itoa(int(r)), strconv.Itoa(int(g)), strconv.Itoa(int(b))}
+	}
+	return nil
+}
+
+// applySGR applies Select Graphic Rendition parameters to an attribute.
+func applySGR(a Attr, params []int) Attr {
+	if len(params) == 0 {
+		return Attr{}
+	}
+
+	for i := 0; i < len(params); i++ {
+		p := params[i]
+		switch {
+		case p == 0:
+			a = Attr{}
+		case p == 1:
+			a.Flags |= AttrBold
+		case p == 2:
+			a.Flags |= AttrDim
+		case p == 3:
+			a.Flags |= AttrItalic
+		case p == 4:
+			a.Flags |= AttrUnderline
+		case p == 5 || p == 6:
+			a.Flags |= AttrBlink
+		case p == 7:
+			a.Flags |= AttrReverse
+		case p == 8:
+			a.Flags |= AttrHidden
+		case p == 9:
+			a.Flags |= AttrStrike
+		case p == 21 || p == 22:
+			a.Flags &^= AttrBold | AttrDim
+		case p == 23:
+			a.Flags &^= AttrItalic
+		case p == 24:
+			a.Flags &^= AttrUnderline
+		case p == 25:
+			a.Flags &^= AttrBlink
+		case p == 27:
+			a.Flags &^= AttrReverse
+		case p == 28:
+			a.Flags &^= AttrHidden
+		case p == 29:
+			a.Flags &^= AttrStrike
+		case p >= 30 && p <= 37:
+			a.Fg = Palette(uint8(p - 30)) //nolint:gosec // G115: p is in range
+		case p == 38:
+			var c Color
+			c, i = extendedColor(params, i)
+			a.Fg = c
+		case p == 39:
+			a.Fg = ColorDefault
+		case p >= 40 && p <= 47:
+			a.Bg = Palette(uint8(p - 40)) //nolint:gosec // G115: p is in range
+		case p == 48:
+			var c Color
+			c, i = extendedColor(params, i)
+			a.Bg = c
+		case p == 49:
+			a.Bg = ColorDefault
+		case p >= 90 && p <= 97:
+			a.Fg = Palette(uint8(p - 90 + 8)) //nolint:gosec // G115: p is in range
+		case p >= 100 && p <= 107:
+			a.Bg = Palette(uint8(p - 100 + 8)) //nolint:gosec // G115: p is in range
+		}
+	}
+	return a
+}
+
+// extendedColor parses a 38/48 color starting at params[i] and returns the
+// color and the index of the last parameter consumed.
+func extendedColor(params []int, i int) (Color, int) {
+	if i+1 >= len(params) {
+		return ColorDefault, i
+	}
+	switch params[i+1] {
+	case 5:
+		if i+2 < len(params) {
+			return Palette(uint8(clamp(params[i+2], 0, 255))), i + 2 //nolint:gosec // G115: clamped to uint8 range
+		}
+	case 2:
+		if i+4 < len(params) {
+			c := RGB(component(params[i+2]), component(params[i+3]), component(params[i+4]))
+			return c, i + 4
+		}
+	}
+	return ColorDefault, len(params)
+}
+
+func component(v int) uint8 {
+	return uint8(clamp(v, 0, 255)) //nolint:gosec // G115: clamped to uint8 range
+}
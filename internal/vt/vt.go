@@ -0,0 +1,705 @@
+// Package vt implements a virtual terminal screen.
+//
+// A Screen interprets the byte stream a program writes to its terminal (text,
+// control characters, and VT100/xterm escape sequences) and maintains the
+// resulting grid of character cells, like a terminal emulator that never
+// draws. This turns output full of cursor movement and redraws, such as that
+// of full-screen TUIs, back into the text a user would have seen.
+//
+// The emulation covers what interactive programs commonly use: cursor
+// movement, erasing, insert and delete, scroll regions, the alternate screen,
+// and SGR text attributes. Everything else is parsed and ignored.
+package vt
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// tabWidth is the distance between tab stops.
+const tabWidth = 8
+
+// Parser states.
+const (
+	stateGround = iota
+	stateEscape
+	stateCharset
+	stateCSI
+	stateString
+	stateStringEscape
+)
+
+// Screen is a virtual terminal screen.
+type Screen struct {
+	rows, cols   int
+	historyLimit int
+
+	main    [][]Cell
+	alt     [][]Cell
+	grid    [][]Cell // main or alt, whichever is active
+	history [][]Cell // Lines scrolled off the top of the main screen
+
+	cx, cy   int
+	pen      Attr
+	wrapNext bool // Cursor is past the last column; the next rune wraps
+	autowrap bool
+
+	scrollTop, scrollBottom int
+
+	saved      cursor
+	mainSaved  cursor // Main screen cursor while the alt screen is active
+	altActive  bool
+	partial    []byte // Incomplete UTF-8 sequence from the previous write
+	state      int
+	params     []byte
+	privateSeq bool
+}
+
+// cursor is a saved cursor position and pen.
+type cursor struct {
+	x, y int
+	pen  Attr
+}
+
+// clamp returns the cursor moved onto a screen of the given size.
+func (c cursor) clamp(rows, cols int) cursor {
+	c.x = min(c.x, cols-1)
+	c.y = min(c.y, rows-1)
+	return c
+}
+
+// New creates a screen of the given size that keeps up to historyLimit lines
+// of scrollback. A negative historyLimit keeps unlimited scrollback.
+func New(rows, cols, historyLimit int) *Screen {
+	rows = max(rows, 1)
+	cols = max(cols, 1)
+
+	s := &Screen{
+		rows:         rows,
+		cols:         cols,
+		historyLimit: historyLimit,
+		autowrap:     true,
+		scrollBottom: rows - 1,
+	}
+	s.main = newGrid(rows, cols)
+	s.grid = s.main
+	return s
+}
+
+// Size returns the screen size in rows and columns.
+func (s *Screen) Size() (rows, cols int) {
+	return s.rows, s.cols
+}
+
+// Resize changes the screen size. Content is truncated or padded rather than
+// reflowed, and lines pushed off the top are moved to the scrollback.
+func (s *Screen) Resize(rows, cols int) {
+	rows = max(rows, 1)
+	cols = max(cols, 1)
+	if rows == s.rows && cols == s.cols {
+		return
+	}
+
+	// Keep the cursor on screen by scrolling lines above it into history
+	if excess := s.cy - (rows - 1); excess > 0 {
+		if !s.altActive {
+			for _, line := range s.main[:excess] {
+				s.pushHistory(line)
+			}
+		}
+		s.grid = s.grid[excess:]
+		s.cy -= excess
+	}
+
+	s.main = resizeGrid(s.activeOr(s.main, false), rows, cols)
+	if s.alt != nil {
+		s.alt = resizeGrid(s.activeOr(s.alt, true), rows, cols)
+	}
+	if s.altActive {
+		s.grid = s.alt
+	} else {
+		s.grid = s.main
+	}
+
+	s.rows, s.cols = rows, cols
+	s.scrollTop, s.scrollBottom = 0, rows-1
+	s.cx = min(s.cx, cols-1)
+	s.cy = min(s.cy, rows-1)
+	s.saved = s.saved.clamp(rows, cols)
+	s.mainSaved = s.mainSaved.clamp(rows, cols)
+	s.wrapNext = false
+}
+
+// activeOr returns the active grid if it is the requested buffer, otherwise g.
+func (s *Screen) activeOr(g [][]Cell, alt bool) [][]Cell {
+	if s.altActive == alt {
+		return s.grid
+	}
+	return g
+}
+
+// Write interprets p as terminal output. It never returns an error.
+func (s *Screen) Write(p []byte) (int, error) {
+	n := len(p)
+	if len(s.partial) > 0 {
+		p = append(s.partial, p...)
+		s.partial = nil
+	}
+
+	for len(p) > 0 {
+		b := p[0]
+		if b < utf8.RuneSelf || s.state != stateGround {
+			s.handleByte(b)
+			p = p[1:]
+			continue
+		}
+
+		if !utf8.FullRune(p) {
+			s.partial = append([]byte(nil), p...)
+			break
+		}
+		r, size := utf8.DecodeRune(p)
+		s.put(r)
+		p = p[size:]
+	}
+
+	return n, nil
+}
+
+// handleByte advances the parser by a single byte.
+func (s *Screen) handleByte(b byte) {
+	switch s.state {
+	case stateGround:
+		s.ground(b)
+	case stateEscape:
+		s.escape(b)
+	case stateCharset:
+		// Character set designations are ignored; consume the set byte
+		s.state = stateGround
+	case stateCSI:
+		s.csiByte(b)
+	case stateString:
+		switch b {
+		case 0x07:
+			s.state = stateGround
+		case 0x1b:
+			s.state = stateStringEscape
+		}
+	case stateStringEscape:
+		// ESC \ terminates the string; anything else starts a new escape
+		if b == '\\' {
+			s.state = stateGround
+		} else {
+			s.state = stateEscape
+			s.escape(b)
+		}
+	}
+}
+
+// ground handles a byte outside of any escape sequence.
+func (s *Screen) ground(b byte) {
+	switch b {
+	case 0x1b:
+		s.state = stateEscape
+	case '\r':
+		s.cx = 0
+		s.wrapNext = false
+	case '\n', 0x0b, 0x0c:
+		s.lineFeed()
+	case '\b':
+		if s.cx > 0 {
+			s.cx--
+		}
+		s.wrapNext = false
+	case '\t':
+		s.cx = min((s.cx/tabWidth+1)*tabWidth, s.cols-1)
+		s.wrapNext = false
+	default:
+		if b >= 0x20 && b != 0x7f {
+			s.put(rune(b))
+		}
+	}
+}
+
+// escape handles the byte following ESC.
+func (s *Screen) escape(b byte) {
+	s.state = stateGround
+
+	switch b {
+	case '[':
+		s.state = stateCSI
+		s.params = s.params[:0]
+		s.privateSeq = false
+	case ']', 'P', '_', '^', 'X':
+		// OSC, DCS, APC, PM, and SOS strings carry nothing visible
+		s.state = stateString
+	case '(', ')', '*', '+':
+		s.state = stateCharset
+	case '7':
+		s.saved = cursor{x: s.cx, y: s.cy, pen: s.pen}
+	case '8':
+		s.restoreCursor(s.saved)
+	case 'D':
+		s.lineFeed()
+	case 'E':
+		s.cx = 0
+		s.lineFeed()
+	case 'M':
+		s.reverseIndex()
+	case 'c':
+		s.reset()
+	}
+}
+
+// csiByte accumulates a control sequence until its final byte.
+func (s *Screen) csiByte(b byte) {
+	switch {
+	case b >= 0x40 && b <= 0x7e:
+		s.state = stateGround
+		if !s.privateSeq || b == 'h' || b == 'l' {
+			s.csi(b, parseParams(s.params))
+		}
+	case b == '?' || b == '>' || b == '<' || b == '=':
+		s.privateSeq = true
+		if b != '?' {
+			// Only DEC private modes (?) are interpreted
+			s.params = append(s.params, 0xff)
+		}
+	case b == 0x1b:
+		s.state = stateEscape
+	case b == 0x18 || b == 0x1a:
+		s.state = stateGround
+	default:
+		s.params = append(s.params, b)
+	}
+}
+
+// csi executes a complete control sequence.
+func (s *Screen) csi(final byte, params []int) {
+	arg := func(i, def int) int {
+		if i < len(params) && params[i] > 0 {
+			return params[i]
+		}
+		return def
+	}
+
+	if s.privateSeq {
+		if len(s.params) > 0 && s.params[0] == 0xff {
+			return
+		}
+		s.setPrivateModes(params, final == 'h')
+		return
+	}
+
+	switch final {
+	case 'A':
+		s.moveTo(s.cx, max(s.cy-arg(0, 1), s.topLimit()))
+	case 'B', 'e':
+		s.moveTo(s.cx, min(s.cy+arg(0, 1), s.bottomLimit()))
+	case 'C', 'a':
+		s.moveTo(s.cx+arg(0, 1), s.cy)
+	case 'D':
+		s.moveTo(s.cx-arg(0, 1), s.cy)
+	case 'E':
+		s.moveTo(0, min(s.cy+arg(0, 1), s.bottomLimit()))
+	case 'F':
+		s.moveTo(0, max(s.cy-arg(0, 1), s.topLimit()))
+	case 'G', '`':
+		s.moveTo(arg(0, 1)-1, s.cy)
+	case 'd':
+		s.moveTo(s.cx, arg(0, 1)-1)
+	case 'H', 'f':
+		s.moveTo(arg(1, 1)-1, arg(0, 1)-1)
+	case 'J':
+		s.eraseDisplay(arg(0, 0))
+	case 'K':
+		s.eraseLine(arg(0, 0))
+	case 'L':
+		s.insertLines(arg(0, 1))
+	case 'M':
+		s.deleteLines(arg(0, 1))
+	case '@':
+		s.insertChars(arg(0, 1))
+	case 'P':
+		s.deleteChars(arg(0, 1))
+	case 'X':
+		s.eraseChars(arg(0, 1))
+	case 'S':
+		s.scrollUp(s.scrollTop, s.scrollBottom, arg(0, 1))
+	case 'T':
+		s.scrollDown(s.scrollTop, s.scrollBottom, arg(0, 1))
+	case 'r':
+		top, bottom := arg(0, 1)-1, arg(1, s.rows)-1
+		if top < bottom && bottom < s.rows {
+			s.scrollTop, s.scrollBottom = top, bottom
+			s.moveTo(0, 0)
+		}
+	case 'm':
+		s.pen = applySGR(s.pen, params)
+	case 's':
+		s.saved = cursor{x: s.cx, y: s.cy, pen: s.pen}
+	case 'u':
+		s.restoreCursor(s.saved)
+	}
+}
+
+// setPrivateModes handles DEC private mode set (h) and reset (l).
+func (s *Screen) setPrivateModes(modes []int, set bool) {
+	for _, mode := range modes {
+		switch mode {
+		case 7:
+			s.autowrap = set
+		case 47, 1047, 1049:
+			if set {
+				s.enterAltScreen(mode == 1049)
+			} else {
+				s.exitAltScreen(mode == 1049)
+			}
+		}
+	}
+}
+
+func (s *Screen) enterAltScreen(saveCursor bool) {
+	if s.altActive {
+		return
+	}
+	if saveCursor {
+		s.mainSaved = cursor{x: s.cx, y: s.cy, pen: s.pen}
+	}
+	s.alt = newGrid(s.rows, s.cols)
+	s.grid = s.alt
+	s.altActive = true
+	s.scrollTop, s.scrollBottom = 0, s.rows-1
+}
+
+func (s *Screen) exitAltScreen(restoreCursor bool) {
+	if !s.altActive {
+		return
+	}
+	s.alt = nil
+	s.grid = s.main
+	s.altActive = false
+	s.scrollTop, s.scrollBottom = 0, s.rows-1
+	if restoreCursor {
+		s.restoreCursor(s.mainSaved)
+	}
+	s.wrapNext = false
+}
+
+// restoreCursor moves the cursor to a saved position, kept on screen, and
+// restores its pen.
+func (s *Screen) restoreCursor(c cursor) {
+	c = c.clamp(s.rows, s.cols)
+	s.cx, s.cy, s.pen = c.x, c.y, c.pen
+	s.wrapNext = false
+}
+
+// reset restores the initial state, keeping the size and scrollback.
+func (s *Screen) reset() {
+	s.exitAltScreen(false)
+	s.main = newGrid(s.rows, s.cols)
+	s.grid = s.main
+	s.cx, s.cy = 0, 0
+	s.pen = Attr{}
+	s.wrapNext = false
+	s.autowrap = true
+	s.scrollTop, s.scrollBottom = 0, s.rows-1
+	s.saved = cursor{}
+}
+
+// put writes a printable rune at the cursor.
+func (s *Screen) put(r rune) {
+	if s.wrapNext {
+		s.cx = 0
+		s.lineFeed()
+	}
+
+	s.grid[s.cy][s.cx] = Cell{Rune: r, Attr: s.pen}
+
+	if s.cx < s.cols-1 {
+		s.cx++
+	} else if s.autowrap {
+		s.wrapNext = true
+	}
+}
+
+// lineFeed moves the cursor down, scrolling at the bottom of the scroll region.
+func (s *Screen) lineFeed() {
+	s.wrapNext = false
+	if s.cy == s.scrollBottom {
+		s.scrollUp(s.scrollTop, s.scrollBottom, 1)
+	} else if s.cy < s.rows-1 {
+		s.cy++
+	}
+}
+
+// reverseIndex moves the cursor up, scrolling at the top of the scroll region.
+func (s *Screen) reverseIndex() {
+	s.wrapNext = false
+	if s.cy == s.scrollTop {
+		s.scrollDown(s.scrollTop, s.scrollBottom, 1)
+	} else if s.cy > 0 {
+		s.cy--
+	}
+}
+
+// moveTo moves the cursor, clamping to the screen.
+func (s *Screen) moveTo(x, y int) {
+	s.cx = clamp(x, 0, s.cols-1)
+	s.cy = clamp(y, 0, s.rows-1)
+	s.wrapNext = false
+}
+
+// topLimit and bottomLimit bound vertical cursor movement: the scroll
+// region stops the cursor only when it starts inside the region.
+func (s *Screen) topLimit() int {
+	if s.cy >= s.scrollTop {
+		return s.scrollTop
+	}
+	return 0
+}
+
+func (s *Screen) bottomLimit() int {
+	if s.cy <= s.scrollBottom {
+		return s.scrollBottom
+	}
+	return s.rows - 1
+}
+
+// scrollUp scrolls lines top..bottom up by n. Lines leaving the top of the
+// full main screen are kept in the scrollback.
+func (s *Screen) scrollUp(top, bottom, n int) {
+	n = min(n, bottom-top+1)
+	for range n {
+		if top == 0 && !s.altActive {
+			s.pushHistory(s.grid[top])
+		}
+		copy(s.grid[top:bottom], s.grid[top+1:bottom+1])
+		s.grid[bottom] = s.blankLine()
+	}
+}
+
+// scrollDown scrolls lines top..bottom down by n.
+func (s *Screen) scrollDown(top, bottom, n int) {
+	n = min(n, bottom-top+1)
+	for range n {
+		copy(s.grid[top+1:bottom+1], s.grid[top:bottom])
+		s.grid[top] = s.blankLine()
+	}
+}
+
+func (s *Screen) pushHistory(line []Cell) {
+	if s.historyLimit == 0 {
+		return
+	}
+	s.history = append(s.history, trimLine(line))
+	if s.historyLimit > 0 && len(s.history) > s.historyLimit {
+		excess := len(s.history) - s.historyLimit
+		s.history = append(s.history[:0:0], s.history[excess:]...)
+	}
+}
+
+func (s *Screen) eraseDisplay(mode int) {
+	switch mode {
+	case 0:
+		s.eraseLine(0)
+		for y := s.cy + 1; y < s.rows; y++ {
+			s.grid[y] = s.blankLine()
+		}
+	case 1:
+		s.eraseLine(1)
+		for y := range s.cy {
+			s.grid[y] = s.blankLine()
+		}
+	case 2:
+		for y := range s.rows {
+			s.grid[y] = s.blankLine()
+		}
+	case 3:
+		s.history = nil
+	}
+}
+
+func (s *Screen) eraseLine(mode int) {
+	line := s.grid[s.cy]
+	start, end := 0, s.cols
+	switch mode {
+	case 0:
+		start = s.cx
+	case 1:
+		end = s.cx + 1
+	}
+	for x := start; x < end; x++ {
+		line[x] = s.blank()
+	}
+	s.wrapNext = false
+}
+
+func (s *Screen) insertLines(n int) {
+	if s.cy < s.scrollTop || s.cy > s.scrollBottom {
+		return
+	}
+	s.scrollDown(s.cy, s.scrollBottom, n)
+	s.cx = 0
+}
+
+func (s *Screen) deleteLines(n int) {
+	if s.cy < s.scrollTop || s.cy > s.scrollBottom {
+		return
+	}
+	n = min(n, s.scrollBottom-s.cy+1)
+	for range n {
+		copy(s.grid[s.cy:s.scrollBottom], s.grid[s.cy+1:s.scrollBottom+1])
+		s.grid[s.scrollBottom] = s.blankLine()
+	}
+	s.cx = 0
+}
+
+func (s *Screen) insertChars(n int) {
+	line := s.grid[s.cy]
+	n = min(n, s.cols-s.cx)
+	copy(line[s.cx+n:], line[s.cx:s.cols-n])
+	for x := s.cx; x < s.cx+n; x++ {
+		line[x] = s.blank()
+	}
+	s.wrapNext = false
+}
+
+func (s *Screen) deleteChars(n int) {
+	line := s.grid[s.cy]
+	n = min(n, s.cols-s.cx)
+	copy(line[s.cx:], line[s.cx+n:])
+	for x := s.cols - n; x < s.cols; x++ {
+		line[x] = s.blank()
+	}
+	s.wrapNext = false
+}
+
+func (s *Screen) eraseChars(n int) {
+	line := s.grid[s.cy]
+	for x := s.cx; x < min(s.cx+n, s.cols); x++ {
+		line[x] = s.blank()
+	}
+	s.wrapNext = false
+}
+
+// blank returns an empty cell in the current background color.
+func (s *Screen) blank() Cell {
+	return Cell{Attr: Attr{Bg: s.pen.Bg}}
+}
+
+func (s *Screen) blankLine() []Cell {
+	line := make([]Cell, s.cols)
+	if s.pen.Bg != ColorDefault {
+		for i := range line {
+			line[i] = s.blank()
+		}
+	}
+	return line
+}
+
+// Lines returns the screen content as lines of cells, preceded by the
+// scrollback when history is true. Trailing blank cells are trimmed from each
+// line, and trailing blank lines are dropped.
+func (s *Screen) Lines(history bool) [][]Cell {
+	var lines [][]Cell
+	if history {
+		lines = append(lines, s.history...)
+	}
+	for _, line := range s.grid {
+		lines = append(lines, trimLine(line))
+	}
+
+	for len(lines) > 0 && len(lines[len(lines)-1]) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// Text returns the screen content as plain text, one line per row.
+func (s *Screen) Text(history bool) string {
+	var b strings.Builder
+	for _, line := range s.Lines(history) {
+		for _, c := range line {
+			b.WriteRune(c.char())
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// ANSI returns the screen content as text with SGR escape sequences that
+// reproduce each cell's colors and attributes.
+func (s *Screen) ANSI(history bool) string {
+	var b strings.Builder
+	for _, line := range s.Lines(history) {
+		current := Attr{}
+		for _, c := range line {
+			if c.Attr != current {
+				b.WriteString(c.Attr.sgr())
+				current = c.Attr
+			}
+			b.WriteRune(c.char())
+		}
+		if current != (Attr{}) {
+			b.WriteString("\x1b[0m")
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+func newGrid(rows, cols int) [][]Cell {
+	grid := make([][]Cell, rows)
+	for i := range grid {
+		grid[i] = make([]Cell, cols)
+	}
+	return grid
+}
+
+func resizeGrid(grid [][]Cell, rows, cols int) [][]Cell {
+	resized := make([][]Cell, rows)
+	for i := range resized {
+		line := make([]Cell, cols)
+		if i < len(grid) {
+			copy(line, grid[i])
+		}
+		resized[i] = line
+	}
+	return resized
+}
+
+// trimLine returns a copy of line without trailing blank cells.
+func trimLine(line []Cell) []Cell {
+	end := len(line)
+	for end > 0 && line[end-1].blank() {
+		end--
+	}
+	return append([]Cell(nil), line[:end]...)
+}
+
+// parseParams parses semicolon- or colon-separated numeric parameters.
+// Missing parameters are returned as 0.
+func parseParams(raw []byte) []int {
+	if len(raw) == 0 {
+		return nil
+	}
+
+	params := []int{0}
+	for _, b := range raw {
+		switch {
+		case b >= '0' && b <= '9':
+			last := len(params) - 1
+			if params[last] < 1<<16 {
+				params[last] = params[last]*10 + int(b-'0')
+			}
+		case b == ';' || b == ':':
+			params = append(params, 0)
+		}
+	}
+	return params
+}
+
+func clamp(v, lo, hi int) int {
+	return max(lo, min(v, hi))
+}
@@ -0,0 +1,244 @@
+package vt
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func write(s *Screen, data string) {
+	_, _ = s.Write([]byte(data))
+}
+
+func TestScreen_Text(t *testing.T) {
+	tests := []struct {
+		name   string
+		rows   int
+		cols   int
+		input  string
+		expect string
+	}{
+		{
+			name:   "plain lines",
+			rows:   5,
+			cols:   20,
+			input:  "hello\r\nworld\r\n",
+			expect: "hello\nworld\n",
+		},
+		{
+			name:   "carriage return overwrites",
+			rows:   3,
+			cols:   20,
+			input:  "loading...\rdone      ",
+			expect: "done\n",
+		},
+		{
+			name:   "cursor positioning",
+			rows:   3,
+			cols:   10,
+			input:  "\x1b[2;3Hx\x1b[1;1Hy",
+			expect: "y\n  x\n",
+		},
+		{
+			name:   "erase line and display",
+			rows:   3,
+			cols:   10,
+			input:  "aaaa\r\nbbbb\r\ncccc\x1b[2;3H\x1b[K\x1b[J",
+			expect: "aaaa\nbb\n",
+		},
+		{
+			name:   "clear screen redraw",
+			rows:   3,
+			cols:   10,
+			input:  "old\r\nstuff\x1b[2J\x1b[Hnew",
+			expect: "new\n",
+		},
+		{
+			name:   "autowrap",
+			rows:   3,
+			cols:   4,
+			input:  "abcdef",
+			expect: "abcd\nef\n",
+		},
+		{
+			name:   "backspace and tab",
+			rows:   2,
+			cols:   20,
+			input:  "abc\bX\tY",
+			expect: "abX     Y\n",
+		},
+		{
+			name:   "insert and delete characters",
+			rows:   2,
+			cols:   10,
+			input:  "abcdef\x1b[1;3H\x1b[2P\x1b[1;2H\x1b[1@",
+			expect: "a bef\n",
+		},
+		{
+			name:   "escape strings are ignored",
+			rows:   2,
+			cols:   20,
+			input:  "\x1b]0;window title\x07a\x1b]8;;http://x\x1b\\b\x1bPq#0\x1b\\c",
+			expect: "abc\n",
+		},
+		{
+			name:   "utf-8 text",
+			rows:   2,
+			cols:   20,
+			input:  "héllo ✓",
+			expect: "héllo ✓\n",
+		},
+		{
+			name:   "alternate screen is discarded on exit",
+			rows:   3,
+			cols:   10,
+			input:  "shell$ \x1b[?1049h\x1b[Hfullscreen\x1b[?1049lls",
+			expect: "shell$ ls\n",
+		},
+		{
+			name:   "scroll region",
+			rows:   4,
+			cols:   10,
+			input:  "header\x1b[2;3r\x1b[2;1Ha\r\nb\r\nc\x1b[4;1Hfooter",
+			expect: "header\nb\nc\nfooter\n",
+		},
+		{
+			name:   "insert lines",
+			rows:   3,
+			cols:   10,
+			input:  "one\r\ntwo\x1b[1;1H\x1b[Lzero",
+			expect: "zero\none\ntwo\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := New(tt.rows, tt.cols, 100)
+			write(s, tt.input)
+			assert.Equal(t, tt.expect, s.Text(false))
+		})
+	}
+}
+
+func TestScreen_History(t *testing.T) {
+	t.Run("keeps lines scrolled off the top", func(t *testing.T) {
+		s := New(2, 10, 100)
+		write(s, "1\r\n2\r\n3\r\n4")
+
+		assert.Equal(t, "3\n4\n", s.Text(false))
+		assert.Equal(t, "1\n2\n3\n4\n", s.Text(true))
+	})
+
+	t.Run("bounds history", func(t *testing.T) {
+		s := New(2, 10, 1)
+		write(s, "1\r\n2\r\n3\r\n4")
+
+		assert.Equal(t, "2\n3\n4\n", s.Text(true))
+	})
+
+	t.Run("alternate screen does not add history", func(t *testing.T) {
+		s := New(2, 10, 100)
+		write(s, "\x1b[?1049h1\r\n2\r\n3\r\n4")
+
+		assert.Equal(t, "3\n4\n", s.Text(true))
+	})
+
+	t.Run("erase saved lines", func(t *testing.T) {
+		s := New(2, 10, 100)
+		write(s, "1\r\n2\r\n3\x1b[3J")
+
+		assert.Equal(t, "2\n3\n", s.Text(true))
+	})
+}
+
+func TestScreen_Write(t *testing.T) {
+	t.Run("sequences split across writes", func(t *testing.T) {
+		s := New(2, 20, 100)
+		for _, chunk := range []string{"a\x1b", "[3", "1mb\xe2", "\x9c", "\x93"} {
+			write(s, chunk)
+		}
+
+		assert.Equal(t, "ab✓\n", s.Text(false))
+		assert.Equal(t, "a\x1b[0;31mb✓\x1b[0m\n", s.ANSI(false))
+	})
+
+	t.Run("reports full length written", func(t *testing.T) {
+		s := New(2, 20, 100)
+		n, err := s.Write([]byte("abc\xe2"))
+
+		assert.NoError(t, err)
+		assert.Equal(t, 4, n)
+	})
+}
+
+func TestScreen_ANSI(t *testing.T) {
+	s := New(2, 40, 100)
+	write(s, "\x1b[1;32mok\x1b[0m plain \x1b[38;5;200mpink\x1b[48;2;1;2;3m!\x1b[m")
+
+	assert.Equal(t, "\x1b[0;1;32mok\x1b[0m plain \x1b[0;38;5;200mpink\x1b[0;38;5;200;48;2;1;2;3m!\x1b[0m\n", s.ANSI(false))
+}
+
+func TestScreen_Resize(t *testing.T) {
+	t.Run("shrinking keeps the cursor line", func(t *testing.T) {
+		s := New(4, 10, 100)
+		write(s, "1\r\n2\r\n3\r\n4")
+		s.Resize(2, 10)
+
+		assert.Equal(t, "3\n4\n", s.Text(false))
+		assert.Equal(t, "1\n2\n3\n4\n", s.Text(true))
+	})
+
+	t.Run("growing pads with blank cells", func(t *testing.T) {
+		s := New(2, 4, 100)
+		write(s, "abcd")
+		s.Resize(3, 8)
+		write(s, "\x1b[1;5Hef")
+
+		rows, cols := s.Size()
+		assert.Equal(t, 3, rows)
+		assert.Equal(t, 8, cols)
+		assert.Equal(t, "abcdef\n", s.Text(false))
+	})
+
+	t.Run("shrinking clamps saved cursors", func(t *testing.T) {
+		for _, tt := range []struct {
+			name          string
+			save, restore string
+		}{
+			{name: "DECSC", save: "\x1b7", restore: "\x1b8"},
+			{name: "SCOSC", save: "\x1b[s", restore: "\x1b[u"},
+			{name: "alt screen", save: "\x1b[?1049h", restore: "\x1b[?1049l"},
+		} {
+			t.Run(tt.name, func(t *testing.T) {
+				s := New(24, 80, 100)
+				write(s, "\x1b[24;80H"+tt.save)
+				s.Resize(10, 40)
+
+				assert.NotPanics(t, func() { write(s, tt.restore+"x") })
+				assert.Equal(t, strings.Repeat("\n", 9)+strings.Repeat(" ", 39)+"x\n", s.Text(false))
+			})
+		}
+	})
+}
+
+func TestApplySGR(t *testing.T) {
+	tests := []struct {
+		name   string
+		params []int
+		expect Attr
+	}{
+		{"reset", nil, Attr{}},
+		{"bold red", []int{1, 31}, Attr{Fg: Palette(1), Flags: AttrBold}},
+		{"bright background", []int{102}, Attr{Bg: Palette(10)}},
+		{"palette", []int{38, 5, 123}, Attr{Fg: Palette(123)}},
+		{"truecolor", []int{48, 2, 10, 20, 30}, Attr{Bg: RGB(10, 20, 30)}},
+		{"truncated extended color", []int{38, 2, 1}, Attr{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expect, applySGR(Attr{}, tt.params))
+		})
+	}
+}